@@ -2,25 +2,42 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log/slog"
+	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/SergeyBogomolovv/l0-order-service/internal/app"
 	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
 	"github.com/SergeyBogomolovv/l0-order-service/internal/handler"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
 	"github.com/SergeyBogomolovv/l0-order-service/internal/postgres"
 	"github.com/SergeyBogomolovv/l0-order-service/internal/repo"
 	"github.com/SergeyBogomolovv/l0-order-service/internal/service"
 	"github.com/SergeyBogomolovv/l0-order-service/pkg/cache"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/fieldcrypt"
 	"github.com/SergeyBogomolovv/l0-order-service/pkg/logger"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/ratelimit"
 	"github.com/SergeyBogomolovv/l0-order-service/pkg/trm"
+	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
 )
 
 // @title           Order Service API
 // @version         1.0
 // @description     Документация HTTP API
+// @securityDefinitions.apikey  ApiKeyAuth
+// @in                          header
+// @name                        X-API-Key
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
 func main() {
 	// optionally load config from .env
 	godotenv.Load()
@@ -42,19 +59,121 @@ func main() {
 	defer db.Close()
 	log.Info("postgres connected")
 
+	var keyring *fieldcrypt.Keyring
+	if conf.Encryption.KeyringFile != "" {
+		keyring, err = fieldcrypt.LoadKeyring(conf.Encryption.KeyringFile)
+		if err != nil {
+			panic("failed to load keyring: " + err.Error())
+		}
+	}
+
 	// init dependencies
-	orderRepo := repo.NewPostgresRepo(db)
+	orderRepo := repo.NewPostgresRepo(db, keyring)
+
+	// encrypt-pii шифрует сохраненные без шифрования доставки и перешифровывает ключи данных
+	// после ротации, затем завершает работу
+	if len(os.Args) > 1 && os.Args[1] == "encrypt-pii" {
+		if err := encryptPII(log, orderRepo, conf.Encryption.MigrationBatchSize); err != nil {
+			panic("failed to encrypt deliveries: " + err.Error())
+		}
+		return
+	}
+
 	txManager := trm.NewManager(db)
+	// ключи из Postgres кэшируются отдельно, чтобы заказы не вытесняли их
+	authCache := cache.NewLRUCache(conf.Cache.Capacity, conf.Auth.APIKeyCacheTTL)
+	trackCache := cache.NewLRUCache(conf.Cache.TrackCapacity, conf.Cache.TTL)
+	// сводки кэшируются отдельно от заказов и сбрасываются на всех репликах через Postgres NOTIFY
+	summaryCache := cache.NewLRUCache(conf.Cache.SummaryCapacity, conf.Cache.TTL)
 	cache := cache.NewLRUCache(conf.Cache.Capacity, conf.Cache.TTL)
-	orderService := service.NewOrderService(log, txManager, orderRepo, cache)
+	orderService := service.NewOrderService(log, txManager, orderRepo, cache, trackCache)
+	orderFeed := service.NewOrderFeed(log, orderRepo, conf.Stream.Buffer, conf.Stream.History)
+	orderWaiter := service.NewOrderWaiter()
+	webhookService := service.NewWebhookService(orderRepo)
+	webhookDispatcher := service.NewWebhookDispatcher(log, conf.Webhooks, orderRepo, handler.EncodeWebhookEvent)
+	orderService.OnSave(orderWaiter.Publish, webhookDispatcher.Publish)
+	orderService.OnUpdate(webhookDispatcher.PublishUpdated)
+	// замененный на другой реплике заказ удаляется из кэша по Postgres NOTIFY
+	updateListener := postgres.NewListener(log, conf.Postgres, repo.OrderUpdatedChannel, orderService)
+	// другие реплики сообщают о сохраненных заказах через Postgres NOTIFY
+	orderListener := postgres.NewListener(log, conf.Postgres, repo.OrderSavedChannel, orderWaiter)
+	// лента потоков наполняется только из NOTIFY, чтобы события и их ID совпадали на всех репликах.
+	// Заказы загружаются из базы мимо кэша, чтобы лента не вытесняла из него запрашиваемые заказы.
+	feedListener := postgres.NewListener(log, conf.Postgres, repo.OrderSavedChannel, orderFeed)
+	idempotencyService := service.NewIdempotencyService(log, orderRepo, conf.HTTP.IdempotencyTTL)
+	var customerCache service.SummaryCache
+	if conf.Cache.CustomerSummaries {
+		customerCache = summaryCache
+	}
+	customerService := service.NewCustomerService(log, orderRepo, customerCache)
+	customerListener := postgres.NewListener(log, conf.Postgres, repo.CustomerOrdersChannel, customerService)
+	authService, err := service.NewAuthService(conf.Auth, orderRepo, authCache)
+	if err != nil {
+		panic("failed to init auth service: " + err.Error())
+	}
+	var authenticator middleware.Authenticator = authService
+	if !conf.Auth.Enabled {
+		authenticator = middleware.AllowAll{}
+	}
+	piiService := service.NewPIIService(log, conf.PII, orderRepo)
+
+	// export выгружает заказы в файл, прерванная выгрузка продолжается повторным запуском, затем завершает работу
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		_ = piiService.Start(context.Background())
+		err := exportOrders(log, conf.Export, orderService, piiService, os.Args[2:])
+		// запись о раскрытии данных выгрузкой попадает в журнал до выхода
+		piiService.Close()
+		if err != nil {
+			_ = db.Close()
+			os.Exit(exportExitCode(err))
+		}
+		return
+	}
+
+	rateLimitStore := newRateLimitStore(conf.RateLimit, db)
 	kafkaHandler := handler.NewKafkaHandler(log, conf.Kafka, orderService)
-	httpHandler := handler.NewHTTPHandler(log, orderService)
+	httpHandler := handler.NewHTTPHandler(log, conf.HTTP, orderService, idempotencyService, orderWaiter, piiService)
+	customerHandler := handler.NewCustomerHandler(log, customerService, piiService)
+	webhookHandler := handler.NewWebhookHandler(log, webhookService)
+	apiKeyHandler := handler.NewAPIKeyHandler(log, authService)
+	grpcHandler := handler.NewGRPCHandler(log, orderService, orderFeed, piiService)
+	streamHandler := handler.NewStreamHandler(log, conf.Stream, conf.Cors, orderFeed, piiService)
+	exportHandler := handler.NewExportHandler(log, conf.Export, orderService, piiService)
+	graphqlHandler, err := handler.NewGraphQLHandler(log, conf.GraphQL, orderService, piiService)
+	if err != nil {
+		panic("failed to init graphql handler: " + err.Error())
+	}
+	cacheWarmUp := &cacheWarmUpAdapter{svc: orderService, count: conf.Cache.Capacity}
+	healthService := service.NewHealthService(conf.Health.CheckTimeout)
+	healthService.AddCheck("postgres", db.PingContext)
+	healthService.AddCheck("kafka", kafkaHandler.PingBrokers)
+	healthService.AddCheck("consumer", kafkaHandler.CheckConsumer)
+	healthService.AddCheck("cache", cacheWarmUp.Check)
+	healthHandler := handler.NewHealthHandler(healthService)
+	adminHandler := handler.NewAdminHandler(log, map[string]handler.CacheAdmin{
+		"orders":    cache,
+		"tracks":    trackCache,
+		"summaries": summaryCache,
+		"auth":      authCache,
+	}, kafkaHandler)
 
 	// init app
-	app := app.New(log, conf)
-	app.SetHTTPHandlers(httpHandler)
+	var limiter middleware.RateLimiter
+	if conf.RateLimit.Enabled {
+		limiter = rateLimitStore
+	}
+	app := app.New(log, conf, authenticator, limiter)
+	app.SetHTTPHandlers(
+		httpHandler, customerHandler, webhookHandler, apiKeyHandler, graphqlHandler, streamHandler, exportHandler,
+	)
+	app.SetAdminHandlers(adminHandler)
+	app.SetProbeHandlers(healthHandler)
+	app.SetGRPCHandlers(grpcHandler)
 	app.SetConsumers(kafkaHandler)
-	app.SetStarters(cache, cacheWarmUpAdapter{svc: orderService, count: conf.Cache.Capacity})
+	app.SetStarters(
+		cache, trackCache, summaryCache, authCache, orderListener, feedListener, updateListener, customerListener,
+		orderFeed, webhookDispatcher, rateLimitStore, idempotencyService, piiService, cacheWarmUp,
+	)
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
 	defer stop()
@@ -66,6 +185,18 @@ func main() {
 		}
 	}()
 
+	go func() {
+		if err := app.RunAdminServer(ctx); err != nil {
+			panic("failed to run admin server: " + err.Error())
+		}
+	}()
+
+	go func() {
+		if err := app.RunGRPCServer(ctx); err != nil {
+			panic("failed to run grpc server: " + err.Error())
+		}
+	}()
+
 	if err := app.StartStarters(ctx); err != nil {
 		panic("failed to start starters: " + err.Error())
 	}
@@ -75,12 +206,24 @@ func main() {
 	<-ctx.Done()
 
 	// graceful shutdown
+	// балансировщик должен увидеть неготовность раньше, чем сервер перестанет принимать соединения
+	healthService.Shutdown()
+	log.Info("waiting before shutdown", slog.Duration("delay", conf.Health.ShutdownDelay))
+	time.Sleep(conf.Health.ShutdownDelay)
+	streamHandler.Close()
 	if err := app.StopServer(); err != nil {
 		log.Error("failed to stop server", slog.Any("error", err))
 	}
+	app.StopGRPCServer()
 	if err := app.CloseConsumers(); err != nil {
 		log.Error("failed to close consumers", slog.Any("error", err))
 	}
+	// административный сервер останавливается последним, чтобы метрики и пробы были доступны до конца остановки
+	if err := app.StopAdminServer(); err != nil {
+		log.Error("failed to stop admin server", slog.Any("error", err))
+	}
+	// журнал аудита дописывается, когда запросов, раскрывающих данные, уже не осталось
+	piiService.Close()
 }
 
 type warmUpper interface {
@@ -90,8 +233,73 @@ type warmUpper interface {
 type cacheWarmUpAdapter struct {
 	svc   warmUpper
 	count int
+	done  atomic.Bool
+}
+
+func (a *cacheWarmUpAdapter) Start(ctx context.Context) error {
+	if err := a.svc.WarmUpCache(ctx, a.count); err != nil {
+		return err
+	}
+	a.done.Store(true)
+	return nil
+}
+
+// Check готовность наступает после прогрева кэша, иначе первые запросы пойдут мимо кэша в Postgres
+func (a *cacheWarmUpAdapter) Check(context.Context) error {
+	if !a.done.Load() {
+		return errors.New("cache warm-up is not finished")
+	}
+	return nil
 }
 
-func (a cacheWarmUpAdapter) Start(ctx context.Context) error {
-	return a.svc.WarmUpCache(ctx, a.count)
+func encryptPII(log *slog.Logger, orderRepo *repo.PostgresRepo, batchSize int) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	// уже обработанные строки остаются зашифрованными, повторный запуск продолжит с оставшихся
+	count, err := orderRepo.EncryptDeliveries(ctx, batchSize)
+	log.Info("deliveries encrypted", slog.Int("count", count))
+	return err
+}
+
+func exportOrders(
+	log *slog.Logger, cfg config.Export, svc handler.OrderExporter, pii handler.PIIPolicy, args []string,
+) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	return handler.NewExportCommand(log, cfg, svc, pii).Run(ctx, args)
+}
+
+// exportExitCode печатает ошибку команды export и возвращает код выхода: 2 при неверных аргументах, как у flag
+func exportExitCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return 2
+	}
+	fmt.Fprintln(os.Stderr, "export:", err)
+	if errors.Is(err, handler.ErrExportUsage) {
+		return 2
+	}
+	return 1
+}
+
+type rateLimiterStarter interface {
+	middleware.RateLimiter
+	app.Starter
+}
+
+// newRateLimitStore хранилище лимитов частоты запросов, redis и postgres делят лимит между репликами
+func newRateLimitStore(cfg config.RateLimit, db *sqlx.DB) rateLimiterStarter {
+	switch cfg.Backend {
+	case "redis":
+		opts, err := redis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			panic("invalid redis url: " + err.Error())
+		}
+		return ratelimit.NewRedisStore(redis.NewClient(opts))
+	case "postgres":
+		return ratelimit.NewPostgresStore(db)
+	default:
+		return ratelimit.NewMemoryStore()
+	}
 }
@@ -1,287 +0,0 @@
-// Package docs Code generated by swaggo/swag. DO NOT EDIT
-package docs
-
-import "github.com/swaggo/swag"
-
-const docTemplate = `{
-    "schemes": {{ marshal .Schemes }},
-    "swagger": "2.0",
-    "info": {
-        "description": "{{escape .Description}}",
-        "title": "{{.Title}}",
-        "contact": {},
-        "version": "{{.Version}}"
-    },
-    "host": "{{.Host}}",
-    "basePath": "{{.BasePath}}",
-    "paths": {
-        "/order/{order_uid}": {
-            "get": {
-                "description": "Возвращает информацию о заказе по его уникальному идентификатору",
-                "tags": [
-                    "orders"
-                ],
-                "summary": "Получить заказ по UID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Уникальный идентификатор заказа",
-                        "name": "order_uid",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/handler.Order"
-                        }
-                    },
-                    "400": {
-                        "description": "Ошибка валидации",
-                        "schema": {
-                            "$ref": "#/definitions/utils.ValidationErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Заказ не найден",
-                        "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
-                        "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
-                        }
-                    }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "handler.Delivery": {
-            "type": "object",
-            "required": [
-                "email",
-                "name",
-                "phone"
-            ],
-            "properties": {
-                "address": {
-                    "type": "string"
-                },
-                "city": {
-                    "type": "string"
-                },
-                "email": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "phone": {
-                    "type": "string"
-                },
-                "region": {
-                    "type": "string"
-                },
-                "zip": {
-                    "type": "string"
-                }
-            }
-        },
-        "handler.Item": {
-            "type": "object",
-            "required": [
-                "chrt_id",
-                "track_number"
-            ],
-            "properties": {
-                "brand": {
-                    "type": "string"
-                },
-                "chrt_id": {
-                    "type": "integer"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "nm_id": {
-                    "type": "integer"
-                },
-                "price": {
-                    "type": "integer"
-                },
-                "rid": {
-                    "type": "string"
-                },
-                "sale": {
-                    "type": "integer"
-                },
-                "size": {
-                    "type": "string"
-                },
-                "status": {
-                    "type": "integer"
-                },
-                "total_price": {
-                    "type": "integer"
-                },
-                "track_number": {
-                    "type": "string"
-                }
-            }
-        },
-        "handler.Order": {
-            "type": "object",
-            "required": [
-                "delivery",
-                "items",
-                "order_uid",
-                "payment",
-                "track_number"
-            ],
-            "properties": {
-                "customer_id": {
-                    "type": "string"
-                },
-                "date_created": {
-                    "type": "string"
-                },
-                "delivery": {
-                    "description": "по идее не должно быть так что ее нету",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/handler.Delivery"
-                        }
-                    ]
-                },
-                "delivery_service": {
-                    "type": "string"
-                },
-                "entry": {
-                    "type": "string"
-                },
-                "internal_signature": {
-                    "type": "string"
-                },
-                "items": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/handler.Item"
-                    }
-                },
-                "locale": {
-                    "type": "string"
-                },
-                "oof_shard": {
-                    "type": "string"
-                },
-                "order_uid": {
-                    "type": "string"
-                },
-                "payment": {
-                    "description": "по идее не должно быть так что его нету",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/handler.Payment"
-                        }
-                    ]
-                },
-                "shardkey": {
-                    "type": "string"
-                },
-                "sm_id": {
-                    "type": "integer"
-                },
-                "track_number": {
-                    "type": "string"
-                }
-            }
-        },
-        "handler.Payment": {
-            "type": "object",
-            "required": [
-                "currency",
-                "payment_dt",
-                "provider",
-                "transaction"
-            ],
-            "properties": {
-                "amount": {
-                    "type": "integer",
-                    "minimum": 0
-                },
-                "bank": {
-                    "type": "string"
-                },
-                "currency": {
-                    "type": "string"
-                },
-                "custom_fee": {
-                    "type": "integer"
-                },
-                "delivery_cost": {
-                    "type": "integer"
-                },
-                "goods_total": {
-                    "type": "integer"
-                },
-                "payment_dt": {
-                    "type": "integer"
-                },
-                "provider": {
-                    "type": "string"
-                },
-                "request_id": {
-                    "type": "string"
-                },
-                "transaction": {
-                    "type": "string"
-                }
-            }
-        },
-        "utils.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
-                }
-            }
-        },
-        "utils.ValidationErrorResponse": {
-            "type": "object",
-            "properties": {
-                "fields": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
-                },
-                "message": {
-                    "type": "string"
-                }
-            }
-        }
-    }
-}`
-
-// SwaggerInfo holds exported Swagger Info so clients can modify it
-var SwaggerInfo = &swag.Spec{
-	Version:          "1.0",
-	Host:             "",
-	BasePath:         "",
-	Schemes:          []string{},
-	Title:            "Order Service API",
-	Description:      "Документация HTTP API",
-	InfoInstanceName: "swagger",
-	SwaggerTemplate:  docTemplate,
-	LeftDelim:        "{{",
-	RightDelim:       "}}",
-}
-
-func init() {
-	swag.Register(SwaggerInfo.InstanceName(), SwaggerInfo)
-}
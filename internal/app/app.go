@@ -9,16 +9,21 @@ import (
 	"net/http"
 	"time"
 
-	// swagger docs
-	_ "github.com/SergeyBogomolovv/l0-order-service/docs"
+	"github.com/SergeyBogomolovv/l0-order-service/docs"
 	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
 	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/problem"
 	"github.com/go-chi/chi/v5"
 	chimw "github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
 type Consumer interface {
@@ -34,53 +39,179 @@ type HTTPHandler interface {
 	Init(r chi.Router)
 }
 
+type GRPCHandler interface {
+	Register(s *grpc.Server)
+}
+
 type Application struct {
 	logger *slog.Logger
 
-	router    chi.Router
-	httpSrv   *http.Server
+	router  chi.Router
+	httpSrv *http.Server
+	// legacy помечает устаревшими пути без версии
+	legacy    func(http.Handler) http.Handler
 	consumers []Consumer
 	starters  []Starter
+
+	// adminRouter маршруты административного сервера, admin - те из них, что требуют аутентификации
+	adminRouter chi.Router
+	admin       chi.Router
+	adminSrv    *http.Server
+
+	grpcSrv    *grpc.Server
+	grpcAddr   string
+	grpcHealth *health.Server
 }
 
-func New(logger *slog.Logger, cfg config.Config) *Application {
+// New создает приложение, limiter хранилище лимитов частоты запросов, nil если лимиты выключены
+func New(
+	logger *slog.Logger, cfg config.Config, auth middleware.Authenticator, limiter middleware.RateLimiter,
+) *Application {
 	router := chi.NewRouter()
 	router.Use(chimw.RequestID)
 	router.Use(chimw.RealIP)
 	router.Use(chimw.Recoverer)
-	router.Use(chimw.Timeout(30 * time.Second))
+	router.Use(middleware.Timeout(30 * time.Second))
 	router.Use(cors.Handler(cors.Options{
 		AllowedOrigins: cfg.Cors.AllowedOrigins,
-		AllowedMethods: []string{"GET", "OPTIONS"},
-		AllowedHeaders: []string{"Accept", "Content-Type", "Authorization"},
-		ExposedHeaders: []string{"Link"},
+		AllowedMethods: []string{"GET", "POST", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{
+			"Accept", "Content-Type", "Authorization", middleware.APIKeyHeader,
+			"Idempotency-Key", "If-None-Match", "If-Modified-Since", "Last-Event-ID",
+		},
+		ExposedHeaders: []string{
+			"Link", "Idempotent-Replayed", "ETag", "Deprecation", "Sunset",
+			"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After",
+		},
 	}))
 	router.Use(middleware.Metrics)
 	router.Use(middleware.Logger(logger))
+	if cfg.Concurrency.Enabled {
+		router.Use(middleware.NewConcurrencyLimiter(logger, cfg.Concurrency).Handler)
+	}
+	router.Use(middleware.Compress(cfg.Compression))
+	router.Use(middleware.Authenticate(logger, auth))
+	if limiter != nil {
+		router.Use(middleware.RateLimit(logger, cfg.RateLimit, limiter, router))
+	}
+
+	router.NotFound(notFound)
+	router.MethodNotAllowed(methodNotAllowed)
 
 	if cfg.Env != "production" {
-		router.Get("/swagger/*", httpSwagger.WrapHandler)
-		router.Mount("/debug", chimw.Profiler())
+		// документация версий генерируется из одних аннотаций, пути в них без префикса версии
+		docs.SwaggerInfov1.BasePath = middleware.APIv1.Prefix()
+		docs.SwaggerInfov2.BasePath = middleware.APIv2.Prefix()
+		docs.SwaggerInfov2.Version = "2.0"
+		for _, v := range middleware.APIVersions {
+			router.Get("/swagger"+v.Prefix()+"/*", httpSwagger.Handler(httpSwagger.InstanceName(v.String())))
+		}
 	}
 
-	router.Mount("/metrics", promhttp.Handler())
-
 	httpSrv := &http.Server{
 		Handler:           router,
 		Addr:              net.JoinHostPort(cfg.HTTP.Host, cfg.HTTP.Port),
 		ReadHeaderTimeout: 30 * time.Second,
 	}
 
+	// у административного сервера нет таймаута запроса: профиль CPU снимается дольше 30 секунд
+	adminRouter := chi.NewRouter()
+	adminRouter.Use(chimw.RequestID)
+	adminRouter.Use(chimw.Recoverer)
+	adminRouter.Use(middleware.Logger(logger))
+	adminRouter.NotFound(notFound)
+	adminRouter.MethodNotAllowed(methodNotAllowed)
+	admin := adminRouter.With()
+	if cfg.Admin.AuthEnabled {
+		admin = adminRouter.With(middleware.Authenticate(logger, auth), middleware.RequireScope(entities.ScopeAdmin))
+	}
+	admin.Mount("/metrics", promhttp.Handler())
+	admin.Mount("/debug", chimw.Profiler())
+
+	adminSrv := &http.Server{
+		Handler:           adminRouter,
+		Addr:              net.JoinHostPort(cfg.Admin.Host, cfg.Admin.Port),
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+
+	// gRPC API только читает заказы, поэтому каждый вызов требует права orders:read
+	grpcSrv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			middleware.UnaryRecoverer(logger), middleware.UnaryLogger(logger),
+			middleware.UnaryAuth(logger, auth, entities.ScopeOrdersRead),
+		),
+		grpc.ChainStreamInterceptor(
+			middleware.StreamRecoverer(logger), middleware.StreamLogger(logger),
+			middleware.StreamAuth(logger, auth, entities.ScopeOrdersRead),
+		),
+	)
+	grpcHealth := health.NewServer()
+	healthpb.RegisterHealthServer(grpcSrv, grpcHealth)
+	if cfg.GRPC.Reflection {
+		reflection.Register(grpcSrv)
+	}
+
 	return &Application{
-		logger:  logger,
-		httpSrv: httpSrv,
-		router:  router,
+		logger:      logger,
+		httpSrv:     httpSrv,
+		router:      router,
+		legacy:      middleware.Deprecated(cfg.HTTP.LegacyDeprecatedAt, cfg.HTTP.LegacySunset),
+		adminRouter: adminRouter,
+		admin:       admin,
+		adminSrv:    adminSrv,
+		grpcSrv:     grpcSrv,
+		grpcAddr:    net.JoinHostPort(cfg.GRPC.Host, cfg.GRPC.Port),
+		grpcHealth:  grpcHealth,
 	}
 }
 
+func notFound(w http.ResponseWriter, r *http.Request) {
+	problem.Write(w, r, problem.CodeNotFound)
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	problem.Write(w, r, problem.CodeMethodNotAllowed)
+}
+
+// SetHTTPHandlers регистрирует обработчики API под префиксом каждой версии и на путях без версии,
+// которые отвечают как v1 и помечены устаревшими
 func (a *Application) SetHTTPHandlers(handlers ...HTTPHandler) {
+	for _, v := range middleware.APIVersions {
+		a.router.Route(v.Prefix(), func(r chi.Router) {
+			r.Use(middleware.Version(v))
+			for _, h := range handlers {
+				h.Init(r)
+			}
+		})
+	}
+
+	a.router.Group(func(r chi.Router) {
+		r.Use(middleware.Version(middleware.APIv1), a.legacy)
+		for _, h := range handlers {
+			h.Init(r)
+		}
+	})
+}
+
+// SetAdminHandlers регистрирует обработчики на административном сервере,
+// с ADMIN_AUTH_ENABLED они доступны только клиентам с правом admin
+func (a *Application) SetAdminHandlers(handlers ...HTTPHandler) {
+	for _, h := range handlers {
+		h.Init(a.admin)
+	}
+}
+
+// SetProbeHandlers регистрирует пробы на административном сервере, они всегда доступны без аутентификации,
+// потому что kubelet не передает учетные данные
+func (a *Application) SetProbeHandlers(handlers ...HTTPHandler) {
+	for _, h := range handlers {
+		h.Init(a.adminRouter)
+	}
+}
+
+func (a *Application) SetGRPCHandlers(handlers ...GRPCHandler) {
 	for _, h := range handlers {
-		h.Init(a.router)
+		h.Register(a.grpcSrv)
 	}
 }
 
@@ -99,7 +230,9 @@ func (a *Application) StartConsumers(ctx context.Context) {
 }
 
 func (a *Application) StartStarters(ctx context.Context) error {
-	eg, ctx := errgroup.WithContext(ctx)
+	// контекст errgroup.WithContext отменяется сразу после Wait,
+	// а стартеры запускают фоновые задачи, которые должны работать до остановки приложения
+	var eg errgroup.Group
 
 	for _, s := range a.starters {
 		eg.Go(func() error {
@@ -131,6 +264,66 @@ func (a *Application) StopServer() error {
 	return a.httpSrv.Shutdown(ctx)
 }
 
+func (a *Application) RunAdminServer(ctx context.Context) error {
+	a.logger.InfoContext(ctx, "starting admin server", slog.String("addr", a.adminSrv.Addr))
+	err := a.adminSrv.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to start admin server: %w", err)
+	}
+
+	return nil
+}
+
+func (a *Application) StopAdminServer() error {
+	const shutdownTimeout = 5 * time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	a.logger.Info("stopping admin server")
+
+	return a.adminSrv.Shutdown(ctx)
+}
+
+func (a *Application) RunGRPCServer(ctx context.Context) error {
+	lis, err := net.Listen("tcp", a.grpcAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen grpc address: %w", err)
+	}
+
+	a.logger.InfoContext(ctx, "starting grpc server", slog.String("addr", a.grpcAddr))
+	for name := range a.grpcSrv.GetServiceInfo() {
+		a.grpcHealth.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+	if err := a.grpcSrv.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return fmt.Errorf("failed to start grpc server: %w", err)
+	}
+
+	return nil
+}
+
+func (a *Application) StopGRPCServer() {
+	const shutdownTimeout = 5 * time.Second
+
+	a.logger.Info("stopping grpc server")
+
+	// клиенты, проверяющие health, перестают слать запросы до закрытия соединений
+	a.grpcHealth.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		a.grpcSrv.GracefulStop()
+		close(stopped)
+	}()
+
+	// открытые потоки WatchOrders не завершаются сами, поэтому после таймаута соединения закрываются
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		a.grpcSrv.Stop()
+	}
+}
+
 func (a *Application) CloseConsumers() error {
 	a.logger.Info("closing consumers")
 	for _, c := range a.consumers {
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"strconv"
 	"strings"
@@ -12,24 +13,204 @@ import (
 type Config struct {
 	Env  string `validate:"required,oneof=development stage production"`
 	HTTP HTTP   `validate:"required"`
+	GRPC GRPC   `validate:"required"`
+
+	Admin Admin `validate:"required"`
+
+	Auth Auth
+
+	PII PII
+
+	Encryption Encryption
+
+	GraphQL GraphQL
+
+	Stream Stream
+
+	Export Export
+
+	Webhooks Webhooks
 
 	Cache Cache `validate:"required"`
 
 	Cors CORS `validate:"required"`
 
+	RateLimit RateLimit
+
+	Concurrency Concurrency
+
+	Compression Compression
+
 	Kafka Kafka `validate:"required"`
 
+	Health Health
+
 	Postgres Postgres `validate:"required"`
 }
 
 type Cache struct {
 	Capacity int           `validate:"required,gt=0"`
 	TTL      time.Duration `validate:"required,gt=0"`
+	// TrackCapacity размер отдельного кэша связей трек номеров с UID заказов
+	TrackCapacity int `validate:"required,gt=0"`
+	// SummaryCapacity размер отдельного кэша сводок по покупателям
+	SummaryCapacity int `validate:"required,gt=0"`
+
+	CustomerSummaries bool
 }
 
 type HTTP struct {
 	Host string `validate:"required,hostname|ip"`
 	Port string `validate:"required,gt=0,lte=65535"`
+
+	IdempotencyTTL time.Duration `validate:"gt=0"`
+
+	// CacheControl значение заголовка Cache-Control для ответов с заказом, пустое - заголовок не отправляется
+	CacheControl string
+
+	// MaxWait наибольшее значение параметра wait, должно быть меньше таймаута запроса
+	MaxWait time.Duration `validate:"gt=0,lt=30s"`
+
+	// LegacyDeprecatedAt и LegacySunset значения Deprecation и Sunset для путей без версии:
+	// с какого дня они устарели и после какого могут быть отключены
+	LegacyDeprecatedAt time.Time `validate:"required"`
+	LegacySunset       time.Time `validate:"required,gtfield=LegacyDeprecatedAt"`
+}
+
+// Admin административный HTTP сервер: метрики, pprof, пробы, управление кэшами и чтением заказов.
+// Публичный порт отдает только API, административный не должен быть доступен снаружи.
+type Admin struct {
+	Host string `validate:"required,hostname|ip"`
+	Port string `validate:"required,gt=0,lte=65535"`
+
+	// AuthEnabled требовать ключ или токен с правом admin для всего, кроме проб. В production обязательно.
+	AuthEnabled bool
+}
+
+// RateLimit ограничение частоты запросов к HTTP API, у каждого клиента своя корзина токенов на маршрут
+type RateLimit struct {
+	Enabled bool
+	// Backend memory - у каждой реплики свой лимит, redis и postgres - общий для всех реплик
+	Backend  string `validate:"oneof=memory redis postgres"`
+	RedisURL string `validate:"required_if=Backend redis,omitempty,url"`
+	// Rate запросов в секунду и Burst запросов подряд для маршрутов без своего лимита
+	Rate  float64 `validate:"gt=0"`
+	Burst int     `validate:"gt=0"`
+	// Routes лимиты отдельных маршрутов
+	Routes []RouteRateLimit `validate:"dive"`
+}
+
+// Concurrency адаптивное ограничение числа одновременных запросов к HTTP API
+type Concurrency struct {
+	Enabled      bool
+	InitialLimit int `validate:"gtefield=MinLimit,ltefield=MaxLimit"`
+	MinLimit     int `validate:"gt=0"`
+	MaxLimit     int `validate:"gtefield=MinLimit"`
+	// LatencyTarget ответы дольше считаются признаком перегрузки
+	LatencyTarget time.Duration `validate:"gt=0"`
+	// Backoff во сколько раз уменьшается лимит при перегрузке
+	Backoff float64 `validate:"gt=0,lt=1"`
+	// CriticalPaths префиксы путей или маршруты с методом, запросы к которым никогда не отклоняются
+	CriticalPaths []string
+	// LowPriorityPaths префиксы путей или маршруты с методом ("GET /orders"), которым доступна
+	// только доля LowPriorityShare лимита. По умолчанию это поиск заказов и GraphQL.
+	LowPriorityPaths []string
+	LowPriorityShare float64 `validate:"gt=0,lte=1"`
+}
+
+type RouteRateLimit struct {
+	// Route метод и шаблон маршрута, например "GET /order/{order_uid}"
+	Route string  `validate:"required"`
+	Rate  float64 `validate:"gt=0"`
+	Burst int     `validate:"gt=0"`
+}
+
+type GRPC struct {
+	Host string `validate:"required,hostname|ip"`
+	Port string `validate:"required,gt=0,lte=65535"`
+
+	Reflection bool
+}
+
+// Stream настройки ленты сохраненных заказов для SSE, WebSocket и WatchOrders
+type Stream struct {
+	// Buffer сколько заказов может накопиться у подписчика, прежде чем он будет отключен
+	Buffer int `validate:"gt=0"`
+	// History сколько последних событий хранится для возобновления по Last-Event-ID
+	History int `validate:"gte=0"`
+	// Heartbeat как часто открытому потоку отправляется пустое сообщение
+	Heartbeat time.Duration `validate:"gt=0"`
+	// WriteTimeout сколько ждать записи в соединение, прежде чем отключить клиента
+	WriteTimeout time.Duration `validate:"gt=0"`
+}
+
+// Export выгрузка заказов через GET /orders/export и команду export
+type Export struct {
+	// BatchSize сколько заказов читается из курсора Postgres и держится в памяти за раз
+	BatchSize int `validate:"gt=0"`
+	// WriteTimeout сколько ждать, пока HTTP клиент примет очередную пачку, курсор все это время держит транзакцию
+	WriteTimeout time.Duration `validate:"gt=0"`
+}
+
+// Auth настройки аутентификации HTTP API
+type Auth struct {
+	// Enabled false - все запросы выполняются с правами admin, допустимо только вне production
+	Enabled bool
+	// APIKeysFile JSON файл с ключами в дополнение к ключам из Postgres
+	APIKeysFile string `validate:"omitempty,file"`
+	// APIKeyCacheTTL сколько ключ из Postgres действует после отзыва на других репликах
+	APIKeyCacheTTL time.Duration `validate:"gt=0"`
+
+	// JWTSecret ключ HS256, пустой - HS256 токены не принимаются
+	JWTSecret string
+	// JWKSFile открытые ключи RS256, пустой - RS256 токены не принимаются
+	JWKSFile    string `validate:"omitempty,file"`
+	JWTIssuer   string
+	JWTAudience string
+}
+
+// PII политика маскирования персональных данных получателя для клиентов без права pii:read
+type PII struct {
+	// MaskedFields поля доставки, которые маскируются
+	MaskedFields []string `validate:"dive,oneof=name phone zip city address region email"`
+	// AuditQueueSize сколько записей журнала аудита может ждать вставки. Когда очередь заполнена,
+	// данные без маскирования не выдаются
+	AuditQueueSize int `validate:"gt=0"`
+	// AuditFlushInterval как часто накопленные записи журнала вставляются в Postgres
+	AuditFlushInterval time.Duration `validate:"gt=0"`
+}
+
+// Encryption шифрование персональных данных доставок в Postgres
+type Encryption struct {
+	// KeyringFile JSON файл связки ключей, пустой если шифрование выключено
+	KeyringFile string `validate:"omitempty,file"`
+	// MigrationBatchSize сколько строк шифрует за раз команда encrypt-pii
+	MigrationBatchSize int `validate:"gt=0"`
+}
+
+// Webhooks настройки доставки событий вебхукам партнеров
+type Webhooks struct {
+	// Workers сколько доставок выполняется одновременно
+	Workers int `validate:"gt=0"`
+	// QueueSize сколько событий может ждать отправки, новые события сверх очереди отбрасываются
+	QueueSize int           `validate:"gt=0"`
+	Timeout   time.Duration `validate:"gt=0"`
+	// MaxAttempts попыток доставки одного события, включая первую
+	MaxAttempts int `validate:"gt=0"`
+	// задержка перед повтором удваивается после каждой попытки, начиная с InitialBackoff, но не больше MaxBackoff
+	InitialBackoff time.Duration `validate:"gt=0"`
+	MaxBackoff     time.Duration `validate:"gtefield=InitialBackoff"`
+	// RetryPollInterval как часто из Postgres забираются повторы, время которых наступило
+	RetryPollInterval time.Duration `validate:"gt=0"`
+	// DisableAfter после стольких недоставленных подряд событий вебхук выключается
+	DisableAfter int `validate:"gt=0"`
+	// DeliveryRetention сколько хранятся записи журнала доставок, записи с запланированным повтором не удаляются
+	DeliveryRetention time.Duration `validate:"gt=0"`
+}
+
+type GraphQL struct {
+	// MaxComplexity запросы со сложностью выше отклоняются до выполнения
+	MaxComplexity int `validate:"gt=0"`
 }
 
 type Kafka struct {
@@ -39,6 +220,16 @@ type Kafka struct {
 
 	ReaderMaxWait time.Duration `validate:"gt=0"`
 	BatchTimeout  time.Duration `validate:"gte=0"`
+	// StallTimeout если одно сообщение обрабатывается дольше, сервис считается неготовым
+	StallTimeout time.Duration `validate:"gt=0"`
+}
+
+// Health настройки проверок готовности
+type Health struct {
+	// CheckTimeout ограничение на каждую проверку зависимости
+	CheckTimeout time.Duration `validate:"gt=0"`
+	// ShutdownDelay сколько сервис отвечает неготовым перед остановкой, чтобы балансировщик успел убрать его
+	ShutdownDelay time.Duration `validate:"gte=0"`
 }
 
 type Postgres struct {
@@ -55,6 +246,15 @@ type Postgres struct {
 	ConnMaxLifetime time.Duration `validate:"gte=0"`
 }
 
+type Compression struct {
+	Enabled bool
+	// ответы меньше MinSize байт отдаются без сжатия
+	MinSize int `validate:"gte=0"`
+	// порядок определяет приоритет при одинаковом q в Accept-Encoding
+	Encodings    []string `validate:"required_if=Enabled true,dive,oneof=br zstd gzip"`
+	ContentTypes []string `validate:"required_if=Enabled true"`
+}
+
 type CORS struct {
 	AllowedOrigins []string `validate:"required,min=1,dive,url"`
 }
@@ -66,17 +266,125 @@ func New() Config {
 		HTTP: HTTP{
 			Host: env("HOST", "localhost"),
 			Port: env("PORT", "8080"),
+
+			IdempotencyTTL: envDuration("HTTP_IDEMPOTENCY_TTL", 24*time.Hour),
+			CacheControl:   env("HTTP_CACHE_CONTROL", "private, no-cache"),
+			MaxWait:        envDuration("HTTP_MAX_WAIT", 25*time.Second),
+
+			LegacyDeprecatedAt: envDate("HTTP_LEGACY_DEPRECATED_AT", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)),
+			LegacySunset:       envDate("HTTP_LEGACY_SUNSET", time.Date(2027, 5, 1, 0, 0, 0, 0, time.UTC)),
+		},
+
+		Admin: Admin{
+			Host:        env("ADMIN_HOST", "localhost"),
+			Port:        env("ADMIN_PORT", "8081"),
+			AuthEnabled: envBool("ADMIN_AUTH_ENABLED", false),
+		},
+
+		Auth: Auth{
+			Enabled:        envBool("AUTH_ENABLED", true),
+			APIKeysFile:    env("AUTH_API_KEYS_FILE", ""),
+			APIKeyCacheTTL: envDuration("AUTH_API_KEY_CACHE_TTL", time.Minute),
+			JWTSecret:      env("AUTH_JWT_SECRET", ""),
+			JWKSFile:       env("AUTH_JWKS_FILE", ""),
+			JWTIssuer:      env("AUTH_JWT_ISSUER", ""),
+			JWTAudience:    env("AUTH_JWT_AUDIENCE", ""),
+		},
+
+		PII: PII{
+			MaskedFields:       strings.Split(env("PII_MASKED_FIELDS", "name,phone,address,email"), ","),
+			AuditQueueSize:     envInt("PII_AUDIT_QUEUE_SIZE", 10000),
+			AuditFlushInterval: envDuration("PII_AUDIT_FLUSH_INTERVAL", time.Second),
+		},
+
+		Encryption: Encryption{
+			KeyringFile:        env("ENCRYPTION_KEYRING_FILE", ""),
+			MigrationBatchSize: envInt("ENCRYPTION_MIGRATION_BATCH_SIZE", 500),
+		},
+
+		RateLimit: RateLimit{
+			Enabled:  envBool("RATE_LIMIT_ENABLED", true),
+			Backend:  env("RATE_LIMIT_BACKEND", "memory"),
+			RedisURL: env("RATE_LIMIT_REDIS_URL", ""),
+			Rate:     envFloat("RATE_LIMIT_RATE", 50),
+			Burst:    envInt("RATE_LIMIT_BURST", 100),
+			Routes:   envRouteRateLimits("RATE_LIMIT_ROUTES"),
+		},
+
+		Concurrency: Concurrency{
+			Enabled:          envBool("CONCURRENCY_LIMIT_ENABLED", true),
+			InitialLimit:     envInt("CONCURRENCY_LIMIT_INITIAL", 100),
+			MinLimit:         envInt("CONCURRENCY_LIMIT_MIN", 10),
+			MaxLimit:         envInt("CONCURRENCY_LIMIT_MAX", 1000),
+			LatencyTarget:    envDuration("CONCURRENCY_LIMIT_LATENCY_TARGET", 500*time.Millisecond),
+			Backoff:          envFloat("CONCURRENCY_LIMIT_BACKOFF", 0.9),
+			CriticalPaths:    strings.Split(env("CONCURRENCY_LIMIT_CRITICAL_PATHS", "/admin"), ","),
+			LowPriorityPaths: strings.Split(env("CONCURRENCY_LIMIT_LOW_PRIORITY_PATHS", "GET /orders,/graphql"), ","),
+			LowPriorityShare: envFloat("CONCURRENCY_LIMIT_LOW_PRIORITY_SHARE", 0.5),
+		},
+
+		GRPC: GRPC{
+			Host: env("GRPC_HOST", "localhost"),
+			Port: env("GRPC_PORT", "50051"),
+
+			Reflection: envBool("GRPC_REFLECTION", true),
+		},
+
+		Stream: Stream{
+			Buffer:       envInt("STREAM_BUFFER", 256),
+			History:      envInt("STREAM_HISTORY", 1000),
+			Heartbeat:    envDuration("STREAM_HEARTBEAT", 15*time.Second),
+			WriteTimeout: envDuration("STREAM_WRITE_TIMEOUT", 10*time.Second),
+		},
+
+		Export: Export{
+			BatchSize:    envInt("EXPORT_BATCH_SIZE", 1000),
+			WriteTimeout: envDuration("EXPORT_WRITE_TIMEOUT", 30*time.Second),
+		},
+
+		Webhooks: Webhooks{
+			Workers:           envInt("WEBHOOK_WORKERS", 4),
+			QueueSize:         envInt("WEBHOOK_QUEUE_SIZE", 1000),
+			Timeout:           envDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			MaxAttempts:       envInt("WEBHOOK_MAX_ATTEMPTS", 6),
+			InitialBackoff:    envDuration("WEBHOOK_INITIAL_BACKOFF", 5*time.Second),
+			MaxBackoff:        envDuration("WEBHOOK_MAX_BACKOFF", 5*time.Minute),
+			RetryPollInterval: envDuration("WEBHOOK_RETRY_POLL_INTERVAL", time.Second),
+			DisableAfter:      envInt("WEBHOOK_DISABLE_AFTER", 10),
+			DeliveryRetention: envDuration("WEBHOOK_DELIVERY_RETENTION", 30*24*time.Hour),
+		},
+
+		GraphQL: GraphQL{
+			MaxComplexity: envInt("GRAPHQL_MAX_COMPLEXITY", 1000),
 		},
 
 		Cache: Cache{
 			Capacity: envInt("CACHE_CAPACITY", 1000),
 			TTL:      envDuration("CACHE_TTL", 5*time.Minute),
+
+			TrackCapacity:   envInt("CACHE_TRACK_CAPACITY", 10000),
+			SummaryCapacity: envInt("CACHE_SUMMARY_CAPACITY", 1000),
+
+			CustomerSummaries: envBool("CACHE_CUSTOMER_SUMMARIES", true),
 		},
 
 		Cors: CORS{
 			AllowedOrigins: strings.Split(env("ALLOWED_CORS_ORIGINS", "HTTP://localhost:3000"), ","),
 		},
 
+		Compression: Compression{
+			Enabled:   envBool("HTTP_COMPRESSION_ENABLED", true),
+			MinSize:   envInt("HTTP_COMPRESSION_MIN_SIZE", 1024),
+			Encodings: strings.Split(env("HTTP_COMPRESSION_ENCODINGS", "br,zstd,gzip"), ","),
+			ContentTypes: strings.Split(env("HTTP_COMPRESSION_CONTENT_TYPES",
+				"application/json,application/xml,application/msgpack,text/csv,text/plain,text/html,text/css,text/javascript,application/javascript"), ","),
+		},
+
+		Health: Health{
+			CheckTimeout:  envDuration("HEALTH_CHECK_TIMEOUT", 2*time.Second),
+			ShutdownDelay: envDuration("HEALTH_SHUTDOWN_DELAY", 5*time.Second),
+		},
+
 		Kafka: Kafka{
 			GroupID: env("KAFKA_GROUP_ID", "order-service"),
 			Topic:   env("KAFKA_TOPIC", "orders"),
@@ -84,6 +392,7 @@ func New() Config {
 
 			ReaderMaxWait: envDuration("KAFKA_READER_MAX_WAIT", 10*time.Millisecond),
 			BatchTimeout:  envDuration("KAFKA_BATCH_TIMEOUT", 10*time.Millisecond),
+			StallTimeout:  envDuration("KAFKA_STALL_TIMEOUT", time.Minute),
 		},
 
 		Postgres: Postgres{
@@ -104,7 +413,16 @@ func New() Config {
 
 func (c Config) Validate() error {
 	validate := validator.New()
-	return validate.Struct(c)
+	if err := validate.Struct(c); err != nil {
+		return err
+	}
+	if c.Env == "production" && !c.Auth.Enabled {
+		return errors.New("auth can not be disabled in production")
+	}
+	if c.Env == "production" && !c.Admin.AuthEnabled {
+		return errors.New("admin auth can not be disabled in production")
+	}
+	return nil
 }
 
 func env(key string, fallback string) string {
@@ -127,6 +445,58 @@ func envInt(key string, fallback int) int {
 	return fallback
 }
 
+func envFloat(key string, fallback float64) float64 {
+	if value, ok := os.LookupEnv(key); ok {
+		f, err := strconv.ParseFloat(value, 64)
+		if err == nil {
+			return f
+		}
+	}
+	return fallback
+}
+
+// envRouteRateLimits разбирает лимиты маршрутов вида "GET /order/{order_uid}=10:20,POST /order=5:10",
+// где 10 запросов в секунду и 20 подряд. Лимит, который не удалось разобрать, остается нулевым и не проходит Validate.
+func envRouteRateLimits(key string) []RouteRateLimit {
+	value := env(key, "")
+	if value == "" {
+		return nil
+	}
+
+	var limits []RouteRateLimit
+	for _, rule := range strings.Split(value, ",") {
+		route, limit, _ := strings.Cut(rule, "=")
+		rate, burst, _ := strings.Cut(limit, ":")
+
+		l := RouteRateLimit{Route: strings.TrimSpace(route)}
+		l.Rate, _ = strconv.ParseFloat(rate, 64)
+		l.Burst, _ = strconv.Atoi(burst)
+		limits = append(limits, l)
+	}
+	return limits
+}
+
+func envBool(key string, fallback bool) bool {
+	if value, ok := os.LookupEnv(key); ok {
+		b, err := strconv.ParseBool(value)
+		if err == nil {
+			return b
+		}
+	}
+	return fallback
+}
+
+// envDate дата в формате 2006-01-02, UTC
+func envDate(key string, fallback time.Time) time.Time {
+	if value, ok := os.LookupEnv(key); ok {
+		t, err := time.Parse(time.DateOnly, value)
+		if err == nil {
+			return t
+		}
+	}
+	return fallback
+}
+
 func envDuration(key string, fallback time.Duration) time.Duration {
 	if value, ok := os.LookupEnv(key); ok {
 		d, err := time.ParseDuration(value)
@@ -0,0 +1,58 @@
+package entities
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// Права доступа к API
+const (
+	ScopeOrdersRead  = "orders:read"
+	ScopeOrdersWrite = "orders:write"
+	// ScopePIIRead разрешает видеть персональные данные получателя без маскирования
+	ScopePIIRead = "pii:read"
+	// ScopeAdmin включает все остальные права
+	ScopeAdmin = "admin"
+)
+
+// Scopes все права, которые можно выдать ключу или токену
+var Scopes = []string{ScopeOrdersRead, ScopeOrdersWrite, ScopePIIRead, ScopeAdmin}
+
+// Principal аутентифицированный клиент API
+type Principal struct {
+	// Subject имя API ключа или sub из JWT
+	Subject string
+	Scopes  []string
+}
+
+func (p Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, ScopeAdmin) || slices.Contains(p.Scopes, scope)
+}
+
+// Credentials учетные данные из запроса, заполнено не больше одного поля
+type Credentials struct {
+	APIKey string
+	Token  string
+}
+
+func (c Credentials) Empty() bool {
+	return c.APIKey == "" && c.Token == ""
+}
+
+// APIKey ключ доступа к API. Сам ключ не хранится, только его SHA-256.
+type APIKey struct {
+	ID     int64
+	Name   string
+	Hash   string
+	Scopes []string
+
+	CreatedAt time.Time
+	// RevokedAt нулевое значение, если ключ действует
+	RevokedAt time.Time
+}
+
+var (
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrAPIKeyNotFound  = errors.New("api key not found")
+)
@@ -0,0 +1,14 @@
+package entities
+
+import "time"
+
+// ConsumerStatus состояние чтения заказов из Kafka
+type ConsumerStatus struct {
+	Running bool
+	// Paused чтение приостановлено администратором
+	Paused bool
+	// ProcessingFor сколько обрабатывается текущее сообщение, 0 пока ждем новое
+	ProcessingFor time.Duration
+	// Lag сколько сообщений осталось прочитать в партиции последнего полученного сообщения
+	Lag int64
+}
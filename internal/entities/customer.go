@@ -0,0 +1,36 @@
+package entities
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CustomerSummary сводка по заказам покупателя
+type CustomerSummary struct {
+	CustomerID string
+	OrderCount int
+	// сумма платежей по каждой валюте
+	TotalSpent   map[string]int64
+	FirstOrderAt time.Time
+	LastOrderAt  time.Time
+}
+
+var ErrCustomerNotFound = errors.New("customer not found")
+
+func (s *CustomerSummary) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(s); err != nil {
+		return nil, fmt.Errorf("failed to marshal customer summary to gob: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *CustomerSummary) Unmarshal(data []byte) error {
+	if err := gob.NewDecoder(bytes.NewBuffer(data)).Decode(s); err != nil {
+		return fmt.Errorf("failed to unmarshal customer summary: %w", err)
+	}
+	return nil
+}
@@ -2,7 +2,10 @@ package entities
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -57,6 +60,8 @@ type Order struct {
 	SmID            int
 	DateCreated     time.Time
 	OofShard        string
+	// SavedAt время сохранения заказа сервисом, задается базой, нулевое у заказов, которые еще не сохранены
+	SavedAt time.Time
 
 	// тут без указателей, потому что предполагается что эти данные всегда присутствуют
 	Delivery Delivery
@@ -64,6 +69,17 @@ type Order struct {
 	Items    []Item
 }
 
+// OrderSaveResult чем закончилось сохранение заказа. Если Created и Updated оба false,
+// заказ с тем же UID и тем же содержимым уже был сохранен и не изменился.
+type OrderSaveResult struct {
+	// Created заказ сохранен впервые
+	Created bool
+	// Updated заказ с тем же UID был сохранен раньше с другим содержимым и заменен
+	Updated bool
+	// SavedAt время сохранения, нулевое если заказ не изменился
+	SavedAt time.Time
+}
+
 var (
 	ErrOrderNotFound = errors.New("order not found")
 	ErrInvalidOrder  = errors.New("invalid order data")
@@ -78,6 +94,15 @@ func (o *Order) Marshal() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// ContentHash хеш содержимого заказа, по которому повторно полученный заказ отличается от сохраненного.
+// SavedAt задает база, поэтому в хеш не входит.
+func (o Order) ContentHash() string {
+	o.SavedAt = time.Time{}
+	data, _ := json.Marshal(o) // в заказе нет типов, которые json не умеет кодировать
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
 func (o *Order) Unmarshal(data []byte) error {
 	buf := bytes.NewBuffer(data)
 	dec := gob.NewDecoder(buf)
@@ -0,0 +1,17 @@
+package entities
+
+import "strconv"
+
+// OrderEvent сохраненный заказ с ID события в ленте.
+// ID строится из даты создания и UID заказа, поэтому одинаков на всех репликах.
+type OrderEvent struct {
+	ID    string
+	Order Order
+}
+
+func NewOrderEvent(order Order) OrderEvent {
+	return OrderEvent{
+		ID:    strconv.FormatInt(order.DateCreated.UnixMicro(), 10) + "-" + order.OrderUID,
+		Order: order,
+	}
+}
@@ -0,0 +1,40 @@
+package entities
+
+import "time"
+
+// OrderFilter параметры поиска заказов. Пустые поля не участвуют в фильтрации.
+type OrderFilter struct {
+	CustomerID      string
+	DeliveryService string
+	Locale          string
+	Currency        string
+	Provider        string
+	Brand           string
+	// Phone и Email ищутся на точное совпадение
+	Phone string
+	Email string
+
+	// CreatedFrom включительно, CreatedTo не включительно
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+
+	// по умолчанию сначала новые заказы
+	SortAsc bool
+	After   *OrderCursor
+	Limit   int
+
+	// ExcludeItems товары не загружаются, у заказов Items пустой
+	ExcludeItems bool
+}
+
+// OrderCursor позиция в списке заказов, отсортированном по дате создания и UID
+type OrderCursor struct {
+	DateCreated time.Time
+	OrderUID    string
+}
+
+type OrderPage struct {
+	Orders []Order
+	// Next nil если это последняя страница
+	Next *OrderCursor
+}
@@ -0,0 +1,15 @@
+package entities
+
+// Readiness готовность сервиса принимать трафик
+type Readiness struct {
+	Ready bool
+	// ShuttingDown сервис останавливается, зависимости не проверялись
+	ShuttingDown bool
+	Checks       []DependencyStatus
+}
+
+// DependencyStatus результат проверки одной зависимости, Err nil если она в порядке
+type DependencyStatus struct {
+	Name string
+	Err  error
+}
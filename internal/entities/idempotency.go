@@ -0,0 +1,25 @@
+package entities
+
+import "errors"
+
+// IdempotencyKey запись о запросе, выполненном с заголовком Idempotency-Key
+type IdempotencyKey struct {
+	Key         string
+	RequestHash string
+
+	// Completed false пока запрос еще обрабатывается
+	Completed bool
+	Response  IdempotentResponse
+}
+
+// IdempotentResponse сохраненный ответ, который повторно отдается клиенту
+type IdempotentResponse struct {
+	StatusCode int
+	Body       []byte
+}
+
+var (
+	ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
+	ErrIdempotencyKeyInUse    = errors.New("idempotency key is in use")
+	ErrIdempotencyKeyMismatch = errors.New("idempotency key was used with another request")
+)
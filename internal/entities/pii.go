@@ -0,0 +1,26 @@
+package entities
+
+import "time"
+
+// Поля получателя, которые политика может маскировать
+const (
+	PIIFieldName    = "name"
+	PIIFieldPhone   = "phone"
+	PIIFieldZIP     = "zip"
+	PIIFieldCity    = "city"
+	PIIFieldAddress = "address"
+	PIIFieldRegion  = "region"
+	PIIFieldEmail   = "email"
+)
+
+// PIIAccess запись журнала аудита о выдаче персональных данных без маскирования
+type PIIAccess struct {
+	// Subject клиент, которому выданы данные
+	Subject string
+	// Resource путь запроса
+	Resource string
+	// OrderUIDs заказы, данные которых раскрыты, пустой для потоков
+	OrderUIDs []string
+	RequestID string
+	CreatedAt time.Time
+}
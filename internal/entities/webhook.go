@@ -0,0 +1,88 @@
+package entities
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// Типы событий, которые отправляются вебхукам
+const (
+	// EventOrderSaved заказ сохранен впервые
+	EventOrderSaved = "order.saved"
+	// EventOrderUpdated получен заказ с UID уже сохраненного, но с другим содержимым, и сохраненный заменен им.
+	// Заказ с тем же содержимым не сохраняется повторно и событий не создает.
+	EventOrderUpdated = "order.updated"
+)
+
+// WebhookEvents все типы событий, на которые можно подписаться
+var WebhookEvents = []string{EventOrderSaved, EventOrderUpdated}
+
+// Webhook адрес партнера, на который отправляются события по заказам
+type Webhook struct {
+	ID  int64
+	URL string
+	// Secret ключ HMAC подписи событий
+	Secret string
+	// Events типы событий, на которые подписан вебхук, пустой список - все события
+	Events  []string
+	Enabled bool
+	// FailureCount сколько событий подряд не удалось доставить после всех попыток
+	FailureCount int
+	CreatedAt    time.Time
+	// DisabledAt когда вебхук был выключен, нулевое значение если он включен
+	DisabledAt time.Time
+}
+
+// Subscribed проверяет, подписан ли вебхук на событие
+func (w Webhook) Subscribed(event string) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, event)
+}
+
+// WebhookUpdate изменения вебхука, nil поля не меняются
+type WebhookUpdate struct {
+	URL     *string
+	Events  *[]string
+	Enabled *bool
+}
+
+// WebhookEvent событие по заказу, которое доставляется вебхукам
+type WebhookEvent struct {
+	// ID одинаков во всех попытках доставки, по нему получатель может отбросить повтор
+	ID        string
+	Type      string
+	CreatedAt time.Time
+	Order     Order
+}
+
+// WebhookDelivery запись журнала об одной попытке доставки события
+type WebhookDelivery struct {
+	ID        int64
+	WebhookID int64
+	EventID   string
+	Event     string
+	OrderUID  string
+	Attempt   int
+	// StatusCode код ответа получателя, 0 если ответ не получен
+	StatusCode int
+	Error      string
+	Duration   time.Duration
+	CreatedAt  time.Time
+	// NextAttemptAt когда запланирован повтор после этой попытки, нулевое значение если повтора не будет
+	NextAttemptAt time.Time
+	// Payload тело события, хранится только пока ждет повтор
+	Payload []byte
+}
+
+// WebhookRetry запланированный повтор доставки события
+type WebhookRetry struct {
+	Webhook  Webhook
+	EventID  string
+	Event    string
+	OrderUID string
+	// Attempt номер неудачной попытки, повтор будет следующей
+	Attempt int
+	Payload []byte
+}
+
+var ErrWebhookNotFound = errors.New("webhook not found")
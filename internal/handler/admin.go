@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/problem"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+type CacheAdmin interface {
+	Size() int
+	Capacity() int
+	Delete(key string)
+	Purge()
+}
+
+type ConsumerAdmin interface {
+	ConsumerStatus() entities.ConsumerStatus
+	Pause()
+	Resume()
+}
+
+// AdminHandler управление кэшами и чтением заказов. Регистрируется только на административном сервере,
+// поэтому не входит в документацию публичного API.
+type AdminHandler struct {
+	logger   *slog.Logger
+	caches   map[string]CacheAdmin
+	consumer ConsumerAdmin
+}
+
+// NewAdminHandler caches кэши по именам, под которыми они доступны в /cache/{name}
+func NewAdminHandler(logger *slog.Logger, caches map[string]CacheAdmin, consumer ConsumerAdmin) *AdminHandler {
+	return &AdminHandler{
+		logger:   logger.With(slog.String("handler", "admin")),
+		caches:   caches,
+		consumer: consumer,
+	}
+}
+
+func (h *AdminHandler) Init(r chi.Router) {
+	r.Get("/cache", h.ListCaches)
+	r.Delete("/cache/{name}", h.PurgeCache)
+	r.Delete("/cache/{name}/{key}", h.DeleteCacheKey)
+	r.Get("/consumer", h.GetConsumer)
+	r.Post("/consumer/pause", h.PauseConsumer)
+	r.Post("/consumer/resume", h.ResumeConsumer)
+}
+
+// ListCaches возвращает заполненность кэшей
+func (h *AdminHandler) ListCaches(w http.ResponseWriter, _ *http.Request) {
+	res := CachesResponse{Caches: make([]CacheStats, 0, len(h.caches))}
+	for name, c := range h.caches {
+		res.Caches = append(res.Caches, CacheStats{Name: name, Size: c.Size(), Capacity: c.Capacity()})
+	}
+	slices.SortFunc(res.Caches, func(a, b CacheStats) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	utils.WriteJSON(w, res, http.StatusOK)
+}
+
+// PurgeCache очищает кэш целиком
+func (h *AdminHandler) PurgeCache(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	c, ok := h.caches[name]
+	if !ok {
+		problem.Write(w, r, problem.CodeCacheNotFound)
+		return
+	}
+
+	c.Purge()
+	h.logger.InfoContext(r.Context(), "cache purged", slog.String("cache", name))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteCacheKey удаляет из кэша одну запись, отсутствие записи не считается ошибкой
+func (h *AdminHandler) DeleteCacheKey(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	c, ok := h.caches[name]
+	if !ok {
+		problem.Write(w, r, problem.CodeCacheNotFound)
+		return
+	}
+
+	key := chi.URLParam(r, "key")
+	c.Delete(key)
+	h.logger.InfoContext(r.Context(), "cache key deleted", slog.String("cache", name), slog.String("key", key))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetConsumer возвращает состояние чтения заказов
+func (h *AdminHandler) GetConsumer(w http.ResponseWriter, _ *http.Request) {
+	utils.WriteJSON(w, ConsumerStatusToJSON(h.consumer.ConsumerStatus()), http.StatusOK)
+}
+
+// PauseConsumer приостанавливает чтение заказов, например на время обслуживания Postgres
+func (h *AdminHandler) PauseConsumer(w http.ResponseWriter, r *http.Request) {
+	h.consumer.Pause()
+	h.logger.InfoContext(r.Context(), "consumer paused")
+	utils.WriteJSON(w, ConsumerStatusToJSON(h.consumer.ConsumerStatus()), http.StatusOK)
+}
+
+func (h *AdminHandler) ResumeConsumer(w http.ResponseWriter, r *http.Request) {
+	h.consumer.Resume()
+	h.logger.InfoContext(r.Context(), "consumer resumed")
+	utils.WriteJSON(w, ConsumerStatusToJSON(h.consumer.ConsumerStatus()), http.StatusOK)
+}
@@ -0,0 +1,123 @@
+package handler_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/handler"
+	mocks "github.com/SergeyBogomolovv/l0-order-service/internal/handler/mocks"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/problem"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminHandler(t *testing.T) {
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		setup      func(orders *mocks.MockCacheAdmin, consumer *mocks.MockConsumerAdmin)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:   "list caches",
+			method: http.MethodGet,
+			path:   "/cache",
+			setup: func(orders *mocks.MockCacheAdmin, _ *mocks.MockConsumerAdmin) {
+				orders.EXPECT().Size().Return(10).Once()
+				orders.EXPECT().Capacity().Return(1000).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"caches":[{"name":"orders","size":10,"capacity":1000}]}`,
+		},
+		{
+			name:   "purge cache",
+			method: http.MethodDelete,
+			path:   "/cache/orders",
+			setup: func(orders *mocks.MockCacheAdmin, _ *mocks.MockConsumerAdmin) {
+				orders.EXPECT().Purge().Once()
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:   "delete cache key",
+			method: http.MethodDelete,
+			path:   "/cache/orders/b563feb7b2b84b6test",
+			setup: func(orders *mocks.MockCacheAdmin, _ *mocks.MockConsumerAdmin) {
+				orders.EXPECT().Delete("b563feb7b2b84b6test").Once()
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "unknown cache",
+			method:     http.MethodDelete,
+			path:       "/cache/sessions",
+			setup:      func(*mocks.MockCacheAdmin, *mocks.MockConsumerAdmin) {},
+			wantStatus: http.StatusNotFound,
+			wantBody: `{"type":"` + problem.TypeBase + `cache_not_found","title":"Cache not found","status":404,` +
+				`"detail":"No cache with this name.","code":"cache_not_found"}`,
+		},
+		{
+			name:   "consumer status",
+			method: http.MethodGet,
+			path:   "/consumer",
+			setup: func(_ *mocks.MockCacheAdmin, consumer *mocks.MockConsumerAdmin) {
+				consumer.EXPECT().ConsumerStatus().Return(entities.ConsumerStatus{
+					Running: true, ProcessingFor: 1500 * time.Millisecond, Lag: 42,
+				}).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"running":true,"paused":false,"processing_for":"1.5s","lag":42}`,
+		},
+		{
+			name:   "pause consumer",
+			method: http.MethodPost,
+			path:   "/consumer/pause",
+			setup: func(_ *mocks.MockCacheAdmin, consumer *mocks.MockConsumerAdmin) {
+				consumer.EXPECT().Pause().Once()
+				consumer.EXPECT().ConsumerStatus().Return(entities.ConsumerStatus{Running: true, Paused: true}).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"running":true,"paused":true,"lag":0}`,
+		},
+		{
+			name:   "resume consumer",
+			method: http.MethodPost,
+			path:   "/consumer/resume",
+			setup: func(_ *mocks.MockCacheAdmin, consumer *mocks.MockConsumerAdmin) {
+				consumer.EXPECT().Resume().Once()
+				consumer.EXPECT().ConsumerStatus().Return(entities.ConsumerStatus{Running: true}).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"running":true,"paused":false,"lag":0}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			orders := mocks.NewMockCacheAdmin(t)
+			consumer := mocks.NewMockConsumerAdmin(t)
+			tc.setup(orders, consumer)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			caches := map[string]handler.CacheAdmin{"orders": orders}
+
+			r := chi.NewRouter()
+			handler.NewAdminHandler(logger, caches, consumer).Init(r)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			if tc.wantBody != "" {
+				assert.JSONEq(t, tc.wantBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/problem"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, name string, scopes []string) (entities.APIKey, string, error)
+	ListAPIKeys(ctx context.Context) ([]entities.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) error
+}
+
+type APIKeyHandler struct {
+	logger   *slog.Logger
+	validate *validator.Validate
+	svc      APIKeyService
+}
+
+func NewAPIKeyHandler(logger *slog.Logger, svc APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		logger:   logger.With(slog.String("handler", "apikey")),
+		validate: newValidator(),
+		svc:      svc,
+	}
+}
+
+func (h *APIKeyHandler) Init(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(entities.ScopeAdmin))
+		r.Post("/admin/api-keys", h.CreateAPIKey)
+		r.Get("/admin/api-keys", h.ListAPIKeys)
+		r.Delete("/admin/api-keys/{id}", h.RevokeAPIKey)
+	})
+}
+
+// CreateAPIKey создает API ключ.
+// @Summary      Создать API ключ
+// @Description  Ключ передается в заголовке X-API-Key или как Bearer токен. В базе хранится только его SHA-256,
+// @Description  поэтому ключ возвращается только в этом ответе.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body  CreateAPIKeyRequest  true  "Имя и права ключа"
+// @Success      201  {object}  CreateAPIKeyResponse
+// @Failure      400  {object}  problem.Problem "Ошибка валидации"
+// @Failure      500  {object}  problem.Problem "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		problem.WriteValidation(w, r, err)
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		problem.WriteValidation(w, r, err)
+		return
+	}
+
+	apiKey, key, err := h.svc.CreateAPIKey(ctx, req.Name, req.Scopes)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to create api key", slog.Any("error", err))
+		problem.Write(w, r, problem.CodeInternal)
+		return
+	}
+
+	utils.WriteJSON(w, CreateAPIKeyResponse{APIKey: APIKeyEntityToJSON(apiKey), Key: key}, http.StatusCreated)
+}
+
+// ListAPIKeys возвращает все API ключи из базы, включая отозванные.
+// @Summary      Список API ключей
+// @Description  Ключи из файла AUTH_API_KEYS_FILE в список не входят.
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  APIKeysResponse
+// @Failure      500  {object}  problem.Problem "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	keys, err := h.svc.ListAPIKeys(ctx)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list api keys", slog.Any("error", err))
+		problem.Write(w, r, problem.CodeInternal)
+		return
+	}
+
+	res := APIKeysResponse{Keys: make([]APIKey, len(keys))}
+	for i, k := range keys {
+		res.Keys[i] = APIKeyEntityToJSON(k)
+	}
+	utils.WriteJSON(w, res, http.StatusOK)
+}
+
+// RevokeAPIKey отзывает API ключ.
+// @Summary      Отозвать API ключ
+// @Description  Другие реплики перестают принимать ключ не позже чем через AUTH_API_KEY_CACHE_TTL.
+// @Tags         auth
+// @Param        id   path  int  true  "ID ключа"
+// @Success      204  "Ключ отозван"
+// @Failure      400  {object}  problem.Problem "Ошибка валидации"
+// @Failure      404  {object}  problem.Problem "Действующий ключ не найден"
+// @Failure      500  {object}  problem.Problem "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.svc.RevokeAPIKey(ctx, id)
+	if errors.Is(err, entities.ErrAPIKeyNotFound) {
+		problem.Write(w, r, problem.CodeAPIKeyNotFound)
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to revoke api key", slog.Any("error", err), slog.Int64("id", id))
+		problem.Write(w, r, problem.CodeInternal)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
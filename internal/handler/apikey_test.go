@@ -0,0 +1,120 @@
+package handler_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/handler"
+	mocks "github.com/SergeyBogomolovv/l0-order-service/internal/handler/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAPIKeyHandler(t *testing.T) {
+	apiKey := entities.APIKey{
+		ID:        1,
+		Name:      "crm",
+		Hash:      "hash",
+		Scopes:    []string{entities.ScopeOrdersRead},
+		CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	testCases := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		mockBehavior func(svc *mocks.MockAPIKeyService)
+		wantStatus   int
+		wantBody     string
+	}{
+		{
+			name:   "create",
+			method: http.MethodPost,
+			path:   "/admin/api-keys",
+			body:   `{"name":"crm","scopes":["orders:read"]}`,
+			mockBehavior: func(svc *mocks.MockAPIKeyService) {
+				svc.EXPECT().CreateAPIKey(mock.Anything, "crm", []string{entities.ScopeOrdersRead}).
+					Return(apiKey, "plaintext", nil).Once()
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   `"scopes":["orders:read"],"created_at":"2025-01-01T00:00:00Z","key":"plaintext"`,
+		},
+		{
+			name:         "create with unknown scope",
+			method:       http.MethodPost,
+			path:         "/admin/api-keys",
+			body:         `{"name":"crm","scopes":["orders:delete"]}`,
+			mockBehavior: func(_ *mocks.MockAPIKeyService) {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     `"scopes[0]"`,
+		},
+		{
+			name:         "create without scopes",
+			method:       http.MethodPost,
+			path:         "/admin/api-keys",
+			body:         `{"name":"crm"}`,
+			mockBehavior: func(_ *mocks.MockAPIKeyService) {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     `"scopes"`,
+		},
+		{
+			name:   "list",
+			method: http.MethodGet,
+			path:   "/admin/api-keys",
+			mockBehavior: func(svc *mocks.MockAPIKeyService) {
+				revoked := apiKey
+				revoked.RevokedAt = time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+				svc.EXPECT().ListAPIKeys(mock.Anything).Return([]entities.APIKey{revoked}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"revoked_at":"2025-02-01T00:00:00Z"`,
+		},
+		{
+			name:   "revoke",
+			method: http.MethodDelete,
+			path:   "/admin/api-keys/1",
+			mockBehavior: func(svc *mocks.MockAPIKeyService) {
+				svc.EXPECT().RevokeAPIKey(mock.Anything, int64(1)).Return(nil).Once()
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:   "revoke missing key",
+			method: http.MethodDelete,
+			path:   "/admin/api-keys/2",
+			mockBehavior: func(svc *mocks.MockAPIKeyService) {
+				svc.EXPECT().RevokeAPIKey(mock.Anything, int64(2)).Return(entities.ErrAPIKeyNotFound).Once()
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `"code":"api_key_not_found"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := mocks.NewMockAPIKeyService(t)
+			tc.mockBehavior(svc)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			h := handler.NewAPIKeyHandler(logger, svc)
+			r := newTestRouter()
+			h.Init(r)
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.wantBody)
+			// хэш ключа наружу не отдается
+			assert.NotContains(t, w.Body.String(), "hash")
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/problem"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/utils"
+)
+
+// writeOrder отдает заказ в выбранном формате с выбранными полями и заголовками ETag, Last-Modified и Cache-Control.
+// Если у клиента уже есть актуальная версия, возвращается 304 без тела.
+func (h *HTTPHandler) writeOrder(
+	w http.ResponseWriter, r *http.Request, order entities.Order, proj projection, enc responseEncoder,
+) {
+	ctx := r.Context()
+	protected, reveal := revealOrders(ctx, h.pii, proj, []entities.Order{order})
+
+	data, err := encodeResponse(OrderEntityToJSON(protected[0]), "", proj, enc, middleware.APIVersionFrom(ctx))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to marshal order", slog.Any("error", err), slog.String("orderUID", order.OrderUID))
+		problem.Write(w, r, problem.CodeInternal)
+		return
+	}
+
+	// ETag строится по отдаваемому телу, которое зависит еще от маскирования, выбранных полей и версии API.
+	// Last-Modified берется из saved_at, а не из date_created: ее задает продюсер, и временем изменения она не является
+	etag := strongETag(data)
+	notMod := notModified(r, etag, order.SavedAt)
+
+	// данные раскрываются только с телом ответа, поэтому 304 в журнал не пишется
+	if !notMod {
+		if err := reveal.log(ctx, h.pii, r.URL.Path); err != nil {
+			h.logger.ErrorContext(ctx, "failed to protect order",
+				slog.Any("error", err), slog.String("orderUID", order.OrderUID))
+			problem.Write(w, r, problem.CodeInternal)
+			return
+		}
+	}
+
+	header := w.Header()
+	// маскирование зависит от клиента
+	header.Add("Vary", "Accept, Authorization, X-API-Key")
+	header.Set("ETag", etag)
+	if !order.SavedAt.IsZero() {
+		header.Set("Last-Modified", order.SavedAt.UTC().Format(http.TimeFormat))
+	}
+	if h.cacheControl != "" {
+		header.Set("Cache-Control", h.cacheControl)
+	}
+
+	if notMod {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	utils.WriteRaw(w, enc.contentType, data, http.StatusOK)
+}
+
+// strongETag вычисляется по содержимому ответа, поэтому одинаков на всех репликах
+func strongETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified проверяет условия запроса по RFC 9110 (раздел 13.2.2): If-Modified-Since учитывается,
+// только если нет If-None-Match
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatch(inm, etag)
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	// в заголовке время с точностью до секунды
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// etagMatch использует слабое сравнение, как требуется для If-None-Match
+func etagMatch(header, etag string) bool {
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
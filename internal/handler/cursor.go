@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+)
+
+// cursor непрозрачный для клиента курсор пагинации
+type cursor struct {
+	DateCreated time.Time `json:"d"`
+	OrderUID    string    `json:"u"`
+}
+
+func encodeCursor(c *entities.OrderCursor) string {
+	if c == nil {
+		return ""
+	}
+	data, _ := json.Marshal(cursor{DateCreated: c.DateCreated, OrderUID: c.OrderUID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (*entities.OrderCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode cursor: %w", err)
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cursor: %w", err)
+	}
+	if c.OrderUID == "" || c.DateCreated.IsZero() {
+		return nil, errors.New("incomplete cursor")
+	}
+
+	return &entities.OrderCursor{DateCreated: c.DateCreated, OrderUID: c.OrderUID}, nil
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/problem"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+type CustomerService interface {
+	ListCustomerOrders(ctx context.Context, customerID string, filter entities.OrderFilter) (entities.OrderPage, error)
+	GetCustomerSummary(ctx context.Context, customerID string) (entities.CustomerSummary, error)
+}
+
+type CustomerHandler struct {
+	logger   *slog.Logger
+	validate *validator.Validate
+	svc      CustomerService
+	pii      PIIPolicy
+}
+
+func NewCustomerHandler(logger *slog.Logger, svc CustomerService, pii PIIPolicy) *CustomerHandler {
+	return &CustomerHandler{
+		logger:   logger.With(slog.String("handler", "customer")),
+		validate: newValidator(),
+		svc:      svc,
+		pii:      pii,
+	}
+}
+
+func (h *CustomerHandler) Init(r chi.Router) {
+	r.With(middleware.RequireScope(entities.ScopeOrdersRead)).
+		Get("/customers/{customer_id}/orders", h.GetCustomerOrders)
+}
+
+// GetCustomerOrders возвращает историю заказов покупателя.
+// @Summary      История заказов покупателя
+// @Description  Возвращает сводку по всем заказам покупателя и страницу его заказов, начиная с новых.
+// @Description  Поддерживает те же параметры фильтрации и пагинации, что и поиск заказов.
+// @Tags         customers
+// @Produce      json,xml,text/csv,application/msgpack
+// @Param        customer_id  path   string  true   "ID покупателя"
+// @Param        sort         query  string  false  "Сортировка" Enums(date_created, -date_created) default(-date_created)
+// @Param        limit        query  int     false  "Размер страницы" minimum(1) maximum(100) default(20)
+// @Param        cursor       query  string  false  "Курсор следующей страницы"
+// @Param        fields  query  string  false  "Поля заказа через запятую, например order_uid,payment.amount,items.name"
+// @Param        exclude  query  string  false  "Не загружать товары" Enums(items)
+// @Success      200  {object}  CustomerOrdersResponse
+// @Failure      400  {object}  problem.Problem "Ошибка валидации"
+// @Failure      404  {object}  problem.Problem "У покупателя нет заказов"
+// @Failure      406  {object}  problem.Problem "Формат из Accept не поддерживается"
+// @Failure      500  {object}  problem.Problem "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /customers/{customer_id}/orders [get]
+func (h *CustomerHandler) GetCustomerOrders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	customerID := chi.URLParam(r, "customer_id")
+
+	if err := h.validate.Var(customerID, "required"); err != nil {
+		problem.WriteValidation(w, r, err)
+		return
+	}
+
+	filter, fields := parseOrderFilter(r)
+	proj, projFields := parseProjection(r)
+	maps.Copy(fields, projFields)
+	if len(fields) > 0 {
+		problem.WriteFields(w, r, fields)
+		return
+	}
+
+	enc, ok := negotiateEncoder(r)
+	if !ok {
+		problem.Write(w, r, problem.CodeNotAcceptable)
+		return
+	}
+	filter.ExcludeItems = proj.excludeItems
+
+	summary, err := h.svc.GetCustomerSummary(ctx, customerID)
+	if errors.Is(err, entities.ErrCustomerNotFound) {
+		problem.Write(w, r, problem.CodeCustomerNotFound)
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to get customer summary",
+			slog.Any("error", err), slog.String("customerID", customerID))
+		problem.Write(w, r, problem.CodeInternal)
+		return
+	}
+
+	page, err := h.svc.ListCustomerOrders(ctx, customerID, filter)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list customer orders",
+			slog.Any("error", err), slog.String("customerID", customerID))
+		problem.Write(w, r, problem.CodeInternal)
+		return
+	}
+
+	page.Orders, err = protectOrders(ctx, h.pii, r.URL.Path, proj, page.Orders)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to protect orders",
+			slog.Any("error", err), slog.String("customerID", customerID))
+		problem.Write(w, r, problem.CodeInternal)
+		return
+	}
+
+	list := OrderPageToJSON(page)
+	writeResponse(w, r, enc, proj, CustomerOrdersResponse{
+		Summary:    CustomerSummaryEntityToJSON(summary),
+		Orders:     list.Orders,
+		NextCursor: list.NextCursor,
+	}, "orders", http.StatusOK)
+}
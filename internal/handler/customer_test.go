@@ -0,0 +1,92 @@
+package handler_test
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/handler"
+	mocks "github.com/SergeyBogomolovv/l0-order-service/internal/handler/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCustomerHandler_GetCustomerOrders(t *testing.T) {
+	summary := entities.CustomerSummary{
+		CustomerID:   "c1",
+		OrderCount:   1,
+		TotalSpent:   map[string]int64{"USD": 100},
+		FirstOrderAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		LastOrderAt:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	testCases := []struct {
+		name         string
+		query        string
+		mockBehavior func(svc *mocks.MockCustomerService)
+		wantStatus   int
+		wantBody     string
+	}{
+		{
+			name:  "success",
+			query: "?limit=10",
+			mockBehavior: func(svc *mocks.MockCustomerService) {
+				svc.EXPECT().GetCustomerSummary(mock.Anything, "c1").Return(summary, nil).Once()
+				svc.EXPECT().ListCustomerOrders(mock.Anything, "c1", entities.OrderFilter{Limit: 10}).
+					Return(entities.OrderPage{Orders: []entities.Order{{OrderUID: "123", CustomerID: "c1"}}}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"summary":{"customer_id":"c1","order_count":1,"total_spent":{"USD":100}`,
+		},
+		{
+			name: "customer without orders",
+			mockBehavior: func(svc *mocks.MockCustomerService) {
+				svc.EXPECT().GetCustomerSummary(mock.Anything, "c1").
+					Return(entities.CustomerSummary{}, entities.ErrCustomerNotFound).Once()
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `"code":"customer_not_found"`,
+		},
+		{
+			name:         "invalid params",
+			query:        "?limit=0",
+			mockBehavior: func(_ *mocks.MockCustomerService) {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     `{"field":"limit","rule":"range"`,
+		},
+		{
+			name: "internal error",
+			mockBehavior: func(svc *mocks.MockCustomerService) {
+				svc.EXPECT().GetCustomerSummary(mock.Anything, "c1").Return(summary, nil).Once()
+				svc.EXPECT().ListCustomerOrders(mock.Anything, "c1", mock.Anything).
+					Return(entities.OrderPage{}, errors.New("db error")).Once()
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `"code":"internal_error"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := mocks.NewMockCustomerService(t)
+			tc.mockBehavior(svc)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			h := handler.NewCustomerHandler(logger, svc, testPII{})
+
+			r := newTestRouter()
+			h.Init(r)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/customers/c1/orders"+tc.query, nil))
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tc.wantBody)
+		})
+	}
+}
@@ -0,0 +1,367 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/problem"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/utils"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// responseEncoder кодирует ответ с заказами в одном из форматов
+type responseEncoder struct {
+	contentType string
+	// mediaTypes значения Accept, которым соответствует формат
+	mediaTypes []string
+	encode     func(doc responseDoc) ([]byte, error)
+}
+
+// responseDoc ответ в виде дерева, полученного из его JSON представления,
+// поэтому все форматы содержат одни и те же поля с теми же именами
+type responseDoc struct {
+	tree any
+	root string
+	proj projection
+}
+
+var (
+	jsonEncoder = responseEncoder{
+		contentType: "application/json",
+		mediaTypes:  []string{"application/json"},
+		encode:      encodeJSON,
+	}
+	msgpackEncoder = responseEncoder{
+		contentType: "application/msgpack",
+		mediaTypes:  []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"},
+		encode:      encodeMsgpack,
+	}
+	xmlEncoder = responseEncoder{
+		contentType: "application/xml; charset=utf-8",
+		mediaTypes:  []string{"application/xml", "text/xml"},
+		encode:      encodeXML,
+	}
+	csvEncoder = responseEncoder{
+		contentType: "text/csv; charset=utf-8",
+		mediaTypes:  []string{"text/csv"},
+		encode:      encodeCSV,
+	}
+
+	// responseEncoders порядок определяет приоритет при одинаковом q, JSON используется по умолчанию
+	responseEncoders = []responseEncoder{jsonEncoder, msgpackEncoder, xmlEncoder, csvEncoder}
+)
+
+// negotiateEncoder выбирает формат ответа по заголовку Accept.
+// false означает, что ни один из поддерживаемых форматов клиенту не подходит.
+func negotiateEncoder(r *http.Request) (responseEncoder, bool) {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return jsonEncoder, true
+	}
+
+	// для каждого формата берется q самого точного совпадения
+	type match struct {
+		specificity int
+		q           float64
+	}
+	matches := make([]match, len(responseEncoders))
+
+	for part := range strings.SplitSeq(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		typ, _, _ := strings.Cut(mediaType, "/")
+
+		for i, enc := range responseEncoders {
+			specificity := 0
+			switch {
+			case slices.Contains(enc.mediaTypes, mediaType):
+				specificity = 3
+			case mediaType == typ+"/*" && slices.ContainsFunc(enc.mediaTypes, func(mt string) bool {
+				return strings.HasPrefix(mt, typ+"/")
+			}):
+				specificity = 2
+			case mediaType == "*/*":
+				specificity = 1
+			default:
+				continue
+			}
+			if specificity > matches[i].specificity {
+				matches[i] = match{specificity: specificity, q: q}
+			}
+		}
+	}
+
+	best, bestQ := -1, 0.0
+	for i, m := range matches {
+		if m.q > bestQ {
+			best, bestQ = i, m.q
+		}
+	}
+	if best < 0 {
+		return responseEncoder{}, false
+	}
+	return responseEncoders[best], true
+}
+
+// encodeResponse кодирует ответ с заказами в выбранном формате с учетом выбранных полей.
+// root - ключ массива заказов в ответе, пустой если ответ сам является заказом.
+func encodeResponse(
+	payload any, root string, proj projection, enc responseEncoder, version middleware.APIVersion,
+) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	if enc.contentType == jsonEncoder.contentType && proj.empty() && version == middleware.APIv1 {
+		return append(data, '\n'), nil
+	}
+
+	tree, err := responseTree(data, root, proj, version)
+	if err != nil {
+		return nil, err
+	}
+	return enc.encode(responseDoc{tree: tree, root: root, proj: proj})
+}
+
+// responseTree разбирает JSON ответа в дерево в форме версии API и оставляет в нем выбранные поля
+func responseTree(data []byte, root string, proj projection, version middleware.APIVersion) (any, error) {
+	// UseNumber сохраняет большие целые без потери точности
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return nil, err
+	}
+	upgradeOrders(tree, root, version)
+	proj.apply(tree, root)
+	return tree, nil
+}
+
+// writeResponse пишет ответ с заказами в выбранном формате
+func writeResponse(
+	w http.ResponseWriter, r *http.Request, enc responseEncoder, proj projection, payload any, root string, code int,
+) {
+	data, err := encodeResponse(payload, root, proj, enc, middleware.APIVersionFrom(r.Context()))
+	if err != nil {
+		problem.Write(w, r, problem.CodeInternal)
+		return
+	}
+	w.Header().Add("Vary", "Accept")
+	utils.WriteRaw(w, enc.contentType, data, code)
+}
+
+func encodeJSON(doc responseDoc) ([]byte, error) {
+	data, err := json.Marshal(doc.tree)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func encodeMsgpack(doc responseDoc) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetSortMapKeys(true)
+	if err := enc.Encode(msgpackValue(doc.tree)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// msgpackValue заменяет json.Number на целые и дробные числа
+func msgpackValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for key, value := range t {
+			t[key] = msgpackValue(value)
+		}
+	case []any:
+		for i, value := range t {
+			t[i] = msgpackValue(value)
+		}
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		f, _ := t.Float64()
+		return f
+	}
+	return v
+}
+
+// xmlElementNames имена элементов массивов, остальные элементы называются value
+var xmlElementNames = map[string]string{
+	"orders":  "order",
+	"items":   "item",
+	"missing": "order_uid",
+}
+
+func encodeXML(doc responseDoc) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	root := "response"
+	if doc.root == "" {
+		root = "order"
+	}
+
+	enc := xml.NewEncoder(&buf)
+	if err := writeXMLElement(enc, root, doc.tree); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func writeXMLElement(enc *xml.Encoder, name string, v any) error {
+	if v == nil {
+		return nil
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch t := v.(type) {
+	case map[string]any:
+		// ключи сортируются, чтобы ответ был одинаковым для одного и того же заказа
+		for _, key := range slices.Sorted(maps.Keys(t)) {
+			if err := writeXMLElement(enc, key, t[key]); err != nil {
+				return err
+			}
+		}
+	case []any:
+		elemName, ok := xmlElementNames[name]
+		if !ok {
+			elemName = "value"
+		}
+		for _, elem := range t {
+			if err := writeXMLElement(enc, elemName, elem); err != nil {
+				return err
+			}
+		}
+	default:
+		if err := enc.EncodeToken(xml.CharData(formatScalar(t))); err != nil {
+			return err
+		}
+	}
+
+	return enc.EncodeToken(start.End())
+}
+
+// encodeCSV выводит по строке на каждый товар заказа, поля заказа повторяются в каждой строке.
+// Заказ без товаров выводится одной строкой с пустыми колонками товара.
+func encodeCSV(doc responseDoc) ([]byte, error) {
+	orderPaths, itemPaths := csvColumns(doc.proj)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(csvHeader(orderPaths, itemPaths)); err != nil {
+		return nil, err
+	}
+	if err := writeCSVOrders(w, ordersIn(doc.tree, doc.root), orderPaths, itemPaths); err != nil {
+		return nil, err
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// csvColumns выбранные поля заказа и поля товара, товары разворачиваются в отдельные строки
+func csvColumns(proj projection) (orderPaths, itemPaths [][]string) {
+	for _, path := range orderFieldPaths {
+		if !proj.selects(path) {
+			continue
+		}
+		if path[0] == "items" {
+			itemPaths = append(itemPaths, path)
+		} else {
+			orderPaths = append(orderPaths, path)
+		}
+	}
+	return orderPaths, itemPaths
+}
+
+func csvHeader(orderPaths, itemPaths [][]string) []string {
+	header := make([]string, 0, len(orderPaths)+len(itemPaths))
+	for _, path := range slices.Concat(orderPaths, itemPaths) {
+		header = append(header, strings.Join(path, "."))
+	}
+	return header
+}
+
+// writeCSVOrders пишет по строке на каждый товар заказа, заказ без товаров занимает одну строку
+func writeCSVOrders(w *csv.Writer, orders []any, orderPaths, itemPaths [][]string) error {
+	for _, order := range orders {
+		base := make([]string, 0, len(orderPaths)+len(itemPaths))
+		for _, path := range orderPaths {
+			base = append(base, formatScalar(lookup(order, path)))
+		}
+
+		items, _ := lookup(order, []string{"items"}).([]any)
+		if len(items) == 0 {
+			items = []any{nil}
+		}
+		for _, item := range items {
+			row := slices.Clone(base)
+			for _, path := range itemPaths {
+				row = append(row, formatScalar(lookup(item, path[1:])))
+			}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func lookup(v any, path []string) any {
+	for _, key := range path {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = obj[key]
+	}
+	return v
+}
+
+func formatScalar(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	case bool:
+		return strconv.FormatBool(t)
+	default:
+		return fmt.Sprint(t)
+	}
+}
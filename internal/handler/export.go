@@ -0,0 +1,336 @@
+package handler
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/problem"
+	"github.com/go-chi/chi/v5"
+	"github.com/parquet-go/parquet-go"
+)
+
+// exportCursorTrailer трейлер с курсором последнего выгруженного заказа, с него продолжается следующая выгрузка
+const exportCursorTrailer = "Export-Cursor"
+
+type OrderExporter interface {
+	ExportOrders(ctx context.Context, filter entities.OrderFilter, fn func(orders []entities.Order) error) error
+}
+
+// exportFormat формат файла выгрузки
+type exportFormat struct {
+	contentType string
+	ext         string
+	// appendable файл, обрезанный по последней записанной пачке, можно дописать. У Parquet метаданные
+	// всех пачек пишутся в конце файла, поэтому команда пишет пачки Parquet в отдельные части.
+	appendable bool
+}
+
+var exportFormats = map[string]exportFormat{
+	"ndjson":  {contentType: "application/x-ndjson", ext: "ndjson", appendable: true},
+	"csv":     {contentType: "text/csv; charset=utf-8", ext: "csv", appendable: true},
+	"parquet": {contentType: "application/vnd.apache.parquet", ext: "parquet"},
+}
+
+type ExportHandler struct {
+	logger       *slog.Logger
+	svc          OrderExporter
+	pii          PIIPolicy
+	batchSize    int
+	writeTimeout time.Duration
+}
+
+func NewExportHandler(logger *slog.Logger, cfg config.Export, svc OrderExporter, pii PIIPolicy) *ExportHandler {
+	return &ExportHandler{
+		logger:       logger.With(slog.String("handler", "export")),
+		svc:          svc,
+		pii:          pii,
+		batchSize:    cfg.BatchSize,
+		writeTimeout: cfg.WriteTimeout,
+	}
+}
+
+func (h *ExportHandler) Init(r chi.Router) {
+	r.With(middleware.RequireScope(entities.ScopeOrdersRead)).Get("/orders/export", h.ExportOrders)
+}
+
+// ExportOrders выгружает заказы потоком.
+// @Summary      Выгрузка заказов
+// @Description  Отдает все заказы по фильтру от старых к новым одним файлом, не загружая их в память целиком.
+// @Description  NDJSON содержит заказ на строку, CSV - строку на товар, как в поиске заказов.
+// @Description  Parquet содержит строку на заказ с товарами списком, пачка заказов пишется группой строк.
+// @Description  После тела приходит трейлер Export-Cursor: переданный в cursor, он продолжает выгрузку
+// @Description  с заказов, созданных после нее. Если ошибка случилась после начала ответа, соединение обрывается.
+// @Tags         orders
+// @Produce      application/x-ndjson,text/csv,application/vnd.apache.parquet,application/gzip
+// @Param        format            query  string  false  "Формат файла" Enums(ndjson, csv, parquet) default(ndjson)
+// @Param        gzip              query  bool    false  "Сжать файл gzip"
+// @Param        customer_id       query  string  false  "ID покупателя"
+// @Param        delivery_service  query  string  false  "Служба доставки"
+// @Param        locale            query  string  false  "Локаль"
+// @Param        currency          query  string  false  "Валюта платежа"
+// @Param        provider          query  string  false  "Платежный провайдер"
+// @Param        brand             query  string  false  "Бренд одного из товаров"
+// @Param        phone             query  string  false  "Телефон получателя, точное совпадение"
+// @Param        email             query  string  false  "Email получателя, точное совпадение"
+// @Param        created_from      query  string  false  "Создан не раньше (RFC 3339)"
+// @Param        created_to        query  string  false  "Создан раньше (RFC 3339)"
+// @Param        cursor            query  string  false  "Курсор из Export-Cursor предыдущей выгрузки"
+// @Param        fields  query  string  false  "Поля заказа через запятую, например order_uid,payment.amount,items.name"
+// @Param        exclude  query  string  false  "Не загружать товары" Enums(items)
+// @Success      200  {file}    file "Файл выгрузки"
+// @Header       200  {string}  Export-Cursor "Трейлер, курсор последнего выгруженного заказа"
+// @Failure      400  {object}  problem.Problem "Ошибка валидации"
+// @Failure      500  {object}  problem.Problem "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /orders/export [get]
+func (h *ExportHandler) ExportOrders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, fields := parseExportRequest(r)
+	if len(fields) > 0 {
+		problem.WriteFields(w, r, fields)
+		return
+	}
+	req.filter.Limit = h.batchSize
+
+	reveal, err := revealPIIOnce(ctx, h.pii, r.URL.Path)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to protect orders", slog.Any("error", err))
+		problem.Write(w, r, problem.CodeInternal)
+		return
+	}
+
+	filename := "orders." + req.format.ext
+	contentType := req.format.contentType
+	var out io.Writer = w
+	var gz *gzip.Writer
+	if req.gzip {
+		filename += ".gz"
+		contentType = "application/gzip"
+		gz = gzip.NewWriter(w)
+		out = gz
+	}
+
+	ew := newExportWriter(out, req.format, req.proj, middleware.APIVersionFrom(ctx))
+	rc := http.NewResponseController(w)
+	last := req.filter.After
+	started := false
+
+	// ответ начинается с первой пачкой, поэтому ошибка до нее еще отдается клиенту как problem
+	start := func() error {
+		started = true
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		w.Header().Set("Trailer", exportCursorTrailer)
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		return ew.writeHeader()
+	}
+
+	err = h.svc.ExportOrders(ctx, req.filter, func(orders []entities.Order) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if !reveal {
+			maskOrders(h.pii, orders)
+		}
+
+		// клиент, который перестал читать, не должен держать транзакцию с курсором бесконечно
+		err := rc.SetWriteDeadline(time.Now().Add(h.writeTimeout))
+		if err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		if err := ew.writeOrders(orders); err != nil {
+			return err
+		}
+		if gz != nil {
+			if err := gz.Flush(); err != nil {
+				return err
+			}
+		}
+		last = orderCursor(orders[len(orders)-1])
+		return rc.Flush()
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		err = ew.close()
+	}
+	if err == nil && gz != nil {
+		err = gz.Close()
+	}
+
+	if err != nil {
+		if ctx.Err() == nil {
+			h.logger.ErrorContext(ctx, "failed to export orders", slog.Any("error", err))
+		}
+		if !started {
+			problem.Write(w, r, problem.CodeInternal)
+			return
+		}
+		// статус уже отправлен, обрыв соединения не дает клиенту принять неполный файл за целый
+		panic(http.ErrAbortHandler)
+	}
+
+	w.Header().Set(exportCursorTrailer, encodeCursor(last))
+}
+
+type exportRequest struct {
+	filter entities.OrderFilter
+	proj   projection
+	format exportFormat
+	gzip   bool
+}
+
+// parseExportRequest разбирает параметры выгрузки. Фильтры и курсор те же, что у поиска заказов,
+// а размер пачки и порядок задает сама выгрузка, поэтому limit и sort не учитываются.
+func parseExportRequest(r *http.Request) (exportRequest, map[string]string) {
+	q := r.URL.Query()
+
+	filter, fields := parseOrderFilter(r)
+	delete(fields, "limit")
+	delete(fields, "sort")
+
+	proj, projFields := parseProjection(r)
+	maps.Copy(fields, projFields)
+	filter.ExcludeItems = proj.excludeItems
+
+	req := exportRequest{filter: filter, proj: proj, format: exportFormats["ndjson"]}
+
+	if v := q.Get("format"); v != "" {
+		format, ok := exportFormats[v]
+		if !ok {
+			fields["format"] = "oneof"
+		}
+		req.format = format
+	}
+
+	if v := q.Get("gzip"); v != "" {
+		gz, err := strconv.ParseBool(v)
+		if err != nil {
+			fields["gzip"] = "boolean"
+		}
+		req.gzip = gz
+	}
+
+	return req, fields
+}
+
+func orderCursor(o entities.Order) *entities.OrderCursor {
+	return &entities.OrderCursor{DateCreated: o.DateCreated, OrderUID: o.OrderUID}
+}
+
+// exportWriter пишет пачки заказов в формате выгрузки в форме версии API
+type exportWriter struct {
+	w       io.Writer
+	proj    projection
+	version middleware.APIVersion
+
+	csv        *csv.Writer
+	orderPaths [][]string
+	itemPaths  [][]string
+
+	parquet *parquet.GenericWriter[any]
+}
+
+func newExportWriter(w io.Writer, format exportFormat, proj projection, version middleware.APIVersion) *exportWriter {
+	ew := &exportWriter{w: w, proj: proj, version: version}
+	switch format.ext {
+	case "csv":
+		ew.csv = csv.NewWriter(w)
+		ew.orderPaths, ew.itemPaths = csvColumns(proj)
+	case "parquet":
+		schema := parquetSchema(proj, version)
+		ew.parquet = parquet.NewGenericWriter[any](w, schema, parquet.Compression(&parquet.Snappy))
+	}
+	return ew
+}
+
+// writeHeader пишет заголовок файла, он есть только у CSV
+func (ew *exportWriter) writeHeader() error {
+	if ew.csv == nil {
+		return nil
+	}
+	if err := ew.csv.Write(csvHeader(ew.orderPaths, ew.itemPaths)); err != nil {
+		return err
+	}
+	ew.csv.Flush()
+	return ew.csv.Error()
+}
+
+func (ew *exportWriter) writeOrders(orders []entities.Order) error {
+	if ew.parquet != nil {
+		return ew.writeParquet(orders)
+	}
+	if ew.csv == nil {
+		for _, o := range orders {
+			line, err := encodeResponse(OrderEntityToJSON(o), "", ew.proj, jsonEncoder, ew.version)
+			if err != nil {
+				return err
+			}
+			if _, err := ew.w.Write(line); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(OrdersEntityToJSON(orders))
+	if err != nil {
+		return err
+	}
+	tree, err := responseTree(data, "orders", ew.proj, ew.version)
+	if err != nil {
+		return err
+	}
+	if err := writeCSVOrders(ew.csv, ordersIn(tree, "orders"), ew.orderPaths, ew.itemPaths); err != nil {
+		return err
+	}
+	ew.csv.Flush()
+	return ew.csv.Error()
+}
+
+// writeParquet пишет пачку отдельной группой строк, чтобы в памяти не копилось больше одной пачки
+func (ew *exportWriter) writeParquet(orders []entities.Order) error {
+	data, err := json.Marshal(OrdersEntityToJSON(orders))
+	if err != nil {
+		return err
+	}
+	tree, err := responseTree(data, "orders", ew.proj, ew.version)
+	if err != nil {
+		return err
+	}
+
+	var rows []any
+	for _, order := range ordersIn(tree, "orders") {
+		rows = append(rows, parquetRow(order, ew.version))
+	}
+	if _, err := ew.parquet.Write(rows); err != nil {
+		return err
+	}
+	return ew.parquet.Flush()
+}
+
+// close дописывает конец файла, он есть только у Parquet
+func (ew *exportWriter) close() error {
+	if ew.parquet == nil {
+		return nil
+	}
+	return ew.parquet.Close()
+}
@@ -0,0 +1,451 @@
+package handler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/parquet-go/parquet-go"
+)
+
+// exportCLISubject от чьего имени команда export раскрывает персональные данные в журнале аудита
+const exportCLISubject = "cli:export"
+
+// ErrExportUsage команда export вызвана с неверными аргументами или с -h, справка по флагам уже выведена
+var ErrExportUsage = errors.New("invalid export arguments")
+
+// ExportCommand команда export: выгружает заказы в файл с тем же содержимым, что и GET /v2/orders/export.
+// После каждой пачки файл синхронизируется на диск и обновляется контрольная точка, поэтому прерванная
+// выгрузка при повторном запуске с теми же параметрами продолжается с последней записанной пачки.
+type ExportCommand struct {
+	logger    *slog.Logger
+	svc       OrderExporter
+	pii       PIIPolicy
+	batchSize int
+}
+
+func NewExportCommand(logger *slog.Logger, cfg config.Export, svc OrderExporter, pii PIIPolicy) *ExportCommand {
+	return &ExportCommand{
+		logger:    logger.With(slog.String("command", "export")),
+		svc:       svc,
+		pii:       pii,
+		batchSize: cfg.BatchSize,
+	}
+}
+
+// exportOptions параметры команды, от которых зависит содержимое файла.
+// Контрольная точка хранит их и не подходит выгрузке с другими параметрами.
+type exportOptions struct {
+	Format          string `json:"format"`
+	Gzip            bool   `json:"gzip"`
+	Out             string `json:"out"`
+	CreatedFrom     string `json:"created_from,omitempty"`
+	CreatedTo       string `json:"created_to,omitempty"`
+	CustomerID      string `json:"customer_id,omitempty"`
+	DeliveryService string `json:"delivery_service,omitempty"`
+	Locale          string `json:"locale,omitempty"`
+	Currency        string `json:"currency,omitempty"`
+	Provider        string `json:"provider,omitempty"`
+	Brand           string `json:"brand,omitempty"`
+	ExcludeItems    bool   `json:"exclude_items,omitempty"`
+	RevealPII       bool   `json:"reveal_pii,omitempty"`
+}
+
+// exportCheckpoint состояние выгрузки после последней записанной пачки
+type exportCheckpoint struct {
+	Params json.RawMessage `json:"params"`
+	// Offset размер файла с записанными пачками, все что дальше дописано после сбоя и отбрасывается
+	Offset int64 `json:"offset,omitempty"`
+	// Parts сколько частей Parquet записано, часть с большим номером дописана после сбоя и перезаписывается
+	Parts    int    `json:"parts,omitempty"`
+	Cursor   string `json:"cursor,omitempty"`
+	Exported int    `json:"exported"`
+}
+
+// exportOutput файл выгрузки, в который пачки записываются с контрольной точкой после каждой
+type exportOutput interface {
+	// writeBatch записывает пачку на диск и отмечает ее в контрольной точке
+	writeBatch(orders []entities.Order) error
+	// finish дописывает файл после последней пачки
+	finish() error
+	Close() error
+}
+
+// Run выполняет команду с аргументами после export
+func (c *ExportCommand) Run(ctx context.Context, args []string) error {
+	opts, checkpointPath, err := parseExportFlags(args)
+	if err != nil {
+		return err
+	}
+	format, ok := exportFormats[opts.Format]
+	if !ok {
+		return fmt.Errorf("unsupported format %q", opts.Format)
+	}
+	filter, err := opts.filter()
+	if err != nil {
+		return err
+	}
+	filter.Limit = c.batchSize
+
+	params, err := json.Marshal(opts)
+	if err != nil {
+		return err
+	}
+	cp, err := loadExportCheckpoint(checkpointPath)
+	if err != nil {
+		return err
+	}
+	if cp != nil && !bytes.Equal(cp.Params, params) {
+		return fmt.Errorf("checkpoint %s belongs to an export with other parameters, remove it to start over",
+			checkpointPath)
+	}
+
+	fresh := cp == nil
+	if fresh {
+		cp = &exportCheckpoint{Params: params}
+	} else if cp.Cursor != "" {
+		if filter.After, err = decodeCursor(cp.Cursor); err != nil {
+			return fmt.Errorf("invalid checkpoint %s: %w", checkpointPath, err)
+		}
+	}
+
+	var output exportOutput
+	if format.appendable {
+		output, err = openAppendOutput(opts, format, cp, fresh)
+		if err != nil {
+			return err
+		}
+	} else {
+		output = &partsOutput{opts: opts, format: format, cp: cp}
+	}
+	defer output.Close()
+	if fresh {
+		if err := saveExportCheckpoint(checkpointPath, cp); err != nil {
+			return err
+		}
+	}
+
+	if opts.RevealPII {
+		err := logPIIAccess(ctx, c.pii, entities.Principal{Subject: exportCLISubject}, opts.Out, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.svc.ExportOrders(ctx, filter, func(orders []entities.Order) error {
+		if !opts.RevealPII {
+			maskOrders(c.pii, orders)
+		}
+		if err := output.writeBatch(orders); err != nil {
+			return err
+		}
+		cp.Cursor = encodeCursor(orderCursor(orders[len(orders)-1]))
+		cp.Exported += len(orders)
+		return saveExportCheckpoint(checkpointPath, cp)
+	})
+	if err != nil {
+		c.logger.ErrorContext(ctx, "export interrupted, run the command again to resume",
+			slog.Int("exported", cp.Exported), slog.String("checkpoint", checkpointPath))
+		return err
+	}
+
+	if err := output.finish(); err != nil {
+		return fmt.Errorf("failed to finish output: %w", err)
+	}
+	if err := os.Remove(checkpointPath); err != nil {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	c.logger.InfoContext(ctx, "orders exported", slog.Int("count", cp.Exported), slog.String("out", opts.Out))
+	return nil
+}
+
+// appendOutput файл NDJSON или CSV, который после сбоя обрезается по контрольной точке и дописывается
+type appendOutput struct {
+	file *os.File
+	gz   *gzip.Writer
+	ew   *exportWriter
+	cp   *exportCheckpoint
+}
+
+func openAppendOutput(
+	opts exportOptions, format exportFormat, cp *exportCheckpoint, fresh bool,
+) (*appendOutput, error) {
+	file, err := os.OpenFile(opts.Out, os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output: %w", err)
+	}
+	o := &appendOutput{file: file, cp: cp}
+	if err := o.open(opts, format, fresh); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return o, nil
+}
+
+func (o *appendOutput) open(opts exportOptions, format exportFormat, fresh bool) error {
+	if err := o.file.Truncate(o.cp.Offset); err != nil {
+		return fmt.Errorf("failed to truncate output: %w", err)
+	}
+	if _, err := o.file.Seek(o.cp.Offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek output: %w", err)
+	}
+
+	// каждая пачка сжимается отдельным gzip членом, их последовательность остается корректным gzip файлом,
+	// а файл, обрезанный по контрольной точке, заканчивается целым членом
+	var out io.Writer = o.file
+	if opts.Gzip {
+		o.gz = gzip.NewWriter(o.file)
+		out = o.gz
+	}
+	// команда пишет в форме актуальной версии API
+	o.ew = newExportWriter(out, format, projection{excludeItems: opts.ExcludeItems}, middleware.APIv2)
+
+	if !fresh {
+		return nil
+	}
+	if err := o.ew.writeHeader(); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+	return o.commit()
+}
+
+func (o *appendOutput) writeBatch(orders []entities.Order) error {
+	if err := o.ew.writeOrders(orders); err != nil {
+		return fmt.Errorf("failed to write orders: %w", err)
+	}
+	return o.commit()
+}
+
+// commit синхронизирует записанное на диск и запоминает размер файла для контрольной точки
+func (o *appendOutput) commit() error {
+	if o.gz != nil {
+		if err := o.gz.Close(); err != nil {
+			return err
+		}
+		o.gz.Reset(o.file)
+	}
+	if err := o.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync output: %w", err)
+	}
+	offset, err := o.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to seek output: %w", err)
+	}
+	o.cp.Offset = offset
+	return nil
+}
+
+func (o *appendOutput) finish() error { return nil }
+
+func (o *appendOutput) Close() error { return o.file.Close() }
+
+// partsOutput выгрузка в Parquet. Метаданные всех групп строк пишутся в конце файла, поэтому недописанный
+// файл продолжить нельзя: каждая пачка записывается отдельным файлом-частью рядом с выходным,
+// контрольная точка хранит число записанных частей, а после последней пачки группы строк всех частей
+// копируются в выходной файл без перекодирования и части удаляются.
+type partsOutput struct {
+	opts   exportOptions
+	format exportFormat
+	cp     *exportCheckpoint
+}
+
+func (o *partsOutput) partPath(n int) string {
+	return fmt.Sprintf("%s.part-%05d", o.opts.Out, n)
+}
+
+func (o *partsOutput) writeBatch(orders []entities.Order) error {
+	file, err := os.Create(o.partPath(o.cp.Parts + 1))
+	if err != nil {
+		return fmt.Errorf("failed to create part: %w", err)
+	}
+	defer file.Close()
+
+	// команда пишет в форме актуальной версии API
+	ew := newExportWriter(file, o.format, projection{excludeItems: o.opts.ExcludeItems}, middleware.APIv2)
+	if err := ew.writeOrders(orders); err != nil {
+		return fmt.Errorf("failed to write orders: %w", err)
+	}
+	if err := ew.close(); err != nil {
+		return fmt.Errorf("failed to close part: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync part: %w", err)
+	}
+	o.cp.Parts++
+	return nil
+}
+
+// finish собирает выходной файл из частей. Прерванная сборка повторяется целиком при следующем запуске.
+func (o *partsOutput) finish() error {
+	file, err := os.Create(o.opts.Out)
+	if err != nil {
+		return fmt.Errorf("failed to create output: %w", err)
+	}
+	defer file.Close()
+
+	var out io.Writer = file
+	var gz *gzip.Writer
+	if o.opts.Gzip {
+		gz = gzip.NewWriter(file)
+		out = gz
+	}
+	schema := parquetSchema(projection{excludeItems: o.opts.ExcludeItems}, middleware.APIv2)
+	pw := parquet.NewGenericWriter[any](out, schema, parquet.Compression(&parquet.Snappy))
+	for n := 1; n <= o.cp.Parts; n++ {
+		if err := o.copyPart(pw, n); err != nil {
+			return err
+		}
+	}
+	if err := pw.Close(); err != nil {
+		return err
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return err
+		}
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync output: %w", err)
+	}
+
+	for n := 1; n <= o.cp.Parts; n++ {
+		if err := os.Remove(o.partPath(n)); err != nil {
+			return fmt.Errorf("failed to remove part: %w", err)
+		}
+	}
+	return nil
+}
+
+func (o *partsOutput) copyPart(pw *parquet.GenericWriter[any], n int) error {
+	file, err := os.Open(o.partPath(n))
+	if err != nil {
+		return fmt.Errorf("failed to open part: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat part: %w", err)
+	}
+	part, err := parquet.OpenFile(file, info.Size())
+	if err != nil {
+		return fmt.Errorf("invalid part %s: %w", file.Name(), err)
+	}
+	for _, rg := range part.RowGroups() {
+		if _, err := pw.WriteRowGroup(rg); err != nil {
+			return fmt.Errorf("failed to copy part %s: %w", file.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (o *partsOutput) Close() error { return nil }
+
+func parseExportFlags(args []string) (exportOptions, string, error) {
+	var opts exportOptions
+	var checkpoint string
+
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.StringVar(&opts.Format, "format", "ndjson", "file format: ndjson, csv or parquet")
+	fs.BoolVar(&opts.Gzip, "gzip", false, "compress the file with gzip")
+	fs.StringVar(&opts.Out, "out", "", "output file (required)")
+	fs.StringVar(&checkpoint, "checkpoint", "", "checkpoint file, defaults to <out>.checkpoint")
+	fs.StringVar(&opts.CreatedFrom, "created-from", "", "orders created at or after, RFC 3339")
+	fs.StringVar(&opts.CreatedTo, "created-to", "", "orders created before, RFC 3339")
+	fs.StringVar(&opts.CustomerID, "customer-id", "", "customer ID")
+	fs.StringVar(&opts.DeliveryService, "delivery-service", "", "delivery service")
+	fs.StringVar(&opts.Locale, "locale", "", "locale")
+	fs.StringVar(&opts.Currency, "currency", "", "payment currency")
+	fs.StringVar(&opts.Provider, "provider", "", "payment provider")
+	fs.StringVar(&opts.Brand, "brand", "", "brand of one of the items")
+	fs.BoolVar(&opts.ExcludeItems, "exclude-items", false, "do not export items")
+	fs.BoolVar(&opts.RevealPII, "reveal-pii", false, "export unmasked delivery data, the access is audited")
+	if err := fs.Parse(args); err != nil {
+		return exportOptions{}, "", fmt.Errorf("%w: %w", ErrExportUsage, err)
+	}
+
+	if opts.Out == "" {
+		fs.Usage()
+		return exportOptions{}, "", fmt.Errorf("%w: --out is required", ErrExportUsage)
+	}
+	if checkpoint == "" {
+		checkpoint = opts.Out + ".checkpoint"
+	}
+	return opts, checkpoint, nil
+}
+
+func (o exportOptions) filter() (entities.OrderFilter, error) {
+	filter := entities.OrderFilter{
+		CustomerID:      o.CustomerID,
+		DeliveryService: o.DeliveryService,
+		Locale:          o.Locale,
+		Currency:        o.Currency,
+		Provider:        o.Provider,
+		Brand:           o.Brand,
+		ExcludeItems:    o.ExcludeItems,
+	}
+
+	for flagName, v := range map[string]struct {
+		value string
+		dst   *time.Time
+	}{
+		"created-from": {o.CreatedFrom, &filter.CreatedFrom},
+		"created-to":   {o.CreatedTo, &filter.CreatedTo},
+	} {
+		if v.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v.value)
+		if err != nil {
+			return entities.OrderFilter{}, fmt.Errorf("invalid --%s: %w", flagName, err)
+		}
+		*v.dst = t
+	}
+
+	return filter, nil
+}
+
+// loadExportCheckpoint читает контрольную точку, nil если выгрузка начинается заново
+func loadExportCheckpoint(path string) (*exportCheckpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var cp exportCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %w", path, err)
+	}
+	return &cp, nil
+}
+
+// saveExportCheckpoint заменяет контрольную точку атомарно, после сбоя остается старая или новая
+func saveExportCheckpoint(path string, cp *exportCheckpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,351 @@
+package handler_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/handler"
+	mocks "github.com/SergeyBogomolovv/l0-order-service/internal/handler/mocks"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var exportConfig = config.Export{BatchSize: 2, WriteTimeout: time.Second}
+
+func exportTestOrders() [][]entities.Order {
+	order := func(n int) entities.Order {
+		return entities.Order{
+			OrderUID:    fmt.Sprintf("o%d", n),
+			DateCreated: time.Date(2025, 1, 1, n, 0, 0, 0, time.UTC),
+			Delivery:    entities.Delivery{Phone: fmt.Sprintf("+7999000000%d", n)},
+		}
+	}
+	return [][]entities.Order{{order(1), order(2)}, {order(3)}}
+}
+
+// exportBatches передает fn пачки по очереди, как курсор в Postgres
+func exportBatches(batches [][]entities.Order, err error) func(
+	context.Context, entities.OrderFilter, func([]entities.Order) error,
+) error {
+	return func(_ context.Context, _ entities.OrderFilter, fn func([]entities.Order) error) error {
+		for _, batch := range batches {
+			if err := fn(batch); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+}
+
+func TestExportHandler_ExportOrders(t *testing.T) {
+	testCases := []struct {
+		name         string
+		query        string
+		scopes       []string
+		mockBehavior func(svc *mocks.MockOrderExporter)
+		wantStatus   int
+		wantType     string
+		gzip         bool
+		wantBody     []string
+		wantTrailer  bool
+	}{
+		{
+			name:  "ndjson",
+			query: "?created_from=2025-01-01T00:00:00Z&limit=abc&sort=-date_created",
+			mockBehavior: func(svc *mocks.MockOrderExporter) {
+				filter := entities.OrderFilter{CreatedFrom: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Limit: 2}
+				svc.EXPECT().ExportOrders(mock.Anything, filter, mock.Anything).
+					RunAndReturn(exportBatches(exportTestOrders(), nil)).Once()
+			},
+			wantStatus:  http.StatusOK,
+			wantType:    "application/x-ndjson",
+			wantBody:    []string{`"order_uid":"o1"`, `"order_uid":"o3"`, `"phone":"masked"`},
+			wantTrailer: true,
+		},
+		{
+			name:   "pii revealed",
+			scopes: []string{entities.ScopeOrdersRead, entities.ScopePIIRead},
+			mockBehavior: func(svc *mocks.MockOrderExporter) {
+				svc.EXPECT().ExportOrders(mock.Anything, mock.Anything, mock.Anything).
+					RunAndReturn(exportBatches(exportTestOrders(), nil)).Once()
+			},
+			wantStatus:  http.StatusOK,
+			wantType:    "application/x-ndjson",
+			wantBody:    []string{`"phone":"+79990000003"`},
+			wantTrailer: true,
+		},
+		{
+			name:  "csv with gzip",
+			query: "?format=csv&gzip=true&fields=order_uid,delivery.phone",
+			mockBehavior: func(svc *mocks.MockOrderExporter) {
+				svc.EXPECT().ExportOrders(mock.Anything, mock.MatchedBy(func(f entities.OrderFilter) bool {
+					return f.ExcludeItems
+				}), mock.Anything).RunAndReturn(exportBatches(exportTestOrders(), nil)).Once()
+			},
+			wantStatus:  http.StatusOK,
+			wantType:    "application/gzip",
+			gzip:        true,
+			wantBody:    []string{"order_uid,delivery.phone\no1,masked\no2,masked\no3,masked\n"},
+			wantTrailer: true,
+		},
+		{
+			name: "no orders",
+			mockBehavior: func(svc *mocks.MockOrderExporter) {
+				svc.EXPECT().ExportOrders(mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantType:   "application/x-ndjson",
+		},
+		{
+			name:         "unsupported format",
+			query:        "?format=xml&gzip=maybe",
+			mockBehavior: func(_ *mocks.MockOrderExporter) {},
+			wantStatus:   http.StatusBadRequest,
+			wantType:     "application/problem+json",
+			wantBody:     []string{`{"field":"format","rule":"oneof"`, `{"field":"gzip","rule":"boolean"`},
+		},
+		{
+			name: "error before first batch",
+			mockBehavior: func(svc *mocks.MockOrderExporter) {
+				svc.EXPECT().ExportOrders(mock.Anything, mock.Anything, mock.Anything).
+					Return(errors.New("db error")).Once()
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantType:   "application/problem+json",
+			wantBody:   []string{`"code":"internal_error"`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := mocks.NewMockOrderExporter(t)
+			tc.mockBehavior(svc)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			h := handler.NewExportHandler(logger, exportConfig, svc, testPII{})
+			scopes := tc.scopes
+			if scopes == nil {
+				scopes = []string{entities.ScopeOrdersRead}
+			}
+			r := newTestRouterWithScopes(scopes...)
+			h.Init(r)
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/export"+tc.query, nil))
+			res := rec.Result()
+
+			assert.Equal(t, tc.wantStatus, res.StatusCode)
+			assert.Contains(t, res.Header.Get("Content-Type"), tc.wantType)
+
+			body := rec.Body.Bytes()
+			if tc.gzip {
+				zr, err := gzip.NewReader(bytes.NewReader(body))
+				require.NoError(t, err)
+				body, err = io.ReadAll(zr)
+				require.NoError(t, err)
+			}
+			for _, want := range tc.wantBody {
+				assert.Contains(t, string(body), want)
+			}
+			if tc.wantTrailer {
+				assert.NotEmpty(t, res.Trailer.Get("Export-Cursor"))
+			}
+		})
+	}
+}
+
+// parquetTestOrder часть строки выгрузки в Parquet, остальные колонки при чтении пропускаются
+type parquetTestOrder struct {
+	OrderUID    string    `parquet:"order_uid,optional"`
+	DateCreated time.Time `parquet:"date_created,optional,timestamp(millisecond)"`
+	Delivery    struct {
+		Phone string `parquet:"phone,optional"`
+	} `parquet:"delivery,optional"`
+	Items []struct {
+		Name  string `parquet:"name,optional"`
+		Price int64  `parquet:"price,optional"`
+	} `parquet:"items,optional,list"`
+}
+
+func readParquetOrders(t *testing.T, data []byte) (*parquet.File, []parquetTestOrder) {
+	t.Helper()
+	f, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	orders, err := parquet.Read[parquetTestOrder](bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	return f, orders
+}
+
+func TestExportHandler_ExportOrdersParquet(t *testing.T) {
+	batches := exportTestOrders()
+	batches[0][0].Items = []entities.Item{{Name: "socks", Price: 100}, {Name: "hat", Price: 200}}
+
+	svc := mocks.NewMockOrderExporter(t)
+	svc.EXPECT().ExportOrders(mock.Anything, mock.Anything, mock.Anything).
+		RunAndReturn(exportBatches(batches, nil)).Once()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := handler.NewExportHandler(logger, exportConfig, svc, testPII{})
+	r := newTestRouterWithScopes(entities.ScopeOrdersRead)
+	h.Init(r)
+
+	query := "?format=parquet&fields=order_uid,date_created,delivery.phone,items.name,items.price"
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/export"+query, nil))
+	res := rec.Result()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/vnd.apache.parquet", res.Header.Get("Content-Type"))
+	assert.NotEmpty(t, res.Trailer.Get("Export-Cursor"))
+
+	f, orders := readParquetOrders(t, rec.Body.Bytes())
+	// пачка пишется отдельной группой строк, в схеме только выбранные поля
+	assert.Len(t, f.RowGroups(), 2)
+	var columns []string
+	for _, path := range f.Schema().Columns() {
+		columns = append(columns, strings.Join(path, "."))
+	}
+	assert.ElementsMatch(t, []string{
+		"order_uid", "date_created", "delivery.phone", "items.list.element.name", "items.list.element.price",
+	}, columns)
+
+	require.Len(t, orders, 3)
+	assert.Equal(t, "o1", orders[0].OrderUID)
+	assert.True(t, batches[0][0].DateCreated.Equal(orders[0].DateCreated))
+	assert.Equal(t, "masked", orders[0].Delivery.Phone)
+	require.Len(t, orders[0].Items, 2)
+	assert.Equal(t, "hat", orders[0].Items[1].Name)
+	assert.Equal(t, int64(200), orders[0].Items[1].Price)
+	assert.Equal(t, "o3", orders[2].OrderUID)
+	assert.Empty(t, orders[2].Items)
+}
+
+func TestExportHandler_ExportOrdersAbort(t *testing.T) {
+	svc := mocks.NewMockOrderExporter(t)
+	svc.EXPECT().ExportOrders(mock.Anything, mock.Anything, mock.Anything).
+		RunAndReturn(exportBatches(exportTestOrders()[:1], errors.New("db error"))).Once()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := handler.NewExportHandler(logger, exportConfig, svc, testPII{})
+	r := newTestRouter()
+	h.Init(r)
+
+	// ответ уже начат, поэтому обработчик обрывает соединение, а не отвечает ошибкой
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/export", nil))
+	})
+}
+
+func TestExportCommand_Run(t *testing.T) {
+	batches := exportTestOrders()
+	out := filepath.Join(t.TempDir(), "orders.csv.gz")
+	args := []string{"--out", out, "--format", "csv", "--gzip", "--exclude-items"}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// первый запуск обрывается после первой пачки
+	svc := mocks.NewMockOrderExporter(t)
+	svc.EXPECT().ExportOrders(mock.Anything, mock.MatchedBy(func(f entities.OrderFilter) bool {
+		return f.After == nil && f.Limit == exportConfig.BatchSize && f.ExcludeItems
+	}), mock.Anything).RunAndReturn(exportBatches(batches[:1], errors.New("connection lost"))).Once()
+
+	err := handler.NewExportCommand(logger, exportConfig, svc, testPII{}).Run(context.Background(), args)
+	require.Error(t, err)
+	assert.FileExists(t, out+".checkpoint")
+
+	// без --out команда не запускается
+	err = handler.NewExportCommand(logger, exportConfig, svc, testPII{}).
+		Run(context.Background(), []string{"--format", "csv"})
+	require.ErrorIs(t, err, handler.ErrExportUsage)
+
+	// контрольная точка не подходит выгрузке с другими параметрами
+	err = handler.NewExportCommand(logger, exportConfig, svc, testPII{}).
+		Run(context.Background(), []string{"--out", out, "--format", "ndjson"})
+	require.ErrorContains(t, err, "other parameters")
+
+	// имитация записи, которую сбой прервал после контрольной точки
+	f, err := os.OpenFile(out, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString("garbage")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// повторный запуск продолжает после последнего записанного заказа
+	svc = mocks.NewMockOrderExporter(t)
+	svc.EXPECT().ExportOrders(mock.Anything, mock.MatchedBy(func(f entities.OrderFilter) bool {
+		return f.After != nil && f.After.OrderUID == "o2"
+	}), mock.Anything).RunAndReturn(exportBatches(batches[1:], nil)).Once()
+
+	err = handler.NewExportCommand(logger, exportConfig, svc, testPII{}).Run(context.Background(), args)
+	require.NoError(t, err)
+	assert.NoFileExists(t, out+".checkpoint")
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	content, err := io.ReadAll(zr)
+	require.NoError(t, err)
+
+	assert.True(t, bytes.HasPrefix(content, []byte("order_uid,track_number,")))
+	lines := bytes.Split(bytes.TrimSpace(content), []byte("\n"))
+	require.Len(t, lines, 4)
+	assert.True(t, bytes.HasPrefix(lines[1], []byte("o1,")))
+	assert.True(t, bytes.HasPrefix(lines[3], []byte("o3,")))
+}
+
+func TestExportCommand_RunParquet(t *testing.T) {
+	batches := exportTestOrders()
+	out := filepath.Join(t.TempDir(), "orders.parquet")
+	args := []string{"--out", out, "--format", "parquet"}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// первый запуск обрывается после первой пачки, она остается отдельной частью
+	svc := mocks.NewMockOrderExporter(t)
+	svc.EXPECT().ExportOrders(mock.Anything, mock.Anything, mock.Anything).
+		RunAndReturn(exportBatches(batches[:1], errors.New("connection lost"))).Once()
+
+	err := handler.NewExportCommand(logger, exportConfig, svc, testPII{}).Run(context.Background(), args)
+	require.Error(t, err)
+	assert.FileExists(t, out+".checkpoint")
+	assert.FileExists(t, out+".part-00001")
+	assert.NoFileExists(t, out)
+
+	// имитация части, которую сбой прервал до контрольной точки
+	require.NoError(t, os.WriteFile(out+".part-00002", []byte("garbage"), 0o644))
+
+	// повторный запуск продолжает после последнего записанного заказа и собирает файл из частей
+	svc = mocks.NewMockOrderExporter(t)
+	svc.EXPECT().ExportOrders(mock.Anything, mock.MatchedBy(func(f entities.OrderFilter) bool {
+		return f.After != nil && f.After.OrderUID == "o2"
+	}), mock.Anything).RunAndReturn(exportBatches(batches[1:], nil)).Once()
+
+	err = handler.NewExportCommand(logger, exportConfig, svc, testPII{}).Run(context.Background(), args)
+	require.NoError(t, err)
+	assert.NoFileExists(t, out+".checkpoint")
+	assert.NoFileExists(t, out+".part-00001")
+	assert.NoFileExists(t, out+".part-00002")
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	f, orders := readParquetOrders(t, data)
+	assert.Len(t, f.RowGroups(), 2)
+	require.Len(t, orders, 3)
+	assert.Equal(t, "o1", orders[0].OrderUID)
+	assert.Equal(t, "masked", orders[2].Delivery.Phone)
+}
@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// fieldset дерево выбранных полей JSON. nil поддерево означает поле целиком.
+type fieldset map[string]fieldset
+
+var (
+	// orderFields все поля заказа, которые можно запросить в параметре fields
+	orderFields = jsonFields(reflect.TypeFor[Order]())
+	// orderFieldPaths пути ко всем полям заказа в порядке объявления, например payment.amount
+	orderFieldPaths = jsonPaths(reflect.TypeFor[Order](), nil)
+)
+
+func jsonFields(t reflect.Type) fieldset {
+	fs := make(fieldset)
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		ft := field.Type
+		if ft.Kind() == reflect.Slice {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && ft.PkgPath() == t.PkgPath() {
+			fs[name] = jsonFields(ft)
+		} else {
+			fs[name] = nil
+		}
+	}
+	return fs
+}
+
+func jsonPaths(t reflect.Type, prefix []string) [][]string {
+	var paths [][]string
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		path := append(slices.Clone(prefix), name)
+
+		ft := field.Type
+		if ft.Kind() == reflect.Slice {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && ft.PkgPath() == t.PkgPath() {
+			paths = append(paths, jsonPaths(ft, path)...)
+		} else {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// ordersIn возвращает заказы из дерева ответа.
+// root - ключ массива заказов в ответе, пустой если ответ сам является заказом.
+func ordersIn(tree any, root string) []any {
+	if root == "" {
+		return []any{tree}
+	}
+	obj, ok := tree.(map[string]any)
+	if !ok {
+		return nil
+	}
+	orders, _ := obj[root].([]any)
+	return orders
+}
+
+func (fs fieldset) add(path []string) {
+	sub, ok := fs[path[0]]
+	if len(path) == 1 {
+		fs[path[0]] = nil
+		return
+	}
+	if ok && sub == nil {
+		// поле уже выбрано целиком
+		return
+	}
+	if sub == nil {
+		sub = make(fieldset)
+		fs[path[0]] = sub
+	}
+	sub.add(path[1:])
+}
+
+func (fs fieldset) selects(path []string) bool {
+	sub, ok := fs[path[0]]
+	if !ok {
+		return false
+	}
+	if sub == nil || len(path) == 1 {
+		return true
+	}
+	return sub.selects(path[1:])
+}
+
+func (fs fieldset) has(path []string) bool {
+	sub, ok := fs[path[0]]
+	if !ok {
+		return false
+	}
+	if len(path) == 1 {
+		return true
+	}
+	return sub != nil && sub.has(path[1:])
+}
+
+// projection описывает, какие поля заказа вернуть клиенту
+type projection struct {
+	// fields nil если клиент не ограничивал поля
+	fields       fieldset
+	excludeItems bool
+}
+
+// parseProjection разбирает параметры fields (например fields=order_uid,payment.amount,items.name)
+// и exclude=items. Ошибки возвращаются в том же виде, что и в parseOrderFilter.
+func parseProjection(r *http.Request) (projection, map[string]string) {
+	q := r.URL.Query()
+	errs := make(map[string]string)
+
+	var p projection
+	if v := q.Get("fields"); v != "" {
+		p.fields = make(fieldset)
+		for name := range strings.SplitSeq(v, ",") {
+			path := strings.Split(strings.TrimSpace(name), ".")
+			if !orderFields.has(path) {
+				errs["fields"] = "fieldset"
+				break
+			}
+			p.fields.add(path)
+		}
+	}
+
+	if v := q.Get("exclude"); v != "" {
+		for name := range strings.SplitSeq(v, ",") {
+			if strings.TrimSpace(name) != "items" {
+				errs["exclude"] = "oneof"
+				break
+			}
+			p.excludeItems = true
+		}
+	}
+
+	// если товары не запрошены, их не нужно загружать из базы
+	if p.fields != nil {
+		if _, ok := p.fields["items"]; !ok {
+			p.excludeItems = true
+		}
+	}
+
+	return p, errs
+}
+
+func (p projection) empty() bool {
+	return p.fields == nil && !p.excludeItems
+}
+
+// apply оставляет в заказах дерева ответа только выбранные поля
+func (p projection) apply(tree any, root string) {
+	if p.empty() {
+		return
+	}
+	for _, order := range ordersIn(tree, root) {
+		p.prune(order)
+	}
+}
+
+// selects сообщает, попадает ли поле с путем path в ответ
+func (p projection) selects(path []string) bool {
+	if p.excludeItems && path[0] == "items" {
+		return false
+	}
+	return p.fields == nil || p.fields.selects(path)
+}
+
+func (p projection) prune(order any) {
+	obj, ok := order.(map[string]any)
+	if !ok {
+		return
+	}
+	if p.fields != nil {
+		pruneFields(obj, p.fields)
+	}
+	if p.excludeItems {
+		delete(obj, "items")
+	}
+}
+
+func pruneFields(v any, fs fieldset) {
+	switch t := v.(type) {
+	case map[string]any:
+		for key, value := range t {
+			sub, ok := fs[key]
+			if !ok {
+				delete(t, key)
+				continue
+			}
+			if sub != nil {
+				pruneFields(value, sub)
+			}
+		}
+	case []any:
+		for _, elem := range t {
+			pruneFields(elem, fs)
+		}
+	}
+}
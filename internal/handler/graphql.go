@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+type GraphQLHandler struct {
+	logger        *slog.Logger
+	svc           OrderService
+	pii           PIIPolicy
+	schema        graphql.Schema
+	maxComplexity int
+}
+
+func NewGraphQLHandler(
+	logger *slog.Logger, cfg config.GraphQL, svc OrderService, pii PIIPolicy,
+) (*GraphQLHandler, error) {
+	h := &GraphQLHandler{
+		logger:        logger.With(slog.String("handler", "graphql")),
+		svc:           svc,
+		pii:           pii,
+		maxComplexity: cfg.MaxComplexity,
+	}
+
+	schema, err := h.newSchema()
+	if err != nil {
+		return nil, err
+	}
+	h.schema = schema
+
+	return h, nil
+}
+
+func (h *GraphQLHandler) Init(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(entities.ScopeOrdersRead))
+		r.Get("/graphql", h.Query)
+		r.Post("/graphql", h.Query)
+	})
+}
+
+// Query выполняет GraphQL запрос.
+// @Summary      GraphQL запрос
+// @Description  Выполняет запрос к GraphQL схеме заказов. Схему можно получить интроспекцией.
+// @Description  Запросы со сложностью выше GRAPHQL_MAX_COMPLEXITY отклоняются без выполнения.
+// @Tags         graphql
+// @Accept       json
+// @Produce      json
+// @Param        request  body  GraphQLRequest  true  "Запрос"
+// @Success      200  {object}  GraphQLResponse "Результат, ошибки полей возвращаются в errors"
+// @Failure      400  {object}  GraphQLResponse "Запрос не разобран, не прошел валидацию или слишком сложный"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /graphql [post]
+func (h *GraphQLHandler) Query(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeGraphQLRequest(w, r)
+	if err != nil {
+		writeGraphQLErrors(w, err.Error())
+		return
+	}
+	if req.Query == "" {
+		writeGraphQLErrors(w, "query is required")
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		utils.WriteJSON(w, graphql.Result{Errors: gqlerrors.FormatErrors(err)}, http.StatusBadRequest)
+		return
+	}
+	if res := graphql.ValidateDocument(&h.schema, doc, nil); !res.IsValid {
+		utils.WriteJSON(w, graphql.Result{Errors: res.Errors}, http.StatusBadRequest)
+		return
+	}
+	if c := queryComplexity(doc, req.OperationName, req.Variables, h.maxComplexity); c > h.maxComplexity {
+		writeGraphQLErrors(w, fmt.Sprintf("query complexity %d exceeds limit %d", c, h.maxComplexity))
+		return
+	}
+
+	ctx := r.Context()
+	access := &graphqlPIIAccess{pii: h.pii}
+	access.principal, access.reveal = canRevealPII(ctx, h.pii)
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		Root:          newOrderLoader(h),
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       context.WithValue(ctx, piiAccessKey{}, access),
+	})
+
+	// заказы, доставку которых запросил клиент, известны только после выполнения запроса
+	if len(access.orderUIDs) > 0 {
+		if err := logPIIAccess(ctx, h.pii, access.principal, r.URL.Path, access.orderUIDs); err != nil {
+			h.logger.ErrorContext(ctx, "failed to protect orders", slog.Any("error", err))
+			utils.WriteJSON(w, graphql.Result{
+				Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError(errGraphQLInternal.Error())},
+			}, http.StatusInternalServerError)
+			return
+		}
+	}
+	utils.WriteJSON(w, result, http.StatusOK)
+}
+
+func decodeGraphQLRequest(w http.ResponseWriter, r *http.Request) (GraphQLRequest, error) {
+	var req GraphQLRequest
+
+	if r.Method == http.MethodGet {
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				return req, fmt.Errorf("invalid variables: %w", err)
+			}
+		}
+		return req, nil
+	}
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		return req, fmt.Errorf("invalid request body: %w", err)
+	}
+	return req, nil
+}
+
+func writeGraphQLErrors(w http.ResponseWriter, message string) {
+	utils.WriteJSON(w, graphql.Result{
+		Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError(message)},
+	}, http.StatusBadRequest)
+}
+
+// orderLoader копит UID заказов, запрошенных полями order в одном GraphQL запросе,
+// и загружает их одним вызовом сервиса при первом обращении к результату
+type orderLoader struct {
+	h *GraphQLHandler
+
+	mu      sync.Mutex
+	pending []string
+	loaded  map[string]entities.Order
+	err     error
+}
+
+func newOrderLoader(h *GraphQLHandler) *orderLoader {
+	return &orderLoader{h: h, loaded: make(map[string]entities.Order)}
+}
+
+func (l *orderLoader) load(ctx context.Context, uid string) func() (any, error) {
+	l.mu.Lock()
+	if _, ok := l.loaded[uid]; !ok && !slices.Contains(l.pending, uid) {
+		l.pending = append(l.pending, uid)
+	}
+	l.mu.Unlock()
+
+	return func() (any, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if err := l.flush(ctx); err != nil {
+			return nil, err
+		}
+		if order, ok := l.loaded[uid]; ok {
+			return order, nil
+		}
+		return nil, nil //nolint:nilnil // null в ответе означает, что заказ не найден
+	}
+}
+
+func (l *orderLoader) flush(ctx context.Context) error {
+	if len(l.pending) == 0 || l.err != nil {
+		return l.err
+	}
+
+	uids := l.pending
+	l.pending = nil
+
+	orders, _, err := l.h.svc.GetOrdersByIDs(ctx, uids)
+	if err != nil {
+		l.h.logger.ErrorContext(ctx, "failed to get orders", slog.Any("error", err), slog.Int("count", len(uids)))
+		l.err = errGraphQLInternal
+		return l.err
+	}
+	for _, order := range orders {
+		l.loaded[order.OrderUID] = order
+	}
+	return nil
+}
+
+type piiAccessKey struct{}
+
+// graphqlPIIAccess решение о раскрытии персональных данных на время одного GraphQL запроса
+type graphqlPIIAccess struct {
+	pii       PIIPolicy
+	principal entities.Principal
+	reveal    bool
+
+	mu sync.Mutex
+	// orderUIDs заказы, доставка которых выдана без маскирования
+	orderUIDs []string
+}
+
+func (a *graphqlPIIAccess) delivery(order entities.Order) entities.Delivery {
+	if !a.reveal {
+		return a.pii.MaskDelivery(order.Delivery)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !slices.Contains(a.orderUIDs, order.OrderUID) {
+		a.orderUIDs = append(a.orderUIDs, order.OrderUID)
+	}
+	return order.Delivery
+}
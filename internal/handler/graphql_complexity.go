@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// itemsComplexityFactor предполагаемое число товаров в заказе.
+// Размер списка товаров заранее неизвестен, поэтому он оценивается этой константой.
+const itemsComplexityFactor = 10
+
+// queryComplexity оценивает стоимость операции: каждое поле стоит 1,
+// а стоимость дочерних полей списка умножается на его ожидаемый размер.
+// Оценка не превышает limit+1, этого достаточно, чтобы отклонить запрос, и сумма не переполняется.
+// Документ должен быть провалидирован, иначе фрагменты могут ссылаться друг на друга по кругу.
+func queryComplexity(doc *ast.Document, operationName string, variables map[string]any, limit int) int {
+	fragments := make(map[string]*ast.SelectionSet)
+	var operations []*ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			fragments[def.Name.Value] = def.SelectionSet
+		case *ast.OperationDefinition:
+			operations = append(operations, def)
+		}
+	}
+
+	for _, op := range operations {
+		name := ""
+		if op.Name != nil {
+			name = op.Name.Value
+		}
+		if operationName == "" || name == operationName {
+			c := complexity{fragments: fragments, variables: variables, limit: limit}
+			return c.selection(op.SelectionSet)
+		}
+	}
+	return 0
+}
+
+type complexity struct {
+	fragments map[string]*ast.SelectionSet
+	variables map[string]any
+	limit     int
+}
+
+func (c complexity) selection(set *ast.SelectionSet) int {
+	if set == nil {
+		return 0
+	}
+
+	total := 0
+	for _, sel := range set.Selections {
+		cost := 0
+		switch sel := sel.(type) {
+		case *ast.Field:
+			cost = 1
+			if sel.SelectionSet != nil {
+				// размер списка не больше maxPageSize, а дочерняя оценка не больше limit+1,
+				// поэтому произведение не переполняется
+				cost += listSize(sel, c.variables) * c.selection(sel.SelectionSet)
+			}
+		case *ast.InlineFragment:
+			cost = c.selection(sel.SelectionSet)
+		case *ast.FragmentSpread:
+			cost = c.selection(c.fragments[sel.Name.Value])
+		}
+		total = min(total+cost, c.limit+1)
+	}
+	return total
+}
+
+// listSize ожидаемое число элементов, которое вернет поле. Размер страницы ограничивается теми же
+// пределами, что и в resolveOrders: страницу больше maxPageSize резолвер не вернет.
+func listSize(field *ast.Field, variables map[string]any) int {
+	switch field.Name.Value {
+	case "orders":
+		for _, arg := range field.Arguments {
+			if arg.Name.Value == "first" {
+				return min(max(intArgument(arg.Value, variables), 1), maxPageSize)
+			}
+		}
+		return defaultPageSize
+	case "items":
+		return itemsComplexityFactor
+	default:
+		return 1
+	}
+}
+
+func intArgument(value ast.Value, variables map[string]any) int {
+	switch v := value.(type) {
+	case *ast.IntValue:
+		n, _ := strconv.Atoi(v.Value)
+		return n
+	case *ast.Variable:
+		// числа в переменных приходят из JSON
+		switch n := variables[v.Name.Value].(type) {
+		case float64:
+			// float64 за пределами int при преобразовании дает произвольное значение
+			return int(min(max(n, 0), maxPageSize))
+		case int:
+			return n
+		}
+	}
+	return defaultPageSize
+}
+
+// selects сообщает, выбрал ли клиент поле по пути path внутри текущего поля, с учетом фрагментов
+func selects(info graphql.ResolveInfo, path ...string) bool {
+	sets := make([]*ast.SelectionSet, 0, len(info.FieldASTs))
+	for _, field := range info.FieldASTs {
+		sets = append(sets, field.SelectionSet)
+	}
+
+	for _, name := range path {
+		var next []*ast.SelectionSet
+		for _, set := range sets {
+			next = append(next, childSelections(set, name, info.Fragments)...)
+		}
+		if len(next) == 0 {
+			return false
+		}
+		sets = next
+	}
+	return true
+}
+
+func childSelections(set *ast.SelectionSet, name string, fragments map[string]ast.Definition) []*ast.SelectionSet {
+	if set == nil {
+		return nil
+	}
+
+	var children []*ast.SelectionSet
+	for _, sel := range set.Selections {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if sel.Name.Value == name {
+				children = append(children, sel.SelectionSet)
+			}
+		case *ast.InlineFragment:
+			children = append(children, childSelections(sel.SelectionSet, name, fragments)...)
+		case *ast.FragmentSpread:
+			if def, ok := fragments[sel.Name.Value].(*ast.FragmentDefinition); ok {
+				children = append(children, childSelections(def.SelectionSet, name, fragments)...)
+			}
+		}
+	}
+	return children
+}
@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/graphql-go/graphql"
+)
+
+// Поля GraphQL типов совпадают с полями сущностей без учета регистра,
+// поэтому для них хватает резолвера по умолчанию.
+
+var deliveryType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Delivery",
+	Fields: graphql.Fields{
+		"name":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"phone":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"zip":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"city":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"address": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"region":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"email":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+	},
+})
+
+var paymentType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Payment",
+	Fields: graphql.Fields{
+		"transaction":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"requestId":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"currency":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"provider":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"amount":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"paymentDt":    &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		"bank":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"deliveryCost": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"goodsTotal":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"customFee":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+	},
+})
+
+var itemType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Item",
+	Fields: graphql.Fields{
+		"chrtId":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"trackNumber": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"price":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"rid":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"name":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"sale":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"size":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"totalPrice":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"nmId":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"brand":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"status":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+	},
+})
+
+var orderType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Order",
+	Fields: graphql.Fields{
+		"orderUid":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"trackNumber":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"entry":           &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"locale":          &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"internalSig":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"customerId":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"deliveryService": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"shardKey":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"smId":            &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"dateCreated":     &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		"oofShard":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"delivery":        &graphql.Field{Type: graphql.NewNonNull(deliveryType), Resolve: resolveDelivery},
+		"payment":         &graphql.Field{Type: graphql.NewNonNull(paymentType)},
+		"items":           &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(itemType)))},
+	},
+})
+
+var orderConnectionType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "OrderConnection",
+	Fields: graphql.Fields{
+		"nodes": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(orderType)))},
+		"nextCursor": &graphql.Field{
+			Type:        graphql.String,
+			Description: "Курсор следующей страницы, null если это последняя страница",
+		},
+	},
+})
+
+var orderSortType = graphql.NewEnum(graphql.EnumConfig{
+	Name: "OrderSort",
+	Values: graphql.EnumValueConfigMap{
+		"DATE_CREATED_DESC": &graphql.EnumValueConfig{Value: false},
+		"DATE_CREATED_ASC":  &graphql.EnumValueConfig{Value: true},
+	},
+})
+
+// orderConnection результат поиска заказов
+type orderConnection struct {
+	Nodes      []entities.Order
+	NextCursor *string
+}
+
+var errGraphQLInternal = errors.New("internal server error")
+
+// resolveDelivery маскирует доставку, если клиенту нельзя видеть персональные данные,
+// иначе запоминает заказ для журнала аудита
+func resolveDelivery(p graphql.ResolveParams) (any, error) {
+	order, ok := p.Source.(entities.Order)
+	if !ok {
+		return nil, errGraphQLInternal
+	}
+	access, ok := p.Context.Value(piiAccessKey{}).(*graphqlPIIAccess)
+	if !ok {
+		return nil, errGraphQLInternal
+	}
+	return access.delivery(order), nil
+}
+
+func (h *GraphQLHandler) newSchema() (graphql.Schema, error) {
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"order": &graphql.Field{
+				Type:        orderType,
+				Description: "Заказ по UID, null если заказ не найден",
+				Args: graphql.FieldConfigArgument{
+					"uid": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: h.resolveOrder,
+			},
+			"orderByTrack": &graphql.Field{
+				Type:        orderType,
+				Description: "Заказ по трек-номеру, null если заказ не найден",
+				Args: graphql.FieldConfigArgument{
+					"trackNumber": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: h.resolveOrderByTrack,
+			},
+			"orders": &graphql.Field{
+				Type:        graphql.NewNonNull(orderConnectionType),
+				Description: "Поиск заказов с пагинацией по курсору",
+				Args: graphql.FieldConfigArgument{
+					"customerId":      &graphql.ArgumentConfig{Type: graphql.String},
+					"deliveryService": &graphql.ArgumentConfig{Type: graphql.String},
+					"locale":          &graphql.ArgumentConfig{Type: graphql.String},
+					"currency":        &graphql.ArgumentConfig{Type: graphql.String},
+					"provider":        &graphql.ArgumentConfig{Type: graphql.String},
+					"brand":           &graphql.ArgumentConfig{Type: graphql.String},
+					"phone":           &graphql.ArgumentConfig{Type: graphql.String},
+					"email":           &graphql.ArgumentConfig{Type: graphql.String},
+					"createdFrom":     &graphql.ArgumentConfig{Type: graphql.DateTime},
+					"createdTo":       &graphql.ArgumentConfig{Type: graphql.DateTime},
+					"sort":            &graphql.ArgumentConfig{Type: orderSortType, DefaultValue: false},
+					"first":           &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+					"after":           &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: h.resolveOrders,
+			},
+		},
+	})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: query})
+	if err != nil {
+		return graphql.Schema{}, fmt.Errorf("failed to build graphql schema: %w", err)
+	}
+	return schema, nil
+}
+
+// resolveOrder не загружает заказ сразу, а откладывает загрузку,
+// чтобы все заказы запроса были получены одним вызовом сервиса
+func (h *GraphQLHandler) resolveOrder(p graphql.ResolveParams) (any, error) {
+	loader, ok := p.Source.(*orderLoader)
+	if !ok {
+		return nil, errGraphQLInternal
+	}
+	uid, _ := p.Args["uid"].(string)
+	return loader.load(p.Context, uid), nil
+}
+
+func (h *GraphQLHandler) resolveOrderByTrack(p graphql.ResolveParams) (any, error) {
+	trackNumber, _ := p.Args["trackNumber"].(string)
+
+	order, err := h.svc.GetOrderByTrackNumber(p.Context, trackNumber, false)
+	if errors.Is(err, entities.ErrOrderNotFound) {
+		return nil, nil //nolint:nilnil // null в ответе означает, что заказ не найден
+	}
+	if err != nil {
+		h.logger.ErrorContext(p.Context, "failed to get order by track number",
+			slog.Any("error", err), slog.String("trackNumber", trackNumber))
+		return nil, errGraphQLInternal
+	}
+	return order, nil
+}
+
+func (h *GraphQLHandler) resolveOrders(p graphql.ResolveParams) (any, error) {
+	filter := entities.OrderFilter{
+		// товары не загружаются, если клиент их не запросил
+		ExcludeItems: !selects(p.Info, "nodes", "items"),
+	}
+	for arg, dst := range map[string]*string{
+		"customerId":      &filter.CustomerID,
+		"deliveryService": &filter.DeliveryService,
+		"locale":          &filter.Locale,
+		"currency":        &filter.Currency,
+		"provider":        &filter.Provider,
+		"brand":           &filter.Brand,
+		"phone":           &filter.Phone,
+		"email":           &filter.Email,
+	} {
+		*dst, _ = p.Args[arg].(string)
+	}
+	if t, ok := p.Args["createdFrom"].(time.Time); ok {
+		filter.CreatedFrom = t
+	}
+	if t, ok := p.Args["createdTo"].(time.Time); ok {
+		filter.CreatedTo = t
+	}
+	filter.SortAsc, _ = p.Args["sort"].(bool)
+
+	filter.Limit, _ = p.Args["first"].(int)
+	if filter.Limit < 1 || filter.Limit > maxPageSize {
+		return nil, fmt.Errorf("first must be from 1 to %d", maxPageSize)
+	}
+
+	if after, ok := p.Args["after"].(string); ok && after != "" {
+		cursor, err := decodeCursor(after)
+		if err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+		filter.After = cursor
+	}
+
+	page, err := h.svc.ListOrders(p.Context, filter)
+	if err != nil {
+		h.logger.ErrorContext(p.Context, "failed to list orders", slog.Any("error", err))
+		return nil, errGraphQLInternal
+	}
+
+	conn := orderConnection{Nodes: page.Orders}
+	if page.Next != nil {
+		next := encodeCursor(page.Next)
+		conn.NextCursor = &next
+	}
+	if conn.Nodes == nil {
+		conn.Nodes = []entities.Order{}
+	}
+	return conn, nil
+}
@@ -0,0 +1,193 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/handler"
+	mocks "github.com/SergeyBogomolovv/l0-order-service/internal/handler/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQLHandler_Query(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name         string
+		query        string
+		variables    map[string]any
+		mockBehavior func(svc *mocks.MockOrderService)
+		wantStatus   int
+		wantBody     []string
+	}{
+		{
+			name:  "orders by uid are loaded in one batch",
+			query: `{ a: order(uid: "1") { orderUid payment { amount } } b: order(uid: "2") { orderUid } }`,
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().GetOrdersByIDs(mock.Anything, mock.MatchedBy(func(uids []string) bool {
+					return len(uids) == 2 && slices.Contains(uids, "1") && slices.Contains(uids, "2")
+				})).Return([]entities.Order{{OrderUID: "1", Payment: entities.Payment{Amount: 100}}}, []string{"2"}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   []string{`"a":{"orderUid":"1","payment":{"amount":100}}`, `"b":null`},
+		},
+		{
+			name:  "order by track not found",
+			query: `{ orderByTrack(trackNumber: "T1") { orderUid } }`,
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().GetOrderByTrackNumber(mock.Anything, "T1", false).
+					Return(entities.Order{}, entities.ErrOrderNotFound).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   []string{`{"data":{"orderByTrack":null}}`},
+		},
+		{
+			name:      "search without items",
+			query:     `query($first: Int) { orders(customerId: "c1", sort: DATE_CREATED_ASC, first: $first) { nodes { orderUid dateCreated } nextCursor } }`,
+			variables: map[string]any{"first": 1},
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().ListOrders(mock.Anything, entities.OrderFilter{
+					CustomerID: "c1", SortAsc: true, Limit: 1, ExcludeItems: true,
+				}).Return(entities.OrderPage{
+					Orders: []entities.Order{{OrderUID: "1", DateCreated: created}},
+					Next:   &entities.OrderCursor{OrderUID: "1", DateCreated: created},
+				}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   []string{`"nodes":[{"dateCreated":"2025-01-01T00:00:00Z","orderUid":"1"}]`, `"nextCursor":"ey`},
+		},
+		{
+			name:  "search with items in fragment",
+			query: `{ orders { nodes { ...withItems } nextCursor } } fragment withItems on Order { items { name } }`,
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().ListOrders(mock.Anything, entities.OrderFilter{Limit: 20}).
+					Return(entities.OrderPage{Orders: []entities.Order{{Items: []entities.Item{{Name: "mask"}}}}}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   []string{`"nodes":[{"items":[{"name":"mask"}]}]`, `"nextCursor":null`},
+		},
+		{
+			name:         "huge page is rejected by resolver",
+			query:        `{ orders(first: 1000) { nextCursor } }`,
+			mockBehavior: func(_ *mocks.MockOrderService) {},
+			wantStatus:   http.StatusOK,
+			wantBody:     []string{`first must be from 1 to 100`},
+		},
+		{
+			name: "huge page does not hide other aliases",
+			query: `{ a: orders(first: 9223372036854775807) { nodes { orderUid } } ` +
+				`b: orders(first: 100) { nodes { items { name brand price } } } }`,
+			mockBehavior: func(_ *mocks.MockOrderService) {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     []string{`query complexity 1001 exceeds limit 1000`},
+		},
+		{
+			name:         "huge page in variables",
+			query:        `query($first: Int) { orders(first: $first) { nodes { orderUid } } }`,
+			variables:    map[string]any{"first": 1e300},
+			mockBehavior: func(_ *mocks.MockOrderService) {},
+			wantStatus:   http.StatusOK,
+			wantBody:     []string{`got invalid value`},
+		},
+		{
+			name:         "page size out of range",
+			query:        `{ orders(first: 0) { nextCursor } }`,
+			mockBehavior: func(_ *mocks.MockOrderService) {},
+			wantStatus:   http.StatusOK,
+			wantBody:     []string{`first must be from 1 to 100`},
+		},
+		{
+			name:         "too complex query",
+			query:        `{ orders(first: 100) { nodes { orderUid items { name brand price } } } }`,
+			mockBehavior: func(_ *mocks.MockOrderService) {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     []string{`exceeds limit`},
+		},
+		{
+			name:         "unknown field",
+			query:        `{ order(uid: "1") { secret } }`,
+			mockBehavior: func(_ *mocks.MockOrderService) {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     []string{`Cannot query field \"secret\" on type \"Order\"`},
+		},
+		{
+			name:         "empty query",
+			mockBehavior: func(_ *mocks.MockOrderService) {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     []string{`query is required`},
+		},
+		{
+			name:  "internal error",
+			query: `{ order(uid: "1") { orderUid } }`,
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().GetOrdersByIDs(mock.Anything, []string{"1"}).Return(nil, nil, errors.New("db error")).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   []string{`"message":"internal server error"`, `"order":null`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := mocks.NewMockOrderService(t)
+			tc.mockBehavior(svc)
+
+			r := newGraphQLRouter(t, svc)
+
+			body, err := json.Marshal(handler.GraphQLRequest{Query: tc.query, Variables: tc.variables})
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			for _, want := range tc.wantBody {
+				assert.Contains(t, rr.Body.String(), want)
+			}
+		})
+	}
+}
+
+func TestGraphQLHandler_QueryGet(t *testing.T) {
+	svc := mocks.NewMockOrderService(t)
+	svc.EXPECT().GetOrdersByIDs(mock.Anything, []string{"1"}).
+		Return([]entities.Order{{OrderUID: "1"}}, nil, nil).Once()
+
+	r := newGraphQLRouter(t, svc)
+
+	q := url.Values{}
+	q.Set("query", `query($uid: String!) { order(uid: $uid) { orderUid } }`)
+	q.Set("variables", `{"uid":"1"}`)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/graphql?"+q.Encode(), nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"data":{"order":{"orderUid":"1"}}}`, rr.Body.String())
+}
+
+func newGraphQLRouter(t *testing.T, svc handler.OrderService) chi.Router {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h, err := handler.NewGraphQLHandler(logger, config.GraphQL{MaxComplexity: 1000}, svc, testPII{})
+	require.NoError(t, err)
+
+	r := newTestRouter()
+	h.Init(r)
+	return r
+}
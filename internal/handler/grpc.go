@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	orderv1 "github.com/SergeyBogomolovv/l0-order-service/api/order/v1"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type OrderFeed interface {
+	// Subscribe возвращает канал сохраненных заказов и функцию отписки.
+	// Если afterID не пустой, сначала приходят события из истории после события afterID.
+	// Канал закрывается, если подписчик не успевает читать.
+	Subscribe(afterID string) (<-chan entities.OrderEvent, func())
+}
+
+type GRPCHandler struct {
+	orderv1.UnimplementedOrderServiceServer
+
+	logger *slog.Logger
+	svc    OrderService
+	feed   OrderFeed
+	pii    PIIPolicy
+}
+
+func NewGRPCHandler(logger *slog.Logger, svc OrderService, feed OrderFeed, pii PIIPolicy) *GRPCHandler {
+	return &GRPCHandler{
+		logger: logger.With(slog.String("handler", "grpc")),
+		svc:    svc,
+		feed:   feed,
+		pii:    pii,
+	}
+}
+
+func (h *GRPCHandler) Register(s *grpc.Server) {
+	orderv1.RegisterOrderServiceServer(s, h)
+}
+
+func (h *GRPCHandler) GetOrder(ctx context.Context, req *orderv1.GetOrderRequest) (*orderv1.Order, error) {
+	if req.GetOrderUid() == "" {
+		return nil, status.Error(codes.InvalidArgument, "order_uid is required")
+	}
+
+	order, err := h.svc.GetOrderByID(ctx, req.GetOrderUid(), false)
+	if errors.Is(err, entities.ErrOrderNotFound) {
+		return nil, status.Error(codes.NotFound, "order not found")
+	}
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to get order", slog.Any("error", err), slog.String("orderUID", req.GetOrderUid()))
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	order, err = protectOrder(ctx, h.pii, grpcMethod(ctx), order)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to protect order", slog.Any("error", err))
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	return OrderEntityToProto(order), nil
+}
+
+func (h *GRPCHandler) BatchGetOrders(
+	ctx context.Context, req *orderv1.BatchGetOrdersRequest,
+) (*orderv1.BatchGetOrdersResponse, error) {
+	uids := req.GetOrderUids()
+	if len(uids) == 0 || len(uids) > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "order_uids must contain from 1 to %d ids", maxBatchSize)
+	}
+
+	orders, missing, err := h.svc.GetOrdersByIDs(ctx, uids)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to get orders", slog.Any("error", err), slog.Int("count", len(uids)))
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	orders, err = protectOrders(ctx, h.pii, grpcMethod(ctx), projection{}, orders)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to protect orders", slog.Any("error", err))
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	return &orderv1.BatchGetOrdersResponse{
+		Orders:  OrdersEntityToProto(orders),
+		Missing: missing,
+	}, nil
+}
+
+func (h *GRPCHandler) ListOrders(ctx context.Context, req *orderv1.ListOrdersRequest) (*orderv1.ListOrdersResponse, error) {
+	filter := entities.OrderFilter{
+		CustomerID:      req.GetCustomerId(),
+		DeliveryService: req.GetDeliveryService(),
+		Locale:          req.GetLocale(),
+		Currency:        req.GetCurrency(),
+		Provider:        req.GetProvider(),
+		Brand:           req.GetBrand(),
+		SortAsc:         req.GetSortAsc(),
+		Limit:           int(req.GetPageSize()),
+		ExcludeItems:    req.GetExcludeItems(),
+	}
+	if req.GetCreatedFrom() != nil {
+		filter.CreatedFrom = req.GetCreatedFrom().AsTime()
+	}
+	if req.GetCreatedTo() != nil {
+		filter.CreatedTo = req.GetCreatedTo().AsTime()
+	}
+
+	switch {
+	case filter.Limit == 0:
+		filter.Limit = defaultPageSize
+	case filter.Limit < 0 || filter.Limit > maxPageSize:
+		return nil, status.Errorf(codes.InvalidArgument, "page_size must be from 1 to %d", maxPageSize)
+	}
+
+	// токен страницы тот же, что и курсор в HTTP API
+	if token := req.GetPageToken(); token != "" {
+		after, err := decodeCursor(token)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+		filter.After = after
+	}
+
+	page, err := h.svc.ListOrders(ctx, filter)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list orders", slog.Any("error", err))
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	page.Orders, err = protectOrders(ctx, h.pii, grpcMethod(ctx), projection{}, page.Orders)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to protect orders", slog.Any("error", err))
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	return &orderv1.ListOrdersResponse{
+		Orders:        OrdersEntityToProto(page.Orders),
+		NextPageToken: encodeCursor(page.Next),
+	}, nil
+}
+
+func (h *GRPCHandler) WatchOrders(req *orderv1.WatchOrdersRequest, stream grpc.ServerStreamingServer[orderv1.Order]) error {
+	ctx := stream.Context()
+
+	reveal, err := revealPIIOnce(ctx, h.pii, grpcMethod(ctx))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to log pii access", slog.Any("error", err))
+		return status.Error(codes.Internal, "internal server error")
+	}
+
+	events, unsubscribe := h.feed.Subscribe("")
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "client is too slow, reconnect to continue")
+			}
+			order := event.Order
+			if req.GetCustomerId() != "" && order.CustomerID != req.GetCustomerId() {
+				continue
+			}
+			if req.GetDeliveryService() != "" && order.DeliveryService != req.GetDeliveryService() {
+				continue
+			}
+			if !reveal {
+				order.Delivery = h.pii.MaskDelivery(order.Delivery)
+			}
+			if err := stream.Send(OrderEntityToProto(order)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// grpcMethod полное имя вызванного метода, в журнале аудита оно заменяет путь запроса
+func grpcMethod(ctx context.Context) string {
+	method, _ := grpc.Method(ctx)
+	return method
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	orderv1 "github.com/SergeyBogomolovv/l0-order-service/api/order/v1"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func OrderEntityToProto(o entities.Order) *orderv1.Order {
+	items := make([]*orderv1.Item, 0, len(o.Items))
+	for _, item := range o.Items {
+		items = append(items, ItemEntityToProto(item))
+	}
+
+	return &orderv1.Order{
+		OrderUid:          o.OrderUID,
+		TrackNumber:       o.TrackNumber,
+		Entry:             o.Entry,
+		Delivery:          DeliveryEntityToProto(o.Delivery),
+		Payment:           PaymentEntityToProto(o.Payment),
+		Items:             items,
+		Locale:            o.Locale,
+		InternalSignature: o.InternalSig,
+		CustomerId:        o.CustomerID,
+		DeliveryService:   o.DeliveryService,
+		Shardkey:          o.ShardKey,
+		SmId:              int64(o.SmID),
+		DateCreated:       timestamppb.New(o.DateCreated),
+		OofShard:          o.OofShard,
+	}
+}
+
+func OrdersEntityToProto(orders []entities.Order) []*orderv1.Order {
+	res := make([]*orderv1.Order, 0, len(orders))
+	for _, order := range orders {
+		res = append(res, OrderEntityToProto(order))
+	}
+	return res
+}
+
+func DeliveryEntityToProto(d entities.Delivery) *orderv1.Delivery {
+	return &orderv1.Delivery{
+		Name:    d.Name,
+		Phone:   d.Phone,
+		Zip:     d.ZIP,
+		City:    d.City,
+		Address: d.Address,
+		Region:  d.Region,
+		Email:   d.Email,
+	}
+}
+
+func PaymentEntityToProto(p entities.Payment) *orderv1.Payment {
+	return &orderv1.Payment{
+		Transaction:  p.Transaction,
+		RequestId:    p.RequestID,
+		Currency:     p.Currency,
+		Provider:     p.Provider,
+		Amount:       int64(p.Amount),
+		PaymentDt:    timestamppb.New(p.PaymentDT),
+		Bank:         p.Bank,
+		DeliveryCost: int64(p.DeliveryCost),
+		GoodsTotal:   int64(p.GoodsTotal),
+		CustomFee:    int64(p.CustomFee),
+	}
+}
+
+func ItemEntityToProto(i entities.Item) *orderv1.Item {
+	return &orderv1.Item{
+		ChrtId:      int64(i.ChrtID),
+		TrackNumber: i.TrackNumber,
+		Price:       int64(i.Price),
+		Rid:         i.RID,
+		Name:        i.Name,
+		Sale:        int64(i.Sale),
+		Size:        i.Size,
+		TotalPrice:  int64(i.TotalPrice),
+		NmId:        int64(i.NmID),
+		Brand:       i.Brand,
+		Status:      int64(i.Status),
+	}
+}
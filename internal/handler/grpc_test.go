@@ -0,0 +1,275 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	orderv1 "github.com/SergeyBogomolovv/l0-order-service/api/order/v1"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/handler"
+	mocks "github.com/SergeyBogomolovv/l0-order-service/internal/handler/mocks"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGRPCClient поднимает сервер в памяти. Запросы выполняются от имени клиента test с правами scopes.
+func newGRPCClient(
+	t *testing.T, svc handler.OrderService, feed handler.OrderFeed, pii handler.PIIPolicy, scopes ...string,
+) orderv1.OrderServiceClient {
+	t.Helper()
+
+	p := entities.Principal{Subject: "test", Scopes: scopes}
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(func(
+			ctx context.Context, req any, _ *grpc.UnaryServerInfo, next grpc.UnaryHandler,
+		) (any, error) {
+			return next(middleware.WithPrincipal(ctx, p), req)
+		}),
+		grpc.StreamInterceptor(func(
+			srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, next grpc.StreamHandler,
+		) error {
+			return next(srv, &testStream{ServerStream: ss, ctx: middleware.WithPrincipal(ss.Context(), p)})
+		}),
+	)
+	handler.NewGRPCHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), svc, feed, pii).Register(srv)
+	go srv.Serve(lis) //nolint:errcheck // ошибка после Stop не важна
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return orderv1.NewOrderServiceClient(conn)
+}
+
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestGRPCHandler_GetOrder(t *testing.T) {
+	testCases := []struct {
+		name         string
+		orderUID     string
+		mockBehavior func(svc *mocks.MockOrderService)
+		wantCode     codes.Code
+	}{
+		{
+			name:     "success",
+			orderUID: "123",
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().GetOrderByID(mock.Anything, "123", false).
+					Return(entities.Order{OrderUID: "123", Items: []entities.Item{{ChrtID: 1}}}, nil).Once()
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name:         "empty uid",
+			mockBehavior: func(_ *mocks.MockOrderService) {},
+			wantCode:     codes.InvalidArgument,
+		},
+		{
+			name:     "not found",
+			orderUID: "123",
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().GetOrderByID(mock.Anything, "123", false).
+					Return(entities.Order{}, entities.ErrOrderNotFound).Once()
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "internal error",
+			orderUID: "123",
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().GetOrderByID(mock.Anything, "123", false).
+					Return(entities.Order{}, errors.New("db error")).Once()
+			},
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := mocks.NewMockOrderService(t)
+			tc.mockBehavior(svc)
+			client := newGRPCClient(t, svc, mocks.NewMockOrderFeed(t), testPII{})
+
+			order, err := client.GetOrder(context.Background(), &orderv1.GetOrderRequest{OrderUid: tc.orderUID})
+
+			assert.Equal(t, tc.wantCode, status.Code(err))
+			if tc.wantCode == codes.OK {
+				assert.Equal(t, "123", order.GetOrderUid())
+				assert.Len(t, order.GetItems(), 1)
+			}
+		})
+	}
+}
+
+func TestGRPCHandler_BatchGetOrders(t *testing.T) {
+	svc := mocks.NewMockOrderService(t)
+	svc.EXPECT().GetOrdersByIDs(mock.Anything, []string{"1", "2"}).
+		Return([]entities.Order{{OrderUID: "1"}}, []string{"2"}, nil).Once()
+	client := newGRPCClient(t, svc, mocks.NewMockOrderFeed(t), testPII{})
+
+	resp, err := client.BatchGetOrders(context.Background(), &orderv1.BatchGetOrdersRequest{OrderUids: []string{"1", "2"}})
+	require.NoError(t, err)
+	assert.Len(t, resp.GetOrders(), 1)
+	assert.Equal(t, []string{"2"}, resp.GetMissing())
+
+	_, err = client.BatchGetOrders(context.Background(), &orderv1.BatchGetOrdersRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPCHandler_ListOrders(t *testing.T) {
+	next := &entities.OrderCursor{DateCreated: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), OrderUID: "123"}
+
+	svc := mocks.NewMockOrderService(t)
+	svc.EXPECT().ListOrders(mock.Anything, entities.OrderFilter{CustomerID: "c1", Limit: 20}).
+		Return(entities.OrderPage{Orders: []entities.Order{{OrderUID: "123"}}, Next: next}, nil).Once()
+	svc.EXPECT().ListOrders(mock.Anything, mock.MatchedBy(func(f entities.OrderFilter) bool {
+		return f.After != nil && f.After.OrderUID == "123" && f.Limit == 1
+	})).Return(entities.OrderPage{}, nil).Once()
+	client := newGRPCClient(t, svc, mocks.NewMockOrderFeed(t), testPII{})
+
+	resp, err := client.ListOrders(context.Background(), &orderv1.ListOrdersRequest{CustomerId: "c1"})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.GetNextPageToken())
+
+	resp, err = client.ListOrders(context.Background(), &orderv1.ListOrdersRequest{PageSize: 1, PageToken: resp.GetNextPageToken()})
+	require.NoError(t, err)
+	assert.Empty(t, resp.GetNextPageToken())
+
+	_, err = client.ListOrders(context.Background(), &orderv1.ListOrdersRequest{PageSize: 1000})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPCHandler_WatchOrders(t *testing.T) {
+	events := make(chan entities.OrderEvent, 3)
+	unsubscribed := make(chan struct{})
+
+	feed := mocks.NewMockOrderFeed(t)
+	feed.EXPECT().Subscribe("").Return(events, func() { close(unsubscribed) }).Once()
+	client := newGRPCClient(t, mocks.NewMockOrderService(t), feed, testPII{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.WatchOrders(ctx, &orderv1.WatchOrdersRequest{CustomerId: "c1"})
+	require.NoError(t, err)
+
+	events <- entities.OrderEvent{ID: "1", Order: entities.Order{OrderUID: "1", CustomerID: "c2"}}
+	events <- entities.OrderEvent{ID: "2", Order: entities.Order{OrderUID: "2", CustomerID: "c1"}}
+
+	order, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "2", order.GetOrderUid())
+
+	// закрытый канал означает, что подписчик отстал
+	close(events)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	cancel()
+	select {
+	case <-unsubscribed:
+	case <-time.After(time.Second):
+		t.Fatal("subscription was not closed")
+	}
+}
+
+func TestGRPCHandler_PIIMasking(t *testing.T) {
+	order := entities.Order{OrderUID: "1", Delivery: entities.Delivery{Phone: "+79991234567"}}
+
+	testCases := []struct {
+		name         string
+		scopes       []string
+		mockBehavior func(pii *mocks.MockPIIPolicy)
+		wantCode     codes.Code
+		wantPhone    string
+	}{
+		{
+			name:   "masked",
+			scopes: []string{entities.ScopeOrdersRead},
+			mockBehavior: func(pii *mocks.MockPIIPolicy) {
+				pii.EXPECT().CanReveal(mock.Anything).Return(false).Once()
+				pii.EXPECT().MaskDelivery(order.Delivery).Return(entities.Delivery{Phone: "+7******4567"}).Once()
+			},
+			wantCode:  codes.OK,
+			wantPhone: "+7******4567",
+		},
+		{
+			name:   "unmasked access is audited",
+			scopes: []string{entities.ScopeOrdersRead, entities.ScopePIIRead},
+			mockBehavior: func(pii *mocks.MockPIIPolicy) {
+				pii.EXPECT().CanReveal(mock.Anything).Return(true).Once()
+				pii.EXPECT().LogAccess(mock.Anything, entities.PIIAccess{
+					Subject:   "test",
+					Resource:  orderv1.OrderService_GetOrder_FullMethodName,
+					OrderUIDs: []string{"1"},
+				}).Return(nil).Once()
+			},
+			wantCode:  codes.OK,
+			wantPhone: "+79991234567",
+		},
+		{
+			name:   "no data without audit record",
+			scopes: []string{entities.ScopeOrdersRead, entities.ScopePIIRead},
+			mockBehavior: func(pii *mocks.MockPIIPolicy) {
+				pii.EXPECT().CanReveal(mock.Anything).Return(true).Once()
+				pii.EXPECT().LogAccess(mock.Anything, mock.Anything).Return(errors.New("db error")).Once()
+			},
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := mocks.NewMockOrderService(t)
+			svc.EXPECT().GetOrderByID(mock.Anything, "1", false).Return(order, nil).Once()
+			pii := mocks.NewMockPIIPolicy(t)
+			tc.mockBehavior(pii)
+			client := newGRPCClient(t, svc, mocks.NewMockOrderFeed(t), pii, tc.scopes...)
+
+			resp, err := client.GetOrder(context.Background(), &orderv1.GetOrderRequest{OrderUid: "1"})
+
+			assert.Equal(t, tc.wantCode, status.Code(err))
+			assert.Equal(t, tc.wantPhone, resp.GetDelivery().GetPhone())
+		})
+	}
+}
+
+func TestGRPCHandler_WatchOrdersPIIMasking(t *testing.T) {
+	events := make(chan entities.OrderEvent, 1)
+
+	feed := mocks.NewMockOrderFeed(t)
+	feed.EXPECT().Subscribe("").Return(events, func() {}).Once()
+	client := newGRPCClient(t, mocks.NewMockOrderService(t), feed, testPII{}, entities.ScopeOrdersRead)
+
+	stream, err := client.WatchOrders(context.Background(), &orderv1.WatchOrdersRequest{})
+	require.NoError(t, err)
+
+	order := entities.Order{OrderUID: "1", Delivery: entities.Delivery{Phone: "+79991234567"}}
+	events <- entities.OrderEvent{ID: "1", Order: order}
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "masked", resp.GetDelivery().GetPhone())
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+type ReadinessChecker interface {
+	Readiness(ctx context.Context) entities.Readiness
+}
+
+// HealthHandler проверки для liveness и readiness проб. Регистрируется на административном сервере
+// без аутентификации и не входит в документацию публичного API.
+type HealthHandler struct {
+	checker ReadinessChecker
+}
+
+func NewHealthHandler(checker ReadinessChecker) *HealthHandler {
+	return &HealthHandler{checker: checker}
+}
+
+func (h *HealthHandler) Init(r chi.Router) {
+	r.Get("/healthz", h.Live)
+	r.Get("/readyz", h.Ready)
+}
+
+// Live отвечает 200, пока процесс обрабатывает запросы. Зависимости не проверяются.
+func (h *HealthHandler) Live(w http.ResponseWriter, _ *http.Request) {
+	utils.WriteJSON(w, HealthResponse{Status: "ok"}, http.StatusOK)
+}
+
+// Ready проверяет Postgres, доступность брокеров Kafka, что чтение заказов не зависло и кэш прогрет.
+// Во время остановки сервиса отвечает 503, не проверяя зависимости.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	readiness := h.checker.Readiness(r.Context())
+
+	status := http.StatusOK
+	if !readiness.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	utils.WriteJSON(w, ReadinessToJSON(readiness), status)
+}
@@ -0,0 +1,74 @@
+package handler_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/handler"
+	mocks "github.com/SergeyBogomolovv/l0-order-service/internal/handler/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHealthHandler_Ready(t *testing.T) {
+	testCases := []struct {
+		name       string
+		readiness  entities.Readiness
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "ready",
+			readiness: entities.Readiness{Ready: true, Checks: []entities.DependencyStatus{
+				{Name: "postgres"}, {Name: "kafka"},
+			}},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"ok","checks":{"postgres":"ok","kafka":"ok"}}`,
+		},
+		{
+			name: "dependency is down",
+			readiness: entities.Readiness{Checks: []entities.DependencyStatus{
+				{Name: "postgres"}, {Name: "cache", Err: errors.New("cache warm-up is not finished")},
+			}},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"status":"unavailable","checks":{"postgres":"ok","cache":"cache warm-up is not finished"}}`,
+		},
+		{
+			name:       "shutting down",
+			readiness:  entities.Readiness{ShuttingDown: true},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"status":"shutting_down"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checker := mocks.NewMockReadinessChecker(t)
+			checker.EXPECT().Readiness(mock.Anything).Return(tc.readiness).Once()
+
+			r := chi.NewRouter()
+			handler.NewHealthHandler(checker).Init(r)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			assert.JSONEq(t, tc.wantBody, w.Body.String())
+		})
+	}
+}
+
+func TestHealthHandler_Live(t *testing.T) {
+	r := chi.NewRouter()
+	handler.NewHealthHandler(mocks.NewMockReadinessChecker(t)).Init(r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+}
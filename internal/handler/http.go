@@ -1,70 +1,524 @@
 package handler
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"io"
 	"log/slog"
+	"maps"
 	"net/http"
+	"time"
 
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
 	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/problem"
 	"github.com/SergeyBogomolovv/l0-order-service/pkg/utils"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 )
 
-type OrderGetter interface {
-	GetOrderByID(ctx context.Context, orderUID string) (entities.Order, error)
+const (
+	maxBodySize  = 10 << 20
+	maxBatchSize = 100
+)
+
+type OrderService interface {
+	GetOrderByID(ctx context.Context, orderUID string, excludeItems bool) (entities.Order, error)
+	GetOrdersByIDs(ctx context.Context, orderUIDs []string) ([]entities.Order, []string, error)
+	SaveOrder(ctx context.Context, order entities.Order) error
+	ListOrders(ctx context.Context, filter entities.OrderFilter) (entities.OrderPage, error)
+	GetOrderByTrackNumber(ctx context.Context, trackNumber string, excludeItems bool) (entities.Order, error)
+	GetOrdersByItemTrackNumber(
+		ctx context.Context, trackNumber string, limit int, after *entities.OrderCursor,
+	) (entities.OrderPage, error)
+}
+
+// IdempotencyStore хранит ключи идемпотентности клиентов, subject - клиент, которому принадлежит ключ
+type IdempotencyStore interface {
+	Begin(ctx context.Context, subject, key, requestHash string) (*entities.IdempotentResponse, error)
+	Complete(ctx context.Context, subject, key string, resp entities.IdempotentResponse) error
+	Release(ctx context.Context, subject, key string) error
+}
+
+type OrderWaiter interface {
+	// Wait возвращает канал, который закроется при сохранении заказа, и функцию отмены ожидания
+	Wait(orderUID string) (<-chan struct{}, func())
 }
 
 type HTTPHandler struct {
-	logger   *slog.Logger
-	validate *validator.Validate
-	svc      OrderGetter
+	logger       *slog.Logger
+	validate     *validator.Validate
+	svc          OrderService
+	idempotency  IdempotencyStore
+	waiter       OrderWaiter
+	pii          PIIPolicy
+	cacheControl string
+	maxWait      time.Duration
 }
 
-func NewHTTPHandler(logger *slog.Logger, svc OrderGetter) *HTTPHandler {
+func NewHTTPHandler(
+	logger *slog.Logger, cfg config.HTTP, svc OrderService, idempotency IdempotencyStore, waiter OrderWaiter,
+	pii PIIPolicy,
+) *HTTPHandler {
 	return &HTTPHandler{
-		logger:   logger.With(slog.String("handler", "http")),
-		validate: validator.New(),
-		svc:      svc,
+		logger:       logger.With(slog.String("handler", "http")),
+		validate:     newValidator(),
+		svc:          svc,
+		idempotency:  idempotency,
+		waiter:       waiter,
+		pii:          pii,
+		cacheControl: cfg.CacheControl,
+		maxWait:      cfg.MaxWait,
 	}
 }
 
 func (h *HTTPHandler) Init(r chi.Router) {
-	r.Get("/order/{order_uid}", h.GetOrderByID)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(entities.ScopeOrdersRead))
+		r.Get("/order/{order_uid}", h.GetOrderByID)
+		r.Get("/orders", h.ListOrders)
+		r.Get("/orders/by-track/{track_number}", h.GetOrderByTrackNumber)
+		r.Get("/orders/by-item-track/{track_number}", h.GetOrdersByItemTrackNumber)
+		r.Post("/orders:batchGet", h.BatchGetOrders)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(entities.ScopeOrdersWrite))
+		r.Post("/orders", h.CreateOrders)
+		r.Post("/orders/validate", h.ValidateOrder)
+	})
 }
 
 // GetOrderByID возвращает заказ по ID.
 // @Summary      Получить заказ по UID
-// @Description  Возвращает информацию о заказе по его уникальному идентификатору
+// @Description  Возвращает информацию о заказе по его уникальному идентификатору.
+// @Description  С параметром wait ждет, пока заказ будет сохранен, и отвечает 404 только по истечении времени.
 // @Tags         orders
+// @Produce      json,xml,text/csv,application/msgpack
 // @Param        order_uid   path      string  true  "Уникальный идентификатор заказа"
+// @Param        wait  query  string  false  "Сколько ждать появления заказа, например 5s"
+// @Param        If-None-Match  header  string  false  "ETag из предыдущего ответа"
+// @Param        If-Modified-Since  header  string  false  "Last-Modified из предыдущего ответа, без If-None-Match"
+// @Param        fields  query  string  false  "Поля заказа через запятую, например order_uid,payment.amount,items.name"
+// @Param        exclude  query  string  false  "Не загружать товары" Enums(items)
 // @Success      200  {object}  Order
-// @Failure      400  {object}  utils.ValidationErrorResponse "Ошибка валидации"
-// @Failure      404  {object}  utils.ErrorResponse "Заказ не найден"
-// @Failure      500  {object}  utils.ErrorResponse "Внутренняя ошибка сервера"
+// @Success      304  "Заказ не изменился"
+// @Failure      400  {object}  problem.Problem "Ошибка валидации"
+// @Failure      404  {object}  problem.Problem "Заказ не найден"
+// @Failure      406  {object}  problem.Problem "Формат из Accept не поддерживается"
+// @Failure      500  {object}  problem.Problem "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
 // @Router       /order/{order_uid} [get]
 func (h *HTTPHandler) GetOrderByID(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	orderUID := chi.URLParam(r, "order_uid")
 
 	if err := h.validate.Var(orderUID, "required"); err != nil {
-		utils.WriteValidationError(w, err)
+		problem.WriteValidation(w, r, err)
 		return
 	}
 
-	order, err := h.svc.GetOrderByID(ctx, orderUID)
+	proj, fields := parseProjection(r)
+	wait, waitErr := h.parseWait(r)
+	if waitErr != "" {
+		fields["wait"] = waitErr
+	}
+	if len(fields) > 0 {
+		problem.WriteFields(w, r, fields)
+		return
+	}
+
+	enc, ok := negotiateEncoder(r)
+	if !ok {
+		problem.Write(w, r, problem.CodeNotAcceptable)
+		return
+	}
+
+	order, err := h.waitForOrder(ctx, orderUID, wait, proj.excludeItems)
 
 	if errors.Is(err, entities.ErrOrderNotFound) {
-		utils.WriteError(w, "order not found", http.StatusNotFound)
+		problem.Write(w, r, problem.CodeOrderNotFound)
 		return
 	}
 
 	if err != nil {
 		h.logger.ErrorContext(ctx, "failed to get order", slog.Any("error", err), slog.String("orderUID", orderUID))
-		utils.WriteError(w, "internal server error", http.StatusInternalServerError)
+		problem.Write(w, r, problem.CodeInternal)
+		return
+	}
+
+	h.writeOrder(w, r, order, proj, enc)
+}
+
+// BatchGetOrders возвращает заказы по списку UID.
+// @Summary      Получить заказы по списку UID
+// @Description  Принимает до 100 UID. Заказы из кэша отдаются сразу, остальные загружаются из базы одним запросом.
+// @Description  UID, которых нет в базе, возвращаются в поле missing.
+// @Tags         orders
+// @Accept       json
+// @Produce      json,xml,text/csv,application/msgpack
+// @Param        request  body  BatchGetOrdersRequest  true  "Список UID"
+// @Param        fields  query  string  false  "Поля заказа через запятую, например order_uid,payment.amount,items.name"
+// @Param        exclude  query  string  false  "Не загружать товары" Enums(items)
+// @Success      200  {object}  BatchGetOrdersResponse
+// @Failure      400  {object}  problem.Problem "Ошибка валидации"
+// @Failure      406  {object}  problem.Problem "Формат из Accept не поддерживается"
+// @Failure      500  {object}  problem.Problem "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /orders:batchGet [post]
+func (h *HTTPHandler) BatchGetOrders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	proj, fields := parseProjection(r)
+	if len(fields) > 0 {
+		problem.WriteFields(w, r, fields)
+		return
+	}
+
+	enc, ok := negotiateEncoder(r)
+	if !ok {
+		problem.Write(w, r, problem.CodeNotAcceptable)
+		return
+	}
+
+	var req BatchGetOrdersRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		problem.WriteValidation(w, r, err)
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		problem.WriteValidation(w, r, err)
+		return
+	}
+
+	orders, missing, err := h.svc.GetOrdersByIDs(ctx, req.OrderUIDs)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to get orders", slog.Any("error", err), slog.Int("count", len(req.OrderUIDs)))
+		problem.Write(w, r, problem.CodeInternal)
+		return
+	}
+
+	orders, err = protectOrders(ctx, h.pii, r.URL.Path, proj, orders)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to protect orders", slog.Any("error", err))
+		problem.Write(w, r, problem.CodeInternal)
+		return
+	}
+
+	writeResponse(w, r, enc, proj, BatchGetOrdersResponse{
+		Orders:  OrdersEntityToJSON(orders).Orders,
+		Missing: missing,
+	}, "orders", http.StatusOK)
+}
+
+// GetOrderByTrackNumber возвращает заказ по трек номеру.
+// @Summary      Получить заказ по трек номеру
+// @Description  Возвращает заказ по трек номеру заказа
+// @Tags         orders
+// @Produce      json,xml,text/csv,application/msgpack
+// @Param        track_number   path      string  true  "Трек номер заказа"
+// @Param        If-None-Match  header  string  false  "ETag из предыдущего ответа"
+// @Param        If-Modified-Since  header  string  false  "Last-Modified из предыдущего ответа, без If-None-Match"
+// @Param        fields  query  string  false  "Поля заказа через запятую, например order_uid,payment.amount,items.name"
+// @Param        exclude  query  string  false  "Не загружать товары" Enums(items)
+// @Success      200  {object}  Order
+// @Success      304  "Заказ не изменился"
+// @Failure      400  {object}  problem.Problem "Ошибка валидации"
+// @Failure      404  {object}  problem.Problem "Заказ не найден"
+// @Failure      406  {object}  problem.Problem "Формат из Accept не поддерживается"
+// @Failure      500  {object}  problem.Problem "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /orders/by-track/{track_number} [get]
+func (h *HTTPHandler) GetOrderByTrackNumber(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	trackNumber := chi.URLParam(r, "track_number")
+
+	if err := h.validate.Var(trackNumber, "required"); err != nil {
+		problem.WriteValidation(w, r, err)
+		return
+	}
+
+	proj, fields := parseProjection(r)
+	if len(fields) > 0 {
+		problem.WriteFields(w, r, fields)
+		return
+	}
+
+	enc, ok := negotiateEncoder(r)
+	if !ok {
+		problem.Write(w, r, problem.CodeNotAcceptable)
+		return
+	}
+
+	order, err := h.svc.GetOrderByTrackNumber(ctx, trackNumber, proj.excludeItems)
+
+	if errors.Is(err, entities.ErrOrderNotFound) {
+		problem.Write(w, r, problem.CodeOrderNotFound)
+		return
+	}
+
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to get order", slog.Any("error", err), slog.String("trackNumber", trackNumber))
+		problem.Write(w, r, problem.CodeInternal)
+		return
+	}
+
+	h.writeOrder(w, r, order, proj, enc)
+}
+
+// GetOrdersByItemTrackNumber возвращает заказы по трек номеру товара.
+// @Summary      Получить заказы по трек номеру товара
+// @Description  Возвращает страницу заказов, в которых есть товар с указанным трек номером, начиная с новых
+// @Tags         orders
+// @Produce      json,xml,text/csv,application/msgpack
+// @Param        track_number   path      string  true  "Трек номер товара"
+// @Param        limit   query  int     false  "Размер страницы" minimum(1) maximum(100) default(20)
+// @Param        cursor  query  string  false  "Курсор следующей страницы"
+// @Param        fields  query  string  false  "Поля заказа через запятую, например order_uid,payment.amount,items.name"
+// @Param        exclude  query  string  false  "Не загружать товары" Enums(items)
+// @Success      200  {object}  OrderListResponse
+// @Failure      400  {object}  problem.Problem "Ошибка валидации"
+// @Failure      404  {object}  problem.Problem "Заказы не найдены"
+// @Failure      406  {object}  problem.Problem "Формат из Accept не поддерживается"
+// @Failure      500  {object}  problem.Problem "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /orders/by-item-track/{track_number} [get]
+func (h *HTTPHandler) GetOrdersByItemTrackNumber(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	trackNumber := chi.URLParam(r, "track_number")
+
+	if err := h.validate.Var(trackNumber, "required"); err != nil {
+		problem.WriteValidation(w, r, err)
+		return
+	}
+
+	proj, fields := parseProjection(r)
+	limit, after := parsePage(r, fields)
+	if len(fields) > 0 {
+		problem.WriteFields(w, r, fields)
+		return
+	}
+
+	enc, ok := negotiateEncoder(r)
+	if !ok {
+		problem.Write(w, r, problem.CodeNotAcceptable)
+		return
+	}
+
+	page, err := h.svc.GetOrdersByItemTrackNumber(ctx, trackNumber, limit, after)
+
+	if errors.Is(err, entities.ErrOrderNotFound) {
+		problem.Write(w, r, problem.CodeOrderNotFound)
+		return
+	}
+
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to get orders", slog.Any("error", err), slog.String("trackNumber", trackNumber))
+		problem.Write(w, r, problem.CodeInternal)
+		return
+	}
+
+	page.Orders, err = protectOrders(ctx, h.pii, r.URL.Path, proj, page.Orders)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to protect orders", slog.Any("error", err))
+		problem.Write(w, r, problem.CodeInternal)
+		return
+	}
+
+	writeResponse(w, r, enc, proj, OrderPageToJSON(page), "orders", http.StatusOK)
+}
+
+// ListOrders возвращает заказы по фильтру.
+// @Summary      Поиск заказов
+// @Description  Возвращает заказы, отсортированные по дате создания, с пагинацией по курсору.
+// @Description  Для следующей страницы нужно передать next_cursor из ответа с теми же фильтрами.
+// @Tags         orders
+// @Produce      json,xml,text/csv,application/msgpack
+// @Param        customer_id       query  string  false  "ID покупателя"
+// @Param        delivery_service  query  string  false  "Служба доставки"
+// @Param        locale            query  string  false  "Локаль"
+// @Param        currency          query  string  false  "Валюта платежа"
+// @Param        provider          query  string  false  "Платежный провайдер"
+// @Param        brand             query  string  false  "Бренд одного из товаров"
+// @Param        phone             query  string  false  "Телефон получателя, точное совпадение"
+// @Param        email             query  string  false  "Email получателя, точное совпадение"
+// @Param        created_from      query  string  false  "Создан не раньше (RFC 3339)"
+// @Param        created_to        query  string  false  "Создан раньше (RFC 3339)"
+// @Param        sort              query  string  false  "Сортировка" Enums(date_created, -date_created) default(-date_created)
+// @Param        limit             query  int     false  "Размер страницы" minimum(1) maximum(100) default(20)
+// @Param        cursor            query  string  false  "Курсор следующей страницы"
+// @Param        fields  query  string  false  "Поля заказа через запятую, например order_uid,payment.amount,items.name"
+// @Param        exclude  query  string  false  "Не загружать товары" Enums(items)
+// @Success      200  {object}  OrderListResponse
+// @Failure      400  {object}  problem.Problem "Ошибка валидации"
+// @Failure      406  {object}  problem.Problem "Формат из Accept не поддерживается"
+// @Failure      500  {object}  problem.Problem "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /orders [get]
+func (h *HTTPHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, fields := parseOrderFilter(r)
+	proj, projFields := parseProjection(r)
+	maps.Copy(fields, projFields)
+	if len(fields) > 0 {
+		problem.WriteFields(w, r, fields)
+		return
+	}
+
+	enc, ok := negotiateEncoder(r)
+	if !ok {
+		problem.Write(w, r, problem.CodeNotAcceptable)
+		return
+	}
+	filter.ExcludeItems = proj.excludeItems
+
+	page, err := h.svc.ListOrders(ctx, filter)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list orders", slog.Any("error", err))
+		problem.Write(w, r, problem.CodeInternal)
+		return
+	}
+
+	page.Orders, err = protectOrders(ctx, h.pii, r.URL.Path, proj, page.Orders)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to protect orders", slog.Any("error", err))
+		problem.Write(w, r, problem.CodeInternal)
+		return
+	}
+
+	writeResponse(w, r, enc, proj, OrderPageToJSON(page), "orders", http.StatusOK)
+}
+
+// CreateOrders сохраняет один заказ или пакет заказов.
+// @Summary      Создать заказ
+// @Description  Принимает заказ или массив заказов (до 100 штук) в том же формате, что и kafka.
+// @Description  Повторный запрос с тем же заголовком Idempotency-Key возвращает сохраненный ответ.
+// @Tags         orders
+// @Accept       json
+// @Produce      json
+// @Param        Idempotency-Key  header  string  false  "Ключ идемпотентности"
+// @Param        order  body  Order  true  "Заказ или массив заказов"
+// @Success      201  {object}  CreateOrderResponse "Заказ сохранен"
+// @Success      207  {object}  BatchCreateOrdersResponse "Часть заказов из пакета не сохранена"
+// @Failure      400  {object}  problem.Problem "Ошибка валидации"
+// @Failure      409  {object}  problem.Problem "Запрос с этим ключом еще выполняется"
+// @Failure      413  {object}  problem.Problem "Слишком большой запрос"
+// @Failure      422  {object}  problem.Problem "Ключ использован с другим запросом"
+// @Failure      500  {object}  problem.Problem "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /orders [post]
+func (h *HTTPHandler) CreateOrders(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		problem.Write(w, r, problem.CodeRequestTooLarge)
+		return
+	}
+
+	h.idempotent(w, r, body, func() (int, any) {
+		return h.createOrders(r, body)
+	})
+}
+
+// ValidateOrder проверяет заказ без сохранения.
+// @Summary      Проверить заказ
+// @Description  Выполняет те же проверки, что и kafka consumer (разбор JSON, теги validate и бизнес-правила),
+// @Description  и возвращает все найденные ошибки с путями к полям. Заказ не сохраняется.
+// @Description  Заказ в форме v2 проверяется так же, как сообщение kafka с заголовком api-version: v2.
+// @Tags         orders
+// @Accept       json
+// @Produce      json
+// @Param        order  body  Order  true  "Заказ"
+// @Success      200  {object}  ValidateOrderResponse "Заказ валиден"
+// @Failure      413  {object}  problem.Problem "Слишком большой запрос"
+// @Failure      422  {object}  ValidateOrderResponse "Заказ невалиден"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /orders/validate [post]
+func (h *HTTPHandler) ValidateOrder(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		problem.Write(w, r, problem.CodeRequestTooLarge)
 		return
 	}
 
-	utils.WriteJSON(w, OrderEntityToJSON(order), http.StatusOK)
+	if _, err := decodeOrder(h.validate, body, middleware.APIVersionFrom(r.Context())); err != nil {
+		utils.WriteJSON(w, ValidateOrderResponse{Errors: problem.FieldErrors(r, err)}, http.StatusUnprocessableEntity)
+		return
+	}
+
+	utils.WriteJSON(w, ValidateOrderResponse{Valid: true}, http.StatusOK)
+}
+
+func (h *HTTPHandler) createOrders(r *http.Request, body []byte) (int, any) {
+	ctx := r.Context()
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		return h.createOrdersBatch(r, body)
+	}
+
+	order, err := decodeOrder(h.validate, body, middleware.APIVersionFrom(ctx))
+	if err != nil {
+		return http.StatusBadRequest, problem.NewValidation(r, err)
+	}
+
+	if err := h.svc.SaveOrder(ctx, OrderJSONToEntity(order)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to save order", slog.Any("error", err), slog.String("orderUID", order.OrderUID))
+		return http.StatusInternalServerError, problem.New(r, problem.CodeInternal)
+	}
+
+	return http.StatusCreated, CreateOrderResponse{OrderUID: order.OrderUID}
+}
+
+func (h *HTTPHandler) createOrdersBatch(r *http.Request, body []byte) (int, any) {
+	ctx := r.Context()
+	var batch []json.RawMessage
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return http.StatusBadRequest, problem.NewValidation(r, err)
+	}
+
+	if len(batch) == 0 {
+		return http.StatusBadRequest, problem.New(r, problem.CodeEmptyBatch)
+	}
+	if len(batch) > maxBatchSize {
+		return http.StatusRequestEntityTooLarge, problem.New(r, problem.CodeBatchTooLarge)
+	}
+
+	version := middleware.APIVersionFrom(ctx)
+	results := make([]CreateOrderResult, len(batch))
+	created := 0
+	for i, data := range batch {
+		results[i].Index = i
+
+		order, err := decodeOrder(h.validate, data, version)
+		results[i].OrderUID = order.OrderUID
+		if err != nil {
+			results[i].Status = CreateStatusInvalid
+			results[i].Fields = utils.ValidationFields(err)
+			continue
+		}
+
+		if err := h.svc.SaveOrder(ctx, OrderJSONToEntity(order)); err != nil {
+			h.logger.ErrorContext(ctx, "failed to save order", slog.Any("error", err), slog.String("orderUID", order.OrderUID))
+			results[i].Status = CreateStatusFailed
+			continue
+		}
+
+		results[i].Status = CreateStatusCreated
+		created++
+	}
+
+	status := http.StatusCreated
+	if created < len(batch) {
+		status = http.StatusMultiStatus
+	}
+	return status, BatchCreateOrdersResponse{Results: results}
 }
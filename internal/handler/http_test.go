@@ -1,21 +1,27 @@
 package handler_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
 	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
 	"github.com/SergeyBogomolovv/l0-order-service/internal/handler"
 	mocks "github.com/SergeyBogomolovv/l0-order-service/internal/handler/mocks"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
 )
 
 func TestHTTPHandler_GetOrderByID(t *testing.T) {
@@ -24,16 +30,16 @@ func TestHTTPHandler_GetOrderByID(t *testing.T) {
 	testCases := []struct {
 		name         string
 		orderUID     string
-		mockBehavior func(svc *mocks.MockOrderGetter)
+		mockBehavior func(svc *mocks.MockOrderService)
 		wantStatus   int
 		wantBody     string
 	}{
 		{
 			name:     "success",
 			orderUID: "123",
-			mockBehavior: func(svc *mocks.MockOrderGetter) {
+			mockBehavior: func(svc *mocks.MockOrderService) {
 				svc.EXPECT().
-					GetOrderByID(mock.Anything, "123").
+					GetOrderByID(mock.Anything, "123", false).
 					Return(validOrder, nil).Once()
 			},
 			wantStatus: http.StatusOK,
@@ -42,36 +48,39 @@ func TestHTTPHandler_GetOrderByID(t *testing.T) {
 		{
 			name:     "not found",
 			orderUID: "not-exist",
-			mockBehavior: func(svc *mocks.MockOrderGetter) {
+			mockBehavior: func(svc *mocks.MockOrderService) {
 				svc.EXPECT().
-					GetOrderByID(mock.Anything, "not-exist").
+					GetOrderByID(mock.Anything, "not-exist", false).
 					Return(entities.Order{}, entities.ErrOrderNotFound).Once()
 			},
 			wantStatus: http.StatusNotFound,
-			wantBody:   `"order not found"`,
+			wantBody:   `"code":"order_not_found"`,
 		},
 		{
 			name:     "internal error",
 			orderUID: "123",
-			mockBehavior: func(svc *mocks.MockOrderGetter) {
+			mockBehavior: func(svc *mocks.MockOrderService) {
 				svc.EXPECT().
-					GetOrderByID(mock.Anything, "123").
+					GetOrderByID(mock.Anything, "123", false).
 					Return(entities.Order{}, errors.New("db error")).Once()
 			},
 			wantStatus: http.StatusInternalServerError,
-			wantBody:   `"internal server error"`,
+			wantBody:   `"code":"internal_error"`,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			svc := mocks.NewMockOrderGetter(t)
+			svc := mocks.NewMockOrderService(t)
 			tc.mockBehavior(svc)
 
 			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
-			h := handler.NewHTTPHandler(logger, svc)
+			h := handler.NewHTTPHandler(
+				logger, config.HTTP{}, svc, mocks.NewMockIdempotencyStore(t), mocks.NewMockOrderWaiter(t),
+				testPII{},
+			)
 
-			r := chi.NewRouter()
+			r := newTestRouter()
 			h.Init(r)
 
 			req := httptest.NewRequest(http.MethodGet, "/order/"+tc.orderUID, nil)
@@ -97,3 +106,1075 @@ func TestHTTPHandler_GetOrderByID(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPHandler_GetOrderByID_Conditional(t *testing.T) {
+	order := entities.Order{
+		OrderUID:    "123",
+		DateCreated: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		SavedAt:     time.Date(2025, 1, 3, 3, 4, 5, 500, time.UTC),
+	}
+
+	svc := mocks.NewMockOrderService(t)
+	svc.EXPECT().GetOrderByID(mock.Anything, "123", false).Return(order, nil)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := config.HTTP{CacheControl: "private, no-cache"}
+	h := handler.NewHTTPHandler(logger, cfg, svc, mocks.NewMockIdempotencyStore(t), mocks.NewMockOrderWaiter(t), testPII{})
+
+	r := newTestRouter()
+	h.Init(r)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/order/123", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	etag := rr.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, "private, no-cache", rr.Header().Get("Cache-Control"))
+	assert.Equal(t, "Fri, 03 Jan 2025 03:04:05 GMT", rr.Header().Get("Last-Modified"))
+
+	testCases := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{name: "matching etag", headers: map[string]string{"If-None-Match": etag}, wantStatus: http.StatusNotModified},
+		{
+			name:       "etag in list",
+			headers:    map[string]string{"If-None-Match": `"other", W/` + etag},
+			wantStatus: http.StatusNotModified,
+		},
+		{name: "stale etag", headers: map[string]string{"If-None-Match": `"other"`}, wantStatus: http.StatusOK},
+		{
+			name:       "not modified since",
+			headers:    map[string]string{"If-Modified-Since": "Fri, 03 Jan 2025 03:04:05 GMT"},
+			wantStatus: http.StatusNotModified,
+		},
+		{
+			name:       "modified since",
+			headers:    map[string]string{"If-Modified-Since": "Fri, 03 Jan 2025 03:04:04 GMT"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid date",
+			headers:    map[string]string{"If-Modified-Since": "yesterday"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "if-none-match takes precedence",
+			headers: map[string]string{
+				"If-None-Match":     `"other"`,
+				"If-Modified-Since": "Thu, 02 Jan 2030 03:04:05 GMT",
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/order/123", nil)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, etag, rr.Header().Get("ETag"))
+			if tc.wantStatus == http.StatusNotModified {
+				assert.Empty(t, rr.Body.String())
+			}
+		})
+	}
+}
+
+const validOrderJSON = `{
+	"order_uid": "123",
+	"track_number": "TRACK",
+	"delivery": {"name": "John", "phone": "+79001234567", "email": "john@example.com"},
+	"payment": {"transaction": "123", "currency": "USD", "provider": "wbpay", "amount": 100, "payment_dt": 1637907727},
+	"items": [{"chrt_id": 1, "track_number": "TRACK", "rid": "rid-1", "name": "Item"}]
+}`
+
+func TestHTTPHandler_GetOrderByID_Wait(t *testing.T) {
+	closed := make(chan struct{})
+	close(closed)
+
+	testCases := []struct {
+		name         string
+		query        string
+		mockBehavior func(svc *mocks.MockOrderService, waiter *mocks.MockOrderWaiter)
+		wantStatus   int
+		wantBody     string
+	}{
+		{
+			name:  "order saved while waiting",
+			query: "?wait=1s",
+			mockBehavior: func(svc *mocks.MockOrderService, waiter *mocks.MockOrderWaiter) {
+				waiter.EXPECT().Wait("123").Return(closed, func() {}).Once()
+				svc.EXPECT().GetOrderByID(mock.Anything, "123", false).
+					Return(entities.Order{}, entities.ErrOrderNotFound).Once()
+				waiter.EXPECT().Wait("123").Return(make(chan struct{}), func() {}).Once()
+				svc.EXPECT().GetOrderByID(mock.Anything, "123", false).
+					Return(entities.Order{OrderUID: "123"}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"order_uid":"123"`,
+		},
+		{
+			name:  "timeout",
+			query: "?wait=10ms",
+			mockBehavior: func(svc *mocks.MockOrderService, waiter *mocks.MockOrderWaiter) {
+				waiter.EXPECT().Wait("123").Return(make(chan struct{}), func() {}).Once()
+				svc.EXPECT().GetOrderByID(mock.Anything, "123", false).
+					Return(entities.Order{}, entities.ErrOrderNotFound).Once()
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `"code":"order_not_found"`,
+		},
+		{
+			name:  "existing order is returned without waiting",
+			query: "?wait=1s",
+			mockBehavior: func(svc *mocks.MockOrderService, waiter *mocks.MockOrderWaiter) {
+				waiter.EXPECT().Wait("123").Return(make(chan struct{}), func() {}).Once()
+				svc.EXPECT().GetOrderByID(mock.Anything, "123", false).
+					Return(entities.Order{OrderUID: "123"}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"order_uid":"123"`,
+		},
+		{
+			name:         "invalid duration",
+			query:        "?wait=soon",
+			mockBehavior: func(_ *mocks.MockOrderService, _ *mocks.MockOrderWaiter) {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     `{"field":"wait","rule":"duration"`,
+		},
+		{
+			name:         "too long",
+			query:        "?wait=1m",
+			mockBehavior: func(_ *mocks.MockOrderService, _ *mocks.MockOrderWaiter) {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     `{"field":"wait","rule":"range"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := mocks.NewMockOrderService(t)
+			waiter := mocks.NewMockOrderWaiter(t)
+			tc.mockBehavior(svc, waiter)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			cfg := config.HTTP{MaxWait: 5 * time.Second}
+			h := handler.NewHTTPHandler(logger, cfg, svc, mocks.NewMockIdempotencyStore(t), waiter, testPII{})
+
+			r := newTestRouter()
+			h.Init(r)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/order/123"+tc.query, nil))
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tc.wantBody)
+		})
+	}
+}
+
+func TestHTTPHandler_CreateOrders(t *testing.T) {
+	type MockBehavior func(svc *mocks.MockOrderService, idem *mocks.MockIdempotencyStore)
+
+	testCases := []struct {
+		name           string
+		body           string
+		idempotencyKey string
+		mockBehavior   MockBehavior
+		wantStatus     int
+		wantBody       string
+		wantReplayed   bool
+	}{
+		{
+			name: "success",
+			body: validOrderJSON,
+			mockBehavior: func(svc *mocks.MockOrderService, _ *mocks.MockIdempotencyStore) {
+				svc.EXPECT().
+					SaveOrder(mock.Anything, mock.MatchedBy(func(o entities.Order) bool { return o.OrderUID == "123" })).
+					Return(nil).Once()
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"order_uid":"123"}`,
+		},
+		{
+			name:         "validation error",
+			body:         `{"order_uid": "123", "delivery": {"phone": "123"}, "items": [{"chrt_id": 1}]}`,
+			mockBehavior: func(_ *mocks.MockOrderService, _ *mocks.MockIdempotencyStore) {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     `{"field":"delivery.phone","rule":"e164"`,
+		},
+		{
+			name:         "invalid json",
+			body:         `{"order_uid": 123}`,
+			mockBehavior: func(_ *mocks.MockOrderService, _ *mocks.MockIdempotencyStore) {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     `{"field":"order_uid","rule":"type"`,
+		},
+		{
+			name: "internal error",
+			body: validOrderJSON,
+			mockBehavior: func(svc *mocks.MockOrderService, _ *mocks.MockIdempotencyStore) {
+				svc.EXPECT().SaveOrder(mock.Anything, mock.Anything).Return(errors.New("db error")).Once()
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `"code":"internal_error"`,
+		},
+		{
+			name: "batch with invalid order",
+			body: "[" + validOrderJSON + `, {"order_uid": "456"}]`,
+			mockBehavior: func(svc *mocks.MockOrderService, _ *mocks.MockIdempotencyStore) {
+				svc.EXPECT().SaveOrder(mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			wantStatus: http.StatusMultiStatus,
+			wantBody:   `{"index":1,"order_uid":"456","status":"invalid"`,
+		},
+		{
+			name:           "idempotent first request",
+			body:           validOrderJSON,
+			idempotencyKey: "key",
+			mockBehavior: func(svc *mocks.MockOrderService, idem *mocks.MockIdempotencyStore) {
+				idem.EXPECT().Begin(mock.Anything, "test", "key", mock.Anything).Return(nil, nil).Once()
+				svc.EXPECT().SaveOrder(mock.Anything, mock.Anything).Return(nil).Once()
+				idem.EXPECT().
+					Complete(mock.Anything, "test", "key", entities.IdempotentResponse{
+						StatusCode: http.StatusCreated,
+						Body:       []byte(`{"order_uid":"123"}` + "\n"),
+					}).
+					Return(nil).Once()
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"order_uid":"123"}`,
+		},
+		{
+			name:           "idempotent replay",
+			body:           validOrderJSON,
+			idempotencyKey: "key",
+			mockBehavior: func(_ *mocks.MockOrderService, idem *mocks.MockIdempotencyStore) {
+				idem.EXPECT().
+					Begin(mock.Anything, "test", "key", mock.Anything).
+					Return(&entities.IdempotentResponse{StatusCode: http.StatusCreated, Body: []byte(`{"order_uid":"123"}`)}, nil).
+					Once()
+			},
+			wantStatus:   http.StatusCreated,
+			wantBody:     `{"order_uid":"123"}`,
+			wantReplayed: true,
+		},
+		{
+			name:           "idempotency key reused with another body",
+			body:           validOrderJSON,
+			idempotencyKey: "key",
+			mockBehavior: func(_ *mocks.MockOrderService, idem *mocks.MockIdempotencyStore) {
+				idem.EXPECT().
+					Begin(mock.Anything, "test", "key", mock.Anything).
+					Return(nil, entities.ErrIdempotencyKeyMismatch).Once()
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `"code":"idempotency_key_mismatch"`,
+		},
+		{
+			name:           "key released after internal error",
+			body:           validOrderJSON,
+			idempotencyKey: "key",
+			mockBehavior: func(svc *mocks.MockOrderService, idem *mocks.MockIdempotencyStore) {
+				idem.EXPECT().Begin(mock.Anything, "test", "key", mock.Anything).Return(nil, nil).Once()
+				svc.EXPECT().SaveOrder(mock.Anything, mock.Anything).Return(errors.New("db error")).Once()
+				idem.EXPECT().Release(mock.Anything, "test", "key").Return(nil).Once()
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `"code":"internal_error"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := mocks.NewMockOrderService(t)
+			idem := mocks.NewMockIdempotencyStore(t)
+			tc.mockBehavior(svc, idem)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			h := handler.NewHTTPHandler(logger, config.HTTP{}, svc, idem, mocks.NewMockOrderWaiter(t), testPII{})
+
+			r := newTestRouter()
+			h.Init(r)
+
+			req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(tc.body))
+			if tc.idempotencyKey != "" {
+				req.Header.Set("Idempotency-Key", tc.idempotencyKey)
+			}
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.wantStatus, res.StatusCode)
+			assert.Contains(t, string(body), tc.wantBody)
+			assert.Equal(t, tc.wantReplayed, res.Header.Get("Idempotent-Replayed") == "true")
+		})
+	}
+}
+
+func TestHTTPHandler_IdempotencyKeyAcrossVersions(t *testing.T) {
+	var hashes []string
+	idem := mocks.NewMockIdempotencyStore(t)
+	idem.EXPECT().Begin(mock.Anything, "test", "key", mock.Anything).
+		RunAndReturn(func(_ context.Context, _, _, hash string) (*entities.IdempotentResponse, error) {
+			hashes = append(hashes, hash)
+			return &entities.IdempotentResponse{StatusCode: http.StatusCreated, Body: []byte(`{}`)}, nil
+		}).Twice()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := handler.NewHTTPHandler(
+		logger, config.HTTP{}, mocks.NewMockOrderService(t), idem, mocks.NewMockOrderWaiter(t), testPII{},
+	)
+	r := newTestRouter()
+	h.Init(r)
+	r.Route("/v1", h.Init)
+
+	// один и тот же запрос к разным версиям API - повтор, а не новый запрос с тем же ключом
+	for _, path := range []string{"/orders", "/v1/orders"} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(validOrderJSON))
+		req.Header.Set("Idempotency-Key", "key")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	require.Len(t, hashes, 2)
+	assert.Equal(t, hashes[0], hashes[1])
+}
+
+func TestHTTPHandler_ValidateOrder(t *testing.T) {
+	testCases := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantValid  bool
+		wantErrors []string
+	}{
+		{
+			name:       "valid order",
+			body:       validOrderJSON,
+			wantStatus: http.StatusOK,
+			wantValid:  true,
+		},
+		{
+			name:       "empty body",
+			body:       "",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErrors: []string{"body"},
+		},
+		{
+			name:       "malformed json",
+			body:       `{"order_uid": "123"`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErrors: []string{"body"},
+		},
+		{
+			name: "all problems are reported",
+			body: `{
+				"order_uid": 123,
+				"track_number": "TRACK",
+				"delivery": {"name": "John", "phone": "123", "email": "john@example.com"},
+				"payment": {"transaction": "123", "currency": "USD", "payment_dt": 1},
+				"items": [
+					{"chrt_id": 1, "track_number": "TRACK", "rid": "rid-1"},
+					{"track_number": "TRACK", "rid": "rid-2"}
+				]
+			}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErrors: []string{
+				"order_uid",
+				"delivery.phone",
+				"payment.provider",
+				"items[1].chrt_id",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			h := handler.NewHTTPHandler(
+				logger, config.HTTP{}, mocks.NewMockOrderService(t), mocks.NewMockIdempotencyStore(t), mocks.NewMockOrderWaiter(t),
+				testPII{},
+			)
+
+			r := newTestRouter()
+			h.Init(r)
+
+			req := httptest.NewRequest(http.MethodPost, "/orders/validate", strings.NewReader(tc.body))
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, res.StatusCode)
+
+			var resp handler.ValidateOrderResponse
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+			assert.Equal(t, tc.wantValid, resp.Valid)
+
+			fields := make([]string, 0, len(resp.Errors))
+			for _, fe := range resp.Errors {
+				fields = append(fields, fe.Field)
+			}
+			assert.ElementsMatch(t, tc.wantErrors, fields)
+		})
+	}
+}
+
+func TestHTTPHandler_ListOrders(t *testing.T) {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	testCases := []struct {
+		name         string
+		query        string
+		mockBehavior func(svc *mocks.MockOrderService)
+		wantStatus   int
+		wantBody     string
+	}{
+		{
+			name:  "filters and next cursor",
+			query: "?customer_id=c1&brand=Nike&currency=USD&created_from=2025-01-01T00:00:00Z&sort=date_created&limit=1",
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().
+					ListOrders(mock.Anything, entities.OrderFilter{
+						CustomerID:  "c1",
+						Brand:       "Nike",
+						Currency:    "USD",
+						CreatedFrom: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+						SortAsc:     true,
+						Limit:       1,
+					}).
+					Return(entities.OrderPage{
+						Orders: []entities.Order{{OrderUID: "123", DateCreated: created}},
+						Next:   &entities.OrderCursor{DateCreated: created, OrderUID: "123"},
+					}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"next_cursor":"`,
+		},
+		{
+			name:  "recipient lookup",
+			query: "?phone=%2B79991234567&email=john@example.com",
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().
+					ListOrders(mock.Anything, entities.OrderFilter{
+						Phone: "+79991234567",
+						Email: "john@example.com",
+						Limit: 20,
+					}).
+					Return(entities.OrderPage{Orders: []entities.Order{}}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"orders":[]`,
+		},
+		{
+			name:         "invalid params",
+			query:        "?limit=1000&sort=name&created_to=yesterday&cursor=broken",
+			mockBehavior: func(_ *mocks.MockOrderService) {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody: `"errors":[` +
+				`{"field":"created_to","rule":"datetime","message":"must be a date-time in RFC 3339 format"},` +
+				`{"field":"cursor","rule":"cursor","message":"is not a valid cursor"},` +
+				`{"field":"limit","rule":"range","message":"is out of the allowed range"},` +
+				`{"field":"sort","rule":"oneof","message":"must be one of the allowed values"}]`,
+		},
+		{
+			name:  "internal error",
+			query: "",
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().ListOrders(mock.Anything, mock.Anything).
+					Return(entities.OrderPage{}, errors.New("db error")).Once()
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `"code":"internal_error"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := mocks.NewMockOrderService(t)
+			tc.mockBehavior(svc)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			h := handler.NewHTTPHandler(
+				logger, config.HTTP{}, svc, mocks.NewMockIdempotencyStore(t), mocks.NewMockOrderWaiter(t),
+				testPII{},
+			)
+
+			r := newTestRouter()
+			h.Init(r)
+
+			req := httptest.NewRequest(http.MethodGet, "/orders"+tc.query, nil)
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			res := rr.Result()
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.wantStatus, res.StatusCode)
+			assert.Contains(t, string(body), tc.wantBody)
+		})
+	}
+}
+
+func TestHTTPHandler_ListOrders_CursorRoundTrip(t *testing.T) {
+	next := &entities.OrderCursor{DateCreated: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), OrderUID: "123"}
+
+	svc := mocks.NewMockOrderService(t)
+	svc.EXPECT().ListOrders(mock.Anything, mock.MatchedBy(func(f entities.OrderFilter) bool { return f.After == nil })).
+		Return(entities.OrderPage{Next: next}, nil).Once()
+	svc.EXPECT().ListOrders(mock.Anything, mock.MatchedBy(func(f entities.OrderFilter) bool {
+		return f.After != nil && f.After.OrderUID == next.OrderUID && f.After.DateCreated.Equal(next.DateCreated)
+	})).
+		Return(entities.OrderPage{}, nil).Once()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := handler.NewHTTPHandler(
+		logger, config.HTTP{}, svc, mocks.NewMockIdempotencyStore(t), mocks.NewMockOrderWaiter(t), testPII{},
+	)
+
+	r := newTestRouter()
+	h.Init(r)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var page handler.OrderListResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&page))
+	require.NotEmpty(t, page.NextCursor)
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/orders?cursor="+page.NextCursor, nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"orders":[]`)
+}
+
+func TestHTTPHandler_TrackLookup(t *testing.T) {
+	testCases := []struct {
+		name         string
+		path         string
+		mockBehavior func(svc *mocks.MockOrderService)
+		wantStatus   int
+		wantBody     string
+	}{
+		{
+			name: "order by track",
+			path: "/orders/by-track/TRACK",
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().GetOrderByTrackNumber(mock.Anything, "TRACK", false).
+					Return(entities.Order{OrderUID: "123", TrackNumber: "TRACK"}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"order_uid":"123"`,
+		},
+		{
+			name: "order by track not found",
+			path: "/orders/by-track/TRACK",
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().GetOrderByTrackNumber(mock.Anything, "TRACK", false).
+					Return(entities.Order{}, entities.ErrOrderNotFound).Once()
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `"code":"order_not_found"`,
+		},
+		{
+			name: "orders by item track",
+			path: "/orders/by-item-track/ITEM",
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().GetOrdersByItemTrackNumber(mock.Anything, "ITEM", 20, (*entities.OrderCursor)(nil)).
+					Return(entities.OrderPage{
+						Orders: []entities.Order{{OrderUID: "1"}, {OrderUID: "2"}},
+						Next:   &entities.OrderCursor{DateCreated: time.Now(), OrderUID: "2"},
+					}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"next_cursor":"`,
+		},
+		{
+			name:         "orders by item track invalid limit",
+			path:         "/orders/by-item-track/ITEM?limit=1000",
+			mockBehavior: func(_ *mocks.MockOrderService) {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     `{"field":"limit","rule":"range"`,
+		},
+		{
+			name: "orders by item track not found",
+			path: "/orders/by-item-track/ITEM",
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().GetOrdersByItemTrackNumber(mock.Anything, "ITEM", 20, (*entities.OrderCursor)(nil)).
+					Return(entities.OrderPage{}, entities.ErrOrderNotFound).Once()
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `"code":"order_not_found"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := mocks.NewMockOrderService(t)
+			tc.mockBehavior(svc)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			h := handler.NewHTTPHandler(
+				logger, config.HTTP{}, svc, mocks.NewMockIdempotencyStore(t), mocks.NewMockOrderWaiter(t),
+				testPII{},
+			)
+
+			r := newTestRouter()
+			h.Init(r)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tc.wantBody)
+		})
+	}
+}
+
+func TestHTTPHandler_BatchGetOrders(t *testing.T) {
+	testCases := []struct {
+		name         string
+		body         string
+		mockBehavior func(svc *mocks.MockOrderService)
+		wantStatus   int
+		wantBody     string
+	}{
+		{
+			name: "found and missing",
+			body: `{"order_uids":["1","2"]}`,
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().GetOrdersByIDs(mock.Anything, []string{"1", "2"}).
+					Return([]entities.Order{{OrderUID: "1"}}, []string{"2"}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"missing":["2"]`,
+		},
+		{
+			name:         "empty list",
+			body:         `{"order_uids":[]}`,
+			mockBehavior: func(_ *mocks.MockOrderService) {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     `{"field":"order_uids","rule":"min"`,
+		},
+		{
+			name:         "too many ids",
+			body:         `{"order_uids":["` + strings.Repeat(`x","`, 100) + `x"]}`,
+			mockBehavior: func(_ *mocks.MockOrderService) {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     `{"field":"order_uids","rule":"max"`,
+		},
+		{
+			name:         "invalid json",
+			body:         `{"order_uids":`,
+			mockBehavior: func(_ *mocks.MockOrderService) {},
+			wantStatus:   http.StatusBadRequest,
+		},
+		{
+			name: "internal error",
+			body: `{"order_uids":["1"]}`,
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().GetOrdersByIDs(mock.Anything, []string{"1"}).
+					Return(nil, nil, errors.New("db error")).Once()
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `"code":"internal_error"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := mocks.NewMockOrderService(t)
+			tc.mockBehavior(svc)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			h := handler.NewHTTPHandler(
+				logger, config.HTTP{}, svc, mocks.NewMockIdempotencyStore(t), mocks.NewMockOrderWaiter(t),
+				testPII{},
+			)
+
+			r := newTestRouter()
+			h.Init(r)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/orders:batchGet", strings.NewReader(tc.body)))
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tc.wantBody)
+		})
+	}
+}
+
+func TestHTTPHandler_Fieldsets(t *testing.T) {
+	order := entities.Order{
+		OrderUID:    "123",
+		TrackNumber: "TRACK",
+		Delivery:    entities.Delivery{Name: "John", Phone: "+79001234567"},
+		Payment:     entities.Payment{Amount: 100, Currency: "USD"},
+		Items:       []entities.Item{{ChrtID: 1, Name: "Item", Price: 50}},
+	}
+
+	testCases := []struct {
+		name         string
+		path         string
+		mockBehavior func(svc *mocks.MockOrderService)
+		wantStatus   int
+		wantBody     string
+		wantAbsent   string
+	}{
+		{
+			name: "nested fields",
+			path: "/order/123?fields=order_uid,payment.amount,items.name",
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().GetOrderByID(mock.Anything, "123", false).Return(order, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"items":[{"name":"Item"}],"order_uid":"123","payment":{"amount":100}}`,
+		},
+		{
+			name: "whole object wins over its field",
+			path: "/order/123?fields=payment.amount,payment",
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().GetOrderByID(mock.Anything, "123", true).Return(order, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"payment":{"amount":100,"currency":"USD"`,
+		},
+		{
+			name: "exclude items",
+			path: "/order/123?exclude=items",
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().GetOrderByID(mock.Anything, "123", true).Return(order, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"order_uid":"123","payment"`,
+			wantAbsent: `"items"`,
+		},
+		{
+			name: "list skips items in repo",
+			path: "/orders?fields=order_uid",
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().ListOrders(mock.Anything, mock.MatchedBy(func(f entities.OrderFilter) bool { return f.ExcludeItems })).
+					Return(entities.OrderPage{Orders: []entities.Order{order}}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"orders":[{"order_uid":"123"}]}`,
+		},
+		{
+			name:         "unknown field",
+			path:         "/orders?fields=order_uid,payment.secret&exclude=delivery",
+			mockBehavior: func(_ *mocks.MockOrderService) {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody: `"errors":[` +
+				`{"field":"exclude","rule":"oneof","message":"must be one of the allowed values"},` +
+				`{"field":"fields","rule":"fieldset","message":"contains an unknown field"}]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := mocks.NewMockOrderService(t)
+			tc.mockBehavior(svc)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			h := handler.NewHTTPHandler(
+				logger, config.HTTP{}, svc, mocks.NewMockIdempotencyStore(t), mocks.NewMockOrderWaiter(t),
+				testPII{},
+			)
+
+			r := newTestRouter()
+			h.Init(r)
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tc.wantBody)
+			if tc.wantAbsent != "" {
+				assert.NotContains(t, rr.Body.String(), tc.wantAbsent)
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_ResponseFormats(t *testing.T) {
+	order := entities.Order{
+		OrderUID: "123",
+		Payment:  entities.Payment{Amount: 100, Currency: "USD"},
+		Items: []entities.Item{
+			{ChrtID: 1, Name: "First"},
+			{ChrtID: 2, Name: "Second, with comma"},
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		path            string
+		accept          string
+		excludeItems    bool
+		wantStatus      int
+		wantContentType string
+		check           func(t *testing.T, body []byte)
+	}{
+		{
+			name:            "default json",
+			path:            "/order/123",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			check: func(t *testing.T, body []byte) {
+				assert.Contains(t, string(body), `"order_uid":"123"`)
+			},
+		},
+		{
+			name:            "csv row per item",
+			path:            "/order/123?fields=order_uid,payment.amount,items.chrt_id,items.name",
+			accept:          "text/csv",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8",
+			check: func(t *testing.T, body []byte) {
+				assert.Equal(t, "order_uid,payment.amount,items.chrt_id,items.name\n"+
+					"123,100,1,First\n"+
+					"123,100,2,\"Second, with comma\"\n", string(body))
+			},
+		},
+		{
+			name:            "csv without items",
+			path:            "/order/123?fields=order_uid,payment.currency",
+			accept:          "text/csv",
+			excludeItems:    true,
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8",
+			check: func(t *testing.T, body []byte) {
+				assert.Equal(t, "order_uid,payment.currency\n123,USD\n", string(body))
+			},
+		},
+		{
+			name:            "xml preferred by q",
+			path:            "/order/123?fields=order_uid,items.name",
+			accept:          "text/csv;q=0.5, application/xml",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/xml; charset=utf-8",
+			check: func(t *testing.T, body []byte) {
+				assert.Contains(t, string(body),
+					`<order><items><item><name>First</name></item><item><name>Second, with comma</name></item></items>`+
+						`<order_uid>123</order_uid></order>`)
+			},
+		},
+		{
+			name:            "msgpack",
+			path:            "/order/123",
+			accept:          "application/msgpack",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/msgpack",
+			check: func(t *testing.T, body []byte) {
+				var got map[string]any
+				require.NoError(t, msgpack.Unmarshal(body, &got))
+				assert.Equal(t, "123", got["order_uid"])
+				assert.EqualValues(t, 100, got["payment"].(map[string]any)["amount"])
+				assert.Len(t, got["items"], 2)
+			},
+		},
+		{
+			name:       "not acceptable",
+			path:       "/order/123",
+			accept:     "image/png",
+			wantStatus: http.StatusNotAcceptable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := mocks.NewMockOrderService(t)
+			if tc.wantStatus == http.StatusOK {
+				svc.EXPECT().GetOrderByID(mock.Anything, "123", tc.excludeItems).Return(order, nil).Once()
+			}
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			h := handler.NewHTTPHandler(
+				logger, config.HTTP{}, svc, mocks.NewMockIdempotencyStore(t), mocks.NewMockOrderWaiter(t),
+				testPII{},
+			)
+
+			r := newTestRouter()
+			h.Init(r)
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantStatus, rr.Code)
+			if tc.check != nil {
+				assert.Equal(t, tc.wantContentType, rr.Header().Get("Content-Type"))
+				tc.check(t, rr.Body.Bytes())
+			}
+		})
+	}
+}
+
+// newTestRouter роутер, в котором каждый запрос выполняется от имени клиента с правами admin
+func TestHTTPHandler_APIVersions(t *testing.T) {
+	order := entities.Order{
+		OrderUID: "123",
+		Payment:  entities.Payment{Amount: 100, PaymentDT: time.Unix(1637907727, 0)},
+	}
+	v2Order := strings.Replace(validOrderJSON, `"payment_dt": 1637907727`, `"payment_dt": "2021-11-26T06:22:07Z"`, 1)
+
+	testCases := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		mockBehavior func(svc *mocks.MockOrderService)
+		wantStatus   int
+		wantBody     string
+	}{
+		{
+			name:   "v1 unix seconds",
+			method: http.MethodGet,
+			path:   "/v1/order/123",
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().GetOrderByID(mock.Anything, "123", false).Return(order, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"payment_dt":1637907727`,
+		},
+		{
+			name:   "v2 rfc 3339",
+			method: http.MethodGet,
+			path:   "/v2/order/123",
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().GetOrderByID(mock.Anything, "123", false).Return(order, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"payment_dt":"2021-11-26T06:22:07Z"`,
+		},
+		{
+			name:   "v2 with fields",
+			method: http.MethodGet,
+			path:   "/v2/order/123?fields=payment.payment_dt",
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().GetOrderByID(mock.Anything, "123", true).Return(order, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"payment":{"payment_dt":"2021-11-26T06:22:07Z"}}`,
+		},
+		{
+			name:   "v2 list",
+			method: http.MethodGet,
+			path:   "/v2/orders?customer_id=customer",
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().ListOrders(mock.Anything, mock.Anything).
+					Return(entities.OrderPage{Orders: []entities.Order{order}}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"payment_dt":"2021-11-26T06:22:07Z"`,
+		},
+		{
+			name:   "v2 create with rfc 3339",
+			method: http.MethodPost,
+			path:   "/v2/orders",
+			body:   v2Order,
+			mockBehavior: func(svc *mocks.MockOrderService) {
+				svc.EXPECT().
+					SaveOrder(mock.Anything, mock.MatchedBy(func(o entities.Order) bool {
+						return o.Payment.PaymentDT.Unix() == 1637907727
+					})).
+					Return(nil).Once()
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"order_uid":"123"}`,
+		},
+		{
+			name:         "v1 rejects rfc 3339",
+			method:       http.MethodPost,
+			path:         "/v1/orders",
+			body:         v2Order,
+			mockBehavior: func(*mocks.MockOrderService) {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     `{"field":"payment.payment_dt","rule":"type"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := mocks.NewMockOrderService(t)
+			tc.mockBehavior(svc)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			h := handler.NewHTTPHandler(
+				logger, config.HTTP{}, svc, mocks.NewMockIdempotencyStore(t), mocks.NewMockOrderWaiter(t),
+				testPII{},
+			)
+
+			r := newTestRouter()
+			for _, v := range middleware.APIVersions {
+				r.Route(v.Prefix(), func(r chi.Router) {
+					r.Use(middleware.Version(v))
+					h.Init(r)
+				})
+			}
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Contains(t, rr.Body.String(), tc.wantBody)
+		})
+	}
+}
+
+func newTestRouter() chi.Router {
+	return newTestRouterWithScopes(entities.ScopeAdmin)
+}
+
+// newTestRouterWithScopes роутер, в котором каждый запрос выполняется от имени клиента с правами scopes
+func newTestRouterWithScopes(scopes ...string) chi.Router {
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p := entities.Principal{Subject: "test", Scopes: scopes}
+			next.ServeHTTP(w, r.WithContext(middleware.WithPrincipal(r.Context(), p)))
+		})
+	})
+	return r
+}
+
+// testPII раскрывает персональные данные клиентам с правом pii:read и ничего не пишет в журнал
+type testPII struct{}
+
+func (testPII) CanReveal(p entities.Principal) bool {
+	return p.HasScope(entities.ScopePIIRead)
+}
+
+func (testPII) LogAccess(context.Context, entities.PIIAccess) error {
+	return nil
+}
+
+func (testPII) MaskDelivery(d entities.Delivery) entities.Delivery {
+	d.Phone = "masked"
+	return d
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/problem"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/utils"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// idempotent выполняет fn и пишет ее результат в ответ.
+// Если в запросе есть заголовок Idempotency-Key, ответ сохраняется и отдается повторно
+// на запросы того же клиента с тем же ключом и телом без повторного выполнения fn.
+func (h *HTTPHandler) idempotent(w http.ResponseWriter, r *http.Request, body []byte, fn func() (int, any)) {
+	ctx := r.Context()
+
+	key := r.Header.Get(idempotencyKeyHeader)
+	if key == "" {
+		status, payload := fn()
+		if p, ok := payload.(problem.Problem); ok {
+			p.Write(w)
+			return
+		}
+		utils.WriteJSON(w, payload, status)
+		return
+	}
+
+	if len(key) > maxIdempotencyKeyLength {
+		problem.Write(w, r, problem.CodeIdempotencyKeyTooLong)
+		return
+	}
+
+	// ключи разных клиентов не пересекаются, иначе один клиент мог бы получить ответ другого
+	principal, _ := middleware.PrincipalFrom(ctx)
+	subject := principal.Subject
+
+	stored, err := h.idempotency.Begin(ctx, subject, key, requestHash(r, body))
+	switch {
+	case errors.Is(err, entities.ErrIdempotencyKeyMismatch):
+		problem.Write(w, r, problem.CodeIdempotencyKeyMismatch)
+		return
+	case errors.Is(err, entities.ErrIdempotencyKeyInUse):
+		problem.Write(w, r, problem.CodeIdempotencyInProgress)
+		return
+	case err != nil:
+		h.logger.ErrorContext(ctx, "failed to begin idempotent request", slog.Any("error", err))
+		problem.Write(w, r, problem.CodeInternal)
+		return
+	case stored != nil:
+		w.Header().Set(idempotentReplayedHeader, "true")
+		utils.WriteRaw(w, responseContentType(stored.StatusCode), stored.Body, stored.StatusCode)
+		return
+	}
+
+	status, payload := fn()
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to marshal response", slog.Any("error", err))
+		status = http.StatusInternalServerError
+		data, _ = json.Marshal(problem.New(r, problem.CodeInternal))
+	}
+	data = append(data, '\n')
+
+	// после внутренних ошибок ключ освобождается, чтобы клиент мог повторить запрос
+	if status >= http.StatusInternalServerError {
+		err = h.idempotency.Release(ctx, subject, key)
+	} else {
+		err = h.idempotency.Complete(ctx, subject, key, entities.IdempotentResponse{StatusCode: status, Body: data})
+	}
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to finish idempotent request", slog.Any("error", err))
+	}
+
+	utils.WriteRaw(w, responseContentType(status), data, status)
+}
+
+// responseContentType сохраненные ответы с ошибками - описания ошибок RFC 7807
+func responseContentType(status int) string {
+	if status >= http.StatusBadRequest {
+		return problem.ContentType
+	}
+	return "application/json"
+}
+
+// requestHash позволяет отличить повторный запрос от нового запроса с тем же ключом.
+// Путь берется без версии: один и тот же запрос к /orders и /v1/orders - повтор, а не конфликт.
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + middleware.UnversionedPath(r.URL.Path) + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
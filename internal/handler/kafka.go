@@ -2,19 +2,24 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
 	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
 	"github.com/go-playground/validator/v10"
 	"github.com/segmentio/kafka-go"
 )
 
+// apiVersionHeader заголовок сообщения с версией API, в форме которой записан заказ
+const apiVersionHeader = "api-version"
+
 type OrderSaver interface {
 	SaveOrder(ctx context.Context, order entities.Order) error
 }
@@ -25,6 +30,15 @@ type KafkaHandler struct {
 	logger   *slog.Logger
 	validate *validator.Validate
 	saver    OrderSaver
+
+	stallTimeout time.Duration
+	running      atomic.Bool
+	// processingSince когда началась обработка текущего сообщения в UnixNano, 0 пока ждем новое сообщение
+	processingSince atomic.Int64
+
+	mu sync.Mutex
+	// resumed закрывается при возобновлении чтения, nil если чтение не на паузе
+	resumed chan struct{}
 }
 
 func NewKafkaHandler(logger *slog.Logger, cfg config.Kafka, saver OrderSaver) *KafkaHandler {
@@ -41,13 +55,21 @@ func NewKafkaHandler(logger *slog.Logger, cfg config.Kafka, saver OrderSaver) *K
 			Balancer:     &kafka.LeastBytes{},
 			BatchTimeout: cfg.BatchTimeout,
 		},
-		validate: validator.New(),
-		saver:    saver,
+		validate:     newValidator(),
+		saver:        saver,
+		stallTimeout: cfg.StallTimeout,
 	}
 }
 
 func (h *KafkaHandler) Consume(ctx context.Context) {
+	h.running.Store(true)
+	defer h.running.Store(false)
+
 	for {
+		h.processingSince.Store(0)
+		if err := h.waitResumed(ctx); err != nil {
+			break
+		}
 		m, err := h.reader.FetchMessage(ctx)
 		if err != nil {
 			if errors.Is(err, io.EOF) || errors.Is(err, context.Canceled) {
@@ -58,6 +80,7 @@ func (h *KafkaHandler) Consume(ctx context.Context) {
 		}
 
 		start := time.Now()
+		h.processingSince.Store(start.UnixNano())
 
 		// В операции сохранения уже есть retry
 		err = h.handleSaveOrder(ctx, m)
@@ -85,19 +108,116 @@ func (h *KafkaHandler) Consume(ctx context.Context) {
 	}
 }
 
+// PingBrokers проверяет, что доступен хотя бы один брокер
+func (h *KafkaHandler) PingBrokers(ctx context.Context) error {
+	var errs []error
+	for _, broker := range h.reader.Config().Brokers {
+		conn, err := kafka.DialContext(ctx, "tcp", broker)
+		if err == nil {
+			return conn.Close()
+		}
+		errs = append(errs, err)
+	}
+	return fmt.Errorf("no kafka broker is reachable: %w", errors.Join(errs...))
+}
+
+// CheckConsumer возвращает ошибку, если чтение заказов не запущено
+// или одно сообщение обрабатывается дольше stallTimeout
+func (h *KafkaHandler) CheckConsumer(context.Context) error {
+	if !h.running.Load() {
+		return errors.New("consumer is not running")
+	}
+	if since := h.processingSince.Load(); since != 0 {
+		if d := time.Since(time.Unix(0, since)); d > h.stallTimeout {
+			return fmt.Errorf("consumer is stalled on a message for %s", d.Round(time.Second))
+		}
+	}
+	return nil
+}
+
+// Pause приостанавливает чтение заказов после обработки текущего сообщения.
+// Группа не перебалансируется, партиции остаются за репликой.
+func (h *KafkaHandler) Pause() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.resumed == nil {
+		h.resumed = make(chan struct{})
+	}
+}
+
+func (h *KafkaHandler) Resume() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.resumed != nil {
+		close(h.resumed)
+		h.resumed = nil
+	}
+}
+
+func (h *KafkaHandler) ConsumerStatus() entities.ConsumerStatus {
+	h.mu.Lock()
+	paused := h.resumed != nil
+	h.mu.Unlock()
+
+	status := entities.ConsumerStatus{
+		Running: h.running.Load(),
+		Paused:  paused,
+		Lag:     h.reader.Stats().Lag,
+	}
+	if since := h.processingSince.Load(); since != 0 {
+		status.ProcessingFor = time.Since(time.Unix(0, since))
+	}
+	return status
+}
+
+// waitResumed ждет возобновления чтения, если оно на паузе
+func (h *KafkaHandler) waitResumed(ctx context.Context) error {
+	h.mu.Lock()
+	resumed := h.resumed
+	h.mu.Unlock()
+	if resumed == nil {
+		return nil
+	}
+
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (h *KafkaHandler) handleSaveOrder(ctx context.Context, m kafka.Message) error {
-	var order Order
-	if err := json.Unmarshal(m.Value, &order); err != nil {
-		return fmt.Errorf("failed to unmarshal order: %w", err)
+	version, err := messageVersion(m)
+	if err != nil {
+		return err
 	}
 
-	if err := h.validate.Struct(order); err != nil {
-		return fmt.Errorf("invalid order data: %w", err)
+	order, err := decodeOrder(h.validate, m.Value, version)
+	if err != nil {
+		return err
 	}
 
 	return h.saver.SaveOrder(ctx, OrderJSONToEntity(order))
 }
 
+// messageVersion версия API, в форме которой записан заказ, из заголовка api-version (v1 или v2).
+// Сообщения без заголовка разбираются как v1, как и раньше.
+func messageVersion(m kafka.Message) (middleware.APIVersion, error) {
+	for _, header := range m.Headers {
+		if header.Key != apiVersionHeader {
+			continue
+		}
+		for _, v := range middleware.APIVersions {
+			if string(header.Value) == v.String() {
+				return v, nil
+			}
+		}
+		return 0, fmt.Errorf("unsupported api version %q", header.Value)
+	}
+	return middleware.APIv1, nil
+}
+
 func (h *KafkaHandler) WriteToDLQ(ctx context.Context, m kafka.Message) error {
 	m.Topic = fmt.Sprintf("%s-dlq", m.Topic)
 	return h.dlq.WriteMessages(ctx, m)
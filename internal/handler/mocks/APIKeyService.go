@@ -0,0 +1,236 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package handler
+
+import (
+	"context"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockAPIKeyService creates a new instance of MockAPIKeyService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockAPIKeyService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockAPIKeyService {
+	mock := &MockAPIKeyService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockAPIKeyService is an autogenerated mock type for the APIKeyService type
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+type MockAPIKeyService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockAPIKeyService) EXPECT() *MockAPIKeyService_Expecter {
+	return &MockAPIKeyService_Expecter{mock: &_m.Mock}
+}
+
+// CreateAPIKey provides a mock function for the type MockAPIKeyService
+func (_mock *MockAPIKeyService) CreateAPIKey(ctx context.Context, name string, scopes []string) (entities.APIKey, string, error) {
+	ret := _mock.Called(ctx, name, scopes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAPIKey")
+	}
+
+	var r0 entities.APIKey
+	var r1 string
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []string) (entities.APIKey, string, error)); ok {
+		return returnFunc(ctx, name, scopes)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []string) entities.APIKey); ok {
+		r0 = returnFunc(ctx, name, scopes)
+	} else {
+		r0 = ret.Get(0).(entities.APIKey)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, []string) string); ok {
+		r1 = returnFunc(ctx, name, scopes)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, string, []string) error); ok {
+		r2 = returnFunc(ctx, name, scopes)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockAPIKeyService_CreateAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAPIKey'
+type MockAPIKeyService_CreateAPIKey_Call struct {
+	*mock.Call
+}
+
+// CreateAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - name string
+//   - scopes []string
+func (_e *MockAPIKeyService_Expecter) CreateAPIKey(ctx interface{}, name interface{}, scopes interface{}) *MockAPIKeyService_CreateAPIKey_Call {
+	return &MockAPIKeyService_CreateAPIKey_Call{Call: _e.mock.On("CreateAPIKey", ctx, name, scopes)}
+}
+
+func (_c *MockAPIKeyService_CreateAPIKey_Call) Run(run func(ctx context.Context, name string, scopes []string)) *MockAPIKeyService_CreateAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 []string
+		if args[2] != nil {
+			arg2 = args[2].([]string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAPIKeyService_CreateAPIKey_Call) Return(aPIKey entities.APIKey, s string, err error) *MockAPIKeyService_CreateAPIKey_Call {
+	_c.Call.Return(aPIKey, s, err)
+	return _c
+}
+
+func (_c *MockAPIKeyService_CreateAPIKey_Call) RunAndReturn(run func(ctx context.Context, name string, scopes []string) (entities.APIKey, string, error)) *MockAPIKeyService_CreateAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAPIKeys provides a mock function for the type MockAPIKeyService
+func (_mock *MockAPIKeyService) ListAPIKeys(ctx context.Context) ([]entities.APIKey, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAPIKeys")
+	}
+
+	var r0 []entities.APIKey
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]entities.APIKey, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []entities.APIKey); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entities.APIKey)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAPIKeyService_ListAPIKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAPIKeys'
+type MockAPIKeyService_ListAPIKeys_Call struct {
+	*mock.Call
+}
+
+// ListAPIKeys is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockAPIKeyService_Expecter) ListAPIKeys(ctx interface{}) *MockAPIKeyService_ListAPIKeys_Call {
+	return &MockAPIKeyService_ListAPIKeys_Call{Call: _e.mock.On("ListAPIKeys", ctx)}
+}
+
+func (_c *MockAPIKeyService_ListAPIKeys_Call) Run(run func(ctx context.Context)) *MockAPIKeyService_ListAPIKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAPIKeyService_ListAPIKeys_Call) Return(aPIKeys []entities.APIKey, err error) *MockAPIKeyService_ListAPIKeys_Call {
+	_c.Call.Return(aPIKeys, err)
+	return _c
+}
+
+func (_c *MockAPIKeyService_ListAPIKeys_Call) RunAndReturn(run func(ctx context.Context) ([]entities.APIKey, error)) *MockAPIKeyService_ListAPIKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeAPIKey provides a mock function for the type MockAPIKeyService
+func (_mock *MockAPIKeyService) RevokeAPIKey(ctx context.Context, id int64) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAPIKey")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAPIKeyService_RevokeAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeAPIKey'
+type MockAPIKeyService_RevokeAPIKey_Call struct {
+	*mock.Call
+}
+
+// RevokeAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+func (_e *MockAPIKeyService_Expecter) RevokeAPIKey(ctx interface{}, id interface{}) *MockAPIKeyService_RevokeAPIKey_Call {
+	return &MockAPIKeyService_RevokeAPIKey_Call{Call: _e.mock.On("RevokeAPIKey", ctx, id)}
+}
+
+func (_c *MockAPIKeyService_RevokeAPIKey_Call) Run(run func(ctx context.Context, id int64)) *MockAPIKeyService_RevokeAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int64
+		if args[1] != nil {
+			arg1 = args[1].(int64)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAPIKeyService_RevokeAPIKey_Call) Return(err error) *MockAPIKeyService_RevokeAPIKey_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAPIKeyService_RevokeAPIKey_Call) RunAndReturn(run func(ctx context.Context, id int64) error) *MockAPIKeyService_RevokeAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -0,0 +1,197 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package handler
+
+import (
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockCacheAdmin creates a new instance of MockCacheAdmin. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockCacheAdmin(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockCacheAdmin {
+	mock := &MockCacheAdmin{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockCacheAdmin is an autogenerated mock type for the CacheAdmin type
+type MockCacheAdmin struct {
+	mock.Mock
+}
+
+type MockCacheAdmin_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockCacheAdmin) EXPECT() *MockCacheAdmin_Expecter {
+	return &MockCacheAdmin_Expecter{mock: &_m.Mock}
+}
+
+// Capacity provides a mock function for the type MockCacheAdmin
+func (_mock *MockCacheAdmin) Capacity() int {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Capacity")
+	}
+
+	var r0 int
+	if returnFunc, ok := ret.Get(0).(func() int); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	return r0
+}
+
+// MockCacheAdmin_Capacity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Capacity'
+type MockCacheAdmin_Capacity_Call struct {
+	*mock.Call
+}
+
+// Capacity is a helper method to define mock.On call
+func (_e *MockCacheAdmin_Expecter) Capacity() *MockCacheAdmin_Capacity_Call {
+	return &MockCacheAdmin_Capacity_Call{Call: _e.mock.On("Capacity")}
+}
+
+func (_c *MockCacheAdmin_Capacity_Call) Run(run func()) *MockCacheAdmin_Capacity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockCacheAdmin_Capacity_Call) Return(n int) *MockCacheAdmin_Capacity_Call {
+	_c.Call.Return(n)
+	return _c
+}
+
+func (_c *MockCacheAdmin_Capacity_Call) RunAndReturn(run func() int) *MockCacheAdmin_Capacity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function for the type MockCacheAdmin
+func (_mock *MockCacheAdmin) Delete(key string) {
+	_mock.Called(key)
+	return
+}
+
+// MockCacheAdmin_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockCacheAdmin_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - key string
+func (_e *MockCacheAdmin_Expecter) Delete(key interface{}) *MockCacheAdmin_Delete_Call {
+	return &MockCacheAdmin_Delete_Call{Call: _e.mock.On("Delete", key)}
+}
+
+func (_c *MockCacheAdmin_Delete_Call) Run(run func(key string)) *MockCacheAdmin_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCacheAdmin_Delete_Call) Return() *MockCacheAdmin_Delete_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockCacheAdmin_Delete_Call) RunAndReturn(run func(key string)) *MockCacheAdmin_Delete_Call {
+	_c.Run(run)
+	return _c
+}
+
+// Purge provides a mock function for the type MockCacheAdmin
+func (_mock *MockCacheAdmin) Purge() {
+	_mock.Called()
+	return
+}
+
+// MockCacheAdmin_Purge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Purge'
+type MockCacheAdmin_Purge_Call struct {
+	*mock.Call
+}
+
+// Purge is a helper method to define mock.On call
+func (_e *MockCacheAdmin_Expecter) Purge() *MockCacheAdmin_Purge_Call {
+	return &MockCacheAdmin_Purge_Call{Call: _e.mock.On("Purge")}
+}
+
+func (_c *MockCacheAdmin_Purge_Call) Run(run func()) *MockCacheAdmin_Purge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockCacheAdmin_Purge_Call) Return() *MockCacheAdmin_Purge_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockCacheAdmin_Purge_Call) RunAndReturn(run func()) *MockCacheAdmin_Purge_Call {
+	_c.Run(run)
+	return _c
+}
+
+// Size provides a mock function for the type MockCacheAdmin
+func (_mock *MockCacheAdmin) Size() int {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Size")
+	}
+
+	var r0 int
+	if returnFunc, ok := ret.Get(0).(func() int); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	return r0
+}
+
+// MockCacheAdmin_Size_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Size'
+type MockCacheAdmin_Size_Call struct {
+	*mock.Call
+}
+
+// Size is a helper method to define mock.On call
+func (_e *MockCacheAdmin_Expecter) Size() *MockCacheAdmin_Size_Call {
+	return &MockCacheAdmin_Size_Call{Call: _e.mock.On("Size")}
+}
+
+func (_c *MockCacheAdmin_Size_Call) Run(run func()) *MockCacheAdmin_Size_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockCacheAdmin_Size_Call) Return(n int) *MockCacheAdmin_Size_Call {
+	_c.Call.Return(n)
+	return _c
+}
+
+func (_c *MockCacheAdmin_Size_Call) RunAndReturn(run func() int) *MockCacheAdmin_Size_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -0,0 +1,147 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package handler
+
+import (
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockConsumerAdmin creates a new instance of MockConsumerAdmin. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockConsumerAdmin(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockConsumerAdmin {
+	mock := &MockConsumerAdmin{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockConsumerAdmin is an autogenerated mock type for the ConsumerAdmin type
+type MockConsumerAdmin struct {
+	mock.Mock
+}
+
+type MockConsumerAdmin_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockConsumerAdmin) EXPECT() *MockConsumerAdmin_Expecter {
+	return &MockConsumerAdmin_Expecter{mock: &_m.Mock}
+}
+
+// ConsumerStatus provides a mock function for the type MockConsumerAdmin
+func (_mock *MockConsumerAdmin) ConsumerStatus() entities.ConsumerStatus {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumerStatus")
+	}
+
+	var r0 entities.ConsumerStatus
+	if returnFunc, ok := ret.Get(0).(func() entities.ConsumerStatus); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(entities.ConsumerStatus)
+	}
+	return r0
+}
+
+// MockConsumerAdmin_ConsumerStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConsumerStatus'
+type MockConsumerAdmin_ConsumerStatus_Call struct {
+	*mock.Call
+}
+
+// ConsumerStatus is a helper method to define mock.On call
+func (_e *MockConsumerAdmin_Expecter) ConsumerStatus() *MockConsumerAdmin_ConsumerStatus_Call {
+	return &MockConsumerAdmin_ConsumerStatus_Call{Call: _e.mock.On("ConsumerStatus")}
+}
+
+func (_c *MockConsumerAdmin_ConsumerStatus_Call) Run(run func()) *MockConsumerAdmin_ConsumerStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockConsumerAdmin_ConsumerStatus_Call) Return(consumerStatus entities.ConsumerStatus) *MockConsumerAdmin_ConsumerStatus_Call {
+	_c.Call.Return(consumerStatus)
+	return _c
+}
+
+func (_c *MockConsumerAdmin_ConsumerStatus_Call) RunAndReturn(run func() entities.ConsumerStatus) *MockConsumerAdmin_ConsumerStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Pause provides a mock function for the type MockConsumerAdmin
+func (_mock *MockConsumerAdmin) Pause() {
+	_mock.Called()
+	return
+}
+
+// MockConsumerAdmin_Pause_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Pause'
+type MockConsumerAdmin_Pause_Call struct {
+	*mock.Call
+}
+
+// Pause is a helper method to define mock.On call
+func (_e *MockConsumerAdmin_Expecter) Pause() *MockConsumerAdmin_Pause_Call {
+	return &MockConsumerAdmin_Pause_Call{Call: _e.mock.On("Pause")}
+}
+
+func (_c *MockConsumerAdmin_Pause_Call) Run(run func()) *MockConsumerAdmin_Pause_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockConsumerAdmin_Pause_Call) Return() *MockConsumerAdmin_Pause_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockConsumerAdmin_Pause_Call) RunAndReturn(run func()) *MockConsumerAdmin_Pause_Call {
+	_c.Run(run)
+	return _c
+}
+
+// Resume provides a mock function for the type MockConsumerAdmin
+func (_mock *MockConsumerAdmin) Resume() {
+	_mock.Called()
+	return
+}
+
+// MockConsumerAdmin_Resume_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Resume'
+type MockConsumerAdmin_Resume_Call struct {
+	*mock.Call
+}
+
+// Resume is a helper method to define mock.On call
+func (_e *MockConsumerAdmin_Expecter) Resume() *MockConsumerAdmin_Resume_Call {
+	return &MockConsumerAdmin_Resume_Call{Call: _e.mock.On("Resume")}
+}
+
+func (_c *MockConsumerAdmin_Resume_Call) Run(run func()) *MockConsumerAdmin_Resume_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockConsumerAdmin_Resume_Call) Return() *MockConsumerAdmin_Resume_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockConsumerAdmin_Resume_Call) RunAndReturn(run func()) *MockConsumerAdmin_Resume_Call {
+	_c.Run(run)
+	return _c
+}
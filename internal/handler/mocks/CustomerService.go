@@ -0,0 +1,177 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package handler
+
+import (
+	"context"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockCustomerService creates a new instance of MockCustomerService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockCustomerService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockCustomerService {
+	mock := &MockCustomerService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockCustomerService is an autogenerated mock type for the CustomerService type
+type MockCustomerService struct {
+	mock.Mock
+}
+
+type MockCustomerService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockCustomerService) EXPECT() *MockCustomerService_Expecter {
+	return &MockCustomerService_Expecter{mock: &_m.Mock}
+}
+
+// GetCustomerSummary provides a mock function for the type MockCustomerService
+func (_mock *MockCustomerService) GetCustomerSummary(ctx context.Context, customerID string) (entities.CustomerSummary, error) {
+	ret := _mock.Called(ctx, customerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCustomerSummary")
+	}
+
+	var r0 entities.CustomerSummary
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (entities.CustomerSummary, error)); ok {
+		return returnFunc(ctx, customerID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) entities.CustomerSummary); ok {
+		r0 = returnFunc(ctx, customerID)
+	} else {
+		r0 = ret.Get(0).(entities.CustomerSummary)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, customerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCustomerService_GetCustomerSummary_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCustomerSummary'
+type MockCustomerService_GetCustomerSummary_Call struct {
+	*mock.Call
+}
+
+// GetCustomerSummary is a helper method to define mock.On call
+//   - ctx context.Context
+//   - customerID string
+func (_e *MockCustomerService_Expecter) GetCustomerSummary(ctx interface{}, customerID interface{}) *MockCustomerService_GetCustomerSummary_Call {
+	return &MockCustomerService_GetCustomerSummary_Call{Call: _e.mock.On("GetCustomerSummary", ctx, customerID)}
+}
+
+func (_c *MockCustomerService_GetCustomerSummary_Call) Run(run func(ctx context.Context, customerID string)) *MockCustomerService_GetCustomerSummary_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCustomerService_GetCustomerSummary_Call) Return(customerSummary entities.CustomerSummary, err error) *MockCustomerService_GetCustomerSummary_Call {
+	_c.Call.Return(customerSummary, err)
+	return _c
+}
+
+func (_c *MockCustomerService_GetCustomerSummary_Call) RunAndReturn(run func(ctx context.Context, customerID string) (entities.CustomerSummary, error)) *MockCustomerService_GetCustomerSummary_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListCustomerOrders provides a mock function for the type MockCustomerService
+func (_mock *MockCustomerService) ListCustomerOrders(ctx context.Context, customerID string, filter entities.OrderFilter) (entities.OrderPage, error) {
+	ret := _mock.Called(ctx, customerID, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListCustomerOrders")
+	}
+
+	var r0 entities.OrderPage
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, entities.OrderFilter) (entities.OrderPage, error)); ok {
+		return returnFunc(ctx, customerID, filter)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, entities.OrderFilter) entities.OrderPage); ok {
+		r0 = returnFunc(ctx, customerID, filter)
+	} else {
+		r0 = ret.Get(0).(entities.OrderPage)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, entities.OrderFilter) error); ok {
+		r1 = returnFunc(ctx, customerID, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCustomerService_ListCustomerOrders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListCustomerOrders'
+type MockCustomerService_ListCustomerOrders_Call struct {
+	*mock.Call
+}
+
+// ListCustomerOrders is a helper method to define mock.On call
+//   - ctx context.Context
+//   - customerID string
+//   - filter entities.OrderFilter
+func (_e *MockCustomerService_Expecter) ListCustomerOrders(ctx interface{}, customerID interface{}, filter interface{}) *MockCustomerService_ListCustomerOrders_Call {
+	return &MockCustomerService_ListCustomerOrders_Call{Call: _e.mock.On("ListCustomerOrders", ctx, customerID, filter)}
+}
+
+func (_c *MockCustomerService_ListCustomerOrders_Call) Run(run func(ctx context.Context, customerID string, filter entities.OrderFilter)) *MockCustomerService_ListCustomerOrders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 entities.OrderFilter
+		if args[2] != nil {
+			arg2 = args[2].(entities.OrderFilter)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCustomerService_ListCustomerOrders_Call) Return(orderPage entities.OrderPage, err error) *MockCustomerService_ListCustomerOrders_Call {
+	_c.Call.Return(orderPage, err)
+	return _c
+}
+
+func (_c *MockCustomerService_ListCustomerOrders_Call) RunAndReturn(run func(ctx context.Context, customerID string, filter entities.OrderFilter) (entities.OrderPage, error)) *MockCustomerService_ListCustomerOrders_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -0,0 +1,251 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package handler
+
+import (
+	"context"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockIdempotencyStore creates a new instance of MockIdempotencyStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockIdempotencyStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockIdempotencyStore {
+	mock := &MockIdempotencyStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockIdempotencyStore is an autogenerated mock type for the IdempotencyStore type
+type MockIdempotencyStore struct {
+	mock.Mock
+}
+
+type MockIdempotencyStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockIdempotencyStore) EXPECT() *MockIdempotencyStore_Expecter {
+	return &MockIdempotencyStore_Expecter{mock: &_m.Mock}
+}
+
+// Begin provides a mock function for the type MockIdempotencyStore
+func (_mock *MockIdempotencyStore) Begin(ctx context.Context, subject string, key string, requestHash string) (*entities.IdempotentResponse, error) {
+	ret := _mock.Called(ctx, subject, key, requestHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Begin")
+	}
+
+	var r0 *entities.IdempotentResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, string) (*entities.IdempotentResponse, error)); ok {
+		return returnFunc(ctx, subject, key, requestHash)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, string) *entities.IdempotentResponse); ok {
+		r0 = returnFunc(ctx, subject, key, requestHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entities.IdempotentResponse)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = returnFunc(ctx, subject, key, requestHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockIdempotencyStore_Begin_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Begin'
+type MockIdempotencyStore_Begin_Call struct {
+	*mock.Call
+}
+
+// Begin is a helper method to define mock.On call
+//   - ctx context.Context
+//   - subject string
+//   - key string
+//   - requestHash string
+func (_e *MockIdempotencyStore_Expecter) Begin(ctx interface{}, subject interface{}, key interface{}, requestHash interface{}) *MockIdempotencyStore_Begin_Call {
+	return &MockIdempotencyStore_Begin_Call{Call: _e.mock.On("Begin", ctx, subject, key, requestHash)}
+}
+
+func (_c *MockIdempotencyStore_Begin_Call) Run(run func(ctx context.Context, subject string, key string, requestHash string)) *MockIdempotencyStore_Begin_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockIdempotencyStore_Begin_Call) Return(idempotentResponse *entities.IdempotentResponse, err error) *MockIdempotencyStore_Begin_Call {
+	_c.Call.Return(idempotentResponse, err)
+	return _c
+}
+
+func (_c *MockIdempotencyStore_Begin_Call) RunAndReturn(run func(ctx context.Context, subject string, key string, requestHash string) (*entities.IdempotentResponse, error)) *MockIdempotencyStore_Begin_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Complete provides a mock function for the type MockIdempotencyStore
+func (_mock *MockIdempotencyStore) Complete(ctx context.Context, subject string, key string, resp entities.IdempotentResponse) error {
+	ret := _mock.Called(ctx, subject, key, resp)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Complete")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, entities.IdempotentResponse) error); ok {
+		r0 = returnFunc(ctx, subject, key, resp)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockIdempotencyStore_Complete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Complete'
+type MockIdempotencyStore_Complete_Call struct {
+	*mock.Call
+}
+
+// Complete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - subject string
+//   - key string
+//   - resp entities.IdempotentResponse
+func (_e *MockIdempotencyStore_Expecter) Complete(ctx interface{}, subject interface{}, key interface{}, resp interface{}) *MockIdempotencyStore_Complete_Call {
+	return &MockIdempotencyStore_Complete_Call{Call: _e.mock.On("Complete", ctx, subject, key, resp)}
+}
+
+func (_c *MockIdempotencyStore_Complete_Call) Run(run func(ctx context.Context, subject string, key string, resp entities.IdempotentResponse)) *MockIdempotencyStore_Complete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 entities.IdempotentResponse
+		if args[3] != nil {
+			arg3 = args[3].(entities.IdempotentResponse)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockIdempotencyStore_Complete_Call) Return(err error) *MockIdempotencyStore_Complete_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockIdempotencyStore_Complete_Call) RunAndReturn(run func(ctx context.Context, subject string, key string, resp entities.IdempotentResponse) error) *MockIdempotencyStore_Complete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Release provides a mock function for the type MockIdempotencyStore
+func (_mock *MockIdempotencyStore) Release(ctx context.Context, subject string, key string) error {
+	ret := _mock.Called(ctx, subject, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Release")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, subject, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockIdempotencyStore_Release_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Release'
+type MockIdempotencyStore_Release_Call struct {
+	*mock.Call
+}
+
+// Release is a helper method to define mock.On call
+//   - ctx context.Context
+//   - subject string
+//   - key string
+func (_e *MockIdempotencyStore_Expecter) Release(ctx interface{}, subject interface{}, key interface{}) *MockIdempotencyStore_Release_Call {
+	return &MockIdempotencyStore_Release_Call{Call: _e.mock.On("Release", ctx, subject, key)}
+}
+
+func (_c *MockIdempotencyStore_Release_Call) Run(run func(ctx context.Context, subject string, key string)) *MockIdempotencyStore_Release_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockIdempotencyStore_Release_Call) Return(err error) *MockIdempotencyStore_Release_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockIdempotencyStore_Release_Call) RunAndReturn(run func(ctx context.Context, subject string, key string) error) *MockIdempotencyStore_Release_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -0,0 +1,102 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package handler
+
+import (
+	"context"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockOrderExporter creates a new instance of MockOrderExporter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockOrderExporter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockOrderExporter {
+	mock := &MockOrderExporter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockOrderExporter is an autogenerated mock type for the OrderExporter type
+type MockOrderExporter struct {
+	mock.Mock
+}
+
+type MockOrderExporter_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockOrderExporter) EXPECT() *MockOrderExporter_Expecter {
+	return &MockOrderExporter_Expecter{mock: &_m.Mock}
+}
+
+// ExportOrders provides a mock function for the type MockOrderExporter
+func (_mock *MockOrderExporter) ExportOrders(ctx context.Context, filter entities.OrderFilter, fn func(orders []entities.Order) error) error {
+	ret := _mock.Called(ctx, filter, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportOrders")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, entities.OrderFilter, func(orders []entities.Order) error) error); ok {
+		r0 = returnFunc(ctx, filter, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockOrderExporter_ExportOrders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportOrders'
+type MockOrderExporter_ExportOrders_Call struct {
+	*mock.Call
+}
+
+// ExportOrders is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter entities.OrderFilter
+//   - fn func(orders []entities.Order) error
+func (_e *MockOrderExporter_Expecter) ExportOrders(ctx interface{}, filter interface{}, fn interface{}) *MockOrderExporter_ExportOrders_Call {
+	return &MockOrderExporter_ExportOrders_Call{Call: _e.mock.On("ExportOrders", ctx, filter, fn)}
+}
+
+func (_c *MockOrderExporter_ExportOrders_Call) Run(run func(ctx context.Context, filter entities.OrderFilter, fn func(orders []entities.Order) error)) *MockOrderExporter_ExportOrders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 entities.OrderFilter
+		if args[1] != nil {
+			arg1 = args[1].(entities.OrderFilter)
+		}
+		var arg2 func(orders []entities.Order) error
+		if args[2] != nil {
+			arg2 = args[2].(func(orders []entities.Order) error)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderExporter_ExportOrders_Call) Return(err error) *MockOrderExporter_ExportOrders_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockOrderExporter_ExportOrders_Call) RunAndReturn(run func(ctx context.Context, filter entities.OrderFilter, fn func(orders []entities.Order) error) error) *MockOrderExporter_ExportOrders_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -0,0 +1,101 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package handler
+
+import (
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockOrderFeed creates a new instance of MockOrderFeed. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockOrderFeed(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockOrderFeed {
+	mock := &MockOrderFeed{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockOrderFeed is an autogenerated mock type for the OrderFeed type
+type MockOrderFeed struct {
+	mock.Mock
+}
+
+type MockOrderFeed_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockOrderFeed) EXPECT() *MockOrderFeed_Expecter {
+	return &MockOrderFeed_Expecter{mock: &_m.Mock}
+}
+
+// Subscribe provides a mock function for the type MockOrderFeed
+func (_mock *MockOrderFeed) Subscribe(afterID string) (<-chan entities.OrderEvent, func()) {
+	ret := _mock.Called(afterID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Subscribe")
+	}
+
+	var r0 <-chan entities.OrderEvent
+	var r1 func()
+	if returnFunc, ok := ret.Get(0).(func(string) (<-chan entities.OrderEvent, func())); ok {
+		return returnFunc(afterID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(string) <-chan entities.OrderEvent); ok {
+		r0 = returnFunc(afterID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan entities.OrderEvent)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(string) func()); ok {
+		r1 = returnFunc(afterID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(func())
+		}
+	}
+	return r0, r1
+}
+
+// MockOrderFeed_Subscribe_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Subscribe'
+type MockOrderFeed_Subscribe_Call struct {
+	*mock.Call
+}
+
+// Subscribe is a helper method to define mock.On call
+//   - afterID string
+func (_e *MockOrderFeed_Expecter) Subscribe(afterID interface{}) *MockOrderFeed_Subscribe_Call {
+	return &MockOrderFeed_Subscribe_Call{Call: _e.mock.On("Subscribe", afterID)}
+}
+
+func (_c *MockOrderFeed_Subscribe_Call) Run(run func(afterID string)) *MockOrderFeed_Subscribe_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderFeed_Subscribe_Call) Return(orderEventCh <-chan entities.OrderEvent, fn func()) *MockOrderFeed_Subscribe_Call {
+	_c.Call.Return(orderEventCh, fn)
+	return _c
+}
+
+func (_c *MockOrderFeed_Subscribe_Call) RunAndReturn(run func(afterID string) (<-chan entities.OrderEvent, func())) *MockOrderFeed_Subscribe_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -0,0 +1,460 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package handler
+
+import (
+	"context"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockOrderService creates a new instance of MockOrderService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockOrderService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockOrderService {
+	mock := &MockOrderService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockOrderService is an autogenerated mock type for the OrderService type
+type MockOrderService struct {
+	mock.Mock
+}
+
+type MockOrderService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockOrderService) EXPECT() *MockOrderService_Expecter {
+	return &MockOrderService_Expecter{mock: &_m.Mock}
+}
+
+// GetOrderByID provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) GetOrderByID(ctx context.Context, orderUID string, excludeItems bool) (entities.Order, error) {
+	ret := _mock.Called(ctx, orderUID, excludeItems)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderByID")
+	}
+
+	var r0 entities.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, bool) (entities.Order, error)); ok {
+		return returnFunc(ctx, orderUID, excludeItems)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, bool) entities.Order); ok {
+		r0 = returnFunc(ctx, orderUID, excludeItems)
+	} else {
+		r0 = ret.Get(0).(entities.Order)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = returnFunc(ctx, orderUID, excludeItems)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_GetOrderByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrderByID'
+type MockOrderService_GetOrderByID_Call struct {
+	*mock.Call
+}
+
+// GetOrderByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - orderUID string
+//   - excludeItems bool
+func (_e *MockOrderService_Expecter) GetOrderByID(ctx interface{}, orderUID interface{}, excludeItems interface{}) *MockOrderService_GetOrderByID_Call {
+	return &MockOrderService_GetOrderByID_Call{Call: _e.mock.On("GetOrderByID", ctx, orderUID, excludeItems)}
+}
+
+func (_c *MockOrderService_GetOrderByID_Call) Run(run func(ctx context.Context, orderUID string, excludeItems bool)) *MockOrderService_GetOrderByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 bool
+		if args[2] != nil {
+			arg2 = args[2].(bool)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_GetOrderByID_Call) Return(order entities.Order, err error) *MockOrderService_GetOrderByID_Call {
+	_c.Call.Return(order, err)
+	return _c
+}
+
+func (_c *MockOrderService_GetOrderByID_Call) RunAndReturn(run func(ctx context.Context, orderUID string, excludeItems bool) (entities.Order, error)) *MockOrderService_GetOrderByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOrderByTrackNumber provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) GetOrderByTrackNumber(ctx context.Context, trackNumber string, excludeItems bool) (entities.Order, error) {
+	ret := _mock.Called(ctx, trackNumber, excludeItems)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderByTrackNumber")
+	}
+
+	var r0 entities.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, bool) (entities.Order, error)); ok {
+		return returnFunc(ctx, trackNumber, excludeItems)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, bool) entities.Order); ok {
+		r0 = returnFunc(ctx, trackNumber, excludeItems)
+	} else {
+		r0 = ret.Get(0).(entities.Order)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = returnFunc(ctx, trackNumber, excludeItems)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_GetOrderByTrackNumber_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrderByTrackNumber'
+type MockOrderService_GetOrderByTrackNumber_Call struct {
+	*mock.Call
+}
+
+// GetOrderByTrackNumber is a helper method to define mock.On call
+//   - ctx context.Context
+//   - trackNumber string
+//   - excludeItems bool
+func (_e *MockOrderService_Expecter) GetOrderByTrackNumber(ctx interface{}, trackNumber interface{}, excludeItems interface{}) *MockOrderService_GetOrderByTrackNumber_Call {
+	return &MockOrderService_GetOrderByTrackNumber_Call{Call: _e.mock.On("GetOrderByTrackNumber", ctx, trackNumber, excludeItems)}
+}
+
+func (_c *MockOrderService_GetOrderByTrackNumber_Call) Run(run func(ctx context.Context, trackNumber string, excludeItems bool)) *MockOrderService_GetOrderByTrackNumber_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 bool
+		if args[2] != nil {
+			arg2 = args[2].(bool)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_GetOrderByTrackNumber_Call) Return(order entities.Order, err error) *MockOrderService_GetOrderByTrackNumber_Call {
+	_c.Call.Return(order, err)
+	return _c
+}
+
+func (_c *MockOrderService_GetOrderByTrackNumber_Call) RunAndReturn(run func(ctx context.Context, trackNumber string, excludeItems bool) (entities.Order, error)) *MockOrderService_GetOrderByTrackNumber_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOrdersByIDs provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) GetOrdersByIDs(ctx context.Context, orderUIDs []string) ([]entities.Order, []string, error) {
+	ret := _mock.Called(ctx, orderUIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrdersByIDs")
+	}
+
+	var r0 []entities.Order
+	var r1 []string
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) ([]entities.Order, []string, error)); ok {
+		return returnFunc(ctx, orderUIDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) []entities.Order); ok {
+		r0 = returnFunc(ctx, orderUIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entities.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []string) []string); ok {
+		r1 = returnFunc(ctx, orderUIDs)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]string)
+		}
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, []string) error); ok {
+		r2 = returnFunc(ctx, orderUIDs)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockOrderService_GetOrdersByIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrdersByIDs'
+type MockOrderService_GetOrdersByIDs_Call struct {
+	*mock.Call
+}
+
+// GetOrdersByIDs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - orderUIDs []string
+func (_e *MockOrderService_Expecter) GetOrdersByIDs(ctx interface{}, orderUIDs interface{}) *MockOrderService_GetOrdersByIDs_Call {
+	return &MockOrderService_GetOrdersByIDs_Call{Call: _e.mock.On("GetOrdersByIDs", ctx, orderUIDs)}
+}
+
+func (_c *MockOrderService_GetOrdersByIDs_Call) Run(run func(ctx context.Context, orderUIDs []string)) *MockOrderService_GetOrdersByIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []string
+		if args[1] != nil {
+			arg1 = args[1].([]string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_GetOrdersByIDs_Call) Return(orders []entities.Order, strings []string, err error) *MockOrderService_GetOrdersByIDs_Call {
+	_c.Call.Return(orders, strings, err)
+	return _c
+}
+
+func (_c *MockOrderService_GetOrdersByIDs_Call) RunAndReturn(run func(ctx context.Context, orderUIDs []string) ([]entities.Order, []string, error)) *MockOrderService_GetOrdersByIDs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOrdersByItemTrackNumber provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) GetOrdersByItemTrackNumber(ctx context.Context, trackNumber string, limit int, after *entities.OrderCursor) (entities.OrderPage, error) {
+	ret := _mock.Called(ctx, trackNumber, limit, after)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrdersByItemTrackNumber")
+	}
+
+	var r0 entities.OrderPage
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int, *entities.OrderCursor) (entities.OrderPage, error)); ok {
+		return returnFunc(ctx, trackNumber, limit, after)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int, *entities.OrderCursor) entities.OrderPage); ok {
+		r0 = returnFunc(ctx, trackNumber, limit, after)
+	} else {
+		r0 = ret.Get(0).(entities.OrderPage)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, int, *entities.OrderCursor) error); ok {
+		r1 = returnFunc(ctx, trackNumber, limit, after)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_GetOrdersByItemTrackNumber_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrdersByItemTrackNumber'
+type MockOrderService_GetOrdersByItemTrackNumber_Call struct {
+	*mock.Call
+}
+
+// GetOrdersByItemTrackNumber is a helper method to define mock.On call
+//   - ctx context.Context
+//   - trackNumber string
+//   - limit int
+//   - after *entities.OrderCursor
+func (_e *MockOrderService_Expecter) GetOrdersByItemTrackNumber(ctx interface{}, trackNumber interface{}, limit interface{}, after interface{}) *MockOrderService_GetOrdersByItemTrackNumber_Call {
+	return &MockOrderService_GetOrdersByItemTrackNumber_Call{Call: _e.mock.On("GetOrdersByItemTrackNumber", ctx, trackNumber, limit, after)}
+}
+
+func (_c *MockOrderService_GetOrdersByItemTrackNumber_Call) Run(run func(ctx context.Context, trackNumber string, limit int, after *entities.OrderCursor)) *MockOrderService_GetOrdersByItemTrackNumber_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		var arg3 *entities.OrderCursor
+		if args[3] != nil {
+			arg3 = args[3].(*entities.OrderCursor)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_GetOrdersByItemTrackNumber_Call) Return(orderPage entities.OrderPage, err error) *MockOrderService_GetOrdersByItemTrackNumber_Call {
+	_c.Call.Return(orderPage, err)
+	return _c
+}
+
+func (_c *MockOrderService_GetOrdersByItemTrackNumber_Call) RunAndReturn(run func(ctx context.Context, trackNumber string, limit int, after *entities.OrderCursor) (entities.OrderPage, error)) *MockOrderService_GetOrdersByItemTrackNumber_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListOrders provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) ListOrders(ctx context.Context, filter entities.OrderFilter) (entities.OrderPage, error) {
+	ret := _mock.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOrders")
+	}
+
+	var r0 entities.OrderPage
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, entities.OrderFilter) (entities.OrderPage, error)); ok {
+		return returnFunc(ctx, filter)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, entities.OrderFilter) entities.OrderPage); ok {
+		r0 = returnFunc(ctx, filter)
+	} else {
+		r0 = ret.Get(0).(entities.OrderPage)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, entities.OrderFilter) error); ok {
+		r1 = returnFunc(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderService_ListOrders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListOrders'
+type MockOrderService_ListOrders_Call struct {
+	*mock.Call
+}
+
+// ListOrders is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter entities.OrderFilter
+func (_e *MockOrderService_Expecter) ListOrders(ctx interface{}, filter interface{}) *MockOrderService_ListOrders_Call {
+	return &MockOrderService_ListOrders_Call{Call: _e.mock.On("ListOrders", ctx, filter)}
+}
+
+func (_c *MockOrderService_ListOrders_Call) Run(run func(ctx context.Context, filter entities.OrderFilter)) *MockOrderService_ListOrders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 entities.OrderFilter
+		if args[1] != nil {
+			arg1 = args[1].(entities.OrderFilter)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_ListOrders_Call) Return(orderPage entities.OrderPage, err error) *MockOrderService_ListOrders_Call {
+	_c.Call.Return(orderPage, err)
+	return _c
+}
+
+func (_c *MockOrderService_ListOrders_Call) RunAndReturn(run func(ctx context.Context, filter entities.OrderFilter) (entities.OrderPage, error)) *MockOrderService_ListOrders_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveOrder provides a mock function for the type MockOrderService
+func (_mock *MockOrderService) SaveOrder(ctx context.Context, order entities.Order) error {
+	ret := _mock.Called(ctx, order)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveOrder")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, entities.Order) error); ok {
+		r0 = returnFunc(ctx, order)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockOrderService_SaveOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveOrder'
+type MockOrderService_SaveOrder_Call struct {
+	*mock.Call
+}
+
+// SaveOrder is a helper method to define mock.On call
+//   - ctx context.Context
+//   - order entities.Order
+func (_e *MockOrderService_Expecter) SaveOrder(ctx interface{}, order interface{}) *MockOrderService_SaveOrder_Call {
+	return &MockOrderService_SaveOrder_Call{Call: _e.mock.On("SaveOrder", ctx, order)}
+}
+
+func (_c *MockOrderService_SaveOrder_Call) Run(run func(ctx context.Context, order entities.Order)) *MockOrderService_SaveOrder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 entities.Order
+		if args[1] != nil {
+			arg1 = args[1].(entities.Order)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderService_SaveOrder_Call) Return(err error) *MockOrderService_SaveOrder_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockOrderService_SaveOrder_Call) RunAndReturn(run func(ctx context.Context, order entities.Order) error) *MockOrderService_SaveOrder_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -0,0 +1,100 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package handler
+
+import (
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockOrderWaiter creates a new instance of MockOrderWaiter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockOrderWaiter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockOrderWaiter {
+	mock := &MockOrderWaiter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockOrderWaiter is an autogenerated mock type for the OrderWaiter type
+type MockOrderWaiter struct {
+	mock.Mock
+}
+
+type MockOrderWaiter_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockOrderWaiter) EXPECT() *MockOrderWaiter_Expecter {
+	return &MockOrderWaiter_Expecter{mock: &_m.Mock}
+}
+
+// Wait provides a mock function for the type MockOrderWaiter
+func (_mock *MockOrderWaiter) Wait(orderUID string) (<-chan struct{}, func()) {
+	ret := _mock.Called(orderUID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Wait")
+	}
+
+	var r0 <-chan struct{}
+	var r1 func()
+	if returnFunc, ok := ret.Get(0).(func(string) (<-chan struct{}, func())); ok {
+		return returnFunc(orderUID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(string) <-chan struct{}); ok {
+		r0 = returnFunc(orderUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan struct{})
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(string) func()); ok {
+		r1 = returnFunc(orderUID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(func())
+		}
+	}
+	return r0, r1
+}
+
+// MockOrderWaiter_Wait_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Wait'
+type MockOrderWaiter_Wait_Call struct {
+	*mock.Call
+}
+
+// Wait is a helper method to define mock.On call
+//   - orderUID string
+func (_e *MockOrderWaiter_Expecter) Wait(orderUID interface{}) *MockOrderWaiter_Wait_Call {
+	return &MockOrderWaiter_Wait_Call{Call: _e.mock.On("Wait", orderUID)}
+}
+
+func (_c *MockOrderWaiter_Wait_Call) Run(run func(orderUID string)) *MockOrderWaiter_Wait_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderWaiter_Wait_Call) Return(valCh <-chan struct{}, fn func()) *MockOrderWaiter_Wait_Call {
+	_c.Call.Return(valCh, fn)
+	return _c
+}
+
+func (_c *MockOrderWaiter_Wait_Call) RunAndReturn(run func(orderUID string) (<-chan struct{}, func())) *MockOrderWaiter_Wait_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -0,0 +1,198 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package handler
+
+import (
+	"context"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockPIIPolicy creates a new instance of MockPIIPolicy. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockPIIPolicy(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockPIIPolicy {
+	mock := &MockPIIPolicy{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockPIIPolicy is an autogenerated mock type for the PIIPolicy type
+type MockPIIPolicy struct {
+	mock.Mock
+}
+
+type MockPIIPolicy_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockPIIPolicy) EXPECT() *MockPIIPolicy_Expecter {
+	return &MockPIIPolicy_Expecter{mock: &_m.Mock}
+}
+
+// CanReveal provides a mock function for the type MockPIIPolicy
+func (_mock *MockPIIPolicy) CanReveal(p entities.Principal) bool {
+	ret := _mock.Called(p)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CanReveal")
+	}
+
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func(entities.Principal) bool); ok {
+		r0 = returnFunc(p)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	return r0
+}
+
+// MockPIIPolicy_CanReveal_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CanReveal'
+type MockPIIPolicy_CanReveal_Call struct {
+	*mock.Call
+}
+
+// CanReveal is a helper method to define mock.On call
+//   - p entities.Principal
+func (_e *MockPIIPolicy_Expecter) CanReveal(p interface{}) *MockPIIPolicy_CanReveal_Call {
+	return &MockPIIPolicy_CanReveal_Call{Call: _e.mock.On("CanReveal", p)}
+}
+
+func (_c *MockPIIPolicy_CanReveal_Call) Run(run func(p entities.Principal)) *MockPIIPolicy_CanReveal_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 entities.Principal
+		if args[0] != nil {
+			arg0 = args[0].(entities.Principal)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPIIPolicy_CanReveal_Call) Return(b bool) *MockPIIPolicy_CanReveal_Call {
+	_c.Call.Return(b)
+	return _c
+}
+
+func (_c *MockPIIPolicy_CanReveal_Call) RunAndReturn(run func(p entities.Principal) bool) *MockPIIPolicy_CanReveal_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LogAccess provides a mock function for the type MockPIIPolicy
+func (_mock *MockPIIPolicy) LogAccess(ctx context.Context, a entities.PIIAccess) error {
+	ret := _mock.Called(ctx, a)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LogAccess")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, entities.PIIAccess) error); ok {
+		r0 = returnFunc(ctx, a)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockPIIPolicy_LogAccess_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LogAccess'
+type MockPIIPolicy_LogAccess_Call struct {
+	*mock.Call
+}
+
+// LogAccess is a helper method to define mock.On call
+//   - ctx context.Context
+//   - a entities.PIIAccess
+func (_e *MockPIIPolicy_Expecter) LogAccess(ctx interface{}, a interface{}) *MockPIIPolicy_LogAccess_Call {
+	return &MockPIIPolicy_LogAccess_Call{Call: _e.mock.On("LogAccess", ctx, a)}
+}
+
+func (_c *MockPIIPolicy_LogAccess_Call) Run(run func(ctx context.Context, a entities.PIIAccess)) *MockPIIPolicy_LogAccess_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 entities.PIIAccess
+		if args[1] != nil {
+			arg1 = args[1].(entities.PIIAccess)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPIIPolicy_LogAccess_Call) Return(err error) *MockPIIPolicy_LogAccess_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockPIIPolicy_LogAccess_Call) RunAndReturn(run func(ctx context.Context, a entities.PIIAccess) error) *MockPIIPolicy_LogAccess_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MaskDelivery provides a mock function for the type MockPIIPolicy
+func (_mock *MockPIIPolicy) MaskDelivery(d entities.Delivery) entities.Delivery {
+	ret := _mock.Called(d)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MaskDelivery")
+	}
+
+	var r0 entities.Delivery
+	if returnFunc, ok := ret.Get(0).(func(entities.Delivery) entities.Delivery); ok {
+		r0 = returnFunc(d)
+	} else {
+		r0 = ret.Get(0).(entities.Delivery)
+	}
+	return r0
+}
+
+// MockPIIPolicy_MaskDelivery_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MaskDelivery'
+type MockPIIPolicy_MaskDelivery_Call struct {
+	*mock.Call
+}
+
+// MaskDelivery is a helper method to define mock.On call
+//   - d entities.Delivery
+func (_e *MockPIIPolicy_Expecter) MaskDelivery(d interface{}) *MockPIIPolicy_MaskDelivery_Call {
+	return &MockPIIPolicy_MaskDelivery_Call{Call: _e.mock.On("MaskDelivery", d)}
+}
+
+func (_c *MockPIIPolicy_MaskDelivery_Call) Run(run func(d entities.Delivery)) *MockPIIPolicy_MaskDelivery_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 entities.Delivery
+		if args[0] != nil {
+			arg0 = args[0].(entities.Delivery)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPIIPolicy_MaskDelivery_Call) Return(delivery entities.Delivery) *MockPIIPolicy_MaskDelivery_Call {
+	_c.Call.Return(delivery)
+	return _c
+}
+
+func (_c *MockPIIPolicy_MaskDelivery_Call) RunAndReturn(run func(d entities.Delivery) entities.Delivery) *MockPIIPolicy_MaskDelivery_Call {
+	_c.Call.Return(run)
+	return _c
+}
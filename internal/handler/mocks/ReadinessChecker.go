@@ -0,0 +1,90 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package handler
+
+import (
+	"context"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockReadinessChecker creates a new instance of MockReadinessChecker. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockReadinessChecker(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockReadinessChecker {
+	mock := &MockReadinessChecker{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockReadinessChecker is an autogenerated mock type for the ReadinessChecker type
+type MockReadinessChecker struct {
+	mock.Mock
+}
+
+type MockReadinessChecker_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockReadinessChecker) EXPECT() *MockReadinessChecker_Expecter {
+	return &MockReadinessChecker_Expecter{mock: &_m.Mock}
+}
+
+// Readiness provides a mock function for the type MockReadinessChecker
+func (_mock *MockReadinessChecker) Readiness(ctx context.Context) entities.Readiness {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Readiness")
+	}
+
+	var r0 entities.Readiness
+	if returnFunc, ok := ret.Get(0).(func(context.Context) entities.Readiness); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Get(0).(entities.Readiness)
+	}
+	return r0
+}
+
+// MockReadinessChecker_Readiness_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Readiness'
+type MockReadinessChecker_Readiness_Call struct {
+	*mock.Call
+}
+
+// Readiness is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockReadinessChecker_Expecter) Readiness(ctx interface{}) *MockReadinessChecker_Readiness_Call {
+	return &MockReadinessChecker_Readiness_Call{Call: _e.mock.On("Readiness", ctx)}
+}
+
+func (_c *MockReadinessChecker_Readiness_Call) Run(run func(ctx context.Context)) *MockReadinessChecker_Readiness_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockReadinessChecker_Readiness_Call) Return(readiness entities.Readiness) *MockReadinessChecker_Readiness_Call {
+	_c.Call.Return(readiness)
+	return _c
+}
+
+func (_c *MockReadinessChecker_Readiness_Call) RunAndReturn(run func(ctx context.Context) entities.Readiness) *MockReadinessChecker_Readiness_Call {
+	_c.Call.Return(run)
+	return _c
+}
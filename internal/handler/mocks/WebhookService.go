@@ -0,0 +1,442 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package handler
+
+import (
+	"context"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockWebhookService creates a new instance of MockWebhookService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockWebhookService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockWebhookService {
+	mock := &MockWebhookService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockWebhookService is an autogenerated mock type for the WebhookService type
+type MockWebhookService struct {
+	mock.Mock
+}
+
+type MockWebhookService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockWebhookService) EXPECT() *MockWebhookService_Expecter {
+	return &MockWebhookService_Expecter{mock: &_m.Mock}
+}
+
+// CreateWebhook provides a mock function for the type MockWebhookService
+func (_mock *MockWebhookService) CreateWebhook(ctx context.Context, url string, events []string) (entities.Webhook, error) {
+	ret := _mock.Called(ctx, url, events)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateWebhook")
+	}
+
+	var r0 entities.Webhook
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []string) (entities.Webhook, error)); ok {
+		return returnFunc(ctx, url, events)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []string) entities.Webhook); ok {
+		r0 = returnFunc(ctx, url, events)
+	} else {
+		r0 = ret.Get(0).(entities.Webhook)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = returnFunc(ctx, url, events)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockWebhookService_CreateWebhook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateWebhook'
+type MockWebhookService_CreateWebhook_Call struct {
+	*mock.Call
+}
+
+// CreateWebhook is a helper method to define mock.On call
+//   - ctx context.Context
+//   - url string
+//   - events []string
+func (_e *MockWebhookService_Expecter) CreateWebhook(ctx interface{}, url interface{}, events interface{}) *MockWebhookService_CreateWebhook_Call {
+	return &MockWebhookService_CreateWebhook_Call{Call: _e.mock.On("CreateWebhook", ctx, url, events)}
+}
+
+func (_c *MockWebhookService_CreateWebhook_Call) Run(run func(ctx context.Context, url string, events []string)) *MockWebhookService_CreateWebhook_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 []string
+		if args[2] != nil {
+			arg2 = args[2].([]string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockWebhookService_CreateWebhook_Call) Return(webhook entities.Webhook, err error) *MockWebhookService_CreateWebhook_Call {
+	_c.Call.Return(webhook, err)
+	return _c
+}
+
+func (_c *MockWebhookService_CreateWebhook_Call) RunAndReturn(run func(ctx context.Context, url string, events []string) (entities.Webhook, error)) *MockWebhookService_CreateWebhook_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteWebhook provides a mock function for the type MockWebhookService
+func (_mock *MockWebhookService) DeleteWebhook(ctx context.Context, id int64) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteWebhook")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockWebhookService_DeleteWebhook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteWebhook'
+type MockWebhookService_DeleteWebhook_Call struct {
+	*mock.Call
+}
+
+// DeleteWebhook is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+func (_e *MockWebhookService_Expecter) DeleteWebhook(ctx interface{}, id interface{}) *MockWebhookService_DeleteWebhook_Call {
+	return &MockWebhookService_DeleteWebhook_Call{Call: _e.mock.On("DeleteWebhook", ctx, id)}
+}
+
+func (_c *MockWebhookService_DeleteWebhook_Call) Run(run func(ctx context.Context, id int64)) *MockWebhookService_DeleteWebhook_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int64
+		if args[1] != nil {
+			arg1 = args[1].(int64)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockWebhookService_DeleteWebhook_Call) Return(err error) *MockWebhookService_DeleteWebhook_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockWebhookService_DeleteWebhook_Call) RunAndReturn(run func(ctx context.Context, id int64) error) *MockWebhookService_DeleteWebhook_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetWebhook provides a mock function for the type MockWebhookService
+func (_mock *MockWebhookService) GetWebhook(ctx context.Context, id int64) (entities.Webhook, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWebhook")
+	}
+
+	var r0 entities.Webhook
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (entities.Webhook, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) entities.Webhook); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(entities.Webhook)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockWebhookService_GetWebhook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetWebhook'
+type MockWebhookService_GetWebhook_Call struct {
+	*mock.Call
+}
+
+// GetWebhook is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+func (_e *MockWebhookService_Expecter) GetWebhook(ctx interface{}, id interface{}) *MockWebhookService_GetWebhook_Call {
+	return &MockWebhookService_GetWebhook_Call{Call: _e.mock.On("GetWebhook", ctx, id)}
+}
+
+func (_c *MockWebhookService_GetWebhook_Call) Run(run func(ctx context.Context, id int64)) *MockWebhookService_GetWebhook_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int64
+		if args[1] != nil {
+			arg1 = args[1].(int64)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockWebhookService_GetWebhook_Call) Return(webhook entities.Webhook, err error) *MockWebhookService_GetWebhook_Call {
+	_c.Call.Return(webhook, err)
+	return _c
+}
+
+func (_c *MockWebhookService_GetWebhook_Call) RunAndReturn(run func(ctx context.Context, id int64) (entities.Webhook, error)) *MockWebhookService_GetWebhook_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListWebhookDeliveries provides a mock function for the type MockWebhookService
+func (_mock *MockWebhookService) ListWebhookDeliveries(ctx context.Context, id int64, limit int) ([]entities.WebhookDelivery, error) {
+	ret := _mock.Called(ctx, id, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListWebhookDeliveries")
+	}
+
+	var r0 []entities.WebhookDelivery
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int) ([]entities.WebhookDelivery, error)); ok {
+		return returnFunc(ctx, id, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int) []entities.WebhookDelivery); ok {
+		r0 = returnFunc(ctx, id, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entities.WebhookDelivery)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int) error); ok {
+		r1 = returnFunc(ctx, id, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockWebhookService_ListWebhookDeliveries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListWebhookDeliveries'
+type MockWebhookService_ListWebhookDeliveries_Call struct {
+	*mock.Call
+}
+
+// ListWebhookDeliveries is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+//   - limit int
+func (_e *MockWebhookService_Expecter) ListWebhookDeliveries(ctx interface{}, id interface{}, limit interface{}) *MockWebhookService_ListWebhookDeliveries_Call {
+	return &MockWebhookService_ListWebhookDeliveries_Call{Call: _e.mock.On("ListWebhookDeliveries", ctx, id, limit)}
+}
+
+func (_c *MockWebhookService_ListWebhookDeliveries_Call) Run(run func(ctx context.Context, id int64, limit int)) *MockWebhookService_ListWebhookDeliveries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int64
+		if args[1] != nil {
+			arg1 = args[1].(int64)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockWebhookService_ListWebhookDeliveries_Call) Return(webhookDeliverys []entities.WebhookDelivery, err error) *MockWebhookService_ListWebhookDeliveries_Call {
+	_c.Call.Return(webhookDeliverys, err)
+	return _c
+}
+
+func (_c *MockWebhookService_ListWebhookDeliveries_Call) RunAndReturn(run func(ctx context.Context, id int64, limit int) ([]entities.WebhookDelivery, error)) *MockWebhookService_ListWebhookDeliveries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListWebhooks provides a mock function for the type MockWebhookService
+func (_mock *MockWebhookService) ListWebhooks(ctx context.Context) ([]entities.Webhook, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListWebhooks")
+	}
+
+	var r0 []entities.Webhook
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]entities.Webhook, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []entities.Webhook); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entities.Webhook)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockWebhookService_ListWebhooks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListWebhooks'
+type MockWebhookService_ListWebhooks_Call struct {
+	*mock.Call
+}
+
+// ListWebhooks is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockWebhookService_Expecter) ListWebhooks(ctx interface{}) *MockWebhookService_ListWebhooks_Call {
+	return &MockWebhookService_ListWebhooks_Call{Call: _e.mock.On("ListWebhooks", ctx)}
+}
+
+func (_c *MockWebhookService_ListWebhooks_Call) Run(run func(ctx context.Context)) *MockWebhookService_ListWebhooks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockWebhookService_ListWebhooks_Call) Return(webhooks []entities.Webhook, err error) *MockWebhookService_ListWebhooks_Call {
+	_c.Call.Return(webhooks, err)
+	return _c
+}
+
+func (_c *MockWebhookService_ListWebhooks_Call) RunAndReturn(run func(ctx context.Context) ([]entities.Webhook, error)) *MockWebhookService_ListWebhooks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateWebhook provides a mock function for the type MockWebhookService
+func (_mock *MockWebhookService) UpdateWebhook(ctx context.Context, id int64, upd entities.WebhookUpdate) (entities.Webhook, error) {
+	ret := _mock.Called(ctx, id, upd)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateWebhook")
+	}
+
+	var r0 entities.Webhook
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, entities.WebhookUpdate) (entities.Webhook, error)); ok {
+		return returnFunc(ctx, id, upd)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, entities.WebhookUpdate) entities.Webhook); ok {
+		r0 = returnFunc(ctx, id, upd)
+	} else {
+		r0 = ret.Get(0).(entities.Webhook)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, entities.WebhookUpdate) error); ok {
+		r1 = returnFunc(ctx, id, upd)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockWebhookService_UpdateWebhook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateWebhook'
+type MockWebhookService_UpdateWebhook_Call struct {
+	*mock.Call
+}
+
+// UpdateWebhook is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+//   - upd entities.WebhookUpdate
+func (_e *MockWebhookService_Expecter) UpdateWebhook(ctx interface{}, id interface{}, upd interface{}) *MockWebhookService_UpdateWebhook_Call {
+	return &MockWebhookService_UpdateWebhook_Call{Call: _e.mock.On("UpdateWebhook", ctx, id, upd)}
+}
+
+func (_c *MockWebhookService_UpdateWebhook_Call) Run(run func(ctx context.Context, id int64, upd entities.WebhookUpdate)) *MockWebhookService_UpdateWebhook_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int64
+		if args[1] != nil {
+			arg1 = args[1].(int64)
+		}
+		var arg2 entities.WebhookUpdate
+		if args[2] != nil {
+			arg2 = args[2].(entities.WebhookUpdate)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockWebhookService_UpdateWebhook_Call) Return(webhook entities.Webhook, err error) *MockWebhookService_UpdateWebhook_Call {
+	_c.Call.Return(webhook, err)
+	return _c
+}
+
+func (_c *MockWebhookService_UpdateWebhook_Call) RunAndReturn(run func(ctx context.Context, id int64, upd entities.WebhookUpdate) (entities.Webhook, error)) *MockWebhookService_UpdateWebhook_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/utils"
 )
 
 // Order представляет заказ
@@ -49,6 +51,22 @@ type Payment struct {
 	CustomFee    int    `json:"custom_fee,omitempty"`
 }
 
+// PaymentV2 информация об оплате в API v2, заменяет Payment в документации v2.
+// Отличается от Payment только временем оплаты.
+type PaymentV2 struct {
+	Transaction string `json:"transaction,omitempty"`
+	RequestID   string `json:"request_id,omitempty"`
+	Currency    string `json:"currency,omitempty"`
+	Provider    string `json:"provider,omitempty"`
+	Amount      int    `json:"amount,omitempty"`
+	// PaymentDT время оплаты в RFC 3339
+	PaymentDT    time.Time `json:"payment_dt,omitempty"`
+	Bank         string    `json:"bank,omitempty"`
+	DeliveryCost int       `json:"delivery_cost,omitempty"`
+	GoodsTotal   int       `json:"goods_total,omitempty"`
+	CustomFee    int       `json:"custom_fee,omitempty"`
+}
+
 // Item товар в заказе
 type Item struct {
 	ChrtID      int    `json:"chrt_id,omitempty"      validate:"required"`
@@ -64,6 +82,186 @@ type Item struct {
 	Status      int    `json:"status,omitempty"`
 }
 
+// OrdersResponse список заказов
+type OrdersResponse struct {
+	Orders []Order `json:"orders"`
+}
+
+// OrderListResponse страница заказов
+type OrderListResponse struct {
+	Orders []Order `json:"orders"`
+	// NextCursor передается в параметре cursor для получения следующей страницы
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// CustomerSummary сводка по заказам покупателя
+type CustomerSummary struct {
+	CustomerID string `json:"customer_id"`
+	OrderCount int    `json:"order_count"`
+	// сумма платежей по каждой валюте
+	TotalSpent   map[string]int64 `json:"total_spent"`
+	FirstOrderAt time.Time        `json:"first_order_at"`
+	LastOrderAt  time.Time        `json:"last_order_at"`
+}
+
+// CustomerOrdersResponse история заказов покупателя
+type CustomerOrdersResponse struct {
+	Summary    CustomerSummary `json:"summary"`
+	Orders     []Order         `json:"orders"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+// ValidateOrderResponse результат проверки заказа
+type ValidateOrderResponse struct {
+	Valid  bool               `json:"valid"`
+	Errors []utils.FieldError `json:"errors,omitempty"`
+}
+
+// CreateOrderResponse ответ на создание заказа
+type CreateOrderResponse struct {
+	OrderUID string `json:"order_uid"`
+}
+
+// Статусы сохранения заказа в пакетном запросе
+const (
+	CreateStatusCreated = "created"
+	CreateStatusInvalid = "invalid"
+	CreateStatusFailed  = "failed"
+)
+
+// CreateOrderResult результат сохранения одного заказа из пакета
+type CreateOrderResult struct {
+	Index    int               `json:"index"`
+	OrderUID string            `json:"order_uid,omitempty"`
+	Status   string            `json:"status"`
+	Fields   map[string]string `json:"fields,omitempty"`
+}
+
+// BatchCreateOrdersResponse ответ на пакетное создание заказов
+type BatchCreateOrdersResponse struct {
+	Results []CreateOrderResult `json:"results"`
+}
+
+// BatchGetOrdersRequest запрос заказов по списку UID
+type BatchGetOrdersRequest struct {
+	OrderUIDs []string `json:"order_uids" validate:"required,min=1,max=100,dive,required"`
+}
+
+// BatchGetOrdersResponse найденные заказы и UID, которых нет в базе
+type BatchGetOrdersResponse struct {
+	Orders  []Order  `json:"orders"`
+	Missing []string `json:"missing"`
+}
+
+// OrderStreamEvent сообщение потока заказов по WebSocket
+type OrderStreamEvent struct {
+	ID    string `json:"id"`
+	Order Order  `json:"order"`
+}
+
+// GraphQLRequest тело GraphQL запроса
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// GraphQLResponse описывает ответ GraphQL для документации, сам ответ кодируется из graphql.Result
+type GraphQLResponse struct {
+	Data   any            `json:"data"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+type GraphQLError struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+// CreateWebhookRequest регистрация вебхука, пустой events - подписка на все события
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"              validate:"required,http_url"`
+	Events []string `json:"events,omitempty" validate:"omitempty,unique,dive,oneof=order.saved order.updated"`
+}
+
+// UpdateWebhookRequest изменение вебхука, отсутствующие поля не меняются.
+// Включение вебхука сбрасывает счетчик неудачных доставок.
+type UpdateWebhookRequest struct {
+	URL     *string   `json:"url,omitempty"     validate:"omitempty,http_url"`
+	Events  *[]string `json:"events,omitempty"  validate:"omitempty,unique,dive,oneof=order.saved order.updated"`
+	Enabled *bool     `json:"enabled,omitempty"`
+}
+
+// Webhook зарегистрированный вебхук, секрет отдается только при регистрации
+type Webhook struct {
+	ID           int64      `json:"id"`
+	URL          string     `json:"url"`
+	Events       []string   `json:"events"`
+	Enabled      bool       `json:"enabled"`
+	FailureCount int        `json:"failure_count"`
+	CreatedAt    time.Time  `json:"created_at"`
+	DisabledAt   *time.Time `json:"disabled_at,omitempty"`
+}
+
+// CreateWebhookResponse зарегистрированный вебхук вместе с секретом подписи
+type CreateWebhookResponse struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+type WebhooksResponse struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+// WebhookDelivery попытка доставки события
+type WebhookDelivery struct {
+	ID         int64     `json:"id"`
+	EventID    string    `json:"event_id"`
+	Event      string    `json:"event"`
+	OrderUID   string    `json:"order_uid"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookDeliveriesResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+// CreateAPIKeyRequest создание API ключа
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name"   validate:"required,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1,unique,dive,oneof=orders:read orders:write pii:read admin"`
+}
+
+// APIKey ключ доступа к API, сам ключ отдается только при создании
+type APIKey struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreateAPIKeyResponse созданный ключ, key нужно сохранить, повторно его получить нельзя
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type APIKeysResponse struct {
+	Keys []APIKey `json:"keys"`
+}
+
+// WebhookEventPayload тело запроса, которое получает вебхук
+type WebhookEventPayload struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      Order     `json:"data"`
+}
+
 func DeliveryEntityToJSON(d entities.Delivery) Delivery {
 	return Delivery{
 		Name:    d.Name,
@@ -174,6 +372,31 @@ func OrderEntityToJSON(o entities.Order) Order {
 	}
 }
 
+func OrdersEntityToJSON(orders []entities.Order) OrdersResponse {
+	res := OrdersResponse{Orders: make([]Order, 0, len(orders))}
+	for _, o := range orders {
+		res.Orders = append(res.Orders, OrderEntityToJSON(o))
+	}
+	return res
+}
+
+func OrderPageToJSON(p entities.OrderPage) OrderListResponse {
+	return OrderListResponse{
+		Orders:     OrdersEntityToJSON(p.Orders).Orders,
+		NextCursor: encodeCursor(p.Next),
+	}
+}
+
+func CustomerSummaryEntityToJSON(s entities.CustomerSummary) CustomerSummary {
+	return CustomerSummary{
+		CustomerID:   s.CustomerID,
+		OrderCount:   s.OrderCount,
+		TotalSpent:   s.TotalSpent,
+		FirstOrderAt: s.FirstOrderAt,
+		LastOrderAt:  s.LastOrderAt,
+	}
+}
+
 func OrderJSONToEntity(o Order) entities.Order {
 	items := make([]entities.Item, 0, len(o.Items))
 	for _, it := range o.Items {
@@ -197,3 +420,110 @@ func OrderJSONToEntity(o Order) entities.Order {
 		Items:           items,
 	}
 }
+
+func WebhookEntityToJSON(w entities.Webhook) Webhook {
+	res := Webhook{
+		ID:           w.ID,
+		URL:          w.URL,
+		Events:       w.Events,
+		Enabled:      w.Enabled,
+		FailureCount: w.FailureCount,
+		CreatedAt:    w.CreatedAt,
+	}
+	if res.Events == nil {
+		res.Events = []string{}
+	}
+	if !w.DisabledAt.IsZero() {
+		res.DisabledAt = &w.DisabledAt
+	}
+	return res
+}
+
+func WebhookDeliveryEntityToJSON(d entities.WebhookDelivery) WebhookDelivery {
+	return WebhookDelivery{
+		ID:         d.ID,
+		EventID:    d.EventID,
+		Event:      d.Event,
+		OrderUID:   d.OrderUID,
+		Attempt:    d.Attempt,
+		StatusCode: d.StatusCode,
+		Error:      d.Error,
+		DurationMs: d.Duration.Milliseconds(),
+		CreatedAt:  d.CreatedAt,
+	}
+}
+
+// EncodeWebhookEvent кодирует событие для отправки вебхукам
+func EncodeWebhookEvent(e entities.WebhookEvent) ([]byte, error) {
+	return json.Marshal(WebhookEventPayload{
+		ID:        e.ID,
+		Type:      e.Type,
+		CreatedAt: e.CreatedAt,
+		Data:      OrderEntityToJSON(e.Order),
+	})
+}
+
+func APIKeyEntityToJSON(k entities.APIKey) APIKey {
+	res := APIKey{
+		ID:        k.ID,
+		Name:      k.Name,
+		Scopes:    k.Scopes,
+		CreatedAt: k.CreatedAt,
+	}
+	if !k.RevokedAt.IsZero() {
+		res.RevokedAt = &k.RevokedAt
+	}
+	return res
+}
+
+// HealthResponse состояние сервиса и его зависимостей
+type HealthResponse struct {
+	// Status ok, unavailable или shutting_down
+	Status string `json:"status"`
+	// Checks ошибки зависимостей, для доступных зависимостей ok
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+func ReadinessToJSON(r entities.Readiness) HealthResponse {
+	if r.ShuttingDown {
+		return HealthResponse{Status: "shutting_down"}
+	}
+
+	res := HealthResponse{Status: "ok", Checks: make(map[string]string, len(r.Checks))}
+	if !r.Ready {
+		res.Status = "unavailable"
+	}
+	for _, c := range r.Checks {
+		res.Checks[c.Name] = "ok"
+		if c.Err != nil {
+			res.Checks[c.Name] = c.Err.Error()
+		}
+	}
+	return res
+}
+
+type CachesResponse struct {
+	Caches []CacheStats `json:"caches"`
+}
+
+type CacheStats struct {
+	Name     string `json:"name"`
+	Size     int    `json:"size"`
+	Capacity int    `json:"capacity"`
+}
+
+type ConsumerStatus struct {
+	Running bool `json:"running"`
+	Paused  bool `json:"paused"`
+	// ProcessingFor сколько обрабатывается текущее сообщение, пусто пока ждем новое
+	ProcessingFor string `json:"processing_for,omitempty"`
+	Lag           int64  `json:"lag"`
+}
+
+func ConsumerStatusToJSON(s entities.ConsumerStatus) ConsumerStatus {
+	res := ConsumerStatus{Running: s.Running, Paused: s.Paused, Lag: s.Lag}
+	if s.ProcessingFor > 0 {
+		res.ProcessingFor = s.ProcessingFor.Round(time.Millisecond).String()
+	}
+	return res
+}
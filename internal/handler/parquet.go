@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetSchema схема выгрузки в Parquet: строка на заказ, товары списком внутри строки.
+// Схема строится по модели ответа версии API и содержит только выбранные поля, поэтому совпадает по составу
+// с NDJSON той же выгрузки. Все поля необязательные: пустые поля в ответе опускаются.
+func parquetSchema(proj projection, version middleware.APIVersion) *parquet.Schema {
+	group, _ := parquetGroup(reflect.TypeFor[Order](), nil, proj, version)
+	return parquet.NewSchema("order", group)
+}
+
+func parquetGroup(
+	t reflect.Type, prefix []string, proj projection, version middleware.APIVersion,
+) (parquet.Group, bool) {
+	group := make(parquet.Group)
+	for name, ft := range parquetFields(t, version) {
+		path := append(slices.Clone(prefix), name)
+		if node, ok := parquetNode(ft, path, proj, version); ok {
+			group[name] = node
+		}
+	}
+	return group, len(group) > 0
+}
+
+func parquetNode(t reflect.Type, path []string, proj projection, version middleware.APIVersion) (parquet.Node, bool) {
+	switch {
+	case t == reflect.TypeFor[time.Time]():
+		if !proj.selects(path) {
+			return nil, false
+		}
+		return parquet.Optional(parquet.Timestamp(parquet.Millisecond)), true
+	case t.Kind() == reflect.Struct:
+		group, ok := parquetGroup(t, path, proj, version)
+		return parquet.Optional(group), ok
+	case t.Kind() == reflect.Slice:
+		elem, ok := parquetGroup(t.Elem(), path, proj, version)
+		return parquet.Optional(parquet.List(elem)), ok
+	case !proj.selects(path):
+		return nil, false
+	case t.Kind() == reflect.String:
+		return parquet.Optional(parquet.String()), true
+	default:
+		return parquet.Optional(parquet.Int(64)), true
+	}
+}
+
+// parquetFields поля модели по именам из JSON. В v2 оплата описывается PaymentV2, как и в документации.
+func parquetFields(t reflect.Type, version middleware.APIVersion) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		ft := field.Type
+		if ft == reflect.TypeFor[Payment]() && version >= middleware.APIv2 {
+			ft = reflect.TypeFor[PaymentV2]()
+		}
+		fields[name] = ft
+	}
+	return fields
+}
+
+// parquetRow переводит заказ из дерева ответа в строку Parquet: числа и время приводятся к типам схемы,
+// отсутствующие в заказе поля остаются пустыми
+func parquetRow(order any, version middleware.APIVersion) any {
+	return parquetValue(order, reflect.TypeFor[Order](), version)
+}
+
+func parquetValue(v any, t reflect.Type, version middleware.APIVersion) any {
+	switch {
+	case t == reflect.TypeFor[time.Time]():
+		s, _ := v.(string)
+		ts, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil
+		}
+		return ts
+	case t.Kind() == reflect.Struct:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		row := make(map[string]any, len(obj))
+		for name, ft := range parquetFields(t, version) {
+			if value := parquetValue(obj[name], ft, version); value != nil {
+				row[name] = value
+			}
+		}
+		return row
+	case t.Kind() == reflect.Slice:
+		arr, ok := v.([]any)
+		if !ok {
+			return nil
+		}
+		list := make([]any, len(arr))
+		for i, elem := range arr {
+			list[i] = parquetValue(elem, t.Elem(), version)
+		}
+		return list
+	case t.Kind() == reflect.String:
+		s, ok := v.(string)
+		if !ok {
+			return nil
+		}
+		return s
+	default:
+		n, ok := v.(json.Number)
+		if !ok {
+			return nil
+		}
+		i, err := n.Int64()
+		if err != nil {
+			return nil
+		}
+		return i
+	}
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	chimw "github.com/go-chi/chi/v5/middleware"
+)
+
+// PIIPolicy решает, кто видит персональные данные получателя без маскирования
+type PIIPolicy interface {
+	CanReveal(p entities.Principal) bool
+	LogAccess(ctx context.Context, a entities.PIIAccess) error
+	MaskDelivery(d entities.Delivery) entities.Delivery
+}
+
+// canRevealPII клиент без права pii:read и запросы без клиента видят только маскированные данные
+func canRevealPII(ctx context.Context, pii PIIPolicy) (entities.Principal, bool) {
+	principal, ok := middleware.PrincipalFrom(ctx)
+	return principal, ok && pii.CanReveal(principal)
+}
+
+// logPIIAccess записывает раскрытие данных заказов в журнал аудита
+func logPIIAccess(ctx context.Context, pii PIIPolicy, p entities.Principal, resource string, orderUIDs []string) error {
+	err := pii.LogAccess(ctx, entities.PIIAccess{
+		Subject:   p.Subject,
+		Resource:  resource,
+		OrderUIDs: orderUIDs,
+		RequestID: chimw.GetReqID(ctx),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to log pii access: %w", err)
+	}
+	return nil
+}
+
+// piiReveal раскрытие персональных данных в ответе. Записывается в журнал перед отправкой тела,
+// поэтому ответ без тела, например 304, раскрытием не считается.
+type piiReveal struct {
+	principal entities.Principal
+	// orderUIDs заказы, доставка которых попадает в ответ без маскирования, пустой если раскрытия нет
+	orderUIDs []string
+}
+
+// log записывает раскрытие в журнал аудита, если оно есть
+func (rv piiReveal) log(ctx context.Context, pii PIIPolicy, resource string) error {
+	if len(rv.orderUIDs) == 0 {
+		return nil
+	}
+	return logPIIAccess(ctx, pii, rv.principal, resource, rv.orderUIDs)
+}
+
+// revealOrders маскирует персональные данные заказов, если клиенту нельзя их видеть. Раскрытием считается
+// только выдача доставки: если proj ее не выбирает, записывать в журнал нечего.
+func revealOrders(
+	ctx context.Context, pii PIIPolicy, proj projection, orders []entities.Order,
+) ([]entities.Order, piiReveal) {
+	if len(orders) == 0 {
+		return orders, piiReveal{}
+	}
+
+	principal, reveal := canRevealPII(ctx, pii)
+	if reveal {
+		rv := piiReveal{principal: principal}
+		if proj.selects([]string{"delivery"}) {
+			rv.orderUIDs = make([]string, len(orders))
+			for i, o := range orders {
+				rv.orderUIDs[i] = o.OrderUID
+			}
+		}
+		return orders, rv
+	}
+
+	// заказы могут лежать в кэше, поэтому маскируется копия
+	masked := make([]entities.Order, len(orders))
+	for i, o := range orders {
+		o.Delivery = pii.MaskDelivery(o.Delivery)
+		masked[i] = o
+	}
+	return masked, piiReveal{}
+}
+
+// protectOrders маскирует персональные данные заказов или, если клиенту можно их видеть,
+// записывает раскрытие в журнал. Если записать не удалось, данные не выдаются.
+func protectOrders(
+	ctx context.Context, pii PIIPolicy, resource string, proj projection, orders []entities.Order,
+) ([]entities.Order, error) {
+	orders, rv := revealOrders(ctx, pii, proj, orders)
+	if err := rv.log(ctx, pii, resource); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// revealPIIOnce решает, видит ли клиент персональные данные в потоке или выгрузке. Заказы заранее
+// неизвестны, поэтому раскрытие записывается в журнал один раз, в начале, без UID заказов.
+func revealPIIOnce(ctx context.Context, pii PIIPolicy, resource string) (bool, error) {
+	principal, reveal := canRevealPII(ctx, pii)
+	if !reveal {
+		return false, nil
+	}
+	if err := logPIIAccess(ctx, pii, principal, resource, nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// maskOrders маскирует персональные данные заказов на месте, поэтому заказы не должны лежать в кэше
+func maskOrders(pii PIIPolicy, orders []entities.Order) {
+	for i := range orders {
+		orders[i].Delivery = pii.MaskDelivery(orders[i].Delivery)
+	}
+}
+
+// protectOrder то же, что protectOrders, для одного заказа со всеми полями
+func protectOrder(ctx context.Context, pii PIIPolicy, resource string, order entities.Order) (entities.Order, error) {
+	orders, err := protectOrders(ctx, pii, resource, projection{}, []entities.Order{order})
+	if err != nil {
+		return entities.Order{}, err
+	}
+	return orders[0], nil
+}
@@ -0,0 +1,184 @@
+package handler_test
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/handler"
+	mocks "github.com/SergeyBogomolovv/l0-order-service/internal/handler/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPHandler_PIIMasking(t *testing.T) {
+	order := entities.Order{OrderUID: "1", Delivery: entities.Delivery{Phone: "+79991234567"}}
+	masked := entities.Delivery{Phone: "+7******4567"}
+
+	testCases := []struct {
+		name         string
+		scopes       []string
+		query        string
+		ifNoneMatch  string
+		excludeItems bool
+		mockBehavior func(pii *mocks.MockPIIPolicy)
+		wantStatus   int
+		wantBody     string
+	}{
+		{
+			name:   "support agent sees masked data",
+			scopes: []string{entities.ScopeOrdersRead},
+			mockBehavior: func(pii *mocks.MockPIIPolicy) {
+				pii.EXPECT().CanReveal(mock.Anything).Return(false).Once()
+				pii.EXPECT().MaskDelivery(order.Delivery).Return(masked).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"phone":"+7******4567"`,
+		},
+		{
+			name:   "unmasked access is audited",
+			scopes: []string{entities.ScopeOrdersRead, entities.ScopePIIRead},
+			mockBehavior: func(pii *mocks.MockPIIPolicy) {
+				pii.EXPECT().CanReveal(mock.Anything).Return(true).Once()
+				pii.EXPECT().LogAccess(mock.Anything, entities.PIIAccess{
+					Subject:   "test",
+					Resource:  "/order/1",
+					OrderUIDs: []string{"1"},
+				}).Return(nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"phone":"+79991234567"`,
+		},
+		{
+			name:         "no audit without delivery in response",
+			scopes:       []string{entities.ScopeOrdersRead, entities.ScopePIIRead},
+			query:        "?fields=order_uid",
+			excludeItems: true,
+			mockBehavior: func(pii *mocks.MockPIIPolicy) {
+				pii.EXPECT().CanReveal(mock.Anything).Return(true).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"order_uid":"1"}`,
+		},
+		{
+			name:        "no audit without response body",
+			scopes:      []string{entities.ScopeOrdersRead, entities.ScopePIIRead},
+			ifNoneMatch: "*",
+			mockBehavior: func(pii *mocks.MockPIIPolicy) {
+				pii.EXPECT().CanReveal(mock.Anything).Return(true).Once()
+			},
+			wantStatus: http.StatusNotModified,
+		},
+		{
+			name:   "no data without audit record",
+			scopes: []string{entities.ScopeOrdersRead, entities.ScopePIIRead},
+			mockBehavior: func(pii *mocks.MockPIIPolicy) {
+				pii.EXPECT().CanReveal(mock.Anything).Return(true).Once()
+				pii.EXPECT().LogAccess(mock.Anything, mock.Anything).Return(errors.New("db error")).Once()
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `"code":"internal_error"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := mocks.NewMockOrderService(t)
+			svc.EXPECT().GetOrderByID(mock.Anything, "1", tc.excludeItems).Return(order, nil).Once()
+			pii := mocks.NewMockPIIPolicy(t)
+			tc.mockBehavior(pii)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			h := handler.NewHTTPHandler(
+				logger, config.HTTP{}, svc, mocks.NewMockIdempotencyStore(t), mocks.NewMockOrderWaiter(t), pii,
+			)
+			r := newTestRouterWithScopes(tc.scopes...)
+			h.Init(r)
+
+			req := httptest.NewRequest(http.MethodGet, "/order/1"+tc.query, nil)
+			if tc.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tc.ifNoneMatch)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.wantBody)
+		})
+	}
+}
+
+func TestGraphQLHandler_PIIMasking(t *testing.T) {
+	order := entities.Order{OrderUID: "1", Delivery: entities.Delivery{Phone: "+79991234567"}}
+
+	testCases := []struct {
+		name         string
+		scopes       []string
+		query        string
+		mockBehavior func(pii *mocks.MockPIIPolicy)
+		wantBody     string
+	}{
+		{
+			name:   "masked",
+			scopes: []string{entities.ScopeOrdersRead},
+			query:  `{ order(uid: "1") { delivery { phone } } }`,
+			mockBehavior: func(pii *mocks.MockPIIPolicy) {
+				pii.EXPECT().CanReveal(mock.Anything).Return(false).Once()
+				pii.EXPECT().MaskDelivery(order.Delivery).Return(entities.Delivery{Phone: "+7******4567"}).Once()
+			},
+			wantBody: `{"data":{"order":{"delivery":{"phone":"+7******4567"}}}}`,
+		},
+		{
+			name:   "revealed delivery is audited",
+			scopes: []string{entities.ScopeAdmin},
+			query:  `{ order(uid: "1") { delivery { phone } } }`,
+			mockBehavior: func(pii *mocks.MockPIIPolicy) {
+				pii.EXPECT().CanReveal(mock.Anything).Return(true).Once()
+				pii.EXPECT().LogAccess(mock.Anything, entities.PIIAccess{
+					Subject:   "test",
+					Resource:  "/graphql",
+					OrderUIDs: []string{"1"},
+				}).Return(nil).Once()
+			},
+			wantBody: `{"data":{"order":{"delivery":{"phone":"+79991234567"}}}}`,
+		},
+		{
+			name:   "no audit without delivery",
+			scopes: []string{entities.ScopeAdmin},
+			query:  `{ order(uid: "1") { orderUid } }`,
+			mockBehavior: func(pii *mocks.MockPIIPolicy) {
+				pii.EXPECT().CanReveal(mock.Anything).Return(true).Once()
+			},
+			wantBody: `{"data":{"order":{"orderUid":"1"}}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := mocks.NewMockOrderService(t)
+			svc.EXPECT().GetOrdersByIDs(mock.Anything, []string{"1"}).Return([]entities.Order{order}, nil, nil).Once()
+			pii := mocks.NewMockPIIPolicy(t)
+			tc.mockBehavior(pii)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			h, err := handler.NewGraphQLHandler(logger, config.GraphQL{MaxComplexity: 1000}, svc, pii)
+			require.NoError(t, err)
+			r := newTestRouterWithScopes(tc.scopes...)
+			h.Init(r)
+
+			q := url.Values{"query": {tc.query}}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphql?"+q.Encode(), nil))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.JSONEq(t, tc.wantBody, w.Body.String())
+		})
+	}
+}
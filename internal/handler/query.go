@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// parseOrderFilter разбирает параметры поиска заказов.
+// Ошибки возвращаются в том же виде, что и ошибки валидации: параметр -> нарушенное правило.
+func parseOrderFilter(r *http.Request) (entities.OrderFilter, map[string]string) {
+	q := r.URL.Query()
+	fields := make(map[string]string)
+
+	filter := entities.OrderFilter{
+		CustomerID:      q.Get("customer_id"),
+		DeliveryService: q.Get("delivery_service"),
+		Locale:          q.Get("locale"),
+		Currency:        q.Get("currency"),
+		Provider:        q.Get("provider"),
+		Brand:           q.Get("brand"),
+		Phone:           q.Get("phone"),
+		Email:           q.Get("email"),
+	}
+
+	for param, dst := range map[string]*time.Time{
+		"created_from": &filter.CreatedFrom,
+		"created_to":   &filter.CreatedTo,
+	} {
+		if v := q.Get(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				fields[param] = "datetime"
+				continue
+			}
+			*dst = t
+		}
+	}
+
+	switch q.Get("sort") {
+	case "", "-date_created":
+	case "date_created":
+		filter.SortAsc = true
+	default:
+		fields["sort"] = "oneof"
+	}
+
+	filter.Limit, filter.After = parsePage(r, fields)
+	return filter, fields
+}
+
+// parsePage разбирает размер страницы и курсор, ошибки добавляет в fields
+func parsePage(r *http.Request, fields map[string]string) (int, *entities.OrderCursor) {
+	q := r.URL.Query()
+
+	limit := defaultPageSize
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageSize {
+			fields["limit"] = "range"
+		} else {
+			limit = n
+		}
+	}
+
+	var after *entities.OrderCursor
+	if v := q.Get("cursor"); v != "" {
+		var err error
+		after, err = decodeCursor(v)
+		if err != nil {
+			fields["cursor"] = "cursor"
+		}
+	}
+
+	return limit, after
+}
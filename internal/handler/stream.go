@@ -0,0 +1,297 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/problem"
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/go-chi/chi/v5"
+)
+
+// sseRetry через сколько EventSource переподключится после разрыва
+const sseRetry = 3 * time.Second
+
+type StreamHandler struct {
+	logger       *slog.Logger
+	feed         OrderFeed
+	pii          PIIPolicy
+	heartbeat    time.Duration
+	writeTimeout time.Duration
+	// originPatterns хосты, с которых разрешено открывать WebSocket, берутся из настроек CORS
+	originPatterns []string
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func NewStreamHandler(
+	logger *slog.Logger, cfg config.Stream, cors config.CORS, feed OrderFeed, pii PIIPolicy,
+) *StreamHandler {
+	patterns := make([]string, 0, len(cors.AllowedOrigins))
+	for _, origin := range cors.AllowedOrigins {
+		if u, err := url.Parse(origin); err == nil && u.Host != "" {
+			patterns = append(patterns, u.Host)
+		}
+	}
+
+	return &StreamHandler{
+		logger:         logger.With(slog.String("handler", "stream")),
+		feed:           feed,
+		pii:            pii,
+		heartbeat:      cfg.Heartbeat,
+		writeTimeout:   cfg.WriteTimeout,
+		originPatterns: patterns,
+		done:           make(chan struct{}),
+	}
+}
+
+func (h *StreamHandler) Init(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(entities.ScopeOrdersRead))
+		r.Get("/orders/stream", h.StreamOrders)
+		r.Get("/orders/stream/ws", h.StreamOrdersWS)
+	})
+}
+
+// Close завершает открытые потоки. http.Server.Shutdown не дожидается их сам:
+// SSE соединение никогда не простаивает, а WebSocket уже забран у сервера.
+func (h *StreamHandler) Close() {
+	h.closeOnce.Do(func() { close(h.done) })
+}
+
+// StreamOrders отправляет новые заказы через Server-Sent Events.
+// @Summary      Поток новых заказов (SSE)
+// @Description  Отправляет сохраненные заказы событиями order. id события одинаков на всех репликах, его можно
+// @Description  передать в Last-Event-ID, чтобы после переподключения к любой реплике получить пропущенные
+// @Description  события из ограниченной истории.
+// @Description  Клиент, который не успевает читать, отключается и должен переподключиться.
+// @Tags         stream
+// @Produce      text/event-stream
+// @Param        customer_id       query   string  false  "Только заказы покупателя"
+// @Param        delivery_service  query   string  false  "Только заказы службы доставки"
+// @Param        Last-Event-ID     header  string  false  "id последнего полученного события"
+// @Success      200  {object}  Order "Поток событий, data содержит заказ"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /orders/stream [get]
+func (h *StreamHandler) StreamOrders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	version := middleware.APIVersionFrom(ctx)
+
+	req := parseStreamRequest(r)
+
+	reveal, ok := h.revealPII(w, r)
+	if !ok {
+		return
+	}
+
+	events, unsubscribe := h.feed.Subscribe(req.lastEventID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// nginx не должен буферизовать поток
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	if err := h.sendSSE(w, rc, fmt.Sprintf("retry: %d\n\n", sseRetry.Milliseconds())); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.done:
+			return
+		case <-ticker.C:
+			if err := h.sendSSE(w, rc, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				// клиент отстал, EventSource переподключится с Last-Event-ID и дочитает историю
+				return
+			}
+			if !req.matches(event.Order) {
+				continue
+			}
+			data, err := h.streamOrder(event.Order, reveal, version)
+			if err != nil {
+				h.logger.ErrorContext(ctx, "failed to marshal order", slog.Any("error", err))
+				return
+			}
+			if err := h.sendSSE(w, rc, fmt.Sprintf("id: %s\nevent: order\ndata: %s\n\n", event.ID, data)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *StreamHandler) sendSSE(w http.ResponseWriter, rc *http.ResponseController, msg string) error {
+	// клиент, который перестал читать из сокета, не должен держать обработчик бесконечно
+	if err := rc.SetWriteDeadline(time.Now().Add(h.writeTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	if _, err := io.WriteString(w, msg); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
+
+// StreamOrdersWS отправляет новые заказы через WebSocket.
+// @Summary      Поток новых заказов (WebSocket)
+// @Description  То же, что и SSE поток, но сообщения приходят JSON объектами с id события и заказом.
+// @Description  Браузер не может передать заголовок, поэтому id последнего события передается в last_event_id.
+// @Tags         stream
+// @Param        customer_id       query  string  false  "Только заказы покупателя"
+// @Param        delivery_service  query  string  false  "Только заказы службы доставки"
+// @Param        last_event_id     query  string  false  "id последнего полученного события"
+// @Success      101  {object}  OrderStreamEvent "Сообщения потока"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /orders/stream/ws [get]
+func (h *StreamHandler) StreamOrdersWS(w http.ResponseWriter, r *http.Request) {
+	req := parseStreamRequest(r)
+
+	reveal, ok := h.revealPII(w, r)
+	if !ok {
+		return
+	}
+
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{OriginPatterns: h.originPatterns})
+	if err != nil {
+		// Accept уже ответил клиенту
+		h.logger.WarnContext(r.Context(), "failed to accept websocket", slog.Any("error", err))
+		return
+	}
+	defer conn.CloseNow() //nolint:errcheck // соединение уже может быть закрыто через Close
+
+	// клиент ничего не отправляет, чтение нужно только для ответов на ping и закрытия соединения
+	ctx := conn.CloseRead(r.Context())
+	version := middleware.APIVersionFrom(ctx)
+
+	events, unsubscribe := h.feed.Subscribe(req.lastEventID)
+	defer unsubscribe()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.done:
+			_ = conn.Close(websocket.StatusGoingAway, "server is shutting down")
+			return
+		case <-ticker.C:
+			if err := h.withWriteTimeout(ctx, conn.Ping); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				_ = conn.Close(websocket.StatusTryAgainLater, "client is too slow, reconnect to continue")
+				return
+			}
+			if !req.matches(event.Order) {
+				continue
+			}
+			data, err := h.streamOrder(event.Order, reveal, version)
+			if err != nil {
+				h.logger.ErrorContext(ctx, "failed to marshal order", slog.Any("error", err))
+				return
+			}
+			msg := streamEvent{ID: event.ID, Order: data}
+			err = h.withWriteTimeout(ctx, func(ctx context.Context) error {
+				return wsjson.Write(ctx, conn, msg)
+			})
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// revealPII решает, видит ли клиент персональные данные в потоке. Если записать раскрытие в журнал
+// не удалось, клиенту отвечает сам.
+func (h *StreamHandler) revealPII(w http.ResponseWriter, r *http.Request) (reveal, ok bool) {
+	reveal, err := revealPIIOnce(r.Context(), h.pii, r.URL.Path)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to protect orders", slog.Any("error", err))
+		problem.Write(w, r, problem.CodeInternal)
+		return false, false
+	}
+	return reveal, true
+}
+
+// streamOrder JSON заказа в форме версии API, по которой подключился клиент
+func (h *StreamHandler) streamOrder(
+	order entities.Order, reveal bool, version middleware.APIVersion,
+) (json.RawMessage, error) {
+	if !reveal {
+		order.Delivery = h.pii.MaskDelivery(order.Delivery)
+	}
+	data, err := encodeResponse(OrderEntityToJSON(order), "", projection{}, jsonEncoder, version)
+	return bytes.TrimSuffix(data, []byte("\n")), err
+}
+
+// streamEvent сообщение WebSocket потока с уже закодированным заказом, его схема описана в OrderStreamEvent
+type streamEvent struct {
+	ID    string          `json:"id"`
+	Order json.RawMessage `json:"order"`
+}
+
+func (h *StreamHandler) withWriteTimeout(ctx context.Context, write func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, h.writeTimeout)
+	defer cancel()
+	return write(ctx)
+}
+
+// streamRequest фильтры и позиция, с которой клиент продолжает чтение
+type streamRequest struct {
+	customerID      string
+	deliveryService string
+	lastEventID     string
+}
+
+func parseStreamRequest(r *http.Request) streamRequest {
+	q := r.URL.Query()
+
+	req := streamRequest{
+		customerID:      q.Get("customer_id"),
+		deliveryService: q.Get("delivery_service"),
+	}
+
+	// EventSource передает id в заголовке, а браузерный WebSocket может передать только параметр запроса
+	req.lastEventID = r.Header.Get("Last-Event-ID")
+	if req.lastEventID == "" {
+		req.lastEventID = q.Get("last_event_id")
+	}
+
+	return req
+}
+
+func (s streamRequest) matches(order entities.Order) bool {
+	if s.customerID != "" && order.CustomerID != s.customerID {
+		return false
+	}
+	return s.deliveryService == "" || order.DeliveryService == s.deliveryService
+}
@@ -0,0 +1,111 @@
+package handler_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/handler"
+	mocks "github.com/SergeyBogomolovv/l0-order-service/internal/handler/mocks"
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStreamServer(t *testing.T, feed handler.OrderFeed) *httptest.Server {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := handler.NewStreamHandler(logger, config.Stream{Heartbeat: 20 * time.Millisecond, WriteTimeout: time.Second},
+		config.CORS{AllowedOrigins: []string{"http://localhost:3000"}}, feed, testPII{})
+
+	r := newTestRouter()
+	h.Init(r)
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	t.Cleanup(h.Close)
+	return srv
+}
+
+func TestStreamHandler_StreamOrders(t *testing.T) {
+	events := make(chan entities.OrderEvent, 2)
+	feed := mocks.NewMockOrderFeed(t)
+	feed.EXPECT().Subscribe("5-0").Return(events, func() {}).Once()
+	srv := newStreamServer(t, feed)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet,
+		srv.URL+"/orders/stream?customer_id=c1", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "5-0")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events <- entities.OrderEvent{ID: "6-1", Order: entities.Order{OrderUID: "1", CustomerID: "c2"}}
+	events <- entities.OrderEvent{ID: "7-2", Order: entities.Order{OrderUID: "2", CustomerID: "c1"}}
+
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if strings.HasPrefix(scanner.Text(), "data:") {
+			break
+		}
+	}
+	assert.Equal(t, "retry: 3000", lines[0])
+	assert.Contains(t, lines, "id: 7-2")
+	assert.Contains(t, lines, "event: order")
+	assert.NotContains(t, lines, "id: 6-1")
+	assert.Contains(t, lines[len(lines)-1], `"order_uid":"2"`)
+
+	// без событий в поток идут heartbeat комментарии
+	require.True(t, scanner.Scan())
+	require.True(t, scanner.Scan())
+	assert.Equal(t, ": heartbeat", scanner.Text())
+
+	// закрытый канал означает, что клиент отстал, поток завершается
+	close(events)
+	for scanner.Scan() {
+	}
+	assert.NoError(t, scanner.Err())
+}
+
+func TestStreamHandler_StreamOrdersWS(t *testing.T) {
+	events := make(chan entities.OrderEvent, 2)
+	feed := mocks.NewMockOrderFeed(t)
+	feed.EXPECT().Subscribe("3-0").Return(events, func() {}).Once()
+	srv := newStreamServer(t, feed)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, srv.URL+"/orders/stream/ws?delivery_service=meest&last_event_id=3-0", nil)
+	require.NoError(t, err)
+	defer conn.CloseNow()
+
+	events <- entities.OrderEvent{ID: "4-1", Order: entities.Order{OrderUID: "1", DeliveryService: "dhl"}}
+	events <- entities.OrderEvent{ID: "5-2", Order: entities.Order{OrderUID: "2", DeliveryService: "meest"}}
+
+	var msg handler.OrderStreamEvent
+	require.NoError(t, wsjson.Read(ctx, conn, &msg))
+	assert.Equal(t, "5-2", msg.ID)
+	assert.Equal(t, "2", msg.Order.OrderUID)
+
+	close(events)
+	_, _, err = conn.Read(ctx)
+	assert.Equal(t, websocket.StatusTryAgainLater, websocket.CloseStatus(err))
+}
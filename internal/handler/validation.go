@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/go-playground/validator/v10"
+)
+
+// newValidator создает валидатор, который в ошибках использует имена полей из json тегов
+func newValidator() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return validate
+}
+
+// decodeOrder приводит заказ версии API к форме v1, разбирает и валидирует его.
+// Используется и kafka, и http обработчиками, чтобы вердикт о валидности заказа был одинаковым.
+// При ошибке валидации возвращает и разобранный заказ, чтобы по нему можно было описать ошибку.
+func decodeOrder(validate *validator.Validate, data []byte, version middleware.APIVersion) (Order, error) {
+	var order Order
+	decodeErr := json.Unmarshal(downgradeOrder(data, version), &order)
+
+	// после ошибки типа остальные поля все равно разобраны, поэтому их можно проверить
+	var typeErr *json.UnmarshalTypeError
+	if decodeErr != nil && !errors.As(decodeErr, &typeErr) {
+		return Order{}, fmt.Errorf("failed to unmarshal order: %w", decodeErr)
+	}
+
+	if err := validate.Struct(order); err != nil || decodeErr != nil {
+		return order, fmt.Errorf("invalid order data: %w", errors.Join(decodeErr, err))
+	}
+
+	return order, nil
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+)
+
+// upgradeOrders приводит заказы дерева ответа к форме версии API. Ответы всех версий строятся из одних
+// и тех же моделей v1, поэтому новая версия описывается здесь только отличиями от предыдущей.
+func upgradeOrders(tree any, root string, version middleware.APIVersion) {
+	if version < middleware.APIv2 {
+		return
+	}
+	for _, order := range ordersIn(tree, root) {
+		paymentDTToRFC3339(order)
+	}
+}
+
+// paymentDTToRFC3339 в v2 время оплаты отдается в RFC 3339 вместо Unix секунд
+func paymentDTToRFC3339(order any) {
+	obj, ok := order.(map[string]any)
+	if !ok {
+		return
+	}
+	payment, ok := obj["payment"].(map[string]any)
+	if !ok {
+		return
+	}
+	n, ok := payment["payment_dt"].(json.Number)
+	if !ok {
+		return
+	}
+	if sec, err := n.Int64(); err == nil {
+		payment["payment_dt"] = time.Unix(sec, 0).UTC().Format(time.RFC3339)
+	}
+}
+
+// downgradeOrder приводит тело заказа к форме v1, в которой его разбирает decodeOrder.
+// Тело, которое не удалось разобрать, возвращается как есть, ошибки найдет decodeOrder.
+func downgradeOrder(data []byte, version middleware.APIVersion) []byte {
+	if version < middleware.APIv2 {
+		return data
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var order map[string]any
+	if err := dec.Decode(&order); err != nil {
+		return data
+	}
+	payment, ok := order["payment"].(map[string]any)
+	if !ok {
+		return data
+	}
+	value, ok := payment["payment_dt"].(string)
+	if !ok {
+		return data
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return data
+	}
+	payment["payment_dt"] = t.Unix()
+
+	res, err := json.Marshal(order)
+	if err != nil {
+		return data
+	}
+	return res
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+)
+
+// parseWait разбирает параметр wait. Вторым значением возвращается нарушенное правило, как в parseOrderFilter.
+func (h *HTTPHandler) parseWait(r *http.Request) (time.Duration, string) {
+	v := r.URL.Query().Get("wait")
+	if v == "" {
+		return 0, ""
+	}
+
+	wait, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, "duration"
+	}
+	if wait < 0 || wait > h.maxWait {
+		return 0, "range"
+	}
+	return wait, ""
+}
+
+// waitForOrder возвращает заказ, а если его еще нет - ждет сохранения не дольше wait.
+// База не опрашивается: повторный запрос делается только после уведомления о сохранении.
+func (h *HTTPHandler) waitForOrder(
+	ctx context.Context, orderUID string, wait time.Duration, excludeItems bool,
+) (entities.Order, error) {
+	if wait == 0 {
+		return h.svc.GetOrderByID(ctx, orderUID, excludeItems)
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		// подписка до запроса в базу, иначе можно пропустить заказ, сохраненный между ними
+		saved, cancel := h.waiter.Wait(orderUID)
+
+		order, err := h.svc.GetOrderByID(ctx, orderUID, excludeItems)
+		if !errors.Is(err, entities.ErrOrderNotFound) {
+			cancel()
+			return order, err
+		}
+
+		select {
+		case <-saved:
+			// уведомление могло быть общим, например после переподключения к Postgres, поэтому проверяем заново
+		case <-timer.C:
+			cancel()
+			return entities.Order{}, err
+		case <-ctx.Done():
+			cancel()
+			return entities.Order{}, ctx.Err()
+		}
+		cancel()
+	}
+}
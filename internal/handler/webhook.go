@@ -0,0 +1,289 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/problem"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, url string, events []string) (entities.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]entities.Webhook, error)
+	GetWebhook(ctx context.Context, id int64) (entities.Webhook, error)
+	UpdateWebhook(ctx context.Context, id int64, upd entities.WebhookUpdate) (entities.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+	ListWebhookDeliveries(ctx context.Context, id int64, limit int) ([]entities.WebhookDelivery, error)
+}
+
+type WebhookHandler struct {
+	logger   *slog.Logger
+	validate *validator.Validate
+	svc      WebhookService
+}
+
+func NewWebhookHandler(logger *slog.Logger, svc WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		logger:   logger.With(slog.String("handler", "webhook")),
+		validate: newValidator(),
+		svc:      svc,
+	}
+}
+
+func (h *WebhookHandler) Init(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(entities.ScopeAdmin))
+		r.Post("/admin/webhooks", h.CreateWebhook)
+		r.Get("/admin/webhooks", h.ListWebhooks)
+		r.Get("/admin/webhooks/{id}", h.GetWebhook)
+		r.Patch("/admin/webhooks/{id}", h.UpdateWebhook)
+		r.Delete("/admin/webhooks/{id}", h.DeleteWebhook)
+		r.Get("/admin/webhooks/{id}/deliveries", h.ListWebhookDeliveries)
+	})
+}
+
+// CreateWebhook регистрирует вебхук.
+// @Summary      Зарегистрировать вебхук
+// @Description  На адрес отправляются POST запросы с событиями order.saved (заказ сохранен впервые)
+// @Description  и order.updated (получен заказ с тем же UID, но другим содержимым, и сохраненный заменен им;
+// @Description  заказ с тем же содержимым событий не создает). Тело подписывается HMAC-SHA256:
+// @Description  X-Webhook-Signature = sha256=hex(HMAC(secret, X-Webhook-Timestamp + "." + тело)).
+// @Description  Секрет возвращается только в этом ответе.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        request  body  CreateWebhookRequest  true  "Адрес и типы событий"
+// @Success      201  {object}  CreateWebhookResponse
+// @Failure      400  {object}  problem.Problem "Ошибка валидации"
+// @Failure      500  {object}  problem.Problem "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req CreateWebhookRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		problem.WriteValidation(w, r, err)
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		problem.WriteValidation(w, r, err)
+		return
+	}
+
+	webhook, err := h.svc.CreateWebhook(ctx, req.URL, req.Events)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to create webhook", slog.Any("error", err))
+		problem.Write(w, r, problem.CodeInternal)
+		return
+	}
+
+	res := CreateWebhookResponse{Webhook: WebhookEntityToJSON(webhook), Secret: webhook.Secret}
+	utils.WriteJSON(w, res, http.StatusCreated)
+}
+
+// ListWebhooks возвращает все вебхуки.
+// @Summary      Список вебхуков
+// @Tags         webhooks
+// @Produce      json
+// @Success      200  {object}  WebhooksResponse
+// @Failure      500  {object}  problem.Problem "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	webhooks, err := h.svc.ListWebhooks(ctx)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list webhooks", slog.Any("error", err))
+		problem.Write(w, r, problem.CodeInternal)
+		return
+	}
+
+	res := WebhooksResponse{Webhooks: make([]Webhook, len(webhooks))}
+	for i, webhook := range webhooks {
+		res.Webhooks[i] = WebhookEntityToJSON(webhook)
+	}
+	utils.WriteJSON(w, res, http.StatusOK)
+}
+
+// GetWebhook возвращает вебхук по ID.
+// @Summary      Получить вебхук
+// @Tags         webhooks
+// @Produce      json
+// @Param        id   path  int  true  "ID вебхука"
+// @Success      200  {object}  Webhook
+// @Failure      400  {object}  problem.Problem "Ошибка валидации"
+// @Failure      404  {object}  problem.Problem "Вебхук не найден"
+// @Failure      500  {object}  problem.Problem "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.svc.GetWebhook(ctx, id)
+	if err != nil {
+		h.writeError(w, r, err, "failed to get webhook", id)
+		return
+	}
+
+	utils.WriteJSON(w, WebhookEntityToJSON(webhook), http.StatusOK)
+}
+
+// UpdateWebhook меняет вебхук.
+// @Summary      Изменить вебхук
+// @Description  Меняет адрес, типы событий или включает и выключает вебхук.
+// @Description  Вебхук выключается автоматически, если ему подряд не удалось доставить несколько событий,
+// @Description  включение сбрасывает счетчик неудачных доставок.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id       path  int                   true  "ID вебхука"
+// @Param        request  body  UpdateWebhookRequest  true  "Изменения"
+// @Success      200  {object}  Webhook
+// @Failure      400  {object}  problem.Problem "Ошибка валидации"
+// @Failure      404  {object}  problem.Problem "Вебхук не найден"
+// @Failure      500  {object}  problem.Problem "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/webhooks/{id} [patch]
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateWebhookRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		problem.WriteValidation(w, r, err)
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		problem.WriteValidation(w, r, err)
+		return
+	}
+
+	webhook, err := h.svc.UpdateWebhook(ctx, id, entities.WebhookUpdate{
+		URL:     req.URL,
+		Events:  req.Events,
+		Enabled: req.Enabled,
+	})
+	if err != nil {
+		h.writeError(w, r, err, "failed to update webhook", id)
+		return
+	}
+
+	utils.WriteJSON(w, WebhookEntityToJSON(webhook), http.StatusOK)
+}
+
+// DeleteWebhook удаляет вебхук вместе с журналом доставок.
+// @Summary      Удалить вебхук
+// @Tags         webhooks
+// @Param        id   path  int  true  "ID вебхука"
+// @Success      204  "Вебхук удален"
+// @Failure      400  {object}  problem.Problem "Ошибка валидации"
+// @Failure      404  {object}  problem.Problem "Вебхук не найден"
+// @Failure      500  {object}  problem.Problem "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.DeleteWebhook(ctx, id); err != nil {
+		h.writeError(w, r, err, "failed to delete webhook", id)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries возвращает журнал доставок вебхука.
+// @Summary      Журнал доставок вебхука
+// @Description  Каждая попытка доставки записывается отдельно, последние попытки идут первыми.
+// @Tags         webhooks
+// @Produce      json
+// @Param        id     path   int  true   "ID вебхука"
+// @Param        limit  query  int  false  "Сколько записей вернуть" minimum(1) maximum(100) default(20)
+// @Success      200  {object}  WebhookDeliveriesResponse
+// @Failure      400  {object}  problem.Problem "Ошибка валидации"
+// @Failure      404  {object}  problem.Problem "Вебхук не найден"
+// @Failure      500  {object}  problem.Problem "Внутренняя ошибка сервера"
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+
+	limit := defaultPageSize
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageSize {
+			fields := map[string]string{"limit": "range"}
+			problem.WriteFields(w, r, fields)
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := h.svc.ListWebhookDeliveries(ctx, id, limit)
+	if err != nil {
+		h.writeError(w, r, err, "failed to list webhook deliveries", id)
+		return
+	}
+
+	res := WebhookDeliveriesResponse{Deliveries: make([]WebhookDelivery, len(deliveries))}
+	for i, d := range deliveries {
+		res.Deliveries[i] = WebhookDeliveryEntityToJSON(d)
+	}
+	utils.WriteJSON(w, res, http.StatusOK)
+}
+
+func (h *WebhookHandler) writeError(w http.ResponseWriter, r *http.Request, err error, msg string, id int64) {
+	if errors.Is(err, entities.ErrWebhookNotFound) {
+		problem.Write(w, r, problem.CodeWebhookNotFound)
+		return
+	}
+	h.logger.ErrorContext(r.Context(), msg, slog.Any("error", err), slog.Int64("webhookID", id))
+	problem.Write(w, r, problem.CodeInternal)
+}
+
+// parseID разбирает числовой id из пути, при ошибке отвечает клиенту сам
+func parseID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id < 1 {
+		fields := map[string]string{"id": "number"}
+		problem.WriteFields(w, r, fields)
+		return 0, false
+	}
+	return id, true
+}
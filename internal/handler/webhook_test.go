@@ -0,0 +1,167 @@
+package handler_test
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/handler"
+	mocks "github.com/SergeyBogomolovv/l0-order-service/internal/handler/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	webhook := entities.Webhook{
+		ID:        1,
+		URL:       "https://partner.example.com/hook",
+		Secret:    "secret",
+		Events:    []string{entities.EventOrderSaved},
+		Enabled:   true,
+		CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	enabled := true
+
+	testCases := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		mockBehavior func(svc *mocks.MockWebhookService)
+		wantStatus   int
+		wantBody     string
+	}{
+		{
+			name:   "create",
+			method: http.MethodPost,
+			path:   "/admin/webhooks",
+			body:   `{"url":"https://partner.example.com/hook","events":["order.saved","order.updated"]}`,
+			mockBehavior: func(svc *mocks.MockWebhookService) {
+				events := []string{entities.EventOrderSaved, entities.EventOrderUpdated}
+				svc.EXPECT().CreateWebhook(mock.Anything, webhook.URL, events).
+					Return(webhook, nil).Once()
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   `"secret":"secret"`,
+		},
+		{
+			name:         "create with unknown event",
+			method:       http.MethodPost,
+			path:         "/admin/webhooks",
+			body:         `{"url":"https://partner.example.com/hook","events":["order.deleted"]}`,
+			mockBehavior: func(_ *mocks.MockWebhookService) {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     `"events[0]"`,
+		},
+		{
+			name:         "create with invalid url",
+			method:       http.MethodPost,
+			path:         "/admin/webhooks",
+			body:         `{"url":"partner.example.com"}`,
+			mockBehavior: func(_ *mocks.MockWebhookService) {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     `"url"`,
+		},
+		{
+			name:   "get hides secret",
+			method: http.MethodGet,
+			path:   "/admin/webhooks/1",
+			mockBehavior: func(svc *mocks.MockWebhookService) {
+				svc.EXPECT().GetWebhook(mock.Anything, int64(1)).Return(webhook, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"events":["order.saved"],"enabled":true,"failure_count":0`,
+		},
+		{
+			name:         "invalid id",
+			method:       http.MethodGet,
+			path:         "/admin/webhooks/abc",
+			mockBehavior: func(_ *mocks.MockWebhookService) {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     `{"field":"id","rule":"number"`,
+		},
+		{
+			name:   "enable",
+			method: http.MethodPatch,
+			path:   "/admin/webhooks/1",
+			body:   `{"enabled":true}`,
+			mockBehavior: func(svc *mocks.MockWebhookService) {
+				svc.EXPECT().UpdateWebhook(mock.Anything, int64(1), entities.WebhookUpdate{Enabled: &enabled}).
+					Return(webhook, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"id":1`,
+		},
+		{
+			name:         "update with unknown event",
+			method:       http.MethodPatch,
+			path:         "/admin/webhooks/1",
+			body:         `{"events":["order.saved","order.deleted"]}`,
+			mockBehavior: func(_ *mocks.MockWebhookService) {},
+			wantStatus:   http.StatusBadRequest,
+			wantBody:     `"events[1]"`,
+		},
+		{
+			name:   "delete missing webhook",
+			method: http.MethodDelete,
+			path:   "/admin/webhooks/2",
+			mockBehavior: func(svc *mocks.MockWebhookService) {
+				svc.EXPECT().DeleteWebhook(mock.Anything, int64(2)).Return(entities.ErrWebhookNotFound).Once()
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `"code":"webhook_not_found"`,
+		},
+		{
+			name:   "deliveries",
+			method: http.MethodGet,
+			path:   "/admin/webhooks/1/deliveries?limit=5",
+			mockBehavior: func(svc *mocks.MockWebhookService) {
+				svc.EXPECT().ListWebhookDeliveries(mock.Anything, int64(1), 5).Return([]entities.WebhookDelivery{{
+					ID: 10, WebhookID: 1, EventID: "e1", Event: entities.EventOrderSaved, OrderUID: "123",
+					Attempt: 2, StatusCode: 500, Error: "unexpected status code 500", Duration: 15 * time.Millisecond,
+				}}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"attempt":2,"status_code":500,"error":"unexpected status code 500","duration_ms":15`,
+		},
+		{
+			name:   "internal error",
+			method: http.MethodGet,
+			path:   "/admin/webhooks",
+			mockBehavior: func(svc *mocks.MockWebhookService) {
+				svc.EXPECT().ListWebhooks(mock.Anything).Return(nil, errors.New("db error")).Once()
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `"code":"internal_error"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := mocks.NewMockWebhookService(t)
+			tc.mockBehavior(svc)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			h := handler.NewWebhookHandler(logger, svc)
+			r := newTestRouter()
+			h.Init(r)
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tc.wantBody)
+			if tc.method != http.MethodPost {
+				// секрет отдается только при регистрации
+				assert.NotContains(t, w.Body.String(), `"secret"`)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/problem"
+)
+
+// APIKeyHeader заголовок с API ключом, ключ также можно передать как Bearer токен
+const APIKeyHeader = "X-API-Key"
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, creds entities.Credentials) (entities.Principal, error)
+}
+
+// AllowAll выдает каждому запросу права admin, используется при выключенной аутентификации
+type AllowAll struct{}
+
+func (AllowAll) Authenticate(context.Context, entities.Credentials) (entities.Principal, error) {
+	return entities.Principal{Subject: "anonymous", Scopes: []string{entities.ScopeAdmin}}, nil
+}
+
+type principalKey struct{}
+
+// WithPrincipal сохраняет клиента в контексте запроса
+func WithPrincipal(ctx context.Context, p entities.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom возвращает клиента, от имени которого выполняется запрос
+func PrincipalFrom(ctx context.Context) (entities.Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(entities.Principal)
+	return p, ok
+}
+
+// Authenticate определяет клиента по учетным данным запроса.
+// Запрос без учетных данных пропускается дальше без клиента, права проверяет RequireScope.
+// Неверные учетные данные отклоняются сразу, даже если маршруту права не нужны.
+func Authenticate(logger *slog.Logger, auth Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			creds := credentials(r)
+
+			principal, err := auth.Authenticate(ctx, creds)
+			if errors.Is(err, entities.ErrUnauthenticated) {
+				if creds.Empty() {
+					next.ServeHTTP(w, r)
+					return
+				}
+				logger.InfoContext(ctx, "invalid credentials", slog.Any("error", err))
+				writeUnauthorized(w, r)
+				return
+			}
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to authenticate", slog.Any("error", err))
+				problem.Write(w, r, problem.CodeInternal)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(ctx, principal)))
+		})
+	}
+}
+
+// RequireScope пропускает только клиентов с правом scope
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFrom(r.Context())
+			if !ok {
+				writeUnauthorized(w, r)
+				return
+			}
+			if !principal.HasScope(scope) {
+				problem.Write(w, r, problem.CodeForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func credentials(r *http.Request) entities.Credentials {
+	return parseCredentials(r.Header.Get(APIKeyHeader), r.Header.Get("Authorization"))
+}
+
+// parseCredentials разбирает значения X-API-Key и Authorization, общие для HTTP заголовков и gRPC метаданных
+func parseCredentials(apiKey, authorization string) entities.Credentials {
+	if apiKey != "" {
+		return entities.Credentials{APIKey: apiKey}
+	}
+
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return entities.Credentials{}
+	}
+	token = strings.TrimSpace(token)
+	// JWT состоит из трех частей через точку, в API ключе точек нет
+	if strings.Count(token, ".") == 2 {
+		return entities.Credentials{Token: token}
+	}
+	return entities.Credentials{APIKey: token}
+}
+
+func writeUnauthorized(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="order-service"`)
+	problem.Write(w, r, problem.CodeUnauthorized)
+}
@@ -0,0 +1,117 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubAuthenticator принимает ключ reader-key, токен a.b.c и отказывает на ключ broken
+type stubAuthenticator struct{}
+
+func (stubAuthenticator) Authenticate(_ context.Context, creds entities.Credentials) (entities.Principal, error) {
+	switch {
+	case creds.APIKey == "reader-key":
+		return entities.Principal{Subject: "reader", Scopes: []string{entities.ScopeOrdersRead}}, nil
+	case creds.Token == "a.b.c":
+		return entities.Principal{Subject: "admin", Scopes: []string{entities.ScopeAdmin}}, nil
+	case creds.APIKey == "broken":
+		return entities.Principal{}, errors.New("db is down")
+	default:
+		return entities.Principal{}, entities.ErrUnauthenticated
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		headers     map[string]string
+		scope       string
+		wantStatus  int
+		wantSubject string
+	}{
+		{name: "public route without credentials", wantStatus: http.StatusOK},
+		{name: "no credentials", scope: entities.ScopeOrdersRead, wantStatus: http.StatusUnauthorized},
+		{
+			name:        "api key header",
+			headers:     map[string]string{"X-API-Key": "reader-key"},
+			scope:       entities.ScopeOrdersRead,
+			wantStatus:  http.StatusOK,
+			wantSubject: "reader",
+		},
+		{
+			name:        "api key as bearer",
+			headers:     map[string]string{"Authorization": "Bearer reader-key"},
+			scope:       entities.ScopeOrdersRead,
+			wantStatus:  http.StatusOK,
+			wantSubject: "reader",
+		},
+		{
+			name:        "jwt",
+			headers:     map[string]string{"Authorization": "bearer a.b.c"},
+			scope:       entities.ScopeOrdersWrite,
+			wantStatus:  http.StatusOK,
+			wantSubject: "admin",
+		},
+		{
+			name:       "missing scope",
+			headers:    map[string]string{"X-API-Key": "reader-key"},
+			scope:      entities.ScopeOrdersWrite,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "invalid key on public route",
+			headers:    map[string]string{"X-API-Key": "wrong"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "basic auth is ignored",
+			headers:    map[string]string{"Authorization": "Basic dXNlcjpwYXNz"},
+			scope:      entities.ScopeOrdersRead,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "authenticator error",
+			headers:    map[string]string{"X-API-Key": "broken"},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var subject string
+			var h http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if p, ok := middleware.PrincipalFrom(r.Context()); ok {
+					subject = p.Subject
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+			if tc.scope != "" {
+				h = middleware.RequireScope(tc.scope)(h)
+			}
+			h = middleware.Authenticate(logger, stubAuthenticator{})(h)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.wantStatus, rec.Code)
+			assert.Equal(t, tc.wantSubject, subject)
+			if tc.wantStatus == http.StatusUnauthorized {
+				assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
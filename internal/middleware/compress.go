@@ -0,0 +1,283 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var encoderFactories = map[string]func() encoder{
+	"gzip": func() encoder {
+		return gzip.NewWriter(io.Discard)
+	},
+	"zstd": func() encoder {
+		// ошибка возможна только при неверных опциях
+		enc, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		return enc
+	},
+	"br": func() encoder {
+		return brotli.NewWriter(nil)
+	},
+}
+
+// Compress сжимает ответы алгоритмом, выбранным по заголовку Accept-Encoding.
+// Ответ буферизуется до cfg.MinSize байт: более короткие ответы отдаются как есть.
+// Сжимаются только ответы с типом из cfg.ContentTypes и без своего Content-Encoding.
+func Compress(cfg config.Compression) func(next http.Handler) http.Handler {
+	if !cfg.Enabled {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	pools := make(map[string]*sync.Pool, len(cfg.Encodings))
+	for _, name := range cfg.Encodings {
+		factory := encoderFactories[name]
+		pools[name] = &sync.Pool{New: func() any { return factory() }}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// при Upgrade соединение забирает обработчик
+			if r.Header.Get("Upgrade") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			// ответ зависит от Accept-Encoding, даже если этому клиенту он отдается несжатым
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"), cfg.Encodings)
+			// у HEAD нет тела
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: w,
+				cfg:            cfg,
+				encoding:       encoding,
+				pool:           pools[encoding],
+				status:         http.StatusOK,
+			}
+			defer cw.close()
+
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding выбирает поддерживаемый алгоритм с наибольшим q.
+// При одинаковом q побеждает тот, что раньше в encodings.
+func negotiateEncoding(header string, encodings []string) string {
+	if header == "" {
+		return ""
+	}
+
+	weights := make(map[string]float64)
+	wildcard := -1.0
+	for part := range strings.SplitSeq(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		if name == "*" {
+			wildcard = q
+		} else {
+			weights[name] = q
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, name := range encodings {
+		q, ok := weights[name]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+// compressWriter откладывает решение о сжатии, пока не наберется cfg.MinSize байт
+// или обработчик не завершится, поэтому WriteHeader тоже откладывается.
+type compressWriter struct {
+	http.ResponseWriter
+	cfg      config.Compression
+	encoding string
+	pool     *sync.Pool
+
+	status  int
+	decided bool
+	buf     []byte
+
+	enc        encoder
+	original   int
+	compressed countingWriter
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	// информационные ответы не относятся к телу и уходят сразу
+	if code < http.StatusOK {
+		cw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if !cw.decided {
+		cw.status = code
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	cw.original += len(p)
+
+	if !cw.decided {
+		cw.buf = append(cw.buf, p...)
+		if len(cw.buf) < cw.cfg.MinSize {
+			return len(p), nil
+		}
+		if err := cw.decide(false); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	if cw.enc != nil {
+		return cw.enc.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// Flush нужен потоковым ответам: решение о сжатии принимается без учета размера
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		if err := cw.decide(true); err != nil {
+			return
+		}
+	}
+	if cw.enc != nil {
+		if err := cw.enc.Flush(); err != nil {
+			return
+		}
+	}
+	_ = http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+func (cw *compressWriter) decide(streaming bool) error {
+	cw.decided = true
+	header := cw.Header()
+
+	if header.Get("Content-Type") == "" && len(cw.buf) > 0 {
+		header.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+
+	if cw.compressible(streaming) {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", cw.encoding)
+		// сжатое тело отличается побайтово, поэтому строгий ETag становится слабым
+		if etag := header.Get("ETag"); strings.HasPrefix(etag, `"`) {
+			header.Set("ETag", "W/"+etag)
+		}
+
+		cw.compressed.w = cw.ResponseWriter
+		cw.enc = cw.pool.Get().(encoder)
+		cw.enc.Reset(&cw.compressed)
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if cw.enc != nil {
+		_, err := cw.enc.Write(buf)
+		return err
+	}
+	_, err := cw.ResponseWriter.Write(buf)
+	return err
+}
+
+func (cw *compressWriter) compressible(streaming bool) bool {
+	if cw.status < http.StatusOK || cw.status == http.StatusNoContent || cw.status == http.StatusNotModified {
+		return false
+	}
+	if !streaming && len(cw.buf) < cw.cfg.MinSize {
+		return false
+	}
+
+	header := cw.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	mediaType, _, _ := strings.Cut(header.Get("Content-Type"), ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	for _, allowed := range cw.cfg.ContentTypes {
+		if allowed == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (cw *compressWriter) close() {
+	if !cw.decided {
+		if err := cw.decide(false); err != nil {
+			return
+		}
+	}
+	if cw.enc == nil {
+		return
+	}
+
+	// ошибка означает, что клиент уже отключился
+	_ = cw.enc.Close()
+	cw.pool.Put(cw.enc)
+	cw.enc = nil
+
+	httpCompressionBytes.WithLabelValues(cw.encoding, "original").Add(float64(cw.original))
+	httpCompressionBytes.WithLabelValues(cw.encoding, "compressed").Add(float64(cw.compressed.n))
+	if cw.original > 0 {
+		httpCompressionRatio.WithLabelValues(cw.encoding).Observe(float64(cw.compressed.n) / float64(cw.original))
+	}
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += n
+	return n, err
+}
@@ -0,0 +1,114 @@
+package middleware_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompress(t *testing.T) {
+	cfg := config.Compression{
+		Enabled:      true,
+		MinSize:      100,
+		Encodings:    []string{"br", "zstd", "gzip"},
+		ContentTypes: []string{"application/json", "text/*"},
+	}
+	large := `{"items":"` + strings.Repeat("a", 1000) + `"}`
+
+	testCases := []struct {
+		name           string
+		acceptEncoding string
+		contentType    string
+		body           string
+		wantEncoding   string
+	}{
+		{name: "brotli preferred", acceptEncoding: "gzip, zstd, br", contentType: "application/json", body: large, wantEncoding: "br"},
+		{name: "client q wins", acceptEncoding: "br;q=0.5, gzip", contentType: "application/json", body: large, wantEncoding: "gzip"},
+		{name: "wildcard", acceptEncoding: "br;q=0, *", contentType: "application/json", body: large, wantEncoding: "zstd"},
+		{name: "wildcard content type", acceptEncoding: "gzip", contentType: "text/csv", body: large, wantEncoding: "gzip"},
+		{name: "no accept encoding", contentType: "application/json", body: large},
+		{name: "below threshold", acceptEncoding: "gzip", contentType: "application/json", body: `{"ok":true}`},
+		{name: "content type not allowed", acceptEncoding: "gzip", contentType: "image/png", body: large},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := middleware.Compress(cfg)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", tc.contentType)
+				w.Header().Set("ETag", `"abc"`)
+				w.WriteHeader(http.StatusCreated)
+				// тело пишется частями, чтобы проверить буферизацию до порога
+				for i := 0; i < len(tc.body); i += 64 {
+					_, err := w.Write([]byte(tc.body[i:min(i+64, len(tc.body))]))
+					require.NoError(t, err)
+				}
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			}
+			rr := httptest.NewRecorder()
+
+			// Metrics оборачивает writer раньше Compress, как в приложении
+			middleware.Metrics(h).ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusCreated, rr.Code)
+			assert.Equal(t, tc.wantEncoding, rr.Header().Get("Content-Encoding"))
+
+			var body io.Reader = rr.Body
+			switch tc.wantEncoding {
+			case "gzip":
+				zr, err := gzip.NewReader(rr.Body)
+				require.NoError(t, err)
+				body = zr
+			case "zstd":
+				zr, err := zstd.NewReader(rr.Body)
+				require.NoError(t, err)
+				defer zr.Close()
+				body = zr
+			case "br":
+				body = brotli.NewReader(rr.Body)
+			}
+
+			assert.Equal(t, "Accept-Encoding", rr.Header().Get("Vary"))
+			if tc.wantEncoding != "" {
+				assert.Equal(t, `W/"abc"`, rr.Header().Get("ETag"))
+			} else {
+				assert.Equal(t, `"abc"`, rr.Header().Get("ETag"))
+			}
+
+			data, err := io.ReadAll(body)
+			require.NoError(t, err)
+			assert.Equal(t, tc.body, string(data))
+		})
+	}
+}
+
+func TestCompress_NotModified(t *testing.T) {
+	cfg := config.Compression{Enabled: true, Encodings: []string{"gzip"}, ContentTypes: []string{"application/json"}}
+
+	h := middleware.Compress(cfg)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotModified)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Header().Get("Content-Encoding"))
+	assert.Empty(t, rr.Body.String())
+}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/problem"
+)
+
+// Priority класс запроса при перегрузке
+type Priority string
+
+const (
+	// PriorityCritical запросы никогда не отклоняются и не учитываются в лимите. По умолчанию это
+	// /admin API любой версии: управление ключами и вебхуками. Административный сервер лимитом не ограничен.
+	PriorityCritical Priority = "critical"
+	PriorityNormal   Priority = "normal"
+	// PriorityLow запросам доступна только часть лимита, при перегрузке они отклоняются первыми
+	PriorityLow Priority = "low"
+)
+
+// ConcurrencyLimiter ограничивает число одновременно обрабатываемых запросов и подстраивает лимит
+// по задержке ответов (AIMD): пока запросы укладываются в LatencyTarget, лимит растет на единицу
+// за каждый лимит запросов, как только задержка превышает цель или сервер отвечает 5xx,
+// лимит умножается на Backoff, но не чаще раза за LatencyTarget, чтобы пачка медленных запросов,
+// начатых до уменьшения, не обрушила лимит до минимума. Запросы сверх лимита отклоняются с 503, не дожидаясь Postgres.
+type ConcurrencyLimiter struct {
+	logger *slog.Logger
+	cfg    config.Concurrency
+
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+	// lastBackoff когда лимит уменьшался в последний раз
+	lastBackoff time.Time
+}
+
+func NewConcurrencyLimiter(logger *slog.Logger, cfg config.Concurrency) *ConcurrencyLimiter {
+	l := &ConcurrencyLimiter{logger: logger, cfg: cfg, limit: float64(cfg.InitialLimit)}
+	httpConcurrencyLimit.Set(l.limit)
+	return l
+}
+
+// Limit текущий лимит одновременных запросов
+func (l *ConcurrencyLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+func (l *ConcurrencyLimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// потоки и долгий опрос заказа с wait ждут событий, а не Postgres: они заняли бы лимит
+		// и своей задержкой уменьшали бы его, поэтому не учитываются. Исключения определяются
+		// маршрутом, а не заголовками и параметрами, которые клиент может добавить к любому запросу
+		priority := l.priority(r)
+		pattern := routePattern(r)
+		longPoll := pattern == "/order/{order_uid}" && r.URL.Query().Has("wait")
+		if priority == PriorityCritical || streamRoutes[pattern] || longPoll {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !l.acquire(priority) {
+			httpShedRequests.WithLabelValues(string(priority)).Inc()
+			l.logger.WarnContext(r.Context(), "request shed", slog.String("priority", string(priority)))
+			w.Header().Set("Retry-After", "1")
+			problem.Write(w, r, problem.CodeOverloaded)
+			return
+		}
+
+		start := time.Now()
+		rw := wrapResponseWriter(w)
+		defer func() {
+			l.release(time.Since(start), rw.status)
+		}()
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// priority сравнивает пути без префикса версии, поэтому /admin из CriticalPaths
+// относится и к /v1/admin, и к /v2/admin
+func (l *ConcurrencyLimiter) priority(r *http.Request) Priority {
+	switch {
+	case matchRoute(r, l.cfg.CriticalPaths):
+		return PriorityCritical
+	case matchRoute(r, l.cfg.LowPriorityPaths):
+		return PriorityLow
+	default:
+		return PriorityNormal
+	}
+}
+
+func (l *ConcurrencyLimiter) acquire(priority Priority) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit := l.limit
+	if priority == PriorityLow {
+		limit = math.Max(1, limit*l.cfg.LowPriorityShare)
+	}
+	if float64(l.inFlight) >= math.Floor(limit) {
+		return false
+	}
+	l.inFlight++
+	return true
+}
+
+func (l *ConcurrencyLimiter) release(latency time.Duration, status int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	overloaded := latency > l.cfg.LatencyTarget || status >= http.StatusInternalServerError
+	switch {
+	case overloaded:
+		if now.Sub(l.lastBackoff) >= l.cfg.LatencyTarget {
+			l.limit = math.Max(float64(l.cfg.MinLimit), l.limit*l.cfg.Backoff)
+			l.lastBackoff = now
+		}
+	case float64(l.inFlight) >= l.limit/2:
+		// лимит растет, только если он используется, иначе после простоя он был бы слишком большим
+		l.limit = math.Min(float64(l.cfg.MaxLimit), l.limit+1/l.limit)
+	}
+	l.inFlight--
+	httpConcurrencyLimit.Set(l.limit)
+}
+
+// matchRoute запрос подходит под одно из правил. Правило с методом, например "GET /orders",
+// совпадает только с этим маршрутом и методом: создание заказов и поиск по трек-номеру под него не попадают.
+// Правило без метода - префикс пути, совпадающий целиком или по границе сегмента.
+func matchRoute(r *http.Request, rules []string) bool {
+	path := UnversionedPath(r.URL.Path)
+	for _, rule := range rules {
+		if method, pattern, ok := strings.Cut(rule, " "); ok {
+			if r.Method == method && routePattern(r) == pattern {
+				return true
+			}
+			continue
+		}
+		if path == rule || strings.HasPrefix(path, strings.TrimSuffix(rule, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,215 @@
+package middleware_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func newConcurrencyLimiter(initial, maxLimit int) *middleware.ConcurrencyLimiter {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return middleware.NewConcurrencyLimiter(logger, config.Concurrency{
+		InitialLimit:     initial,
+		MinLimit:         1,
+		MaxLimit:         maxLimit,
+		LatencyTarget:    time.Hour,
+		Backoff:          0.5,
+		CriticalPaths:    []string{"/healthz", "/admin"},
+		LowPriorityPaths: []string{"/orders"},
+		LowPriorityShare: 0.5,
+	})
+}
+
+func TestConcurrencyLimiter_Shedding(t *testing.T) {
+	limiter := newConcurrencyLimiter(2, 2)
+
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	h := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/order/slow" {
+			started <- struct{}{}
+			<-unblock
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	do := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	done := make(chan struct{})
+	go func() {
+		do("/order/slow")
+		close(done)
+	}()
+	<-started
+
+	// низкоприоритетным запросам доступна половина лимита, и она занята
+	w := do("/orders")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusServiceUnavailable, do("/v2/orders").Code)
+	assert.Equal(t, http.StatusOK, do("/order/1").Code)
+
+	go func() {
+		do("/order/slow")
+	}()
+	<-started
+
+	assert.Equal(t, http.StatusServiceUnavailable, do("/order/1").Code)
+	assert.Equal(t, http.StatusOK, do("/healthz").Code)
+	assert.Equal(t, http.StatusOK, do("/admin/webhooks").Code)
+	assert.Equal(t, http.StatusOK, do("/v1/admin/webhooks").Code)
+
+	close(unblock)
+	<-done
+	assert.Eventually(t, func() bool {
+		return do("/order/1").Code == http.StatusOK
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestConcurrencyLimiter_AIMD(t *testing.T) {
+	limiter := newConcurrencyLimiter(1, 4)
+
+	status := http.StatusOK
+	h := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+	}))
+	do := func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/order/1", nil))
+	}
+
+	// лимит растет на 1/limit за успешный запрос: 2, 2.5
+	do()
+	assert.Equal(t, 2, limiter.Limit())
+	do()
+	assert.Equal(t, 2, limiter.Limit())
+	// один запрос занимает меньше половины лимита, без нагрузки лимит не растет
+	do()
+	do()
+	assert.Equal(t, 2, limiter.Limit())
+
+	// 2.5 * 0.5
+	status = http.StatusInternalServerError
+	do()
+	assert.Equal(t, 1, limiter.Limit())
+
+	// лимит не опускается ниже минимума
+	do()
+	assert.Equal(t, 1, limiter.Limit())
+}
+
+func TestConcurrencyLimiter_RoutePriority(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	limiter := middleware.NewConcurrencyLimiter(logger, config.Concurrency{
+		InitialLimit:     2,
+		MinLimit:         1,
+		MaxLimit:         2,
+		LatencyTarget:    time.Hour,
+		Backoff:          0.5,
+		LowPriorityPaths: []string{"GET /orders", "/graphql"},
+		LowPriorityShare: 0.5,
+	})
+
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	ok := func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	r := chi.NewRouter()
+	r.Use(limiter.Handler)
+	r.Route("/v2", func(r chi.Router) {
+		r.Get("/orders", ok)
+		r.Post("/orders", ok)
+		r.Get("/orders/by-track/{track_number}", ok)
+		r.Post("/graphql", ok)
+		r.Get("/slow", func(w http.ResponseWriter, _ *http.Request) {
+			started <- struct{}{}
+			<-unblock
+			w.WriteHeader(http.StatusOK)
+		})
+	})
+
+	do := func(method, target string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+		return w.Code
+	}
+
+	done := make(chan struct{})
+	go func() {
+		do(http.MethodGet, "/v2/slow")
+		close(done)
+	}()
+	<-started
+
+	// доля низкоприоритетных запросов занята, но создание заказов и поиск по трек-номеру
+	// низкоприоритетными не считаются
+	assert.Equal(t, http.StatusServiceUnavailable, do(http.MethodGet, "/v2/orders"))
+	assert.Equal(t, http.StatusServiceUnavailable, do(http.MethodPost, "/v2/graphql"))
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/v2/orders"))
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/v2/orders/by-track/T1"))
+
+	close(unblock)
+	<-done
+}
+
+func TestConcurrencyLimiter_Exemptions(t *testing.T) {
+	limiter := newConcurrencyLimiter(1, 1)
+
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	ok := func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	r := chi.NewRouter()
+	r.Use(limiter.Handler)
+	r.Route("/v2", func(r chi.Router) {
+		r.Get("/order/{order_uid}", ok)
+		r.Get("/orders", ok)
+		r.Get("/orders/stream", ok)
+		r.Get("/slow", func(w http.ResponseWriter, _ *http.Request) {
+			started <- struct{}{}
+			<-unblock
+			w.WriteHeader(http.StatusOK)
+		})
+	})
+
+	do := func(target string, header ...string) int {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if len(header) == 2 {
+			req.Header.Set(header[0], header[1])
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	done := make(chan struct{})
+	go func() {
+		do("/v2/slow")
+		close(done)
+	}()
+	<-started
+
+	// лимит занят, обходят его только потоки и долгий опрос заказа
+	assert.Equal(t, http.StatusOK, do("/v2/orders/stream"))
+	assert.Equal(t, http.StatusOK, do("/v2/order/1?wait=5s"))
+	assert.Equal(t, http.StatusServiceUnavailable, do("/v2/order/1"))
+	assert.Equal(t, http.StatusServiceUnavailable, do("/v2/orders?wait=5s"))
+	assert.Equal(t, http.StatusServiceUnavailable, do("/v2/order/1", "Accept", "text/event-stream"))
+	assert.Equal(t, http.StatusServiceUnavailable, do("/v2/order/1", "Upgrade", "websocket"))
+
+	close(unblock)
+	<-done
+}
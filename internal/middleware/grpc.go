@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryLogger логирует gRPC вызовы так же, как Logger логирует HTTP запросы
+func UnaryLogger(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logGRPC(ctx, logger, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+func StreamLogger(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logGRPC(ss.Context(), logger, info.FullMethod, start, err)
+		return err
+	}
+}
+
+func logGRPC(ctx context.Context, logger *slog.Logger, method string, start time.Time, err error) {
+	logger.InfoContext(ctx, "grpc request",
+		slog.String("code", status.Code(err).String()),
+		slog.String("method", method),
+		slog.String("duration", time.Since(start).String()),
+	)
+}
+
+// UnaryRecoverer превращает панику в обработчике в ошибку Internal, как chi Recoverer для HTTP
+func UnaryRecoverer(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) { //nolint:nonamedreturns // нужно для recover
+		defer func() {
+			if p := recover(); p != nil {
+				logger.ErrorContext(ctx, "grpc handler panic", slog.Any("panic", p), slog.String("method", info.FullMethod))
+				err = status.Error(codes.Internal, "internal server error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+func StreamRecoverer(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) { //nolint:nonamedreturns // нужно для recover
+		defer func() {
+			if p := recover(); p != nil {
+				logger.ErrorContext(ss.Context(), "grpc handler panic", slog.Any("panic", p), slog.String("method", info.FullMethod))
+				err = status.Error(codes.Internal, "internal server error")
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// UnaryAuth пропускает только вызовы клиентов с правом scope, как Authenticate и RequireScope для HTTP.
+// Учетные данные передаются в метаданных x-api-key или authorization: Bearer.
+// Проверки здоровья доступны без них, потому что балансировщики не передают учетные данные.
+func UnaryAuth(logger *slog.Logger, auth Authenticator, scope string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authorizeGRPC(ctx, logger, auth, scope, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func StreamAuth(logger *slog.Logger, auth Authenticator, scope string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorizeGRPC(ss.Context(), logger, auth, scope, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &principalStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// principalStream передает обработчику потока контекст с клиентом
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *principalStream) Context() context.Context {
+	return s.ctx
+}
+
+func authorizeGRPC(
+	ctx context.Context, logger *slog.Logger, auth Authenticator, scope, method string,
+) (context.Context, error) {
+	if strings.HasPrefix(method, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if v := md.Get(key); len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	creds := parseCredentials(first(strings.ToLower(APIKeyHeader)), first("authorization"))
+
+	principal, err := auth.Authenticate(ctx, creds)
+	if errors.Is(err, entities.ErrUnauthenticated) {
+		if !creds.Empty() {
+			logger.InfoContext(ctx, "invalid credentials", slog.Any("error", err), slog.String("method", method))
+		}
+		return nil, status.Error(codes.Unauthenticated, "valid API key or token required")
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to authenticate", slog.Any("error", err), slog.String("method", method))
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+	if !principal.HasScope(scope) {
+		return nil, status.Error(codes.PermissionDenied, "scope "+scope+" required")
+	}
+	return WithPrincipal(ctx, principal), nil
+}
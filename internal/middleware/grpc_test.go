@@ -0,0 +1,115 @@
+package middleware_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+
+	orderv1 "github.com/SergeyBogomolovv/l0-order-service/api/order/v1"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// subjectServer отвечает на GetOrder UID заказа, равным клиенту из контекста
+type subjectServer struct {
+	orderv1.UnimplementedOrderServiceServer
+}
+
+func (subjectServer) GetOrder(ctx context.Context, _ *orderv1.GetOrderRequest) (*orderv1.Order, error) {
+	principal, _ := middleware.PrincipalFrom(ctx)
+	return &orderv1.Order{OrderUid: principal.Subject}, nil
+}
+
+func (subjectServer) WatchOrders(
+	_ *orderv1.WatchOrdersRequest, stream grpc.ServerStreamingServer[orderv1.Order],
+) error {
+	principal, _ := middleware.PrincipalFrom(stream.Context())
+	return stream.Send(&orderv1.Order{OrderUid: principal.Subject})
+}
+
+func newAuthGRPCConn(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(middleware.UnaryAuth(logger, stubAuthenticator{}, entities.ScopeOrdersRead)),
+		grpc.StreamInterceptor(middleware.StreamAuth(logger, stubAuthenticator{}, entities.ScopeOrdersRead)),
+	)
+	orderv1.RegisterOrderServiceServer(srv, subjectServer{})
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(lis) //nolint:errcheck // ошибка после Stop не важна
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestGRPCAuth(t *testing.T) {
+	testCases := []struct {
+		name        string
+		md          []string
+		wantCode    codes.Code
+		wantSubject string
+	}{
+		{name: "no credentials", wantCode: codes.Unauthenticated},
+		{name: "invalid key", md: []string{"x-api-key", "wrong"}, wantCode: codes.Unauthenticated},
+		{name: "api key", md: []string{"x-api-key", "reader-key"}, wantCode: codes.OK, wantSubject: "reader"},
+		{
+			name: "bearer api key", md: []string{"authorization", "Bearer reader-key"},
+			wantCode: codes.OK, wantSubject: "reader",
+		},
+		{
+			name: "jwt with admin scope", md: []string{"authorization", "Bearer a.b.c"},
+			wantCode: codes.OK, wantSubject: "admin",
+		},
+		{name: "authenticator error", md: []string{"x-api-key", "broken"}, wantCode: codes.Internal},
+	}
+
+	client := orderv1.NewOrderServiceClient(newAuthGRPCConn(t))
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := metadata.AppendToOutgoingContext(context.Background(), tc.md...)
+
+			order, err := client.GetOrder(ctx, &orderv1.GetOrderRequest{OrderUid: "1"})
+			assert.Equal(t, tc.wantCode, status.Code(err))
+			if tc.wantCode == codes.OK {
+				assert.Equal(t, tc.wantSubject, order.GetOrderUid())
+			}
+
+			stream, err := client.WatchOrders(ctx, &orderv1.WatchOrdersRequest{})
+			require.NoError(t, err)
+			order, err = stream.Recv()
+			assert.Equal(t, tc.wantCode, status.Code(err))
+			if tc.wantCode == codes.OK {
+				assert.Equal(t, tc.wantSubject, order.GetOrderUid())
+			}
+		})
+	}
+}
+
+func TestGRPCAuth_HealthIsPublic(t *testing.T) {
+	client := healthpb.NewHealthClient(newAuthGRPCConn(t))
+
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+}
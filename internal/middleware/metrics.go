@@ -32,6 +32,49 @@ var (
 		Help:      "HTTP request latencies in seconds.",
 		Buckets:   prometheus.DefBuckets,
 	}, []string{"method", "route", "status"})
+
+	httpCompressionRatio = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "order_service",
+		Subsystem: "http",
+		Name:      "compression_ratio",
+		Help:      "Ratio of compressed to original response size.",
+		Buckets:   prometheus.LinearBuckets(0.1, 0.1, 10),
+	}, []string{"encoding"})
+
+	httpCompressionBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "order_service",
+		Subsystem: "http",
+		Name:      "compression_bytes_total",
+		Help:      "Total response bytes before and after compression.",
+	}, []string{"encoding", "stage"})
+
+	httpConcurrencyLimit = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "order_service",
+		Subsystem: "http",
+		Name:      "concurrency_limit",
+		Help:      "Current adaptive limit of concurrent HTTP requests.",
+	})
+
+	httpShedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "order_service",
+		Subsystem: "http",
+		Name:      "shed_requests_total",
+		Help:      "Total number of HTTP requests rejected because of overload.",
+	}, []string{"priority"})
+
+	httpRateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "order_service",
+		Subsystem: "http",
+		Name:      "rate_limited_requests_total",
+		Help:      "Total number of HTTP requests rejected by the rate limiter.",
+	}, []string{"route"})
+
+	httpRateLimitErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "order_service",
+		Subsystem: "http",
+		Name:      "rate_limit_errors_total",
+		Help:      "Total number of requests let through because the rate limit store failed.",
+	})
 )
 
 func Metrics(next http.Handler) http.Handler {
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/problem"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/ratelimit"
+	"github.com/go-chi/chi/v5"
+)
+
+// RateLimiter хранилище корзин токенов
+type RateLimiter interface {
+	Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error)
+}
+
+// RateLimit ограничивает частоту запросов клиента к маршруту, routes нужен, чтобы найти шаблон маршрута
+// до маршрутизации. Клиент определяется по учетным данным, запросы без них - по IP адресу.
+// Если хранилище недоступно, запрос пропускается, чтобы сбой хранилища не останавливал API.
+func RateLimit(
+	logger *slog.Logger, cfg config.RateLimit, limiter RateLimiter, routes chi.Routes,
+) func(http.Handler) http.Handler {
+	defaultLimit := ratelimit.Limit{Rate: cfg.Rate, Burst: cfg.Burst}
+	limits := make(map[string]ratelimit.Limit, len(cfg.Routes))
+	for _, l := range cfg.Routes {
+		limits[l.Route] = ratelimit.Limit{Rate: l.Rate, Burst: l.Burst}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			// у всех версий API общий лимит, иначе клиент мог бы умножить его, чередуя префиксы
+			route := r.Method + " " + UnversionedPath(routes.Find(chi.NewRouteContext(), r.Method, r.URL.Path))
+			limit, ok := limits[route]
+			if !ok {
+				limit = defaultLimit
+			}
+
+			res, err := limiter.Take(ctx, route+"|"+rateLimitClient(r), limit)
+			if err != nil {
+				httpRateLimitErrors.Inc()
+				logger.ErrorContext(ctx, "failed to check rate limit", slog.Any("error", err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+
+			if !res.Allowed {
+				httpRateLimited.WithLabelValues(route).Inc()
+				h.Set("Retry-After", strconv.Itoa(max(ceilSeconds(res.RetryAfter), 1)))
+				problem.Write(w, r, problem.CodeRateLimited)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitClient у клиента с учетными данными своя корзина, клиенты без них делят корзину своего IP адреса
+func rateLimitClient(r *http.Request) string {
+	if principal, ok := PrincipalFrom(r.Context()); ok && !credentials(r).Empty() {
+		return "sub:" + principal.Subject
+	}
+	// chimw.RealIP оставляет в RemoteAddr только адрес, без него в RemoteAddr еще и порт
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	return "ip:" + ip
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,96 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/problem"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/ratelimit"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingLimiter struct{}
+
+func (failingLimiter) Take(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("redis is down")
+}
+
+func newRateLimitedRouter(limiter middleware.RateLimiter) chi.Router {
+	cfg := config.RateLimit{
+		Rate:   0.001,
+		Burst:  2,
+		Routes: []config.RouteRateLimit{{Route: "GET /order/{order_uid}", Rate: 0.001, Burst: 1}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	r := chi.NewRouter()
+	r.Use(middleware.Authenticate(logger, stubAuthenticator{}))
+	r.Use(middleware.RateLimit(logger, cfg, limiter, r))
+	ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }
+	r.Get("/order/{order_uid}", ok)
+	r.Get("/orders", ok)
+	r.Route(middleware.APIv1.Prefix(), func(r chi.Router) {
+		r.Get("/order/{order_uid}", ok)
+	})
+	return r
+}
+
+func TestRateLimit(t *testing.T) {
+	r := newRateLimitedRouter(ratelimit.NewMemoryStore())
+
+	do := func(path, ip, apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = ip + ":1234"
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// лимит маршрута общий для всех заказов
+	w := do("/order/1", "10.0.0.1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+
+	w = do("/order/2", "10.0.0.1", "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `"code":"rate_limited"`)
+
+	// у другого маршрута, IP адреса и API ключа свои корзины
+	w = do("/orders", "10.0.0.1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+
+	assert.Equal(t, http.StatusOK, do("/order/1", "10.0.0.2", "").Code)
+	assert.Equal(t, http.StatusOK, do("/order/1", "10.0.0.1", "reader-key").Code)
+	// ключ один и тот же с разных адресов
+	assert.Equal(t, http.StatusTooManyRequests, do("/order/1", "10.0.0.3", "reader-key").Code)
+
+	// у маршрута с префиксом версии тот же лимит и та же корзина
+	w = do("/v1/order/1", "10.0.0.1", "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+}
+
+func TestRateLimit_StoreFailure(t *testing.T) {
+	r := newRateLimitedRouter(failingLimiter{})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/order/1", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+}
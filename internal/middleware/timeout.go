@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimw "github.com/go-chi/chi/v5/middleware"
+)
+
+// streamRoutes долгоживущие маршруты: потоки SSE и WebSocket и выгрузка заказов
+var streamRoutes = map[string]bool{
+	"/orders/stream":    true,
+	"/orders/stream/ws": true,
+	"/orders/export":    true,
+}
+
+// Timeout работает как chimw.Timeout, но не ограничивает долгоживущие потоки SSE и WebSocket
+// и выгрузку заказов: они закрываются вместе с соединением клиента или при остановке сервера
+func Timeout(timeout time.Duration) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		limited := chimw.Timeout(timeout)(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isStream(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			limited.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isStream определяется маршрутом, а не заголовками: иначе любой запрос обходил бы таймаут и лимиты
+func isStream(r *http.Request) bool {
+	return streamRoutes[routePattern(r)]
+}
+
+// routePattern шаблон маршрута chi без префикса версии, пустой если маршрут не найден.
+// Общие middleware выполняются до маршрутизации, поэтому маршрут ищется заранее.
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return ""
+	}
+	return UnversionedPath(rctx.Routes.Find(chi.NewRouteContext(), r.Method, r.URL.Path))
+}
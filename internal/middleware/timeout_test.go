@@ -0,0 +1,56 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	testCases := []struct {
+		name         string
+		path         string
+		header       string
+		value        string
+		wantDeadline bool
+	}{
+		{name: "regular request", path: "/order/1", header: "Accept", value: "application/json", wantDeadline: true},
+		{name: "server-sent events", path: "/orders/stream", header: "Accept", value: "text/event-stream"},
+		{name: "websocket", path: "/v1/orders/stream/ws", header: "Upgrade", value: "websocket"},
+		{name: "orders export", path: "/v2/orders/export", header: "Accept", value: "*/*"},
+		{name: "spoofed accept", path: "/order/1", header: "Accept", value: "text/event-stream", wantDeadline: true},
+		{name: "spoofed upgrade", path: "/v2/order/1", header: "Upgrade", value: "websocket", wantDeadline: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var hasDeadline bool
+			h := func(_ http.ResponseWriter, r *http.Request) {
+				_, hasDeadline = r.Context().Deadline()
+			}
+
+			r := chi.NewRouter()
+			r.Use(middleware.Timeout(time.Minute))
+			routes := func(r chi.Router) {
+				r.Get("/order/{order_uid}", h)
+				r.Get("/orders/stream", h)
+				r.Get("/orders/stream/ws", h)
+				r.Get("/orders/export", h)
+			}
+			r.Route("/v1", routes)
+			r.Route("/v2", routes)
+			r.Group(routes)
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.Header.Set(tc.header, tc.value)
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tc.wantDeadline, hasDeadline)
+		})
+	}
+}
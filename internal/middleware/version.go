@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIVersion версия HTTP API, определяется префиксом пути
+type APIVersion int
+
+const (
+	APIv1 APIVersion = 1
+	// APIv2 отличается от v1 только формой ответов: payment_dt в RFC 3339 вместо Unix секунд
+	APIv2 APIVersion = 2
+)
+
+// APIVersions поддерживаемые версии, последняя - актуальная
+var APIVersions = []APIVersion{APIv1, APIv2}
+
+func (v APIVersion) String() string {
+	return "v" + strconv.Itoa(int(v))
+}
+
+// Prefix префикс пути версии, например /v1
+func (v APIVersion) Prefix() string {
+	return "/" + v.String()
+}
+
+type apiVersionKey struct{}
+
+// Version сохраняет версию API в контексте запроса
+func Version(v APIVersion) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiVersionKey{}, v)))
+		})
+	}
+}
+
+// APIVersionFrom возвращает версию API запроса, пути без версии отвечают как v1
+func APIVersionFrom(ctx context.Context) APIVersion {
+	if v, ok := ctx.Value(apiVersionKey{}).(APIVersion); ok {
+		return v
+	}
+	return APIv1
+}
+
+// Deprecated помечает пути без версии устаревшими (RFC 9745, RFC 8594): с deprecatedAt они не развиваются,
+// после sunset могут быть отключены. Link указывает на тот же путь в v1, контракт которого совпадает.
+func Deprecated(deprecatedAt, sunset time.Time) func(http.Handler) http.Handler {
+	deprecation := "@" + strconv.FormatInt(deprecatedAt.Unix(), 10)
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set("Deprecation", deprecation)
+			h.Set("Sunset", sunsetDate)
+			h.Add("Link", "<"+APIv1.Prefix()+r.URL.RequestURI()+`>; rel="successor-version"`)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// UnversionedPath путь без префикса версии, лимиты, приоритеты и ключи идемпотентности одинаковы для всех версий
+func UnversionedPath(path string) string {
+	for _, v := range APIVersions {
+		if rest, ok := strings.CutPrefix(path, v.Prefix()); ok && (rest == "" || rest[0] == '/') {
+			return rest
+		}
+	}
+	return path
+}
@@ -0,0 +1,54 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersion(t *testing.T) {
+	deprecatedAt := time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	var got middleware.APIVersion
+	h := func(_ http.ResponseWriter, r *http.Request) { got = middleware.APIVersionFrom(r.Context()) }
+
+	r := chi.NewRouter()
+	for _, v := range middleware.APIVersions {
+		r.With(middleware.Version(v)).Get(v.Prefix()+"/orders", h)
+	}
+	r.With(middleware.Deprecated(deprecatedAt, sunset)).Get("/orders", h)
+
+	testCases := []struct {
+		path           string
+		wantVersion    middleware.APIVersion
+		wantDeprecated bool
+	}{
+		{path: "/v1/orders", wantVersion: middleware.APIv1},
+		{path: "/v2/orders", wantVersion: middleware.APIv2},
+		{path: "/orders?limit=10", wantVersion: middleware.APIv1, wantDeprecated: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tc.wantVersion, got)
+			if !tc.wantDeprecated {
+				assert.Empty(t, w.Header().Get("Deprecation"))
+				assert.Empty(t, w.Header().Get("Sunset"))
+				return
+			}
+			assert.Equal(t, "@1793491200", w.Header().Get("Deprecation"))
+			assert.Equal(t, "Sat, 01 May 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+			assert.Equal(t, `</v1/orders?limit=10>; rel="successor-version"`, w.Header().Get("Link"))
+		})
+	}
+}
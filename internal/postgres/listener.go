@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/lib/pq"
+)
+
+const (
+	minReconnectInterval = time.Second
+	maxReconnectInterval = time.Minute
+	// pingInterval проверка соединения, когда уведомлений долго нет
+	pingInterval = 90 * time.Second
+)
+
+// NotificationHandler получает уведомления из канала LISTEN
+type NotificationHandler interface {
+	Notify(payload string)
+	// NotifyAll вызывается после переподключения, когда уведомления за время разрыва потеряны
+	NotifyAll()
+}
+
+// Listener слушает канал Postgres через отдельное соединение и передает уведомления обработчику
+type Listener struct {
+	logger  *slog.Logger
+	dsn     string
+	channel string
+	handler NotificationHandler
+}
+
+func NewListener(logger *slog.Logger, cfg config.Postgres, channel string, handler NotificationHandler) *Listener {
+	return &Listener{
+		logger:  logger.With(slog.String("listener", channel)),
+		dsn:     dsn(cfg),
+		channel: channel,
+		handler: handler,
+	}
+}
+
+// Start подписывается на канал и обрабатывает уведомления в фоне до отмены ctx
+func (l *Listener) Start(ctx context.Context) error {
+	listener := pq.NewListener(l.dsn, minReconnectInterval, maxReconnectInterval,
+		func(event pq.ListenerEventType, err error) {
+			if err != nil {
+				l.logger.Warn("postgres listener connection problem", slog.Int("event", int(event)), slog.Any("error", err))
+			}
+		})
+
+	if err := listener.Listen(l.channel); err != nil {
+		_ = listener.Close()
+		return fmt.Errorf("failed to listen channel %s: %w", l.channel, err)
+	}
+
+	go l.run(ctx, listener)
+	return nil
+}
+
+func (l *Listener) run(ctx context.Context, listener *pq.Listener) {
+	defer listener.Close() //nolint:errcheck // ошибка закрытия при остановке не важна
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-listener.Notify:
+			// nil приходит после переподключения
+			if n == nil {
+				l.handler.NotifyAll()
+				continue
+			}
+			l.handler.Notify(n.Extra)
+		case <-ticker.C:
+			go listener.Ping()
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/lib/pq"
+)
+
+var apiKeyColumns = []string{"id", "name", "key_hash", "scopes", "created_at", "revoked_at"}
+
+func (r *PostgresRepo) CreateAPIKey(ctx context.Context, k entities.APIKey) (entities.APIKey, error) {
+	query, args := r.qb.Insert("api_keys").
+		Columns("name", "key_hash", "scopes").
+		Values(k.Name, k.Hash, pq.Array(k.Scopes)).
+		Suffix("RETURNING " + strings.Join(apiKeyColumns, ", ")).
+		MustSql()
+
+	var created APIKey
+	if err := r.getContext(ctx, &created, query, args...); err != nil {
+		return entities.APIKey{}, fmt.Errorf("failed to create api key: %w", err)
+	}
+	return APIKeyToEntity(created), nil
+}
+
+func (r *PostgresRepo) ListAPIKeys(ctx context.Context) ([]entities.APIKey, error) {
+	query, args := r.qb.Select(apiKeyColumns...).
+		From("api_keys").
+		OrderBy("id").
+		MustSql()
+
+	var keys []APIKey
+	if err := r.selectContext(ctx, &keys, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to select api keys: %w", err)
+	}
+
+	result := make([]entities.APIKey, len(keys))
+	for i, k := range keys {
+		result[i] = APIKeyToEntity(k)
+	}
+	return result, nil
+}
+
+// GetAPIKeyByHash возвращает действующий ключ по его хэшу
+func (r *PostgresRepo) GetAPIKeyByHash(ctx context.Context, hash string) (entities.APIKey, error) {
+	query, args := r.qb.Select(apiKeyColumns...).
+		From("api_keys").
+		Where(sq.Eq{"key_hash": hash, "revoked_at": nil}).
+		MustSql()
+
+	var k APIKey
+	err := r.getContext(ctx, &k, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.APIKey{}, entities.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return entities.APIKey{}, fmt.Errorf("failed to get api key: %w", err)
+	}
+	return APIKeyToEntity(k), nil
+}
+
+// RevokeAPIKey отзывает действующий ключ и возвращает его хэш
+func (r *PostgresRepo) RevokeAPIKey(ctx context.Context, id int64) (string, error) {
+	query, args := r.qb.Update("api_keys").
+		Set("revoked_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": id, "revoked_at": nil}).
+		Suffix("RETURNING key_hash").
+		MustSql()
+
+	var hash string
+	err := r.getContext(ctx, &hash, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", entities.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	return hash, nil
+}
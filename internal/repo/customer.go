@@ -0,0 +1,45 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+)
+
+// CustomerSummary считает сводку по заказам покупателя.
+// Оба запроса используют индекс orders_customer_id_idx.
+func (r *PostgresRepo) CustomerSummary(ctx context.Context, customerID string) (entities.CustomerSummary, error) {
+	query, args := r.qb.Select(
+		"count(*) AS order_count",
+		"min(date_created) AS first_order_at",
+		"max(date_created) AS last_order_at",
+	).
+		From("orders").
+		Where(sq.Eq{"customer_id": customerID}).
+		MustSql()
+
+	var stats CustomerStats
+	if err := r.getContext(ctx, &stats, query, args...); err != nil {
+		return entities.CustomerSummary{}, fmt.Errorf("failed to get customer stats: %w", err)
+	}
+	if stats.OrderCount == 0 {
+		return entities.CustomerSummary{}, entities.ErrCustomerNotFound
+	}
+
+	query, args = r.qb.Select("p.currency", "sum(p.amount) AS total").
+		From("orders o").
+		Join("payments p ON p.order_uid = o.order_uid").
+		Where(sq.Eq{"o.customer_id": customerID}).
+		GroupBy("p.currency").
+		OrderBy("p.currency").
+		MustSql()
+
+	var totals []CurrencyTotal
+	if err := r.selectContext(ctx, &totals, query, args...); err != nil {
+		return entities.CustomerSummary{}, fmt.Errorf("failed to select customer totals: %w", err)
+	}
+
+	return CustomerSummaryToEntity(customerID, stats, totals), nil
+}
@@ -0,0 +1,198 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/fieldcrypt"
+)
+
+var errNoKeyring = errors.New("delivery is encrypted, but keyring is not configured")
+
+// deliveryAAD привязывает шифротекст к заказу и колонке
+func deliveryAAD(orderUID, column string) string {
+	return orderUID + ":" + column
+}
+
+// encryptedFields зашифрованные колонки deliveries
+func encryptedFields(d *Delivery) map[string]*sql.NullString {
+	return map[string]*sql.NullString{
+		"name":    &d.Name,
+		"phone":   &d.Phone,
+		"address": &d.Address,
+		"email":   &d.Email,
+	}
+}
+
+// decryptDelivery расшифровывает доставку, незашифрованные строки возвращаются как есть
+func (r *PostgresRepo) decryptDelivery(d Delivery) (Delivery, error) {
+	if !d.KeyID.Valid {
+		return d, nil
+	}
+	if r.keyring == nil {
+		return Delivery{}, errNoKeyring
+	}
+
+	dk, err := r.keyring.OpenDataKey(d.KeyID.String, d.DataKey)
+	if err != nil {
+		return Delivery{}, fmt.Errorf("failed to open data key of order %s: %w", d.OrderUID, err)
+	}
+	for column, field := range encryptedFields(&d) {
+		if !field.Valid {
+			continue
+		}
+		field.String, err = dk.Decrypt(field.String, deliveryAAD(d.OrderUID, column))
+		if err != nil {
+			return Delivery{}, fmt.Errorf("failed to decrypt %s of order %s: %w", column, d.OrderUID, err)
+		}
+	}
+	return d, nil
+}
+
+// encryptDelivery шифрует заполненные персональные данные доставки и считает слепые индексы
+func (r *PostgresRepo) encryptDelivery(d Delivery, dk *fieldcrypt.DataKey) (Delivery, error) {
+	d.PhoneBIdx = r.blindIndex("phone", normalizePhone(d.Phone.String))
+	d.EmailBIdx = r.blindIndex("email", normalizeEmail(d.Email.String))
+
+	var err error
+	for column, field := range encryptedFields(&d) {
+		if !field.Valid {
+			continue
+		}
+		field.String, err = dk.Encrypt(field.String, deliveryAAD(d.OrderUID, column))
+		if err != nil {
+			return Delivery{}, fmt.Errorf("failed to encrypt %s: %w", column, err)
+		}
+	}
+	d.KeyID = sql.NullString{String: dk.KeyID, Valid: true}
+	d.DataKey = dk.Wrapped
+	return d, nil
+}
+
+func (r *PostgresRepo) blindIndex(field, value string) sql.NullString {
+	if value == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: r.keyring.BlindIndex(field, value), Valid: true}
+}
+
+// deliveryLookup условие на заказы, у доставки которых column равна value.
+// Зашифрованные строки ищутся по слепому индексу, еще не зашифрованные по самому значению.
+func (r *PostgresRepo) deliveryLookup(column, value, normalized string) sq.Sqlizer {
+	const exists = "EXISTS (SELECT 1 FROM deliveries d WHERE d.order_uid = o.order_uid AND "
+	if r.keyring == nil {
+		return sq.Expr(exists+"d."+column+" = ?)", value)
+	}
+	return sq.Expr(
+		exists+"(d."+column+"_bidx = ? OR d.key_id IS NULL AND d."+column+" = ?))",
+		r.keyring.BlindIndex(column, normalized), value,
+	)
+}
+
+// normalizePhone оставляет только цифры и ведущий +, чтобы индекс не зависел от форматирования
+func normalizePhone(phone string) string {
+	var b strings.Builder
+	for i, c := range strings.TrimSpace(phone) {
+		if c >= '0' && c <= '9' || c == '+' && i == 0 {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// EncryptDeliveries шифрует доставки, сохраненные без шифрования, и перешифровывает ключи данных,
+// зашифрованные неактивным ключом связки. Строки обрабатываются пачками по batchSize,
+// поэтому миграцию можно прервать и продолжить. Возвращает число измененных строк.
+func (r *PostgresRepo) EncryptDeliveries(ctx context.Context, batchSize int) (int, error) {
+	if r.keyring == nil {
+		return 0, errors.New("keyring is not configured")
+	}
+
+	var total int
+	var last string
+	for {
+		query, args := r.qb.Select(deliveryColumns...).
+			From("deliveries").
+			Where(sq.Or{sq.Eq{"key_id": nil}, sq.NotEq{"key_id": r.keyring.ActiveKeyID()}}).
+			Where(sq.Gt{"order_uid": last}).
+			OrderBy("order_uid").
+			Limit(uint64(batchSize)).
+			MustSql()
+
+		var deliveries []Delivery
+		if err := r.selectContext(ctx, &deliveries, query, args...); err != nil {
+			return total, fmt.Errorf("failed to select deliveries: %w", err)
+		}
+		if len(deliveries) == 0 {
+			return total, nil
+		}
+
+		for _, d := range deliveries {
+			updated, err := r.encryptStoredDelivery(ctx, d)
+			if err != nil {
+				return total, err
+			}
+			if updated {
+				total++
+			}
+		}
+		last = deliveries[len(deliveries)-1].OrderUID
+	}
+}
+
+// encryptStoredDelivery шифрует или перешифровывает одну строку. Обновление проверяет прежний key_id,
+// чтобы не затереть строку, которую параллельно обработал другой процесс.
+func (r *PostgresRepo) encryptStoredDelivery(ctx context.Context, d Delivery) (bool, error) {
+	var q sq.UpdateBuilder
+	if d.KeyID.Valid {
+		// ротация: поля не меняются, перешифровывается только ключ данных
+		dk, err := r.keyring.Rewrap(d.KeyID.String, d.DataKey)
+		if err != nil {
+			return false, fmt.Errorf("failed to rewrap data key of order %s: %w", d.OrderUID, err)
+		}
+		q = r.qb.Update("deliveries").
+			Set("key_id", dk.KeyID).
+			Set("data_key", dk.Wrapped).
+			Where(sq.Eq{"order_uid": d.OrderUID, "key_id": d.KeyID.String})
+	} else {
+		dk, err := r.keyring.NewDataKey()
+		if err != nil {
+			return false, err
+		}
+		enc, err := r.encryptDelivery(d, dk)
+		if err != nil {
+			return false, fmt.Errorf("failed to encrypt delivery of order %s: %w", d.OrderUID, err)
+		}
+		q = r.qb.Update("deliveries").
+			SetMap(map[string]any{
+				"name":       enc.Name,
+				"phone":      enc.Phone,
+				"address":    enc.Address,
+				"email":      enc.Email,
+				"key_id":     enc.KeyID,
+				"data_key":   enc.DataKey,
+				"phone_bidx": enc.PhoneBIdx,
+				"email_bidx": enc.EmailBIdx,
+			}).
+			Where(sq.Eq{"order_uid": d.OrderUID, "key_id": nil})
+	}
+
+	query, args := q.MustSql()
+	res, err := r.execContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update delivery of order %s: %w", d.OrderUID, err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,99 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+)
+
+// AcquireIdempotencyKey создает запись для ключа клиента subject. Существующая запись перезаписывается,
+// если она устарела (создана раньше expiredBefore) или зависла в обработке (раньше staleBefore).
+// Возвращает false, если ключ уже занят.
+func (r *PostgresRepo) AcquireIdempotencyKey(
+	ctx context.Context, subject, key, requestHash string, expiredBefore, staleBefore time.Time,
+) (bool, error) {
+	query, args := r.qb.Insert("idempotency_keys").
+		Columns("subject", "key", "request_hash").
+		Values(subject, key, requestHash).
+		Suffix(`ON CONFLICT (subject, key) DO UPDATE
+			SET request_hash = EXCLUDED.request_hash, status_code = NULL, response = NULL, created_at = now()
+			WHERE idempotency_keys.created_at < ?
+			OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at < ?)`,
+			expiredBefore, staleBefore).
+		MustSql()
+
+	res, err := r.execContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire idempotency key: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return affected > 0, nil
+}
+
+func (r *PostgresRepo) GetIdempotencyKey(ctx context.Context, subject, key string) (entities.IdempotencyKey, error) {
+	query, args := r.qb.Select("key", "request_hash", "status_code", "response").
+		From("idempotency_keys").
+		Where(sq.Eq{"subject": subject, "key": key}).
+		MustSql()
+
+	var k IdempotencyKey
+	err := r.getContext(ctx, &k, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.IdempotencyKey{}, entities.ErrIdempotencyKeyNotFound
+	}
+	if err != nil {
+		return entities.IdempotencyKey{}, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	return IdempotencyKeyToEntity(k), nil
+}
+
+func (r *PostgresRepo) CompleteIdempotencyKey(
+	ctx context.Context, subject, key string, resp entities.IdempotentResponse,
+) error {
+	query, args := r.qb.Update("idempotency_keys").
+		Set("status_code", resp.StatusCode).
+		Set("response", resp.Body).
+		Where(sq.Eq{"subject": subject, "key": key}).
+		MustSql()
+
+	if _, err := r.execContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRepo) DeleteIdempotencyKey(ctx context.Context, subject, key string) error {
+	query, args := r.qb.Delete("idempotency_keys").
+		Where(sq.Eq{"subject": subject, "key": key}).
+		MustSql()
+
+	if _, err := r.execContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys удаляет записи, созданные раньше expiredBefore, и возвращает их число
+func (r *PostgresRepo) DeleteExpiredIdempotencyKeys(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	query, args := r.qb.Delete("idempotency_keys").
+		Where(sq.Lt{"created_at": expiredBefore}).
+		MustSql()
+
+	res, err := r.execContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return deleted, nil
+}
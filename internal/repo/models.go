@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/lib/pq"
 )
 
 type Order struct {
@@ -19,6 +20,7 @@ type Order struct {
 	SmID              int            `db:"sm_id"`
 	DateCreated       time.Time      `db:"date_created"`
 	OofShard          sql.NullString `db:"oof_shard"`
+	SavedAt           time.Time      `db:"saved_at"`
 }
 
 type Delivery struct {
@@ -30,6 +32,11 @@ type Delivery struct {
 	Address  sql.NullString `db:"address"`
 	Region   sql.NullString `db:"region"`
 	Email    sql.NullString `db:"email"`
+	// KeyID и DataKey заданы, если name, phone, address и email зашифрованы
+	KeyID     sql.NullString `db:"key_id"`
+	DataKey   []byte         `db:"data_key"`
+	PhoneBIdx sql.NullString `db:"phone_bidx"`
+	EmailBIdx sql.NullString `db:"email_bidx"`
 }
 
 type Payment struct {
@@ -73,6 +80,20 @@ func DeliveryToEntity(d Delivery) entities.Delivery {
 	}
 }
 
+// deliveryFromEntity модель строки deliveries для сохранения
+func deliveryFromEntity(orderUID string, d entities.Delivery) Delivery {
+	return Delivery{
+		OrderUID: orderUID,
+		Name:     nullString(d.Name),
+		Phone:    nullString(d.Phone),
+		Zip:      nullString(d.ZIP),
+		City:     nullString(d.City),
+		Address:  nullString(d.Address),
+		Region:   nullString(d.Region),
+		Email:    nullString(d.Email),
+	}
+}
+
 func PaymentToEntity(p Payment) entities.Payment {
 	return entities.Payment{
 		Transaction:  p.Transaction,
@@ -117,6 +138,7 @@ func OrderToEntity(o Order, d Delivery, p Payment, items []Item) entities.Order
 		SmID:            o.SmID,
 		DateCreated:     o.DateCreated,
 		OofShard:        nullStringToString(o.OofShard),
+		SavedAt:         o.SavedAt,
 		Delivery:        DeliveryToEntity(d),
 		Payment:         PaymentToEntity(p),
 	}
@@ -144,3 +166,141 @@ func nullInt32ToInt(ni sql.NullInt32) int {
 	}
 	return 0
 }
+
+type CustomerStats struct {
+	OrderCount   int          `db:"order_count"`
+	FirstOrderAt sql.NullTime `db:"first_order_at"`
+	LastOrderAt  sql.NullTime `db:"last_order_at"`
+}
+
+type CurrencyTotal struct {
+	Currency string `db:"currency"`
+	Total    int64  `db:"total"`
+}
+
+func CustomerSummaryToEntity(customerID string, stats CustomerStats, totals []CurrencyTotal) entities.CustomerSummary {
+	summary := entities.CustomerSummary{
+		CustomerID:   customerID,
+		OrderCount:   stats.OrderCount,
+		TotalSpent:   make(map[string]int64, len(totals)),
+		FirstOrderAt: stats.FirstOrderAt.Time,
+		LastOrderAt:  stats.LastOrderAt.Time,
+	}
+	for _, t := range totals {
+		summary.TotalSpent[t.Currency] = t.Total
+	}
+	return summary
+}
+
+type IdempotencyKey struct {
+	Key         string        `db:"key"`
+	RequestHash string        `db:"request_hash"`
+	StatusCode  sql.NullInt32 `db:"status_code"`
+	Response    []byte        `db:"response"`
+}
+
+func IdempotencyKeyToEntity(k IdempotencyKey) entities.IdempotencyKey {
+	return entities.IdempotencyKey{
+		Key:         k.Key,
+		RequestHash: k.RequestHash,
+		Completed:   k.StatusCode.Valid,
+		Response: entities.IdempotentResponse{
+			StatusCode: nullInt32ToInt(k.StatusCode),
+			Body:       k.Response,
+		},
+	}
+}
+
+type Webhook struct {
+	ID           int64          `db:"id"`
+	URL          string         `db:"url"`
+	Secret       string         `db:"secret"`
+	Events       pq.StringArray `db:"events"`
+	Enabled      bool           `db:"enabled"`
+	FailureCount int            `db:"failure_count"`
+	CreatedAt    time.Time      `db:"created_at"`
+	DisabledAt   sql.NullTime   `db:"disabled_at"`
+}
+
+func WebhookToEntity(w Webhook) entities.Webhook {
+	return entities.Webhook{
+		ID:           w.ID,
+		URL:          w.URL,
+		Secret:       w.Secret,
+		Events:       []string(w.Events),
+		Enabled:      w.Enabled,
+		FailureCount: w.FailureCount,
+		CreatedAt:    w.CreatedAt,
+		DisabledAt:   w.DisabledAt.Time,
+	}
+}
+
+type WebhookDelivery struct {
+	ID         int64          `db:"id"`
+	WebhookID  int64          `db:"webhook_id"`
+	EventID    string         `db:"event_id"`
+	Event      string         `db:"event"`
+	OrderUID   string         `db:"order_uid"`
+	Attempt    int            `db:"attempt"`
+	StatusCode sql.NullInt32  `db:"status_code"`
+	Error      sql.NullString `db:"error"`
+	DurationMs int64          `db:"duration_ms"`
+	CreatedAt  time.Time      `db:"created_at"`
+}
+
+func WebhookDeliveryToEntity(d WebhookDelivery) entities.WebhookDelivery {
+	return entities.WebhookDelivery{
+		ID:         d.ID,
+		WebhookID:  d.WebhookID,
+		EventID:    d.EventID,
+		Event:      d.Event,
+		OrderUID:   d.OrderUID,
+		Attempt:    d.Attempt,
+		StatusCode: nullInt32ToInt(d.StatusCode),
+		Error:      nullStringToString(d.Error),
+		Duration:   time.Duration(d.DurationMs) * time.Millisecond,
+		CreatedAt:  d.CreatedAt,
+	}
+}
+
+type WebhookRetry struct {
+	WebhookID int64  `db:"webhook_id"`
+	URL       string `db:"url"`
+	Secret    string `db:"secret"`
+	EventID   string `db:"event_id"`
+	Event     string `db:"event"`
+	OrderUID  string `db:"order_uid"`
+	Attempt   int    `db:"attempt"`
+	Payload   []byte `db:"payload"`
+}
+
+func WebhookRetryToEntity(r WebhookRetry) entities.WebhookRetry {
+	return entities.WebhookRetry{
+		Webhook:  entities.Webhook{ID: r.WebhookID, URL: r.URL, Secret: r.Secret, Enabled: true},
+		EventID:  r.EventID,
+		Event:    r.Event,
+		OrderUID: r.OrderUID,
+		Attempt:  r.Attempt,
+		Payload:  r.Payload,
+	}
+}
+
+type APIKey struct {
+	ID        int64          `db:"id"`
+	Name      string         `db:"name"`
+	Hash      string         `db:"key_hash"`
+	Scopes    pq.StringArray `db:"scopes"`
+	CreatedAt time.Time      `db:"created_at"`
+	RevokedAt sql.NullTime   `db:"revoked_at"`
+}
+
+func APIKeyToEntity(k APIKey) entities.APIKey {
+	return entities.APIKey{
+		ID:        k.ID,
+		Name:      k.Name,
+		Hash:      k.Hash,
+		Scopes:    []string(k.Scopes),
+		CreatedAt: k.CreatedAt,
+		RevokedAt: k.RevokedAt.Time,
+	}
+}
@@ -0,0 +1,31 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/lib/pq"
+)
+
+// SavePIIAccesses записывает выдачи персональных данных в журнал аудита одним запросом
+func (r *PostgresRepo) SavePIIAccesses(ctx context.Context, accesses []entities.PIIAccess) error {
+	if len(accesses) == 0 {
+		return nil
+	}
+
+	q := r.qb.Insert("pii_access_log").Columns("subject", "resource", "order_uids", "request_id", "created_at")
+	for _, a := range accesses {
+		uids := a.OrderUIDs
+		if uids == nil {
+			uids = []string{}
+		}
+		q = q.Values(a.Subject, a.Resource, pq.Array(uids), a.RequestID, a.CreatedAt)
+	}
+	query, args := q.MustSql()
+
+	if _, err := r.execContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to save pii access: %w", err)
+	}
+	return nil
+}
@@ -5,31 +5,68 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/fieldcrypt"
 	"github.com/SergeyBogomolovv/l0-order-service/pkg/trm"
 	"github.com/jmoiron/sqlx"
 )
 
+// OrderSavedChannel канал NOTIFY, в который триггер на orders отправляет UID сохраненного заказа
+const OrderSavedChannel = "order_saved"
+
+// OrderUpdatedChannel канал NOTIFY, в который триггер на orders отправляет UID замененного заказа
+const OrderUpdatedChannel = "order_updated"
+
+// CustomerOrdersChannel канал NOTIFY, в который те же триггеры отправляют ID покупателя нового или замененного заказа
+const CustomerOrdersChannel = "customer_orders_changed"
+
+// exportCursor имя курсора выгрузки, курсор виден только своей транзакции, поэтому выгрузки не мешают друг другу
+const exportCursor = "orders_export"
+
+var errExportWithoutTx = errors.New("orders export requires a transaction")
+
+var (
+	orderColumns = []string{
+		"order_uid", "track_number", "entry", "locale",
+		"internal_signature", "customer_id", "delivery_service",
+		"shardkey", "sm_id", "date_created", "oof_shard", "saved_at",
+	}
+	deliveryColumns = []string{
+		"order_uid", "name", "phone", "zip",
+		"city", "address", "region", "email",
+		"key_id", "data_key",
+	}
+	paymentColumns = []string{
+		"order_uid", "transaction", "request_id", "currency", "provider", "amount",
+		"payment_dt", "bank", "delivery_cost", "goods_total", "custom_fee",
+	}
+	itemColumns = []string{
+		"order_uid", "chrt_id", "track_number", "price", "rid", "name", "sale",
+		"size", "total_price", "nm_id", "brand", "status",
+	}
+)
+
 type PostgresRepo struct {
 	db *sqlx.DB
 	qb sq.StatementBuilderType
+	// keyring шифрует персональные данные доставок, nil если шифрование выключено
+	keyring *fieldcrypt.Keyring
 }
 
-func NewPostgresRepo(db *sqlx.DB) *PostgresRepo {
+func NewPostgresRepo(db *sqlx.DB, keyring *fieldcrypt.Keyring) *PostgresRepo {
 	return &PostgresRepo{
-		db: db,
-		qb: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+		db:      db,
+		qb:      sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+		keyring: keyring,
 	}
 }
 
 func (r *PostgresRepo) LatestOrders(ctx context.Context, count int) ([]entities.Order, error) {
 	// Получаем последние count заказов
-	query, args := r.qb.Select(
-		"order_uid", "track_number", "entry", "locale",
-		"internal_signature", "customer_id", "delivery_service",
-		"shardkey", "sm_id", "date_created", "oof_shard").
+	query, args := r.qb.Select(orderColumns...).
 		From("orders").
 		OrderBy("date_created DESC").
 		Limit(uint64(count)).
@@ -41,6 +78,114 @@ func (r *PostgresRepo) LatestOrders(ctx context.Context, count int) ([]entities.
 		return nil, fmt.Errorf("failed to select orders: %w", err)
 	}
 
+	return r.loadOrderDetails(ctx, orders, true)
+}
+
+func (r *PostgresRepo) ListOrders(ctx context.Context, filter entities.OrderFilter) ([]entities.Order, error) {
+	query, args := r.ordersQuery(filter).Limit(uint64(filter.Limit)).MustSql()
+
+	var orders []Order
+	if err := r.selectContext(ctx, &orders, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to select orders: %w", err)
+	}
+
+	return r.loadOrderDetails(ctx, orders, !filter.ExcludeItems)
+}
+
+// ExportOrders читает заказы по фильтру через серверный курсор, по filter.Limit за раз, и передает их fn,
+// пока заказы не закончатся или fn не вернет ошибку. В памяти находится только одна пачка.
+// Курсор живет до конца транзакции, поэтому выгрузка выполняется только в транзакции.
+func (r *PostgresRepo) ExportOrders(
+	ctx context.Context, filter entities.OrderFilter, fn func(orders []entities.Order) error,
+) error {
+	if trm.ExtractTx(ctx) == nil {
+		return errExportWithoutTx
+	}
+
+	query, args := r.ordersQuery(filter).MustSql()
+	if _, err := r.execContext(ctx, "DECLARE "+exportCursor+" NO SCROLL CURSOR FOR "+query, args...); err != nil {
+		return fmt.Errorf("failed to declare cursor: %w", err)
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM %s", filter.Limit, exportCursor)
+	for {
+		var orders []Order
+		if err := r.selectContext(ctx, &orders, fetch); err != nil {
+			return fmt.Errorf("failed to fetch orders: %w", err)
+		}
+		if len(orders) == 0 {
+			return nil
+		}
+
+		batch, err := r.loadOrderDetails(ctx, orders, !filter.ExcludeItems)
+		if err != nil {
+			return err
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+	}
+}
+
+// ordersQuery запрос заказов по фильтру, отсортированных по (date_created, order_uid), без ограничения числа
+func (r *PostgresRepo) ordersQuery(filter entities.OrderFilter) sq.SelectBuilder {
+	columns := make([]string, len(orderColumns))
+	for i, c := range orderColumns {
+		columns[i] = "o." + c
+	}
+
+	q := r.qb.Select(columns...).From("orders o")
+
+	if filter.CustomerID != "" {
+		q = q.Where(sq.Eq{"o.customer_id": filter.CustomerID})
+	}
+	if filter.DeliveryService != "" {
+		q = q.Where(sq.Eq{"o.delivery_service": filter.DeliveryService})
+	}
+	if filter.Locale != "" {
+		q = q.Where(sq.Eq{"o.locale": filter.Locale})
+	}
+	if !filter.CreatedFrom.IsZero() {
+		q = q.Where(sq.GtOrEq{"o.date_created": filter.CreatedFrom})
+	}
+	if !filter.CreatedTo.IsZero() {
+		q = q.Where(sq.Lt{"o.date_created": filter.CreatedTo})
+	}
+	if filter.Currency != "" || filter.Provider != "" {
+		q = q.Join("payments p ON p.order_uid = o.order_uid")
+		if filter.Currency != "" {
+			q = q.Where(sq.Eq{"p.currency": filter.Currency})
+		}
+		if filter.Provider != "" {
+			q = q.Where(sq.Eq{"p.provider": filter.Provider})
+		}
+	}
+	if filter.Phone != "" {
+		q = q.Where(r.deliveryLookup("phone", filter.Phone, normalizePhone(filter.Phone)))
+	}
+	if filter.Email != "" {
+		q = q.Where(r.deliveryLookup("email", filter.Email, normalizeEmail(filter.Email)))
+	}
+	if filter.Brand != "" {
+		// EXISTS, чтобы заказ с несколькими товарами бренда не дублировался
+		q = q.Where("EXISTS (SELECT 1 FROM items i WHERE i.order_uid = o.order_uid AND i.brand = ?)", filter.Brand)
+	}
+
+	// keyset пагинация по (date_created, order_uid)
+	order, cmp := "DESC", "<"
+	if filter.SortAsc {
+		order, cmp = "ASC", ">"
+	}
+	if filter.After != nil {
+		q = q.Where("(o.date_created, o.order_uid) "+cmp+" (?, ?)", filter.After.DateCreated, filter.After.OrderUID)
+	}
+
+	return q.OrderBy("o.date_created "+order, "o.order_uid "+order)
+}
+
+// loadOrderDetails загружает доставки, платежи и товары для списка заказов,
+// по одному запросу на каждую таблицу. Если withItems false, товары не загружаются.
+func (r *PostgresRepo) loadOrderDetails(ctx context.Context, orders []Order, withItems bool) ([]entities.Order, error) {
 	if len(orders) == 0 {
 		return []entities.Order{}, nil
 	}
@@ -51,29 +196,26 @@ func (r *PostgresRepo) LatestOrders(ctx context.Context, count int) ([]entities.
 	}
 
 	// Получаем доставки для этих заказов
-	query, args = r.qb.Select(
-		"order_uid", "name", "phone", "zip",
-		"city", "address", "region", "email",
-	).
+	query, args := r.qb.Select(deliveryColumns...).
 		From("deliveries").
 		Where(sq.Eq{"order_uid": uids}).
 		MustSql()
 
 	var deliveries []Delivery
-	err = r.selectContext(ctx, &deliveries, query, args...)
+	err := r.selectContext(ctx, &deliveries, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to select deliveries: %w", err)
 	}
 	deliveryMap := make(map[string]Delivery, len(deliveries))
 	for _, delivery := range deliveries {
-		deliveryMap[delivery.OrderUID] = delivery
+		deliveryMap[delivery.OrderUID], err = r.decryptDelivery(delivery)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Получаем платежи для этих заказов
-	query, args = r.qb.Select(
-		"order_uid", "transaction", "request_id", "currency", "provider", "amount",
-		"payment_dt", "bank", "delivery_cost", "goods_total", "custom_fee",
-	).
+	query, args = r.qb.Select(paymentColumns...).
 		From("payments").
 		Where(sq.Eq{"order_uid": uids}).
 		MustSql()
@@ -89,22 +231,21 @@ func (r *PostgresRepo) LatestOrders(ctx context.Context, count int) ([]entities.
 	}
 
 	// Получаем товары для этих заказов
-	query, args = r.qb.Select(
-		"order_uid", "chrt_id", "track_number", "price", "rid", "name", "sale",
-		"size", "total_price", "nm_id", "brand", "status",
-	).
-		From("items").
-		Where(sq.Eq{"order_uid": uids}).
-		MustSql()
-
-	var items []Item
-	err = r.selectContext(ctx, &items, query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to select items: %w", err)
-	}
 	itemsMap := make(map[string][]Item, len(uids))
-	for _, item := range items {
-		itemsMap[item.OrderUID] = append(itemsMap[item.OrderUID], item)
+	if withItems {
+		query, args = r.qb.Select(itemColumns...).
+			From("items").
+			Where(sq.Eq{"order_uid": uids}).
+			MustSql()
+
+		var items []Item
+		err = r.selectContext(ctx, &items, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select items: %w", err)
+		}
+		for _, item := range items {
+			itemsMap[item.OrderUID] = append(itemsMap[item.OrderUID], item)
+		}
 	}
 
 	// Формируем ответ
@@ -120,12 +261,10 @@ func (r *PostgresRepo) LatestOrders(ctx context.Context, count int) ([]entities.
 	return result, nil
 }
 
-func (r *PostgresRepo) GetOrderByID(ctx context.Context, orderUID string) (entities.Order, error) {
+// GetOrderByID возвращает заказ по UID, товары загружаются только с withItems
+func (r *PostgresRepo) GetOrderByID(ctx context.Context, orderUID string, withItems bool) (entities.Order, error) {
 	// Получаем заказ
-	query, args := r.qb.Select(
-		"order_uid", "track_number", "entry", "locale",
-		"internal_signature", "customer_id", "delivery_service",
-		"shardkey", "sm_id", "date_created", "oof_shard").
+	query, args := r.qb.Select(orderColumns...).
 		From("orders").
 		Where(sq.Eq{"order_uid": orderUID}).
 		MustSql()
@@ -140,9 +279,7 @@ func (r *PostgresRepo) GetOrderByID(ctx context.Context, orderUID string) (entit
 	}
 
 	// Получаем данные о доставке
-	query, args = r.qb.Select(
-		"order_uid", "name", "phone", "zip",
-		"city", "address", "region", "email").
+	query, args = r.qb.Select(deliveryColumns...).
 		From("deliveries").
 		Where(sq.Eq{"order_uid": orderUID}).
 		MustSql()
@@ -152,11 +289,13 @@ func (r *PostgresRepo) GetOrderByID(ctx context.Context, orderUID string) (entit
 	if err != nil {
 		return entities.Order{}, fmt.Errorf("failed to get delivery: %w", err)
 	}
+	delivery, err = r.decryptDelivery(delivery)
+	if err != nil {
+		return entities.Order{}, err
+	}
 
 	// Получаем данные о платеже
-	query, args = r.qb.Select(
-		"order_uid", "transaction", "request_id", "currency", "provider", "amount",
-		"payment_dt", "bank", "delivery_cost", "goods_total", "custom_fee").
+	query, args = r.qb.Select(paymentColumns...).
 		From("payments").
 		Where(sq.Eq{"order_uid": orderUID}).
 		MustSql()
@@ -168,58 +307,157 @@ func (r *PostgresRepo) GetOrderByID(ctx context.Context, orderUID string) (entit
 	}
 
 	// Получаем товары
-	query, args = r.qb.Select(
-		"order_uid", "chrt_id", "track_number", "price", "rid", "name", "sale",
-		"size", "total_price", "nm_id", "brand", "status").
-		From("items").
-		Where(sq.Eq{"order_uid": orderUID}).
-		MustSql()
-
 	var items []Item
-	err = r.selectContext(ctx, &items, query, args...)
-	if err != nil {
-		return entities.Order{}, fmt.Errorf("failed to get items: %w", err)
+	if withItems {
+		query, args = r.qb.Select(itemColumns...).
+			From("items").
+			Where(sq.Eq{"order_uid": orderUID}).
+			MustSql()
+
+		err = r.selectContext(ctx, &items, query, args...)
+		if err != nil {
+			return entities.Order{}, fmt.Errorf("failed to get items: %w", err)
+		}
 	}
 
 	// Формируем ответ
 	return OrderToEntity(order, delivery, payment, items), nil
 }
 
-func (r *PostgresRepo) SaveOrder(ctx context.Context, o entities.Order) error {
+// GetOrdersByIDs возвращает найденные заказы из списка UID, отсутствующие заказы пропускаются
+func (r *PostgresRepo) GetOrdersByIDs(ctx context.Context, orderUIDs []string) ([]entities.Order, error) {
+	if len(orderUIDs) == 0 {
+		return []entities.Order{}, nil
+	}
+
+	query, args := r.qb.Select(orderColumns...).
+		From("orders").
+		Where(sq.Eq{"order_uid": orderUIDs}).
+		MustSql()
+
+	var orders []Order
+	if err := r.selectContext(ctx, &orders, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to select orders: %w", err)
+	}
+
+	return r.loadOrderDetails(ctx, orders, true)
+}
+
+func (r *PostgresRepo) GetOrderUIDByTrackNumber(ctx context.Context, trackNumber string) (string, error) {
+	query, args := r.qb.Select("order_uid").
+		From("orders").
+		Where(sq.Eq{"track_number": trackNumber}).
+		MustSql()
+
+	var orderUID string
+	err := r.getContext(ctx, &orderUID, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", entities.ErrOrderNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get order uid: %w", err)
+	}
+	return orderUID, nil
+}
+
+// GetOrderUIDsByItemTrackNumber возвращает ключи не больше limit заказов, в которых есть товар с таким трек номером,
+// от новых к старым, начиная после after. Трек номер товара не уникален, поэтому заказов может быть много.
+func (r *PostgresRepo) GetOrderUIDsByItemTrackNumber(
+	ctx context.Context, trackNumber string, limit int, after *entities.OrderCursor,
+) ([]entities.OrderCursor, error) {
+	// EXISTS, чтобы заказ с несколькими такими товарами не дублировался
+	q := r.qb.Select("o.order_uid", "o.date_created").
+		From("orders o").
+		Where("EXISTS (SELECT 1 FROM items i WHERE i.order_uid = o.order_uid AND i.track_number = ?)", trackNumber)
+	if after != nil {
+		q = q.Where("(o.date_created, o.order_uid) < (?, ?)", after.DateCreated, after.OrderUID)
+	}
+	query, args := q.OrderBy("o.date_created DESC", "o.order_uid DESC").Limit(uint64(limit)).MustSql()
+
+	var keys []struct {
+		OrderUID    string    `db:"order_uid"`
+		DateCreated time.Time `db:"date_created"`
+	}
+	if err := r.selectContext(ctx, &keys, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to select order uids: %w", err)
+	}
+
+	cursors := make([]entities.OrderCursor, len(keys))
+	for i, k := range keys {
+		cursors[i] = entities.OrderCursor{DateCreated: k.DateCreated, OrderUID: k.OrderUID}
+	}
+	return cursors, nil
+}
+
+// SaveOrder сохраняет заказ. Заказ с UID уже сохраненного заменяет его, только если отличается содержимым,
+// иначе ничего не меняется. Доставку, оплату и товары замененного заказа сохраняет вызывающий.
+func (r *PostgresRepo) SaveOrder(ctx context.Context, o entities.Order) (entities.OrderSaveResult, error) {
 	query, args := r.qb.Insert("orders").
 		Columns(
 			"order_uid", "track_number", "entry", "locale",
 			"internal_signature", "customer_id", "delivery_service",
-			"shardkey", "sm_id", "date_created", "oof_shard",
+			"shardkey", "sm_id", "date_created", "oof_shard", "payload_hash",
 		).
 		Values(
 			o.OrderUID, o.TrackNumber, nullString(o.Entry), nullString(o.Locale),
 			nullString(o.InternalSig), o.CustomerID, o.DeliveryService,
-			nullString(o.ShardKey), o.SmID, o.DateCreated, nullString(o.OofShard),
+			nullString(o.ShardKey), o.SmID, o.DateCreated, nullString(o.OofShard), o.ContentHash(),
 		).
-		Suffix("ON CONFLICT (order_uid) DO NOTHING").
+		Suffix(`ON CONFLICT (order_uid) DO UPDATE SET
+			track_number = EXCLUDED.track_number, entry = EXCLUDED.entry, locale = EXCLUDED.locale,
+			internal_signature = EXCLUDED.internal_signature, customer_id = EXCLUDED.customer_id,
+			delivery_service = EXCLUDED.delivery_service, shardkey = EXCLUDED.shardkey, sm_id = EXCLUDED.sm_id,
+			date_created = EXCLUDED.date_created, oof_shard = EXCLUDED.oof_shard,
+			payload_hash = EXCLUDED.payload_hash, saved_at = now()
+		WHERE orders.payload_hash IS DISTINCT FROM EXCLUDED.payload_hash
+		RETURNING xmax = 0 AS created, saved_at`).
 		MustSql()
 
-	_, err := r.execContext(ctx, query, args...)
+	// xmax новой строки равен 0, у обновленной в нем номер обновившей транзакции
+	var row struct {
+		Created bool      `db:"created"`
+		SavedAt time.Time `db:"saved_at"`
+	}
+	err := r.getContext(ctx, &row, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.OrderSaveResult{}, nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to save order: %w", err)
+		return entities.OrderSaveResult{}, fmt.Errorf("failed to save order: %w", err)
 	}
-	return nil
+	return entities.OrderSaveResult{Created: row.Created, Updated: !row.Created, SavedAt: row.SavedAt}, nil
 }
 
+// SaveDelivery сохраняет доставку. Если задана связка ключей, персональные данные шифруются
+// новым ключом данных, а для поиска по телефону и email сохраняются слепые индексы.
+// Доставка замененного заказа перезаписывается, как и оплата.
 func (r *PostgresRepo) SaveDelivery(ctx context.Context, orderUID string, d entities.Delivery) error {
+	row := deliveryFromEntity(orderUID, d)
+	if r.keyring != nil {
+		dk, err := r.keyring.NewDataKey()
+		if err != nil {
+			return err
+		}
+		row, err = r.encryptDelivery(row, dk)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt delivery: %w", err)
+		}
+	}
+
 	query, args := r.qb.Insert("deliveries").
-		Columns("order_uid", "name", "phone", "zip", "city", "address", "region", "email").
-		Values(orderUID,
-			nullString(d.Name),
-			nullString(d.Phone),
-			nullString(d.ZIP),
-			nullString(d.City),
-			nullString(d.Address),
-			nullString(d.Region),
-			nullString(d.Email),
+		Columns(
+			"order_uid", "name", "phone", "zip", "city", "address", "region", "email",
+			"key_id", "data_key", "phone_bidx", "email_bidx",
 		).
-		Suffix("ON CONFLICT (order_uid) DO NOTHING").
+		Values(
+			row.OrderUID, row.Name, row.Phone, row.Zip, row.City, row.Address, row.Region, row.Email,
+			row.KeyID, row.DataKey, row.PhoneBIdx, row.EmailBIdx,
+		).
+		Suffix(`ON CONFLICT (order_uid) DO UPDATE SET
+			name = EXCLUDED.name, phone = EXCLUDED.phone, zip = EXCLUDED.zip, city = EXCLUDED.city,
+			address = EXCLUDED.address, region = EXCLUDED.region, email = EXCLUDED.email,
+			key_id = EXCLUDED.key_id, data_key = EXCLUDED.data_key,
+			phone_bidx = EXCLUDED.phone_bidx, email_bidx = EXCLUDED.email_bidx`).
 		MustSql()
 
 	_, err := r.execContext(ctx, query, args...)
@@ -237,7 +475,11 @@ func (r *PostgresRepo) SavePayment(ctx context.Context, orderUID string, p entit
 			orderUID, p.Transaction, nullString(p.RequestID), p.Currency, p.Provider, p.Amount,
 			p.PaymentDT, nullString(p.Bank), p.DeliveryCost, p.GoodsTotal, nullInt32(p.CustomFee),
 		).
-		Suffix("ON CONFLICT (order_uid) DO NOTHING").
+		Suffix(`ON CONFLICT (order_uid) DO UPDATE SET
+			transaction = EXCLUDED.transaction, request_id = EXCLUDED.request_id, currency = EXCLUDED.currency,
+			provider = EXCLUDED.provider, amount = EXCLUDED.amount, payment_dt = EXCLUDED.payment_dt,
+			bank = EXCLUDED.bank, delivery_cost = EXCLUDED.delivery_cost, goods_total = EXCLUDED.goods_total,
+			custom_fee = EXCLUDED.custom_fee`).
 		MustSql()
 
 	_, err := r.execContext(ctx, query, args...)
@@ -282,6 +524,16 @@ func (r *PostgresRepo) SaveItems(ctx context.Context, orderUID string, items []e
 	return nil
 }
 
+// DeleteItems удаляет товары заказа, перед заменой заказа товары сохраняются заново
+func (r *PostgresRepo) DeleteItems(ctx context.Context, orderUID string) error {
+	query, args := r.qb.Delete("items").Where(sq.Eq{"order_uid": orderUID}).MustSql()
+
+	if _, err := r.execContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to delete items: %w", err)
+	}
+	return nil
+}
+
 func nullString(s string) sql.NullString {
 	if s == "" {
 		return sql.NullString{}
@@ -0,0 +1,294 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/lib/pq"
+)
+
+var webhookColumns = []string{
+	"id", "url", "secret", "events", "enabled", "failure_count", "created_at", "disabled_at",
+}
+
+func (r *PostgresRepo) CreateWebhook(ctx context.Context, w entities.Webhook) (entities.Webhook, error) {
+	events := w.Events
+	if events == nil {
+		events = []string{}
+	}
+	query, args := r.qb.Insert("webhooks").
+		Columns("url", "secret", "events").
+		Values(w.URL, w.Secret, pq.Array(events)).
+		Suffix("RETURNING " + strings.Join(webhookColumns, ", ")).
+		MustSql()
+
+	var created Webhook
+	if err := r.getContext(ctx, &created, query, args...); err != nil {
+		return entities.Webhook{}, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return WebhookToEntity(created), nil
+}
+
+func (r *PostgresRepo) ListWebhooks(ctx context.Context) ([]entities.Webhook, error) {
+	query, args := r.qb.Select(webhookColumns...).
+		From("webhooks").
+		OrderBy("id").
+		MustSql()
+
+	return r.selectWebhooks(ctx, query, args...)
+}
+
+// ListEnabledWebhooks возвращает включенные вебхуки, подписанные на событие
+func (r *PostgresRepo) ListEnabledWebhooks(ctx context.Context, event string) ([]entities.Webhook, error) {
+	query, args := r.qb.Select(webhookColumns...).
+		From("webhooks").
+		Where(sq.Eq{"enabled": true}).
+		Where("(cardinality(events) = 0 OR ? = ANY(events))", event).
+		OrderBy("id").
+		MustSql()
+
+	return r.selectWebhooks(ctx, query, args...)
+}
+
+func (r *PostgresRepo) GetWebhook(ctx context.Context, id int64) (entities.Webhook, error) {
+	query, args := r.qb.Select(webhookColumns...).
+		From("webhooks").
+		Where(sq.Eq{"id": id}).
+		MustSql()
+
+	var w Webhook
+	err := r.getContext(ctx, &w, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.Webhook{}, entities.ErrWebhookNotFound
+	}
+	if err != nil {
+		return entities.Webhook{}, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return WebhookToEntity(w), nil
+}
+
+// UpdateWebhook меняет вебхук. Включение сбрасывает счетчик неудачных доставок.
+func (r *PostgresRepo) UpdateWebhook(
+	ctx context.Context, id int64, upd entities.WebhookUpdate,
+) (entities.Webhook, error) {
+	if upd.URL == nil && upd.Events == nil && upd.Enabled == nil {
+		return r.GetWebhook(ctx, id)
+	}
+
+	q := r.qb.Update("webhooks").Where(sq.Eq{"id": id})
+	if upd.URL != nil {
+		q = q.Set("url", *upd.URL)
+	}
+	if upd.Events != nil {
+		events := *upd.Events
+		if events == nil {
+			events = []string{}
+		}
+		q = q.Set("events", pq.Array(events))
+	}
+	if upd.Enabled != nil {
+		if *upd.Enabled {
+			q = q.Set("enabled", true).
+				Set("failure_count", 0).
+				Set("disabled_at", nil)
+		} else {
+			q = q.Set("enabled", false).
+				Set("disabled_at", sq.Expr("COALESCE(disabled_at, now())"))
+		}
+	}
+	query, args := q.Suffix("RETURNING " + strings.Join(webhookColumns, ", ")).MustSql()
+
+	var w Webhook
+	err := r.getContext(ctx, &w, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.Webhook{}, entities.ErrWebhookNotFound
+	}
+	if err != nil {
+		return entities.Webhook{}, fmt.Errorf("failed to update webhook: %w", err)
+	}
+	return WebhookToEntity(w), nil
+}
+
+func (r *PostgresRepo) DeleteWebhook(ctx context.Context, id int64) error {
+	query, args := r.qb.Delete("webhooks").
+		Where(sq.Eq{"id": id}).
+		MustSql()
+
+	res, err := r.execContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return entities.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// RecordWebhookSuccess сбрасывает счетчик неудачных доставок
+func (r *PostgresRepo) RecordWebhookSuccess(ctx context.Context, id int64) error {
+	query, args := r.qb.Update("webhooks").
+		Set("failure_count", 0).
+		Where(sq.Eq{"id": id}).
+		Where(sq.Gt{"failure_count": 0}).
+		MustSql()
+
+	if _, err := r.execContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to record webhook success: %w", err)
+	}
+	return nil
+}
+
+// RecordWebhookFailure увеличивает счетчик неудачных доставок и выключает вебхук,
+// когда счетчик достигает disableAfter. Возвращает true, если вебхук выключен.
+func (r *PostgresRepo) RecordWebhookFailure(ctx context.Context, id int64, disableAfter int) (bool, error) {
+	query, args := r.qb.Update("webhooks").
+		Set("failure_count", sq.Expr("failure_count + 1")).
+		Set("enabled", sq.Expr("enabled AND failure_count + 1 < ?", disableAfter)).
+		Set("disabled_at", sq.Expr(
+			"CASE WHEN enabled AND failure_count + 1 >= ? THEN now() ELSE disabled_at END", disableAfter)).
+		Where(sq.Eq{"id": id}).
+		Suffix("RETURNING enabled").
+		MustSql()
+
+	var enabled bool
+	err := r.getContext(ctx, &enabled, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, entities.ErrWebhookNotFound
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to record webhook failure: %w", err)
+	}
+	return !enabled, nil
+}
+
+func (r *PostgresRepo) SaveWebhookDelivery(ctx context.Context, d entities.WebhookDelivery) error {
+	var nextAttemptAt sql.NullTime
+	if !d.NextAttemptAt.IsZero() {
+		nextAttemptAt = sql.NullTime{Time: d.NextAttemptAt, Valid: true}
+	}
+
+	// новая попытка того же события снимает повтор, запланированный предыдущей, в той же команде
+	query, args := r.qb.Insert("webhook_deliveries").
+		Prefix(`WITH done AS (
+			UPDATE webhook_deliveries SET next_attempt_at = NULL, payload = NULL
+			WHERE webhook_id = ? AND event_id = ? AND next_attempt_at IS NOT NULL
+		)`, d.WebhookID, d.EventID).
+		Columns(
+			"webhook_id", "event_id", "event", "order_uid", "attempt", "status_code", "error", "duration_ms",
+			"next_attempt_at", "payload",
+		).
+		Values(
+			d.WebhookID, d.EventID, d.Event, d.OrderUID, d.Attempt,
+			nullInt32(d.StatusCode), nullString(d.Error), d.Duration.Milliseconds(),
+			nextAttemptAt, d.Payload,
+		).
+		MustSql()
+
+	if _, err := r.execContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to save webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// ClaimWebhookRetries забирает до limit повторов, время которых наступило, у включенных вебхуков.
+// Забранный повтор откладывается на lease: другие реплики его не видят, а если реплика остановилась,
+// не успев записать попытку, после lease его заберет любая реплика.
+func (r *PostgresRepo) ClaimWebhookRetries(
+	ctx context.Context, now time.Time, limit int, lease time.Duration,
+) ([]entities.WebhookRetry, error) {
+	due := sq.Select("d.id").
+		From("webhook_deliveries d").
+		Join("webhooks w ON w.id = d.webhook_id").
+		Where(sq.LtOrEq{"d.next_attempt_at": now}).
+		Where("w.enabled").
+		OrderBy("d.next_attempt_at").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE OF d SKIP LOCKED")
+
+	query, args := r.qb.Update("webhook_deliveries d").
+		Set("next_attempt_at", now.Add(lease)).
+		From("webhooks w").
+		Where("w.id = d.webhook_id").
+		Where(sq.Expr("d.id IN (?)", due)).
+		Suffix("RETURNING d.webhook_id, w.url, w.secret, d.event_id, d.event, d.order_uid, d.attempt, d.payload").
+		MustSql()
+
+	var retries []WebhookRetry
+	if err := r.selectContext(ctx, &retries, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to claim webhook retries: %w", err)
+	}
+
+	result := make([]entities.WebhookRetry, len(retries))
+	for i, retry := range retries {
+		result[i] = WebhookRetryToEntity(retry)
+	}
+	return result, nil
+}
+
+// ListWebhookDeliveries возвращает последние попытки доставки, начиная с новых
+func (r *PostgresRepo) ListWebhookDeliveries(
+	ctx context.Context, webhookID int64, limit int,
+) ([]entities.WebhookDelivery, error) {
+	query, args := r.qb.Select(
+		"id", "webhook_id", "event_id", "event", "order_uid", "attempt",
+		"status_code", "error", "duration_ms", "created_at",
+	).
+		From("webhook_deliveries").
+		Where(sq.Eq{"webhook_id": webhookID}).
+		OrderBy("id DESC").
+		Limit(uint64(limit)).
+		MustSql()
+
+	var deliveries []WebhookDelivery
+	if err := r.selectContext(ctx, &deliveries, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to select webhook deliveries: %w", err)
+	}
+
+	result := make([]entities.WebhookDelivery, len(deliveries))
+	for i, d := range deliveries {
+		result[i] = WebhookDeliveryToEntity(d)
+	}
+	return result, nil
+}
+
+func (r *PostgresRepo) selectWebhooks(ctx context.Context, query string, args ...any) ([]entities.Webhook, error) {
+	var webhooks []Webhook
+	if err := r.selectContext(ctx, &webhooks, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to select webhooks: %w", err)
+	}
+
+	result := make([]entities.Webhook, len(webhooks))
+	for i, w := range webhooks {
+		result[i] = WebhookToEntity(w)
+	}
+	return result, nil
+}
+
+// DeleteOldWebhookDeliveries удаляет записи журнала доставок, созданные раньше createdBefore, и возвращает их число.
+// Запись с запланированным повтором хранит его тело, поэтому остается, пока повтор не выполнен.
+func (r *PostgresRepo) DeleteOldWebhookDeliveries(ctx context.Context, createdBefore time.Time) (int64, error) {
+	query, args := r.qb.Delete("webhook_deliveries").
+		Where(sq.Lt{"created_at": createdBefore}).
+		Where(sq.Eq{"next_attempt_at": nil}).
+		MustSql()
+
+	res, err := r.execContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old webhook deliveries: %w", err)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+)
+
+const (
+	// apiKeySize длина ключа в байтах, ключ случайный, поэтому для хранения хватает SHA-256 без соли
+	apiKeySize = 32
+	// apiKeyKeyPrefix префикс ключей кэша с клиентами, найденными по хэшу API ключа
+	apiKeyKeyPrefix = "apikey:"
+)
+
+type APIKeyRepo interface {
+	CreateAPIKey(ctx context.Context, k entities.APIKey) (entities.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]entities.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, hash string) (entities.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) (string, error)
+}
+
+// fileAPIKey ключ из файла AUTH_API_KEYS_FILE
+type fileAPIKey struct {
+	Name   string   `json:"name"`
+	Hash   string   `json:"hash"`
+	Scopes []string `json:"scopes"`
+}
+
+// AuthService проверяет API ключи и JWT и управляет ключами в Postgres
+type AuthService struct {
+	repo APIKeyRepo
+	// cache хранит найденные в Postgres ключи, чтобы не ходить в базу на каждый запрос
+	cache Cache
+	// fileKeys ключи из файла по хэшу
+	fileKeys map[string]entities.Principal
+	tokens   *tokenVerifier
+}
+
+func NewAuthService(cfg config.Auth, repo APIKeyRepo, cache Cache) (*AuthService, error) {
+	fileKeys, err := loadAPIKeys(cfg.APIKeysFile)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := newTokenVerifier(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthService{
+		repo:     repo,
+		cache:    cache,
+		fileKeys: fileKeys,
+		tokens:   tokens,
+	}, nil
+}
+
+// HashAPIKey хэш, под которым ключ хранится в Postgres и в файле ключей
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Authenticate возвращает клиента по учетным данным или ErrUnauthenticated
+func (s *AuthService) Authenticate(ctx context.Context, creds entities.Credentials) (entities.Principal, error) {
+	switch {
+	case creds.APIKey != "":
+		return s.authenticateAPIKey(ctx, creds.APIKey)
+	case creds.Token != "":
+		return s.tokens.verify(creds.Token)
+	default:
+		return entities.Principal{}, entities.ErrUnauthenticated
+	}
+}
+
+func (s *AuthService) authenticateAPIKey(ctx context.Context, key string) (entities.Principal, error) {
+	hash := HashAPIKey(key)
+	if principal, ok := s.fileKeys[hash]; ok {
+		return principal, nil
+	}
+
+	if data, ok := s.cache.Get(apiKeyKeyPrefix + hash); ok {
+		var principal entities.Principal
+		if err := json.Unmarshal(data, &principal); err == nil {
+			return principal, nil
+		}
+	}
+
+	apiKey, err := s.repo.GetAPIKeyByHash(ctx, hash)
+	if errors.Is(err, entities.ErrAPIKeyNotFound) {
+		return entities.Principal{}, entities.ErrUnauthenticated
+	}
+	if err != nil {
+		return entities.Principal{}, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	principal := entities.Principal{Subject: apiKey.Name, Scopes: apiKey.Scopes}
+	if data, err := json.Marshal(principal); err == nil {
+		s.cache.Set(apiKeyKeyPrefix+hash, data)
+	}
+	return principal, nil
+}
+
+// CreateAPIKey создает ключ и возвращает его вместе с самим ключом, который больше нигде не сохраняется
+func (s *AuthService) CreateAPIKey(ctx context.Context, name string, scopes []string) (entities.APIKey, string, error) {
+	raw := make([]byte, apiKeySize)
+	if _, err := rand.Read(raw); err != nil {
+		return entities.APIKey{}, "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	key := hex.EncodeToString(raw)
+
+	apiKey, err := s.repo.CreateAPIKey(ctx, entities.APIKey{Name: name, Hash: HashAPIKey(key), Scopes: scopes})
+	if err != nil {
+		return entities.APIKey{}, "", err
+	}
+	return apiKey, key, nil
+}
+
+func (s *AuthService) ListAPIKeys(ctx context.Context) ([]entities.APIKey, error) {
+	return s.repo.ListAPIKeys(ctx)
+}
+
+// RevokeAPIKey отзывает ключ. Другие реплики перестают принимать его, когда истечет кэш.
+func (s *AuthService) RevokeAPIKey(ctx context.Context, id int64) error {
+	hash, err := s.repo.RevokeAPIKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	s.cache.Delete(apiKeyKeyPrefix + hash)
+	return nil
+}
+
+func loadAPIKeys(path string) (map[string]entities.Principal, error) {
+	keys := make(map[string]entities.Principal)
+	if path == "" {
+		return keys, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read api keys file: %w", err)
+	}
+	var fileKeys []fileAPIKey
+	if err := json.Unmarshal(data, &fileKeys); err != nil {
+		return nil, fmt.Errorf("failed to parse api keys file: %w", err)
+	}
+
+	for i, k := range fileKeys {
+		if k.Name == "" || k.Hash == "" {
+			return nil, fmt.Errorf("api key %d: name and hash are required", i)
+		}
+		if err := checkScopes(k.Scopes); err != nil {
+			return nil, fmt.Errorf("api key %s: %w", k.Name, err)
+		}
+		keys[strings.ToLower(k.Hash)] = entities.Principal{Subject: k.Name, Scopes: k.Scopes}
+	}
+	return keys, nil
+}
+
+func checkScopes(scopes []string) error {
+	for _, scope := range scopes {
+		if !slices.Contains(entities.Scopes, scope) {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+	return nil
+}
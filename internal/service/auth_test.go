@@ -0,0 +1,190 @@
+package service_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/service"
+	mocks "github.com/SergeyBogomolovv/l0-order-service/internal/service/mocks"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthService_APIKey(t *testing.T) {
+	keysFile := filepath.Join(t.TempDir(), "keys.json")
+	fileKeys := `[{"name":"ops","hash":"` + service.HashAPIKey("file-key") + `","scopes":["admin"]}]`
+	require.NoError(t, os.WriteFile(keysFile, []byte(fileKeys), 0o600))
+
+	testCases := []struct {
+		name         string
+		key          string
+		mockBehavior func(repo *mocks.MockAPIKeyRepo, cache *mocks.MockCache)
+		want         entities.Principal
+		wantErr      error
+	}{
+		{
+			name:         "key from file",
+			key:          "file-key",
+			mockBehavior: func(_ *mocks.MockAPIKeyRepo, _ *mocks.MockCache) {},
+			want:         entities.Principal{Subject: "ops", Scopes: []string{entities.ScopeAdmin}},
+		},
+		{
+			name: "key from cache",
+			key:  "db-key",
+			mockBehavior: func(_ *mocks.MockAPIKeyRepo, cache *mocks.MockCache) {
+				cache.EXPECT().Get("apikey:"+service.HashAPIKey("db-key")).
+					Return([]byte(`{"Subject":"crm","Scopes":["orders:read"]}`), true).Once()
+			},
+			want: entities.Principal{Subject: "crm", Scopes: []string{entities.ScopeOrdersRead}},
+		},
+		{
+			name: "key from db is cached",
+			key:  "db-key",
+			mockBehavior: func(repo *mocks.MockAPIKeyRepo, cache *mocks.MockCache) {
+				hash := service.HashAPIKey("db-key")
+				cache.EXPECT().Get("apikey:"+hash).Return(nil, false).Once()
+				repo.EXPECT().GetAPIKeyByHash(mock.Anything, hash).
+					Return(entities.APIKey{Name: "crm", Scopes: []string{entities.ScopeOrdersRead}}, nil).Once()
+				cache.EXPECT().Set("apikey:"+hash, mock.Anything).Once()
+			},
+			want: entities.Principal{Subject: "crm", Scopes: []string{entities.ScopeOrdersRead}},
+		},
+		{
+			name: "unknown key",
+			key:  "unknown",
+			mockBehavior: func(repo *mocks.MockAPIKeyRepo, cache *mocks.MockCache) {
+				cache.EXPECT().Get(mock.Anything).Return(nil, false).Once()
+				repo.EXPECT().GetAPIKeyByHash(mock.Anything, mock.Anything).
+					Return(entities.APIKey{}, entities.ErrAPIKeyNotFound).Once()
+			},
+			wantErr: entities.ErrUnauthenticated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := mocks.NewMockAPIKeyRepo(t)
+			cache := mocks.NewMockCache(t)
+			tc.mockBehavior(repo, cache)
+
+			cfg := config.Auth{Enabled: true, APIKeysFile: keysFile}
+			svc, err := service.NewAuthService(cfg, repo, cache)
+			require.NoError(t, err)
+
+			got, err := svc.Authenticate(context.Background(), entities.Credentials{APIKey: tc.key})
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestAuthService_RevokeAPIKey(t *testing.T) {
+	repo := mocks.NewMockAPIKeyRepo(t)
+	cache := mocks.NewMockCache(t)
+	repo.EXPECT().RevokeAPIKey(mock.Anything, int64(1)).Return("hash", nil).Once()
+	cache.EXPECT().Delete("apikey:hash").Once()
+
+	svc, err := service.NewAuthService(config.Auth{Enabled: true}, repo, cache)
+	require.NoError(t, err)
+	assert.NoError(t, svc.RevokeAPIKey(context.Background(), 1))
+}
+
+func TestAuthService_Token(t *testing.T) {
+	secret := []byte("secret")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "k1",
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+	}}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(jwksFile, jwks, 0o600))
+
+	claims := func(exp time.Duration, extra jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{"sub": "crm", "iss": "auth.example.com", "exp": time.Now().Add(exp).Unix()}
+		for k, v := range extra {
+			c[k] = v
+		}
+		return c
+	}
+	hs256 := func(key []byte, c jwt.MapClaims) string {
+		s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString(key)
+		require.NoError(t, err)
+		return s
+	}
+	rs256 := func(kid string, c jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+		token.Header["kid"] = kid
+		s, err := token.SignedString(rsaKey)
+		require.NoError(t, err)
+		return s
+	}
+
+	testCases := []struct {
+		name    string
+		token   string
+		want    entities.Principal
+		wantErr error
+	}{
+		{
+			name:  "hs256 with scope string",
+			token: hs256(secret, claims(time.Hour, jwt.MapClaims{"scope": "orders:read orders:write"})),
+			want:  entities.Principal{Subject: "crm", Scopes: []string{entities.ScopeOrdersRead, entities.ScopeOrdersWrite}},
+		},
+		{
+			name:  "rs256 with scopes array",
+			token: rs256("k1", claims(time.Hour, jwt.MapClaims{"scopes": []string{"admin"}})),
+			want:  entities.Principal{Subject: "crm", Scopes: []string{entities.ScopeAdmin}},
+		},
+		{
+			name:    "expired",
+			token:   hs256(secret, claims(-time.Hour, nil)),
+			wantErr: entities.ErrUnauthenticated,
+		},
+		{
+			name:    "wrong secret",
+			token:   hs256([]byte("other"), claims(time.Hour, nil)),
+			wantErr: entities.ErrUnauthenticated,
+		},
+		{
+			name:    "wrong issuer",
+			token:   hs256(secret, claims(time.Hour, jwt.MapClaims{"iss": "evil.example.com"})),
+			wantErr: entities.ErrUnauthenticated,
+		},
+		{
+			name:    "unknown kid",
+			token:   rs256("k2", claims(time.Hour, nil)),
+			wantErr: entities.ErrUnauthenticated,
+		},
+	}
+
+	cfg := config.Auth{Enabled: true, JWTSecret: string(secret), JWKSFile: jwksFile, JWTIssuer: "auth.example.com"}
+	svc, err := service.NewAuthService(cfg, mocks.NewMockAPIKeyRepo(t), mocks.NewMockCache(t))
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := svc.Authenticate(context.Background(), entities.Credentials{Token: tc.token})
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
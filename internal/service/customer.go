@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/utils"
+)
+
+// customerKeyPrefix префикс ключей кэша со сводками по покупателям
+const customerKeyPrefix = "customer:"
+
+// SummaryCache отдельный кэш сводок, его можно очистить целиком, не трогая заказы
+type SummaryCache interface {
+	Cache
+	Purge()
+}
+
+type CustomerRepo interface {
+	ListOrders(ctx context.Context, filter entities.OrderFilter) ([]entities.Order, error)
+	CustomerSummary(ctx context.Context, customerID string) (entities.CustomerSummary, error)
+}
+
+type CustomerService struct {
+	logger *slog.Logger
+	repo   CustomerRepo
+	// nil если кэширование сводок выключено
+	cache SummaryCache
+}
+
+func NewCustomerService(logger *slog.Logger, repo CustomerRepo, cache SummaryCache) *CustomerService {
+	return &CustomerService{
+		logger: logger.With(slog.String("service", "customer")),
+		repo:   repo,
+		cache:  cache,
+	}
+}
+
+// ListCustomerOrders возвращает страницу заказов покупателя
+func (s *CustomerService) ListCustomerOrders(
+	ctx context.Context, customerID string, filter entities.OrderFilter,
+) (entities.OrderPage, error) {
+	filter.CustomerID = customerID
+	return pageOrders(ctx, s.repo.ListOrders, filter)
+}
+
+// GetCustomerSummary возвращает сводку по заказам покупателя
+func (s *CustomerService) GetCustomerSummary(ctx context.Context, customerID string) (entities.CustomerSummary, error) {
+	key := customerKeyPrefix + customerID
+
+	if s.cache != nil {
+		if data, ok := s.cache.Get(key); ok {
+			s.logger.DebugContext(ctx, "cache hit", "customer_id", customerID)
+			var summary entities.CustomerSummary
+			if err := summary.Unmarshal(data); err != nil {
+				return entities.CustomerSummary{}, fmt.Errorf("failed to unmarshal summary: %w", err)
+			}
+			return summary, nil
+		}
+	}
+
+	var summary entities.CustomerSummary
+	fn := func() error {
+		var err error
+		summary, err = s.repo.CustomerSummary(ctx, customerID)
+		if err != nil {
+			return fmt.Errorf("failed to get summary: %w", err)
+		}
+		return nil
+	}
+	if err := utils.Retry(retryConfig, fn, entities.ErrCustomerNotFound); err != nil {
+		return entities.CustomerSummary{}, fmt.Errorf("failed after retry: %w", err)
+	}
+
+	if s.cache != nil {
+		data, err := summary.Marshal()
+		if err != nil {
+			return entities.CustomerSummary{}, fmt.Errorf("failed to marshal summary: %w", err)
+		}
+		s.cache.Set(key, data)
+	}
+
+	return summary, nil
+}
+
+// Notify сбрасывает сводку покупателя, у которого появился новый заказ. Уведомления приходят через
+// Postgres NOTIFY от реплики, сохранившей заказ, поэтому сводка сбрасывается на всех репликах.
+func (s *CustomerService) Notify(customerID string) {
+	if s.cache != nil {
+		s.cache.Delete(customerKeyPrefix + customerID)
+	}
+}
+
+// NotifyAll очищает все сводки, когда уведомления могли потеряться при переподключении к Postgres
+func (s *CustomerService) NotifyAll() {
+	if s.cache != nil {
+		s.cache.Purge()
+	}
+}
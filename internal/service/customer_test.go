@@ -0,0 +1,112 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/service"
+	mocks "github.com/SergeyBogomolovv/l0-order-service/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomerService_GetCustomerSummary(t *testing.T) {
+	type MockBehavior func(repo *mocks.MockCustomerRepo, cache *mocks.MockSummaryCache)
+
+	summary := entities.CustomerSummary{
+		CustomerID:   "c1",
+		OrderCount:   2,
+		TotalSpent:   map[string]int64{"USD": 300},
+		FirstOrderAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		LastOrderAt:  time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+	data, err := summary.Marshal()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name         string
+		withCache    bool
+		mockBehavior MockBehavior
+		want         entities.CustomerSummary
+		wantErr      error
+	}{
+		{
+			name:      "from cache",
+			withCache: true,
+			mockBehavior: func(_ *mocks.MockCustomerRepo, cache *mocks.MockSummaryCache) {
+				cache.EXPECT().Get("customer:c1").Return(data, true).Once()
+			},
+			want: summary,
+		},
+		{
+			name:      "from repo and set to cache",
+			withCache: true,
+			mockBehavior: func(repo *mocks.MockCustomerRepo, cache *mocks.MockSummaryCache) {
+				cache.EXPECT().Get("customer:c1").Return(nil, false).Once()
+				repo.EXPECT().CustomerSummary(mock.Anything, "c1").Return(summary, nil).Once()
+				cache.EXPECT().Set("customer:c1", data).Return().Once()
+			},
+			want: summary,
+		},
+		{
+			name: "cache disabled",
+			mockBehavior: func(repo *mocks.MockCustomerRepo, _ *mocks.MockSummaryCache) {
+				repo.EXPECT().CustomerSummary(mock.Anything, "c1").Return(summary, nil).Once()
+			},
+			want: summary,
+		},
+		{
+			name: "not found",
+			mockBehavior: func(repo *mocks.MockCustomerRepo, _ *mocks.MockSummaryCache) {
+				repo.EXPECT().CustomerSummary(mock.Anything, "c1").
+					Return(entities.CustomerSummary{}, entities.ErrCustomerNotFound).Once()
+			},
+			wantErr: entities.ErrCustomerNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := mocks.NewMockCustomerRepo(t)
+			cache := mocks.NewMockSummaryCache(t)
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			tc.mockBehavior(repo, cache)
+
+			var c service.SummaryCache
+			if tc.withCache {
+				c = cache
+			}
+			svc := service.NewCustomerService(logger, repo, c)
+
+			got, err := svc.GetCustomerSummary(context.Background(), "c1")
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestCustomerService_Notify(t *testing.T) {
+	cache := mocks.NewMockSummaryCache(t)
+	cache.EXPECT().Delete("customer:c1").Return().Once()
+	cache.EXPECT().Purge().Return().Once()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := service.NewCustomerService(logger, mocks.NewMockCustomerRepo(t), cache)
+	svc.Notify("c1")
+	svc.NotifyAll()
+
+	// без кэша уведомления ничего не делают
+	svc = service.NewCustomerService(logger, mocks.NewMockCustomerRepo(t), nil)
+	svc.Notify("c1")
+	svc.NotifyAll()
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+)
+
+const (
+	// feedLoadTimeout сколько лента ждет загрузки пачки заказов из уведомлений
+	feedLoadTimeout = 5 * time.Second
+	// feedBatchSize сколько заказов из накопившихся уведомлений загружается одним запросом
+	feedBatchSize = 100
+	// feedQueueSize сколько уведомлений может ждать загрузки, остальные отбрасываются
+	feedQueueSize = 10000
+)
+
+// OrderLoader загружает заказы, о сохранении которых пришли уведомления
+type OrderLoader interface {
+	GetOrdersByIDs(ctx context.Context, orderUIDs []string) ([]entities.Order, error)
+}
+
+// OrderFeed рассылает сохраненные заказы подписчикам.
+// Заказы приходят через Postgres NOTIFY, который получает каждая реплика, поэтому подписчики всех реплик
+// видят одни и те же события с одинаковыми ID и могут переподключаться к любой из них.
+// Уведомления только ставятся в очередь, заказы из нее загружаются пачками в фоне мимо кэша заказов.
+// Публикация не блокируется: подписчик, который не успевает читать, отключается.
+// Последние события хранятся в кольцевом буфере, чтобы переподключившийся подписчик мог их дочитать.
+type OrderFeed struct {
+	logger *slog.Logger
+	orders OrderLoader
+	queue  chan string
+
+	mu     sync.Mutex
+	subs   map[chan entities.OrderEvent]struct{}
+	buffer int
+
+	// history кольцевой буфер, next - позиция для следующего события
+	history []entities.OrderEvent
+	next    int
+	// seen ID событий в истории, повторное уведомление о том же заказе не рассылается
+	seen map[string]struct{}
+}
+
+func NewOrderFeed(logger *slog.Logger, orders OrderLoader, buffer, history int) *OrderFeed {
+	return &OrderFeed{
+		logger:  logger.With(slog.String("service", "feed")),
+		orders:  orders,
+		queue:   make(chan string, feedQueueSize),
+		subs:    make(map[chan entities.OrderEvent]struct{}),
+		buffer:  buffer,
+		history: make([]entities.OrderEvent, 0, history),
+		seen:    make(map[string]struct{}, history),
+	}
+}
+
+// Start запускает загрузку заказов из очереди уведомлений в фоне до отмены ctx
+func (f *OrderFeed) Start(ctx context.Context) error {
+	go f.run(ctx)
+	return nil
+}
+
+// Subscribe возвращает канал новых заказов и функцию отписки.
+// Если afterID не пустой, сначала в канал попадают события из истории после события afterID.
+// Канал закрывается после отписки или если подписчик отстал больше чем на размер буфера.
+func (f *OrderFeed) Subscribe(afterID string) (<-chan entities.OrderEvent, func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// история копируется под той же блокировкой, что и регистрация, поэтому события не теряются и не дублируются
+	replay := f.since(afterID)
+	ch := make(chan entities.OrderEvent, f.buffer+len(replay))
+	for _, event := range replay {
+		ch <- event
+	}
+	f.subs[ch] = struct{}{}
+
+	return ch, func() { f.unsubscribe(ch) }
+}
+
+// Notify ставит заказ из уведомления о сохранении в очередь загрузки, не дожидаясь ее
+func (f *OrderFeed) Notify(orderUID string) {
+	select {
+	case f.queue <- orderUID:
+	default:
+		feedNotificationsDropped.Inc()
+		f.logger.Warn("feed queue is full, order dropped", slog.String("orderUID", orderUID))
+	}
+}
+
+// NotifyAll ничего не делает: какие заказы сохранены за время разрыва соединения с Postgres, неизвестно,
+// а подписчики потока их не ждут
+func (f *OrderFeed) NotifyAll() {}
+
+func (f *OrderFeed) run(ctx context.Context) {
+	batch := make([]string, 0, feedBatchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case orderUID := <-f.queue:
+			batch = append(batch[:0], orderUID)
+		}
+		// забираем накопившиеся уведомления, не дожидаясь новых
+	drain:
+		for len(batch) < feedBatchSize {
+			select {
+			case orderUID := <-f.queue:
+				batch = append(batch, orderUID)
+			default:
+				break drain
+			}
+		}
+		f.load(ctx, batch)
+	}
+}
+
+// load загружает пачку заказов и рассылает их в порядке уведомлений
+func (f *OrderFeed) load(ctx context.Context, orderUIDs []string) {
+	ctx, cancel := context.WithTimeout(ctx, feedLoadTimeout)
+	defer cancel()
+
+	orders, err := f.orders.GetOrdersByIDs(ctx, orderUIDs)
+	if err != nil {
+		f.logger.Error("failed to load saved orders", slog.Any("error", err), slog.Int("count", len(orderUIDs)))
+		return
+	}
+
+	byUID := make(map[string]entities.Order, len(orders))
+	for _, order := range orders {
+		byUID[order.OrderUID] = order
+	}
+	for _, orderUID := range orderUIDs {
+		order, ok := byUID[orderUID]
+		if !ok {
+			continue
+		}
+		// повторное уведомление в той же пачке не рассылается
+		delete(byUID, orderUID)
+		f.publish(entities.NewOrderEvent(order))
+	}
+}
+
+func (f *OrderFeed) publish(event entities.OrderEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.seen[event.ID]; ok {
+		return
+	}
+	f.remember(event)
+
+	for ch := range f.subs {
+		select {
+		case ch <- event:
+		default:
+			delete(f.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+func (f *OrderFeed) remember(event entities.OrderEvent) {
+	if cap(f.history) == 0 {
+		return
+	}
+	f.seen[event.ID] = struct{}{}
+	if len(f.history) < cap(f.history) {
+		f.history = append(f.history, event)
+		return
+	}
+	delete(f.seen, f.history[f.next].ID)
+	f.history[f.next] = event
+	f.next = (f.next + 1) % len(f.history)
+}
+
+// since возвращает события из истории после события afterID, от старых к новым.
+// Если события afterID в истории уже нет, подписчик отстал больше чем на историю и получит ее целиком.
+func (f *OrderFeed) since(afterID string) []entities.OrderEvent {
+	if afterID == "" {
+		return nil
+	}
+
+	events := make([]entities.OrderEvent, 0, len(f.history))
+	for i := range f.history {
+		event := f.history[(f.next+i)%len(f.history)]
+		if event.ID == afterID {
+			events = events[:0]
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func (f *OrderFeed) unsubscribe(ch chan entities.OrderEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.subs[ch]; ok {
+		delete(f.subs, ch)
+		close(ch)
+	}
+}
@@ -0,0 +1,129 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/service"
+	mocks "github.com/SergeyBogomolovv/l0-order-service/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newFeedTestOrders(t *testing.T, repo *mocks.MockOrderRepo, uids ...string) *mocks.MockOrderRepo {
+	if repo == nil {
+		repo = mocks.NewMockOrderRepo(t)
+	}
+	orders := make(map[string]entities.Order, len(uids))
+	for i, uid := range uids {
+		orders[uid] = entities.Order{OrderUID: uid, DateCreated: time.UnixMicro(int64(i + 1))}
+	}
+	// заказы в ответе перемешаны, как и из базы
+	repo.EXPECT().GetOrdersByIDs(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, ids []string) ([]entities.Order, error) {
+			found := make([]entities.Order, 0, len(ids))
+			for _, uid := range slices.Backward(ids) {
+				if order, ok := orders[uid]; ok {
+					found = append(found, order)
+				}
+			}
+			return found, nil
+		}).Maybe()
+	return repo
+}
+
+func startFeed(t *testing.T, orders service.OrderLoader, buffer, history int) *service.OrderFeed {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	feed := service.NewOrderFeed(logger, orders, buffer, history)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	require.NoError(t, feed.Start(ctx))
+	return feed
+}
+
+func TestOrderFeed(t *testing.T) {
+	repo := mocks.NewMockOrderRepo(t)
+	failed := make(chan struct{})
+	repo.EXPECT().GetOrdersByIDs(mock.Anything, []string{"err"}).
+		RunAndReturn(func(context.Context, []string) ([]entities.Order, error) {
+			close(failed)
+			return nil, errors.New("db error")
+		}).Once()
+	feed := startFeed(t, newFeedTestOrders(t, repo, "1", "2"), 1, 0)
+
+	fast, unsubscribeFast := feed.Subscribe("")
+	defer unsubscribeFast()
+	slow, unsubscribeSlow := feed.Subscribe("")
+	defer unsubscribeSlow()
+
+	// заказы, которые не удалось загрузить, не рассылаются
+	feed.Notify("err")
+	<-failed
+
+	feed.Notify("1")
+	event := <-fast
+	assert.Equal(t, "1-1", event.ID)
+	assert.Equal(t, "1", event.Order.OrderUID)
+
+	// заказ, которого нет в базе, пропускается;
+	// буфер медленного подписчика заполнен, поэтому он отключается
+	feed.Notify("3")
+	feed.Notify("2")
+	assert.Equal(t, "2", (<-fast).Order.OrderUID)
+
+	assert.Equal(t, "1", (<-slow).Order.OrderUID)
+	_, ok := <-slow
+	assert.False(t, ok)
+
+	unsubscribeFast()
+	_, ok = <-fast
+	assert.False(t, ok)
+}
+
+func TestOrderFeed_Resume(t *testing.T) {
+	feed := startFeed(t, newFeedTestOrders(t, nil, "1", "2", "3", "4", "5"), 10, 3)
+	live, unsubscribe := feed.Subscribe("")
+	// повторное уведомление о том же заказе не создает нового события
+	for _, uid := range []string{"1", "2", "3", "3", "4", "5"} {
+		feed.Notify(uid)
+	}
+	var liveIDs []string
+	for event := range live {
+		liveIDs = append(liveIDs, event.ID)
+		if event.ID == "5-5" {
+			unsubscribe()
+		}
+	}
+	assert.Equal(t, []string{"1-1", "2-2", "3-3", "4-4", "5-5"}, liveIDs)
+
+	testCases := []struct {
+		name    string
+		afterID string
+		wantIDs []string
+	}{
+		{name: "no resume"},
+		{name: "resume from history", afterID: "3-3", wantIDs: []string{"4-4", "5-5"}},
+		{name: "evicted events are skipped", afterID: "1-1", wantIDs: []string{"3-3", "4-4", "5-5"}},
+		{name: "up to date", afterID: "5-5"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			events, unsubscribe := feed.Subscribe(tc.afterID)
+			unsubscribe()
+
+			var ids []string
+			for event := range events {
+				ids = append(ids, event.ID)
+			}
+			assert.Equal(t, tc.wantIDs, ids)
+		})
+	}
+}
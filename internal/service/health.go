@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+)
+
+// HealthCheck проверяет одну зависимость, nil если она доступна
+type HealthCheck func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check HealthCheck
+}
+
+// HealthService проверяет, готов ли сервис принимать трафик
+type HealthService struct {
+	timeout      time.Duration
+	checks       []namedCheck
+	shuttingDown atomic.Bool
+}
+
+// NewHealthService каждая проверка ограничена timeout, чтобы зависшая зависимость не задерживала ответ
+func NewHealthService(timeout time.Duration) *HealthService {
+	return &HealthService{timeout: timeout}
+}
+
+// AddCheck добавляет проверку, вызывается до запуска сервера
+func (s *HealthService) AddCheck(name string, check HealthCheck) {
+	s.checks = append(s.checks, namedCheck{name: name, check: check})
+}
+
+// Shutdown переводит сервис в неготовое состояние, чтобы балансировщик перестал слать запросы до остановки сервера
+func (s *HealthService) Shutdown() {
+	s.shuttingDown.Store(true)
+}
+
+// Readiness выполняет проверки параллельно, сервис готов, если прошли все
+func (s *HealthService) Readiness(ctx context.Context) entities.Readiness {
+	if s.shuttingDown.Load() {
+		return entities.Readiness{ShuttingDown: true}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	res := entities.Readiness{Ready: true, Checks: make([]entities.DependencyStatus, len(s.checks))}
+	var wg sync.WaitGroup
+	for i, c := range s.checks {
+		wg.Go(func() {
+			res.Checks[i] = entities.DependencyStatus{Name: c.name, Err: c.check(ctx)}
+		})
+	}
+	wg.Wait()
+
+	for _, c := range res.Checks {
+		if c.Err != nil {
+			res.Ready = false
+		}
+	}
+	return res
+}
@@ -0,0 +1,75 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthService_Readiness(t *testing.T) {
+	errDown := errors.New("connection refused")
+	ok := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errDown }
+	// зависшая зависимость ограничена таймаутом проверки
+	hanging := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	testCases := []struct {
+		name   string
+		checks map[string]service.HealthCheck
+		want   entities.Readiness
+	}{
+		{
+			name:   "all dependencies are up",
+			checks: map[string]service.HealthCheck{"postgres": ok},
+			want: entities.Readiness{
+				Ready:  true,
+				Checks: []entities.DependencyStatus{{Name: "postgres"}},
+			},
+		},
+		{
+			name:   "dependency is down",
+			checks: map[string]service.HealthCheck{"kafka": down},
+			want: entities.Readiness{
+				Checks: []entities.DependencyStatus{{Name: "kafka", Err: errDown}},
+			},
+		},
+		{
+			name:   "dependency hangs",
+			checks: map[string]service.HealthCheck{"postgres": hanging},
+			want: entities.Readiness{
+				Checks: []entities.DependencyStatus{{Name: "postgres", Err: context.DeadlineExceeded}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := service.NewHealthService(10 * time.Millisecond)
+			for name, check := range tc.checks {
+				svc.AddCheck(name, check)
+			}
+
+			assert.Equal(t, tc.want, svc.Readiness(context.Background()))
+		})
+	}
+}
+
+func TestHealthService_Shutdown(t *testing.T) {
+	svc := service.NewHealthService(time.Second)
+	svc.AddCheck("postgres", func(context.Context) error {
+		t.Fatal("dependencies should not be checked during shutdown")
+		return nil
+	})
+
+	svc.Shutdown()
+
+	assert.Equal(t, entities.Readiness{ShuttingDown: true}, svc.Readiness(context.Background()))
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+)
+
+const (
+	// Через это время незавершенный запрос считается зависшим и ключ можно занять снова
+	idempotencyLockTimeout = time.Minute
+	// idempotencyCleanupInterval как часто удаляются записи ключей старше ttl
+	idempotencyCleanupInterval = 10 * time.Minute
+)
+
+// IdempotencyRepo хранит ключи идемпотентности. Ключ принадлежит клиенту subject, у разных клиентов
+// одинаковые ключи не пересекаются.
+type IdempotencyRepo interface {
+	AcquireIdempotencyKey(
+		ctx context.Context, subject, key, requestHash string, expiredBefore, staleBefore time.Time,
+	) (bool, error)
+	GetIdempotencyKey(ctx context.Context, subject, key string) (entities.IdempotencyKey, error)
+	CompleteIdempotencyKey(ctx context.Context, subject, key string, resp entities.IdempotentResponse) error
+	DeleteIdempotencyKey(ctx context.Context, subject, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, expiredBefore time.Time) (int64, error)
+}
+
+type IdempotencyService struct {
+	logger *slog.Logger
+	repo   IdempotencyRepo
+	ttl    time.Duration
+}
+
+func NewIdempotencyService(logger *slog.Logger, repo IdempotencyRepo, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{
+		logger: logger.With(slog.String("service", "idempotency")),
+		repo:   repo,
+		ttl:    ttl,
+	}
+}
+
+// Begin занимает ключ клиента subject перед выполнением запроса.
+// Если запрос с этим ключом уже был выполнен, возвращает сохраненный ответ.
+func (s *IdempotencyService) Begin(
+	ctx context.Context, subject, key, requestHash string,
+) (*entities.IdempotentResponse, error) {
+	record, err := s.acquire(ctx, subject, key, requestHash)
+	// ключ освободили между попыткой его занять и чтением записи, теперь его можно занять
+	if errors.Is(err, entities.ErrIdempotencyKeyNotFound) {
+		record, err = s.acquire(ctx, subject, key, requestHash)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, nil //nolint:nilnil // nil ответ означает что запрос нужно выполнить
+	}
+
+	if record.RequestHash != requestHash {
+		return nil, entities.ErrIdempotencyKeyMismatch
+	}
+	if !record.Completed {
+		return nil, entities.ErrIdempotencyKeyInUse
+	}
+	return &record.Response, nil
+}
+
+// acquire занимает ключ или, если он уже занят, возвращает его запись
+func (s *IdempotencyService) acquire(
+	ctx context.Context, subject, key, requestHash string,
+) (*entities.IdempotencyKey, error) {
+	now := time.Now()
+	acquired, err := s.repo.AcquireIdempotencyKey(
+		ctx, subject, key, requestHash, now.Add(-s.ttl), now.Add(-idempotencyLockTimeout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire key: %w", err)
+	}
+	if acquired {
+		return nil, nil //nolint:nilnil // ключ занят этим запросом
+	}
+
+	record, err := s.repo.GetIdempotencyKey(ctx, subject, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key: %w", err)
+	}
+	return &record, nil
+}
+
+// Complete сохраняет ответ для повторных запросов с тем же ключом
+func (s *IdempotencyService) Complete(
+	ctx context.Context, subject, key string, resp entities.IdempotentResponse,
+) error {
+	if err := s.repo.CompleteIdempotencyKey(ctx, subject, key, resp); err != nil {
+		return fmt.Errorf("failed to complete key: %w", err)
+	}
+	return nil
+}
+
+// Release освобождает ключ, чтобы запрос можно было повторить, например после внутренней ошибки
+func (s *IdempotencyService) Release(ctx context.Context, subject, key string) error {
+	if err := s.repo.DeleteIdempotencyKey(ctx, subject, key); err != nil {
+		return fmt.Errorf("failed to release key: %w", err)
+	}
+	return nil
+}
+
+// Start периодически удаляет записи ключей старше ttl: их ответы уже не отдаются повторно,
+// а ключ в любом случае можно занять заново
+func (s *IdempotencyService) Start(ctx context.Context) error {
+	go func() {
+		ticker := time.NewTicker(idempotencyCleanupInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.cleanup(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// очистка идет в фоне до отмены ctx, запуск приложения не ждет ее
+	return nil
+}
+
+func (s *IdempotencyService) cleanup(ctx context.Context) {
+	deleted, err := s.repo.DeleteExpiredIdempotencyKeys(ctx, time.Now().Add(-s.ttl))
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.ErrorContext(ctx, "failed to delete expired idempotency keys", slog.Any("error", err))
+		}
+		return
+	}
+	if deleted > 0 {
+		s.logger.DebugContext(ctx, "expired idempotency keys deleted", slog.Int64("count", deleted))
+	}
+}
@@ -0,0 +1,114 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/service"
+	mocks "github.com/SergeyBogomolovv/l0-order-service/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIdempotencyService_Begin(t *testing.T) {
+	type MockBehavior func(repo *mocks.MockIdempotencyRepo)
+
+	stored := entities.IdempotentResponse{StatusCode: 201, Body: []byte(`{"order_uid":"123"}`)}
+
+	testCases := []struct {
+		name         string
+		mockBehavior MockBehavior
+		want         *entities.IdempotentResponse
+		wantErr      error
+	}{
+		{
+			name: "new key",
+			mockBehavior: func(repo *mocks.MockIdempotencyRepo) {
+				repo.EXPECT().
+					AcquireIdempotencyKey(mock.Anything, "client", "key", "hash", mock.Anything, mock.Anything).
+					Return(true, nil).Once()
+			},
+		},
+		{
+			name: "completed request is replayed",
+			mockBehavior: func(repo *mocks.MockIdempotencyRepo) {
+				repo.EXPECT().
+					AcquireIdempotencyKey(mock.Anything, "client", "key", "hash", mock.Anything, mock.Anything).
+					Return(false, nil).Once()
+				repo.EXPECT().GetIdempotencyKey(mock.Anything, "client", "key").
+					Return(entities.IdempotencyKey{Key: "key", RequestHash: "hash", Completed: true, Response: stored}, nil).
+					Once()
+			},
+			want: &stored,
+		},
+		{
+			name: "request in progress",
+			mockBehavior: func(repo *mocks.MockIdempotencyRepo) {
+				repo.EXPECT().
+					AcquireIdempotencyKey(mock.Anything, "client", "key", "hash", mock.Anything, mock.Anything).
+					Return(false, nil).Once()
+				repo.EXPECT().GetIdempotencyKey(mock.Anything, "client", "key").
+					Return(entities.IdempotencyKey{Key: "key", RequestHash: "hash"}, nil).Once()
+			},
+			wantErr: entities.ErrIdempotencyKeyInUse,
+		},
+		{
+			name: "key used with another request",
+			mockBehavior: func(repo *mocks.MockIdempotencyRepo) {
+				repo.EXPECT().
+					AcquireIdempotencyKey(mock.Anything, "client", "key", "hash", mock.Anything, mock.Anything).
+					Return(false, nil).Once()
+				repo.EXPECT().GetIdempotencyKey(mock.Anything, "client", "key").
+					Return(entities.IdempotencyKey{Key: "key", RequestHash: "other", Completed: true}, nil).Once()
+			},
+			wantErr: entities.ErrIdempotencyKeyMismatch,
+		},
+		{
+			name: "key released before it was read",
+			mockBehavior: func(repo *mocks.MockIdempotencyRepo) {
+				repo.EXPECT().
+					AcquireIdempotencyKey(mock.Anything, "client", "key", "hash", mock.Anything, mock.Anything).
+					Return(false, nil).Once()
+				repo.EXPECT().GetIdempotencyKey(mock.Anything, "client", "key").
+					Return(entities.IdempotencyKey{}, entities.ErrIdempotencyKeyNotFound).Once()
+				repo.EXPECT().
+					AcquireIdempotencyKey(mock.Anything, "client", "key", "hash", mock.Anything, mock.Anything).
+					Return(true, nil).Once()
+			},
+		},
+		{
+			name: "repo error",
+			mockBehavior: func(repo *mocks.MockIdempotencyRepo) {
+				repo.EXPECT().
+					AcquireIdempotencyKey(mock.Anything, "client", "key", "hash", mock.Anything, mock.Anything).
+					Return(false, errors.New("db error")).Once()
+			},
+			wantErr: errors.New("failed to acquire key: db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := mocks.NewMockIdempotencyRepo(t)
+			tc.mockBehavior(repo)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			svc := service.NewIdempotencyService(logger, repo, time.Hour)
+
+			got, err := svc.Begin(context.Background(), "client", "key", "hash")
+
+			if tc.wantErr != nil {
+				assert.ErrorContains(t, err, tc.wantErr.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	webhookDeliveries = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "order_service",
+			Subsystem: "webhooks",
+			Name:      "deliveries_total",
+			Help:      "Total number of webhook delivery attempts by result",
+		},
+		[]string{"result"},
+	)
+
+	webhookEventsDropped = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "order_service",
+			Subsystem: "webhooks",
+			Name:      "events_dropped_total",
+			Help:      "Total number of webhook events dropped because the queue was full",
+		},
+	)
+
+	piiAuditRejected = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "order_service",
+			Subsystem: "pii_audit",
+			Name:      "rejected_total",
+			Help:      "Total number of unmasked PII reads refused because the audit queue was full",
+		},
+	)
+
+	piiAuditLost = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "order_service",
+			Subsystem: "pii_audit",
+			Name:      "lost_total",
+			Help:      "Total number of PII audit records that could not be saved after retries",
+		},
+	)
+
+	feedNotificationsDropped = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "order_service",
+			Subsystem: "feed",
+			Name:      "notifications_dropped_total",
+			Help:      "Total number of saved order notifications dropped because the feed queue was full",
+		},
+	)
+
+	webhooksDisabled = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "order_service",
+			Subsystem: "webhooks",
+			Name:      "disabled_total",
+			Help:      "Total number of webhooks disabled after consecutive failed deliveries",
+		},
+	)
+)
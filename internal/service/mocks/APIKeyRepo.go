@@ -0,0 +1,299 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package service
+
+import (
+	"context"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockAPIKeyRepo creates a new instance of MockAPIKeyRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockAPIKeyRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockAPIKeyRepo {
+	mock := &MockAPIKeyRepo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockAPIKeyRepo is an autogenerated mock type for the APIKeyRepo type
+type MockAPIKeyRepo struct {
+	mock.Mock
+}
+
+type MockAPIKeyRepo_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockAPIKeyRepo) EXPECT() *MockAPIKeyRepo_Expecter {
+	return &MockAPIKeyRepo_Expecter{mock: &_m.Mock}
+}
+
+// CreateAPIKey provides a mock function for the type MockAPIKeyRepo
+func (_mock *MockAPIKeyRepo) CreateAPIKey(ctx context.Context, k entities.APIKey) (entities.APIKey, error) {
+	ret := _mock.Called(ctx, k)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAPIKey")
+	}
+
+	var r0 entities.APIKey
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, entities.APIKey) (entities.APIKey, error)); ok {
+		return returnFunc(ctx, k)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, entities.APIKey) entities.APIKey); ok {
+		r0 = returnFunc(ctx, k)
+	} else {
+		r0 = ret.Get(0).(entities.APIKey)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, entities.APIKey) error); ok {
+		r1 = returnFunc(ctx, k)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAPIKeyRepo_CreateAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAPIKey'
+type MockAPIKeyRepo_CreateAPIKey_Call struct {
+	*mock.Call
+}
+
+// CreateAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - k entities.APIKey
+func (_e *MockAPIKeyRepo_Expecter) CreateAPIKey(ctx interface{}, k interface{}) *MockAPIKeyRepo_CreateAPIKey_Call {
+	return &MockAPIKeyRepo_CreateAPIKey_Call{Call: _e.mock.On("CreateAPIKey", ctx, k)}
+}
+
+func (_c *MockAPIKeyRepo_CreateAPIKey_Call) Run(run func(ctx context.Context, k entities.APIKey)) *MockAPIKeyRepo_CreateAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 entities.APIKey
+		if args[1] != nil {
+			arg1 = args[1].(entities.APIKey)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAPIKeyRepo_CreateAPIKey_Call) Return(aPIKey entities.APIKey, err error) *MockAPIKeyRepo_CreateAPIKey_Call {
+	_c.Call.Return(aPIKey, err)
+	return _c
+}
+
+func (_c *MockAPIKeyRepo_CreateAPIKey_Call) RunAndReturn(run func(ctx context.Context, k entities.APIKey) (entities.APIKey, error)) *MockAPIKeyRepo_CreateAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAPIKeyByHash provides a mock function for the type MockAPIKeyRepo
+func (_mock *MockAPIKeyRepo) GetAPIKeyByHash(ctx context.Context, hash string) (entities.APIKey, error) {
+	ret := _mock.Called(ctx, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAPIKeyByHash")
+	}
+
+	var r0 entities.APIKey
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (entities.APIKey, error)); ok {
+		return returnFunc(ctx, hash)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) entities.APIKey); ok {
+		r0 = returnFunc(ctx, hash)
+	} else {
+		r0 = ret.Get(0).(entities.APIKey)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAPIKeyRepo_GetAPIKeyByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAPIKeyByHash'
+type MockAPIKeyRepo_GetAPIKeyByHash_Call struct {
+	*mock.Call
+}
+
+// GetAPIKeyByHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - hash string
+func (_e *MockAPIKeyRepo_Expecter) GetAPIKeyByHash(ctx interface{}, hash interface{}) *MockAPIKeyRepo_GetAPIKeyByHash_Call {
+	return &MockAPIKeyRepo_GetAPIKeyByHash_Call{Call: _e.mock.On("GetAPIKeyByHash", ctx, hash)}
+}
+
+func (_c *MockAPIKeyRepo_GetAPIKeyByHash_Call) Run(run func(ctx context.Context, hash string)) *MockAPIKeyRepo_GetAPIKeyByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAPIKeyRepo_GetAPIKeyByHash_Call) Return(aPIKey entities.APIKey, err error) *MockAPIKeyRepo_GetAPIKeyByHash_Call {
+	_c.Call.Return(aPIKey, err)
+	return _c
+}
+
+func (_c *MockAPIKeyRepo_GetAPIKeyByHash_Call) RunAndReturn(run func(ctx context.Context, hash string) (entities.APIKey, error)) *MockAPIKeyRepo_GetAPIKeyByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAPIKeys provides a mock function for the type MockAPIKeyRepo
+func (_mock *MockAPIKeyRepo) ListAPIKeys(ctx context.Context) ([]entities.APIKey, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAPIKeys")
+	}
+
+	var r0 []entities.APIKey
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]entities.APIKey, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []entities.APIKey); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entities.APIKey)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAPIKeyRepo_ListAPIKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAPIKeys'
+type MockAPIKeyRepo_ListAPIKeys_Call struct {
+	*mock.Call
+}
+
+// ListAPIKeys is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockAPIKeyRepo_Expecter) ListAPIKeys(ctx interface{}) *MockAPIKeyRepo_ListAPIKeys_Call {
+	return &MockAPIKeyRepo_ListAPIKeys_Call{Call: _e.mock.On("ListAPIKeys", ctx)}
+}
+
+func (_c *MockAPIKeyRepo_ListAPIKeys_Call) Run(run func(ctx context.Context)) *MockAPIKeyRepo_ListAPIKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAPIKeyRepo_ListAPIKeys_Call) Return(aPIKeys []entities.APIKey, err error) *MockAPIKeyRepo_ListAPIKeys_Call {
+	_c.Call.Return(aPIKeys, err)
+	return _c
+}
+
+func (_c *MockAPIKeyRepo_ListAPIKeys_Call) RunAndReturn(run func(ctx context.Context) ([]entities.APIKey, error)) *MockAPIKeyRepo_ListAPIKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeAPIKey provides a mock function for the type MockAPIKeyRepo
+func (_mock *MockAPIKeyRepo) RevokeAPIKey(ctx context.Context, id int64) (string, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAPIKey")
+	}
+
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (string, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) string); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAPIKeyRepo_RevokeAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeAPIKey'
+type MockAPIKeyRepo_RevokeAPIKey_Call struct {
+	*mock.Call
+}
+
+// RevokeAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+func (_e *MockAPIKeyRepo_Expecter) RevokeAPIKey(ctx interface{}, id interface{}) *MockAPIKeyRepo_RevokeAPIKey_Call {
+	return &MockAPIKeyRepo_RevokeAPIKey_Call{Call: _e.mock.On("RevokeAPIKey", ctx, id)}
+}
+
+func (_c *MockAPIKeyRepo_RevokeAPIKey_Call) Run(run func(ctx context.Context, id int64)) *MockAPIKeyRepo_RevokeAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int64
+		if args[1] != nil {
+			arg1 = args[1].(int64)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAPIKeyRepo_RevokeAPIKey_Call) Return(s string, err error) *MockAPIKeyRepo_RevokeAPIKey_Call {
+	_c.Call.Return(s, err)
+	return _c
+}
+
+func (_c *MockAPIKeyRepo_RevokeAPIKey_Call) RunAndReturn(run func(ctx context.Context, id int64) (string, error)) *MockAPIKeyRepo_RevokeAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
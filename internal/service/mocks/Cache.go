@@ -35,6 +35,46 @@ func (_m *MockCache) EXPECT() *MockCache_Expecter {
 	return &MockCache_Expecter{mock: &_m.Mock}
 }
 
+// Delete provides a mock function for the type MockCache
+func (_mock *MockCache) Delete(key string) {
+	_mock.Called(key)
+	return
+}
+
+// MockCache_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockCache_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - key string
+func (_e *MockCache_Expecter) Delete(key interface{}) *MockCache_Delete_Call {
+	return &MockCache_Delete_Call{Call: _e.mock.On("Delete", key)}
+}
+
+func (_c *MockCache_Delete_Call) Run(run func(key string)) *MockCache_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCache_Delete_Call) Return() *MockCache_Delete_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockCache_Delete_Call) RunAndReturn(run func(key string)) *MockCache_Delete_Call {
+	_c.Run(run)
+	return _c
+}
+
 // Get provides a mock function for the type MockCache
 func (_mock *MockCache) Get(key string) ([]byte, bool) {
 	ret := _mock.Called(key)
@@ -97,6 +137,39 @@ func (_c *MockCache_Get_Call) RunAndReturn(run func(key string) ([]byte, bool))
 	return _c
 }
 
+// Purge provides a mock function for the type MockCache
+func (_mock *MockCache) Purge() {
+	_mock.Called()
+	return
+}
+
+// MockCache_Purge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Purge'
+type MockCache_Purge_Call struct {
+	*mock.Call
+}
+
+// Purge is a helper method to define mock.On call
+func (_e *MockCache_Expecter) Purge() *MockCache_Purge_Call {
+	return &MockCache_Purge_Call{Call: _e.mock.On("Purge")}
+}
+
+func (_c *MockCache_Purge_Call) Run(run func()) *MockCache_Purge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockCache_Purge_Call) Return() *MockCache_Purge_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockCache_Purge_Call) RunAndReturn(run func()) *MockCache_Purge_Call {
+	_c.Run(run)
+	return _c
+}
+
 // Set provides a mock function for the type MockCache
 func (_mock *MockCache) Set(key string, value []byte) {
 	_mock.Called(key, value)
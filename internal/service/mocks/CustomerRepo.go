@@ -0,0 +1,173 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package service
+
+import (
+	"context"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockCustomerRepo creates a new instance of MockCustomerRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockCustomerRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockCustomerRepo {
+	mock := &MockCustomerRepo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockCustomerRepo is an autogenerated mock type for the CustomerRepo type
+type MockCustomerRepo struct {
+	mock.Mock
+}
+
+type MockCustomerRepo_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockCustomerRepo) EXPECT() *MockCustomerRepo_Expecter {
+	return &MockCustomerRepo_Expecter{mock: &_m.Mock}
+}
+
+// CustomerSummary provides a mock function for the type MockCustomerRepo
+func (_mock *MockCustomerRepo) CustomerSummary(ctx context.Context, customerID string) (entities.CustomerSummary, error) {
+	ret := _mock.Called(ctx, customerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CustomerSummary")
+	}
+
+	var r0 entities.CustomerSummary
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (entities.CustomerSummary, error)); ok {
+		return returnFunc(ctx, customerID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) entities.CustomerSummary); ok {
+		r0 = returnFunc(ctx, customerID)
+	} else {
+		r0 = ret.Get(0).(entities.CustomerSummary)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, customerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCustomerRepo_CustomerSummary_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CustomerSummary'
+type MockCustomerRepo_CustomerSummary_Call struct {
+	*mock.Call
+}
+
+// CustomerSummary is a helper method to define mock.On call
+//   - ctx context.Context
+//   - customerID string
+func (_e *MockCustomerRepo_Expecter) CustomerSummary(ctx interface{}, customerID interface{}) *MockCustomerRepo_CustomerSummary_Call {
+	return &MockCustomerRepo_CustomerSummary_Call{Call: _e.mock.On("CustomerSummary", ctx, customerID)}
+}
+
+func (_c *MockCustomerRepo_CustomerSummary_Call) Run(run func(ctx context.Context, customerID string)) *MockCustomerRepo_CustomerSummary_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCustomerRepo_CustomerSummary_Call) Return(customerSummary entities.CustomerSummary, err error) *MockCustomerRepo_CustomerSummary_Call {
+	_c.Call.Return(customerSummary, err)
+	return _c
+}
+
+func (_c *MockCustomerRepo_CustomerSummary_Call) RunAndReturn(run func(ctx context.Context, customerID string) (entities.CustomerSummary, error)) *MockCustomerRepo_CustomerSummary_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListOrders provides a mock function for the type MockCustomerRepo
+func (_mock *MockCustomerRepo) ListOrders(ctx context.Context, filter entities.OrderFilter) ([]entities.Order, error) {
+	ret := _mock.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOrders")
+	}
+
+	var r0 []entities.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, entities.OrderFilter) ([]entities.Order, error)); ok {
+		return returnFunc(ctx, filter)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, entities.OrderFilter) []entities.Order); ok {
+		r0 = returnFunc(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entities.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, entities.OrderFilter) error); ok {
+		r1 = returnFunc(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCustomerRepo_ListOrders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListOrders'
+type MockCustomerRepo_ListOrders_Call struct {
+	*mock.Call
+}
+
+// ListOrders is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter entities.OrderFilter
+func (_e *MockCustomerRepo_Expecter) ListOrders(ctx interface{}, filter interface{}) *MockCustomerRepo_ListOrders_Call {
+	return &MockCustomerRepo_ListOrders_Call{Call: _e.mock.On("ListOrders", ctx, filter)}
+}
+
+func (_c *MockCustomerRepo_ListOrders_Call) Run(run func(ctx context.Context, filter entities.OrderFilter)) *MockCustomerRepo_ListOrders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 entities.OrderFilter
+		if args[1] != nil {
+			arg1 = args[1].(entities.OrderFilter)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCustomerRepo_ListOrders_Call) Return(orders []entities.Order, err error) *MockCustomerRepo_ListOrders_Call {
+	_c.Call.Return(orders, err)
+	return _c
+}
+
+func (_c *MockCustomerRepo_ListOrders_Call) RunAndReturn(run func(ctx context.Context, filter entities.OrderFilter) ([]entities.Order, error)) *MockCustomerRepo_ListOrders_Call {
+	_c.Call.Return(run)
+	return _c
+}
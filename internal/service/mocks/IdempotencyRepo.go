@@ -0,0 +1,400 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockIdempotencyRepo creates a new instance of MockIdempotencyRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockIdempotencyRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockIdempotencyRepo {
+	mock := &MockIdempotencyRepo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockIdempotencyRepo is an autogenerated mock type for the IdempotencyRepo type
+type MockIdempotencyRepo struct {
+	mock.Mock
+}
+
+type MockIdempotencyRepo_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockIdempotencyRepo) EXPECT() *MockIdempotencyRepo_Expecter {
+	return &MockIdempotencyRepo_Expecter{mock: &_m.Mock}
+}
+
+// AcquireIdempotencyKey provides a mock function for the type MockIdempotencyRepo
+func (_mock *MockIdempotencyRepo) AcquireIdempotencyKey(ctx context.Context, subject string, key string, requestHash string, expiredBefore time.Time, staleBefore time.Time) (bool, error) {
+	ret := _mock.Called(ctx, subject, key, requestHash, expiredBefore, staleBefore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AcquireIdempotencyKey")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, string, time.Time, time.Time) (bool, error)); ok {
+		return returnFunc(ctx, subject, key, requestHash, expiredBefore, staleBefore)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, string, time.Time, time.Time) bool); ok {
+		r0 = returnFunc(ctx, subject, key, requestHash, expiredBefore, staleBefore)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, string, time.Time, time.Time) error); ok {
+		r1 = returnFunc(ctx, subject, key, requestHash, expiredBefore, staleBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockIdempotencyRepo_AcquireIdempotencyKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AcquireIdempotencyKey'
+type MockIdempotencyRepo_AcquireIdempotencyKey_Call struct {
+	*mock.Call
+}
+
+// AcquireIdempotencyKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - subject string
+//   - key string
+//   - requestHash string
+//   - expiredBefore time.Time
+//   - staleBefore time.Time
+func (_e *MockIdempotencyRepo_Expecter) AcquireIdempotencyKey(ctx interface{}, subject interface{}, key interface{}, requestHash interface{}, expiredBefore interface{}, staleBefore interface{}) *MockIdempotencyRepo_AcquireIdempotencyKey_Call {
+	return &MockIdempotencyRepo_AcquireIdempotencyKey_Call{Call: _e.mock.On("AcquireIdempotencyKey", ctx, subject, key, requestHash, expiredBefore, staleBefore)}
+}
+
+func (_c *MockIdempotencyRepo_AcquireIdempotencyKey_Call) Run(run func(ctx context.Context, subject string, key string, requestHash string, expiredBefore time.Time, staleBefore time.Time)) *MockIdempotencyRepo_AcquireIdempotencyKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		var arg4 time.Time
+		if args[4] != nil {
+			arg4 = args[4].(time.Time)
+		}
+		var arg5 time.Time
+		if args[5] != nil {
+			arg5 = args[5].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+			arg5,
+		)
+	})
+	return _c
+}
+
+func (_c *MockIdempotencyRepo_AcquireIdempotencyKey_Call) Return(b bool, err error) *MockIdempotencyRepo_AcquireIdempotencyKey_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockIdempotencyRepo_AcquireIdempotencyKey_Call) RunAndReturn(run func(ctx context.Context, subject string, key string, requestHash string, expiredBefore time.Time, staleBefore time.Time) (bool, error)) *MockIdempotencyRepo_AcquireIdempotencyKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CompleteIdempotencyKey provides a mock function for the type MockIdempotencyRepo
+func (_mock *MockIdempotencyRepo) CompleteIdempotencyKey(ctx context.Context, subject string, key string, resp entities.IdempotentResponse) error {
+	ret := _mock.Called(ctx, subject, key, resp)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CompleteIdempotencyKey")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, entities.IdempotentResponse) error); ok {
+		r0 = returnFunc(ctx, subject, key, resp)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockIdempotencyRepo_CompleteIdempotencyKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CompleteIdempotencyKey'
+type MockIdempotencyRepo_CompleteIdempotencyKey_Call struct {
+	*mock.Call
+}
+
+// CompleteIdempotencyKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - subject string
+//   - key string
+//   - resp entities.IdempotentResponse
+func (_e *MockIdempotencyRepo_Expecter) CompleteIdempotencyKey(ctx interface{}, subject interface{}, key interface{}, resp interface{}) *MockIdempotencyRepo_CompleteIdempotencyKey_Call {
+	return &MockIdempotencyRepo_CompleteIdempotencyKey_Call{Call: _e.mock.On("CompleteIdempotencyKey", ctx, subject, key, resp)}
+}
+
+func (_c *MockIdempotencyRepo_CompleteIdempotencyKey_Call) Run(run func(ctx context.Context, subject string, key string, resp entities.IdempotentResponse)) *MockIdempotencyRepo_CompleteIdempotencyKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 entities.IdempotentResponse
+		if args[3] != nil {
+			arg3 = args[3].(entities.IdempotentResponse)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockIdempotencyRepo_CompleteIdempotencyKey_Call) Return(err error) *MockIdempotencyRepo_CompleteIdempotencyKey_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockIdempotencyRepo_CompleteIdempotencyKey_Call) RunAndReturn(run func(ctx context.Context, subject string, key string, resp entities.IdempotentResponse) error) *MockIdempotencyRepo_CompleteIdempotencyKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteExpiredIdempotencyKeys provides a mock function for the type MockIdempotencyRepo
+func (_mock *MockIdempotencyRepo) DeleteExpiredIdempotencyKeys(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	ret := _mock.Called(ctx, expiredBefore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpiredIdempotencyKeys")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return returnFunc(ctx, expiredBefore)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = returnFunc(ctx, expiredBefore)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = returnFunc(ctx, expiredBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockIdempotencyRepo_DeleteExpiredIdempotencyKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteExpiredIdempotencyKeys'
+type MockIdempotencyRepo_DeleteExpiredIdempotencyKeys_Call struct {
+	*mock.Call
+}
+
+// DeleteExpiredIdempotencyKeys is a helper method to define mock.On call
+//   - ctx context.Context
+//   - expiredBefore time.Time
+func (_e *MockIdempotencyRepo_Expecter) DeleteExpiredIdempotencyKeys(ctx interface{}, expiredBefore interface{}) *MockIdempotencyRepo_DeleteExpiredIdempotencyKeys_Call {
+	return &MockIdempotencyRepo_DeleteExpiredIdempotencyKeys_Call{Call: _e.mock.On("DeleteExpiredIdempotencyKeys", ctx, expiredBefore)}
+}
+
+func (_c *MockIdempotencyRepo_DeleteExpiredIdempotencyKeys_Call) Run(run func(ctx context.Context, expiredBefore time.Time)) *MockIdempotencyRepo_DeleteExpiredIdempotencyKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 time.Time
+		if args[1] != nil {
+			arg1 = args[1].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockIdempotencyRepo_DeleteExpiredIdempotencyKeys_Call) Return(n int64, err error) *MockIdempotencyRepo_DeleteExpiredIdempotencyKeys_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockIdempotencyRepo_DeleteExpiredIdempotencyKeys_Call) RunAndReturn(run func(ctx context.Context, expiredBefore time.Time) (int64, error)) *MockIdempotencyRepo_DeleteExpiredIdempotencyKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteIdempotencyKey provides a mock function for the type MockIdempotencyRepo
+func (_mock *MockIdempotencyRepo) DeleteIdempotencyKey(ctx context.Context, subject string, key string) error {
+	ret := _mock.Called(ctx, subject, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteIdempotencyKey")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, subject, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockIdempotencyRepo_DeleteIdempotencyKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteIdempotencyKey'
+type MockIdempotencyRepo_DeleteIdempotencyKey_Call struct {
+	*mock.Call
+}
+
+// DeleteIdempotencyKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - subject string
+//   - key string
+func (_e *MockIdempotencyRepo_Expecter) DeleteIdempotencyKey(ctx interface{}, subject interface{}, key interface{}) *MockIdempotencyRepo_DeleteIdempotencyKey_Call {
+	return &MockIdempotencyRepo_DeleteIdempotencyKey_Call{Call: _e.mock.On("DeleteIdempotencyKey", ctx, subject, key)}
+}
+
+func (_c *MockIdempotencyRepo_DeleteIdempotencyKey_Call) Run(run func(ctx context.Context, subject string, key string)) *MockIdempotencyRepo_DeleteIdempotencyKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockIdempotencyRepo_DeleteIdempotencyKey_Call) Return(err error) *MockIdempotencyRepo_DeleteIdempotencyKey_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockIdempotencyRepo_DeleteIdempotencyKey_Call) RunAndReturn(run func(ctx context.Context, subject string, key string) error) *MockIdempotencyRepo_DeleteIdempotencyKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetIdempotencyKey provides a mock function for the type MockIdempotencyRepo
+func (_mock *MockIdempotencyRepo) GetIdempotencyKey(ctx context.Context, subject string, key string) (entities.IdempotencyKey, error) {
+	ret := _mock.Called(ctx, subject, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetIdempotencyKey")
+	}
+
+	var r0 entities.IdempotencyKey
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (entities.IdempotencyKey, error)); ok {
+		return returnFunc(ctx, subject, key)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) entities.IdempotencyKey); ok {
+		r0 = returnFunc(ctx, subject, key)
+	} else {
+		r0 = ret.Get(0).(entities.IdempotencyKey)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(ctx, subject, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockIdempotencyRepo_GetIdempotencyKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetIdempotencyKey'
+type MockIdempotencyRepo_GetIdempotencyKey_Call struct {
+	*mock.Call
+}
+
+// GetIdempotencyKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - subject string
+//   - key string
+func (_e *MockIdempotencyRepo_Expecter) GetIdempotencyKey(ctx interface{}, subject interface{}, key interface{}) *MockIdempotencyRepo_GetIdempotencyKey_Call {
+	return &MockIdempotencyRepo_GetIdempotencyKey_Call{Call: _e.mock.On("GetIdempotencyKey", ctx, subject, key)}
+}
+
+func (_c *MockIdempotencyRepo_GetIdempotencyKey_Call) Run(run func(ctx context.Context, subject string, key string)) *MockIdempotencyRepo_GetIdempotencyKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockIdempotencyRepo_GetIdempotencyKey_Call) Return(idempotencyKey entities.IdempotencyKey, err error) *MockIdempotencyRepo_GetIdempotencyKey_Call {
+	_c.Call.Return(idempotencyKey, err)
+	return _c
+}
+
+func (_c *MockIdempotencyRepo_GetIdempotencyKey_Call) RunAndReturn(run func(ctx context.Context, subject string, key string) (entities.IdempotencyKey, error)) *MockIdempotencyRepo_GetIdempotencyKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
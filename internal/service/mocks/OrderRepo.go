@@ -38,26 +38,146 @@ func (_m *MockOrderRepo) EXPECT() *MockOrderRepo_Expecter {
 	return &MockOrderRepo_Expecter{mock: &_m.Mock}
 }
 
-// GetOrderByID provides a mock function for the type MockOrderRepo
-func (_mock *MockOrderRepo) GetOrderByID(ctx context.Context, orderUID string) (entities.Order, error) {
+// DeleteItems provides a mock function for the type MockOrderRepo
+func (_mock *MockOrderRepo) DeleteItems(ctx context.Context, orderUID string) error {
 	ret := _mock.Called(ctx, orderUID)
 
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteItems")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, orderUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockOrderRepo_DeleteItems_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteItems'
+type MockOrderRepo_DeleteItems_Call struct {
+	*mock.Call
+}
+
+// DeleteItems is a helper method to define mock.On call
+//   - ctx context.Context
+//   - orderUID string
+func (_e *MockOrderRepo_Expecter) DeleteItems(ctx interface{}, orderUID interface{}) *MockOrderRepo_DeleteItems_Call {
+	return &MockOrderRepo_DeleteItems_Call{Call: _e.mock.On("DeleteItems", ctx, orderUID)}
+}
+
+func (_c *MockOrderRepo_DeleteItems_Call) Run(run func(ctx context.Context, orderUID string)) *MockOrderRepo_DeleteItems_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepo_DeleteItems_Call) Return(err error) *MockOrderRepo_DeleteItems_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockOrderRepo_DeleteItems_Call) RunAndReturn(run func(ctx context.Context, orderUID string) error) *MockOrderRepo_DeleteItems_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExportOrders provides a mock function for the type MockOrderRepo
+func (_mock *MockOrderRepo) ExportOrders(ctx context.Context, filter entities.OrderFilter, fn func(orders []entities.Order) error) error {
+	ret := _mock.Called(ctx, filter, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportOrders")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, entities.OrderFilter, func(orders []entities.Order) error) error); ok {
+		r0 = returnFunc(ctx, filter, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockOrderRepo_ExportOrders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportOrders'
+type MockOrderRepo_ExportOrders_Call struct {
+	*mock.Call
+}
+
+// ExportOrders is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter entities.OrderFilter
+//   - fn func(orders []entities.Order) error
+func (_e *MockOrderRepo_Expecter) ExportOrders(ctx interface{}, filter interface{}, fn interface{}) *MockOrderRepo_ExportOrders_Call {
+	return &MockOrderRepo_ExportOrders_Call{Call: _e.mock.On("ExportOrders", ctx, filter, fn)}
+}
+
+func (_c *MockOrderRepo_ExportOrders_Call) Run(run func(ctx context.Context, filter entities.OrderFilter, fn func(orders []entities.Order) error)) *MockOrderRepo_ExportOrders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 entities.OrderFilter
+		if args[1] != nil {
+			arg1 = args[1].(entities.OrderFilter)
+		}
+		var arg2 func(orders []entities.Order) error
+		if args[2] != nil {
+			arg2 = args[2].(func(orders []entities.Order) error)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepo_ExportOrders_Call) Return(err error) *MockOrderRepo_ExportOrders_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockOrderRepo_ExportOrders_Call) RunAndReturn(run func(ctx context.Context, filter entities.OrderFilter, fn func(orders []entities.Order) error) error) *MockOrderRepo_ExportOrders_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOrderByID provides a mock function for the type MockOrderRepo
+func (_mock *MockOrderRepo) GetOrderByID(ctx context.Context, orderUID string, withItems bool) (entities.Order, error) {
+	ret := _mock.Called(ctx, orderUID, withItems)
+
 	if len(ret) == 0 {
 		panic("no return value specified for GetOrderByID")
 	}
 
 	var r0 entities.Order
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (entities.Order, error)); ok {
-		return returnFunc(ctx, orderUID)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, bool) (entities.Order, error)); ok {
+		return returnFunc(ctx, orderUID, withItems)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) entities.Order); ok {
-		r0 = returnFunc(ctx, orderUID)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, bool) entities.Order); ok {
+		r0 = returnFunc(ctx, orderUID, withItems)
 	} else {
 		r0 = ret.Get(0).(entities.Order)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = returnFunc(ctx, orderUID)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = returnFunc(ctx, orderUID, withItems)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -72,11 +192,12 @@ type MockOrderRepo_GetOrderByID_Call struct {
 // GetOrderByID is a helper method to define mock.On call
 //   - ctx context.Context
 //   - orderUID string
-func (_e *MockOrderRepo_Expecter) GetOrderByID(ctx interface{}, orderUID interface{}) *MockOrderRepo_GetOrderByID_Call {
-	return &MockOrderRepo_GetOrderByID_Call{Call: _e.mock.On("GetOrderByID", ctx, orderUID)}
+//   - withItems bool
+func (_e *MockOrderRepo_Expecter) GetOrderByID(ctx interface{}, orderUID interface{}, withItems interface{}) *MockOrderRepo_GetOrderByID_Call {
+	return &MockOrderRepo_GetOrderByID_Call{Call: _e.mock.On("GetOrderByID", ctx, orderUID, withItems)}
 }
 
-func (_c *MockOrderRepo_GetOrderByID_Call) Run(run func(ctx context.Context, orderUID string)) *MockOrderRepo_GetOrderByID_Call {
+func (_c *MockOrderRepo_GetOrderByID_Call) Run(run func(ctx context.Context, orderUID string, withItems bool)) *MockOrderRepo_GetOrderByID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -86,9 +207,14 @@ func (_c *MockOrderRepo_GetOrderByID_Call) Run(run func(ctx context.Context, ord
 		if args[1] != nil {
 			arg1 = args[1].(string)
 		}
+		var arg2 bool
+		if args[2] != nil {
+			arg2 = args[2].(bool)
+		}
 		run(
 			arg0,
 			arg1,
+			arg2,
 		)
 	})
 	return _c
@@ -99,7 +225,221 @@ func (_c *MockOrderRepo_GetOrderByID_Call) Return(order entities.Order, err erro
 	return _c
 }
 
-func (_c *MockOrderRepo_GetOrderByID_Call) RunAndReturn(run func(ctx context.Context, orderUID string) (entities.Order, error)) *MockOrderRepo_GetOrderByID_Call {
+func (_c *MockOrderRepo_GetOrderByID_Call) RunAndReturn(run func(ctx context.Context, orderUID string, withItems bool) (entities.Order, error)) *MockOrderRepo_GetOrderByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOrderUIDByTrackNumber provides a mock function for the type MockOrderRepo
+func (_mock *MockOrderRepo) GetOrderUIDByTrackNumber(ctx context.Context, trackNumber string) (string, error) {
+	ret := _mock.Called(ctx, trackNumber)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderUIDByTrackNumber")
+	}
+
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+		return returnFunc(ctx, trackNumber)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = returnFunc(ctx, trackNumber)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, trackNumber)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepo_GetOrderUIDByTrackNumber_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrderUIDByTrackNumber'
+type MockOrderRepo_GetOrderUIDByTrackNumber_Call struct {
+	*mock.Call
+}
+
+// GetOrderUIDByTrackNumber is a helper method to define mock.On call
+//   - ctx context.Context
+//   - trackNumber string
+func (_e *MockOrderRepo_Expecter) GetOrderUIDByTrackNumber(ctx interface{}, trackNumber interface{}) *MockOrderRepo_GetOrderUIDByTrackNumber_Call {
+	return &MockOrderRepo_GetOrderUIDByTrackNumber_Call{Call: _e.mock.On("GetOrderUIDByTrackNumber", ctx, trackNumber)}
+}
+
+func (_c *MockOrderRepo_GetOrderUIDByTrackNumber_Call) Run(run func(ctx context.Context, trackNumber string)) *MockOrderRepo_GetOrderUIDByTrackNumber_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepo_GetOrderUIDByTrackNumber_Call) Return(s string, err error) *MockOrderRepo_GetOrderUIDByTrackNumber_Call {
+	_c.Call.Return(s, err)
+	return _c
+}
+
+func (_c *MockOrderRepo_GetOrderUIDByTrackNumber_Call) RunAndReturn(run func(ctx context.Context, trackNumber string) (string, error)) *MockOrderRepo_GetOrderUIDByTrackNumber_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOrderUIDsByItemTrackNumber provides a mock function for the type MockOrderRepo
+func (_mock *MockOrderRepo) GetOrderUIDsByItemTrackNumber(ctx context.Context, trackNumber string, limit int, after *entities.OrderCursor) ([]entities.OrderCursor, error) {
+	ret := _mock.Called(ctx, trackNumber, limit, after)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderUIDsByItemTrackNumber")
+	}
+
+	var r0 []entities.OrderCursor
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int, *entities.OrderCursor) ([]entities.OrderCursor, error)); ok {
+		return returnFunc(ctx, trackNumber, limit, after)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int, *entities.OrderCursor) []entities.OrderCursor); ok {
+		r0 = returnFunc(ctx, trackNumber, limit, after)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entities.OrderCursor)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, int, *entities.OrderCursor) error); ok {
+		r1 = returnFunc(ctx, trackNumber, limit, after)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepo_GetOrderUIDsByItemTrackNumber_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrderUIDsByItemTrackNumber'
+type MockOrderRepo_GetOrderUIDsByItemTrackNumber_Call struct {
+	*mock.Call
+}
+
+// GetOrderUIDsByItemTrackNumber is a helper method to define mock.On call
+//   - ctx context.Context
+//   - trackNumber string
+//   - limit int
+//   - after *entities.OrderCursor
+func (_e *MockOrderRepo_Expecter) GetOrderUIDsByItemTrackNumber(ctx interface{}, trackNumber interface{}, limit interface{}, after interface{}) *MockOrderRepo_GetOrderUIDsByItemTrackNumber_Call {
+	return &MockOrderRepo_GetOrderUIDsByItemTrackNumber_Call{Call: _e.mock.On("GetOrderUIDsByItemTrackNumber", ctx, trackNumber, limit, after)}
+}
+
+func (_c *MockOrderRepo_GetOrderUIDsByItemTrackNumber_Call) Run(run func(ctx context.Context, trackNumber string, limit int, after *entities.OrderCursor)) *MockOrderRepo_GetOrderUIDsByItemTrackNumber_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		var arg3 *entities.OrderCursor
+		if args[3] != nil {
+			arg3 = args[3].(*entities.OrderCursor)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepo_GetOrderUIDsByItemTrackNumber_Call) Return(orderCursors []entities.OrderCursor, err error) *MockOrderRepo_GetOrderUIDsByItemTrackNumber_Call {
+	_c.Call.Return(orderCursors, err)
+	return _c
+}
+
+func (_c *MockOrderRepo_GetOrderUIDsByItemTrackNumber_Call) RunAndReturn(run func(ctx context.Context, trackNumber string, limit int, after *entities.OrderCursor) ([]entities.OrderCursor, error)) *MockOrderRepo_GetOrderUIDsByItemTrackNumber_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOrdersByIDs provides a mock function for the type MockOrderRepo
+func (_mock *MockOrderRepo) GetOrdersByIDs(ctx context.Context, orderUIDs []string) ([]entities.Order, error) {
+	ret := _mock.Called(ctx, orderUIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrdersByIDs")
+	}
+
+	var r0 []entities.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) ([]entities.Order, error)); ok {
+		return returnFunc(ctx, orderUIDs)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) []entities.Order); ok {
+		r0 = returnFunc(ctx, orderUIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entities.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = returnFunc(ctx, orderUIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepo_GetOrdersByIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrdersByIDs'
+type MockOrderRepo_GetOrdersByIDs_Call struct {
+	*mock.Call
+}
+
+// GetOrdersByIDs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - orderUIDs []string
+func (_e *MockOrderRepo_Expecter) GetOrdersByIDs(ctx interface{}, orderUIDs interface{}) *MockOrderRepo_GetOrdersByIDs_Call {
+	return &MockOrderRepo_GetOrdersByIDs_Call{Call: _e.mock.On("GetOrdersByIDs", ctx, orderUIDs)}
+}
+
+func (_c *MockOrderRepo_GetOrdersByIDs_Call) Run(run func(ctx context.Context, orderUIDs []string)) *MockOrderRepo_GetOrdersByIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []string
+		if args[1] != nil {
+			arg1 = args[1].([]string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepo_GetOrdersByIDs_Call) Return(orders []entities.Order, err error) *MockOrderRepo_GetOrdersByIDs_Call {
+	_c.Call.Return(orders, err)
+	return _c
+}
+
+func (_c *MockOrderRepo_GetOrdersByIDs_Call) RunAndReturn(run func(ctx context.Context, orderUIDs []string) ([]entities.Order, error)) *MockOrderRepo_GetOrdersByIDs_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -172,6 +512,74 @@ func (_c *MockOrderRepo_LatestOrders_Call) RunAndReturn(run func(ctx context.Con
 	return _c
 }
 
+// ListOrders provides a mock function for the type MockOrderRepo
+func (_mock *MockOrderRepo) ListOrders(ctx context.Context, filter entities.OrderFilter) ([]entities.Order, error) {
+	ret := _mock.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOrders")
+	}
+
+	var r0 []entities.Order
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, entities.OrderFilter) ([]entities.Order, error)); ok {
+		return returnFunc(ctx, filter)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, entities.OrderFilter) []entities.Order); ok {
+		r0 = returnFunc(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entities.Order)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, entities.OrderFilter) error); ok {
+		r1 = returnFunc(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrderRepo_ListOrders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListOrders'
+type MockOrderRepo_ListOrders_Call struct {
+	*mock.Call
+}
+
+// ListOrders is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter entities.OrderFilter
+func (_e *MockOrderRepo_Expecter) ListOrders(ctx interface{}, filter interface{}) *MockOrderRepo_ListOrders_Call {
+	return &MockOrderRepo_ListOrders_Call{Call: _e.mock.On("ListOrders", ctx, filter)}
+}
+
+func (_c *MockOrderRepo_ListOrders_Call) Run(run func(ctx context.Context, filter entities.OrderFilter)) *MockOrderRepo_ListOrders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 entities.OrderFilter
+		if args[1] != nil {
+			arg1 = args[1].(entities.OrderFilter)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrderRepo_ListOrders_Call) Return(orders []entities.Order, err error) *MockOrderRepo_ListOrders_Call {
+	_c.Call.Return(orders, err)
+	return _c
+}
+
+func (_c *MockOrderRepo_ListOrders_Call) RunAndReturn(run func(ctx context.Context, filter entities.OrderFilter) ([]entities.Order, error)) *MockOrderRepo_ListOrders_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SaveDelivery provides a mock function for the type MockOrderRepo
 func (_mock *MockOrderRepo) SaveDelivery(ctx context.Context, orderUID string, d entities.Delivery) error {
 	ret := _mock.Called(ctx, orderUID, d)
@@ -299,20 +707,29 @@ func (_c *MockOrderRepo_SaveItems_Call) RunAndReturn(run func(ctx context.Contex
 }
 
 // SaveOrder provides a mock function for the type MockOrderRepo
-func (_mock *MockOrderRepo) SaveOrder(ctx context.Context, o entities.Order) error {
+func (_mock *MockOrderRepo) SaveOrder(ctx context.Context, o entities.Order) (entities.OrderSaveResult, error) {
 	ret := _mock.Called(ctx, o)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SaveOrder")
 	}
 
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, entities.Order) error); ok {
+	var r0 entities.OrderSaveResult
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, entities.Order) (entities.OrderSaveResult, error)); ok {
+		return returnFunc(ctx, o)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, entities.Order) entities.OrderSaveResult); ok {
 		r0 = returnFunc(ctx, o)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(entities.OrderSaveResult)
 	}
-	return r0
+	if returnFunc, ok := ret.Get(1).(func(context.Context, entities.Order) error); ok {
+		r1 = returnFunc(ctx, o)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
 }
 
 // MockOrderRepo_SaveOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveOrder'
@@ -345,12 +762,12 @@ func (_c *MockOrderRepo_SaveOrder_Call) Run(run func(ctx context.Context, o enti
 	return _c
 }
 
-func (_c *MockOrderRepo_SaveOrder_Call) Return(err error) *MockOrderRepo_SaveOrder_Call {
-	_c.Call.Return(err)
+func (_c *MockOrderRepo_SaveOrder_Call) Return(orderSaveResult entities.OrderSaveResult, err error) *MockOrderRepo_SaveOrder_Call {
+	_c.Call.Return(orderSaveResult, err)
 	return _c
 }
 
-func (_c *MockOrderRepo_SaveOrder_Call) RunAndReturn(run func(ctx context.Context, o entities.Order) error) *MockOrderRepo_SaveOrder_Call {
+func (_c *MockOrderRepo_SaveOrder_Call) RunAndReturn(run func(ctx context.Context, o entities.Order) (entities.OrderSaveResult, error)) *MockOrderRepo_SaveOrder_Call {
 	_c.Call.Return(run)
 	return _c
 }
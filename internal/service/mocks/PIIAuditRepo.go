@@ -0,0 +1,96 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package service
+
+import (
+	"context"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockPIIAuditRepo creates a new instance of MockPIIAuditRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockPIIAuditRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockPIIAuditRepo {
+	mock := &MockPIIAuditRepo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockPIIAuditRepo is an autogenerated mock type for the PIIAuditRepo type
+type MockPIIAuditRepo struct {
+	mock.Mock
+}
+
+type MockPIIAuditRepo_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockPIIAuditRepo) EXPECT() *MockPIIAuditRepo_Expecter {
+	return &MockPIIAuditRepo_Expecter{mock: &_m.Mock}
+}
+
+// SavePIIAccesses provides a mock function for the type MockPIIAuditRepo
+func (_mock *MockPIIAuditRepo) SavePIIAccesses(ctx context.Context, accesses []entities.PIIAccess) error {
+	ret := _mock.Called(ctx, accesses)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SavePIIAccesses")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []entities.PIIAccess) error); ok {
+		r0 = returnFunc(ctx, accesses)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockPIIAuditRepo_SavePIIAccesses_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SavePIIAccesses'
+type MockPIIAuditRepo_SavePIIAccesses_Call struct {
+	*mock.Call
+}
+
+// SavePIIAccesses is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accesses []entities.PIIAccess
+func (_e *MockPIIAuditRepo_Expecter) SavePIIAccesses(ctx interface{}, accesses interface{}) *MockPIIAuditRepo_SavePIIAccesses_Call {
+	return &MockPIIAuditRepo_SavePIIAccesses_Call{Call: _e.mock.On("SavePIIAccesses", ctx, accesses)}
+}
+
+func (_c *MockPIIAuditRepo_SavePIIAccesses_Call) Run(run func(ctx context.Context, accesses []entities.PIIAccess)) *MockPIIAuditRepo_SavePIIAccesses_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []entities.PIIAccess
+		if args[1] != nil {
+			arg1 = args[1].([]entities.PIIAccess)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPIIAuditRepo_SavePIIAccesses_Call) Return(err error) *MockPIIAuditRepo_SavePIIAccesses_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockPIIAuditRepo_SavePIIAccesses_Call) RunAndReturn(run func(ctx context.Context, accesses []entities.PIIAccess) error) *MockPIIAuditRepo_SavePIIAccesses_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -0,0 +1,217 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package service
+
+import (
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockSummaryCache creates a new instance of MockSummaryCache. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockSummaryCache(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockSummaryCache {
+	mock := &MockSummaryCache{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockSummaryCache is an autogenerated mock type for the SummaryCache type
+type MockSummaryCache struct {
+	mock.Mock
+}
+
+type MockSummaryCache_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockSummaryCache) EXPECT() *MockSummaryCache_Expecter {
+	return &MockSummaryCache_Expecter{mock: &_m.Mock}
+}
+
+// Delete provides a mock function for the type MockSummaryCache
+func (_mock *MockSummaryCache) Delete(key string) {
+	_mock.Called(key)
+	return
+}
+
+// MockSummaryCache_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockSummaryCache_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - key string
+func (_e *MockSummaryCache_Expecter) Delete(key interface{}) *MockSummaryCache_Delete_Call {
+	return &MockSummaryCache_Delete_Call{Call: _e.mock.On("Delete", key)}
+}
+
+func (_c *MockSummaryCache_Delete_Call) Run(run func(key string)) *MockSummaryCache_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSummaryCache_Delete_Call) Return() *MockSummaryCache_Delete_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockSummaryCache_Delete_Call) RunAndReturn(run func(key string)) *MockSummaryCache_Delete_Call {
+	_c.Run(run)
+	return _c
+}
+
+// Get provides a mock function for the type MockSummaryCache
+func (_mock *MockSummaryCache) Get(key string) ([]byte, bool) {
+	ret := _mock.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 []byte
+	var r1 bool
+	if returnFunc, ok := ret.Get(0).(func(string) ([]byte, bool)); ok {
+		return returnFunc(key)
+	}
+	if returnFunc, ok := ret.Get(0).(func(string) []byte); ok {
+		r0 = returnFunc(key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = returnFunc(key)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+	return r0, r1
+}
+
+// MockSummaryCache_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockSummaryCache_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - key string
+func (_e *MockSummaryCache_Expecter) Get(key interface{}) *MockSummaryCache_Get_Call {
+	return &MockSummaryCache_Get_Call{Call: _e.mock.On("Get", key)}
+}
+
+func (_c *MockSummaryCache_Get_Call) Run(run func(key string)) *MockSummaryCache_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSummaryCache_Get_Call) Return(bytes []byte, b bool) *MockSummaryCache_Get_Call {
+	_c.Call.Return(bytes, b)
+	return _c
+}
+
+func (_c *MockSummaryCache_Get_Call) RunAndReturn(run func(key string) ([]byte, bool)) *MockSummaryCache_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Purge provides a mock function for the type MockSummaryCache
+func (_mock *MockSummaryCache) Purge() {
+	_mock.Called()
+	return
+}
+
+// MockSummaryCache_Purge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Purge'
+type MockSummaryCache_Purge_Call struct {
+	*mock.Call
+}
+
+// Purge is a helper method to define mock.On call
+func (_e *MockSummaryCache_Expecter) Purge() *MockSummaryCache_Purge_Call {
+	return &MockSummaryCache_Purge_Call{Call: _e.mock.On("Purge")}
+}
+
+func (_c *MockSummaryCache_Purge_Call) Run(run func()) *MockSummaryCache_Purge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockSummaryCache_Purge_Call) Return() *MockSummaryCache_Purge_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockSummaryCache_Purge_Call) RunAndReturn(run func()) *MockSummaryCache_Purge_Call {
+	_c.Run(run)
+	return _c
+}
+
+// Set provides a mock function for the type MockSummaryCache
+func (_mock *MockSummaryCache) Set(key string, value []byte) {
+	_mock.Called(key, value)
+	return
+}
+
+// MockSummaryCache_Set_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Set'
+type MockSummaryCache_Set_Call struct {
+	*mock.Call
+}
+
+// Set is a helper method to define mock.On call
+//   - key string
+//   - value []byte
+func (_e *MockSummaryCache_Expecter) Set(key interface{}, value interface{}) *MockSummaryCache_Set_Call {
+	return &MockSummaryCache_Set_Call{Call: _e.mock.On("Set", key, value)}
+}
+
+func (_c *MockSummaryCache_Set_Call) Run(run func(key string, value []byte)) *MockSummaryCache_Set_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		var arg1 []byte
+		if args[1] != nil {
+			arg1 = args[1].([]byte)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSummaryCache_Set_Call) Return() *MockSummaryCache_Set_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockSummaryCache_Set_Call) RunAndReturn(run func(key string, value []byte)) *MockSummaryCache_Set_Call {
+	_c.Run(run)
+	return _c
+}
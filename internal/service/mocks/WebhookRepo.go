@@ -0,0 +1,837 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockWebhookRepo creates a new instance of MockWebhookRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockWebhookRepo(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockWebhookRepo {
+	mock := &MockWebhookRepo{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockWebhookRepo is an autogenerated mock type for the WebhookRepo type
+type MockWebhookRepo struct {
+	mock.Mock
+}
+
+type MockWebhookRepo_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockWebhookRepo) EXPECT() *MockWebhookRepo_Expecter {
+	return &MockWebhookRepo_Expecter{mock: &_m.Mock}
+}
+
+// ClaimWebhookRetries provides a mock function for the type MockWebhookRepo
+func (_mock *MockWebhookRepo) ClaimWebhookRetries(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]entities.WebhookRetry, error) {
+	ret := _mock.Called(ctx, now, limit, lease)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimWebhookRetries")
+	}
+
+	var r0 []entities.WebhookRetry
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time, int, time.Duration) ([]entities.WebhookRetry, error)); ok {
+		return returnFunc(ctx, now, limit, lease)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time, int, time.Duration) []entities.WebhookRetry); ok {
+		r0 = returnFunc(ctx, now, limit, lease)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entities.WebhookRetry)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, time.Time, int, time.Duration) error); ok {
+		r1 = returnFunc(ctx, now, limit, lease)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockWebhookRepo_ClaimWebhookRetries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ClaimWebhookRetries'
+type MockWebhookRepo_ClaimWebhookRetries_Call struct {
+	*mock.Call
+}
+
+// ClaimWebhookRetries is a helper method to define mock.On call
+//   - ctx context.Context
+//   - now time.Time
+//   - limit int
+//   - lease time.Duration
+func (_e *MockWebhookRepo_Expecter) ClaimWebhookRetries(ctx interface{}, now interface{}, limit interface{}, lease interface{}) *MockWebhookRepo_ClaimWebhookRetries_Call {
+	return &MockWebhookRepo_ClaimWebhookRetries_Call{Call: _e.mock.On("ClaimWebhookRetries", ctx, now, limit, lease)}
+}
+
+func (_c *MockWebhookRepo_ClaimWebhookRetries_Call) Run(run func(ctx context.Context, now time.Time, limit int, lease time.Duration)) *MockWebhookRepo_ClaimWebhookRetries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 time.Time
+		if args[1] != nil {
+			arg1 = args[1].(time.Time)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		var arg3 time.Duration
+		if args[3] != nil {
+			arg3 = args[3].(time.Duration)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockWebhookRepo_ClaimWebhookRetries_Call) Return(webhookRetrys []entities.WebhookRetry, err error) *MockWebhookRepo_ClaimWebhookRetries_Call {
+	_c.Call.Return(webhookRetrys, err)
+	return _c
+}
+
+func (_c *MockWebhookRepo_ClaimWebhookRetries_Call) RunAndReturn(run func(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]entities.WebhookRetry, error)) *MockWebhookRepo_ClaimWebhookRetries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateWebhook provides a mock function for the type MockWebhookRepo
+func (_mock *MockWebhookRepo) CreateWebhook(ctx context.Context, w entities.Webhook) (entities.Webhook, error) {
+	ret := _mock.Called(ctx, w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateWebhook")
+	}
+
+	var r0 entities.Webhook
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, entities.Webhook) (entities.Webhook, error)); ok {
+		return returnFunc(ctx, w)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, entities.Webhook) entities.Webhook); ok {
+		r0 = returnFunc(ctx, w)
+	} else {
+		r0 = ret.Get(0).(entities.Webhook)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, entities.Webhook) error); ok {
+		r1 = returnFunc(ctx, w)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockWebhookRepo_CreateWebhook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateWebhook'
+type MockWebhookRepo_CreateWebhook_Call struct {
+	*mock.Call
+}
+
+// CreateWebhook is a helper method to define mock.On call
+//   - ctx context.Context
+//   - w entities.Webhook
+func (_e *MockWebhookRepo_Expecter) CreateWebhook(ctx interface{}, w interface{}) *MockWebhookRepo_CreateWebhook_Call {
+	return &MockWebhookRepo_CreateWebhook_Call{Call: _e.mock.On("CreateWebhook", ctx, w)}
+}
+
+func (_c *MockWebhookRepo_CreateWebhook_Call) Run(run func(ctx context.Context, w entities.Webhook)) *MockWebhookRepo_CreateWebhook_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 entities.Webhook
+		if args[1] != nil {
+			arg1 = args[1].(entities.Webhook)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockWebhookRepo_CreateWebhook_Call) Return(webhook entities.Webhook, err error) *MockWebhookRepo_CreateWebhook_Call {
+	_c.Call.Return(webhook, err)
+	return _c
+}
+
+func (_c *MockWebhookRepo_CreateWebhook_Call) RunAndReturn(run func(ctx context.Context, w entities.Webhook) (entities.Webhook, error)) *MockWebhookRepo_CreateWebhook_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteOldWebhookDeliveries provides a mock function for the type MockWebhookRepo
+func (_mock *MockWebhookRepo) DeleteOldWebhookDeliveries(ctx context.Context, createdBefore time.Time) (int64, error) {
+	ret := _mock.Called(ctx, createdBefore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteOldWebhookDeliveries")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return returnFunc(ctx, createdBefore)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = returnFunc(ctx, createdBefore)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = returnFunc(ctx, createdBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockWebhookRepo_DeleteOldWebhookDeliveries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteOldWebhookDeliveries'
+type MockWebhookRepo_DeleteOldWebhookDeliveries_Call struct {
+	*mock.Call
+}
+
+// DeleteOldWebhookDeliveries is a helper method to define mock.On call
+//   - ctx context.Context
+//   - createdBefore time.Time
+func (_e *MockWebhookRepo_Expecter) DeleteOldWebhookDeliveries(ctx interface{}, createdBefore interface{}) *MockWebhookRepo_DeleteOldWebhookDeliveries_Call {
+	return &MockWebhookRepo_DeleteOldWebhookDeliveries_Call{Call: _e.mock.On("DeleteOldWebhookDeliveries", ctx, createdBefore)}
+}
+
+func (_c *MockWebhookRepo_DeleteOldWebhookDeliveries_Call) Run(run func(ctx context.Context, createdBefore time.Time)) *MockWebhookRepo_DeleteOldWebhookDeliveries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 time.Time
+		if args[1] != nil {
+			arg1 = args[1].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockWebhookRepo_DeleteOldWebhookDeliveries_Call) Return(n int64, err error) *MockWebhookRepo_DeleteOldWebhookDeliveries_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockWebhookRepo_DeleteOldWebhookDeliveries_Call) RunAndReturn(run func(ctx context.Context, createdBefore time.Time) (int64, error)) *MockWebhookRepo_DeleteOldWebhookDeliveries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteWebhook provides a mock function for the type MockWebhookRepo
+func (_mock *MockWebhookRepo) DeleteWebhook(ctx context.Context, id int64) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteWebhook")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockWebhookRepo_DeleteWebhook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteWebhook'
+type MockWebhookRepo_DeleteWebhook_Call struct {
+	*mock.Call
+}
+
+// DeleteWebhook is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+func (_e *MockWebhookRepo_Expecter) DeleteWebhook(ctx interface{}, id interface{}) *MockWebhookRepo_DeleteWebhook_Call {
+	return &MockWebhookRepo_DeleteWebhook_Call{Call: _e.mock.On("DeleteWebhook", ctx, id)}
+}
+
+func (_c *MockWebhookRepo_DeleteWebhook_Call) Run(run func(ctx context.Context, id int64)) *MockWebhookRepo_DeleteWebhook_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int64
+		if args[1] != nil {
+			arg1 = args[1].(int64)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockWebhookRepo_DeleteWebhook_Call) Return(err error) *MockWebhookRepo_DeleteWebhook_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockWebhookRepo_DeleteWebhook_Call) RunAndReturn(run func(ctx context.Context, id int64) error) *MockWebhookRepo_DeleteWebhook_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetWebhook provides a mock function for the type MockWebhookRepo
+func (_mock *MockWebhookRepo) GetWebhook(ctx context.Context, id int64) (entities.Webhook, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWebhook")
+	}
+
+	var r0 entities.Webhook
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (entities.Webhook, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) entities.Webhook); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(entities.Webhook)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockWebhookRepo_GetWebhook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetWebhook'
+type MockWebhookRepo_GetWebhook_Call struct {
+	*mock.Call
+}
+
+// GetWebhook is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+func (_e *MockWebhookRepo_Expecter) GetWebhook(ctx interface{}, id interface{}) *MockWebhookRepo_GetWebhook_Call {
+	return &MockWebhookRepo_GetWebhook_Call{Call: _e.mock.On("GetWebhook", ctx, id)}
+}
+
+func (_c *MockWebhookRepo_GetWebhook_Call) Run(run func(ctx context.Context, id int64)) *MockWebhookRepo_GetWebhook_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int64
+		if args[1] != nil {
+			arg1 = args[1].(int64)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockWebhookRepo_GetWebhook_Call) Return(webhook entities.Webhook, err error) *MockWebhookRepo_GetWebhook_Call {
+	_c.Call.Return(webhook, err)
+	return _c
+}
+
+func (_c *MockWebhookRepo_GetWebhook_Call) RunAndReturn(run func(ctx context.Context, id int64) (entities.Webhook, error)) *MockWebhookRepo_GetWebhook_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListEnabledWebhooks provides a mock function for the type MockWebhookRepo
+func (_mock *MockWebhookRepo) ListEnabledWebhooks(ctx context.Context, event string) ([]entities.Webhook, error) {
+	ret := _mock.Called(ctx, event)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListEnabledWebhooks")
+	}
+
+	var r0 []entities.Webhook
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]entities.Webhook, error)); ok {
+		return returnFunc(ctx, event)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []entities.Webhook); ok {
+		r0 = returnFunc(ctx, event)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entities.Webhook)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, event)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockWebhookRepo_ListEnabledWebhooks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListEnabledWebhooks'
+type MockWebhookRepo_ListEnabledWebhooks_Call struct {
+	*mock.Call
+}
+
+// ListEnabledWebhooks is a helper method to define mock.On call
+//   - ctx context.Context
+//   - event string
+func (_e *MockWebhookRepo_Expecter) ListEnabledWebhooks(ctx interface{}, event interface{}) *MockWebhookRepo_ListEnabledWebhooks_Call {
+	return &MockWebhookRepo_ListEnabledWebhooks_Call{Call: _e.mock.On("ListEnabledWebhooks", ctx, event)}
+}
+
+func (_c *MockWebhookRepo_ListEnabledWebhooks_Call) Run(run func(ctx context.Context, event string)) *MockWebhookRepo_ListEnabledWebhooks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockWebhookRepo_ListEnabledWebhooks_Call) Return(webhooks []entities.Webhook, err error) *MockWebhookRepo_ListEnabledWebhooks_Call {
+	_c.Call.Return(webhooks, err)
+	return _c
+}
+
+func (_c *MockWebhookRepo_ListEnabledWebhooks_Call) RunAndReturn(run func(ctx context.Context, event string) ([]entities.Webhook, error)) *MockWebhookRepo_ListEnabledWebhooks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListWebhookDeliveries provides a mock function for the type MockWebhookRepo
+func (_mock *MockWebhookRepo) ListWebhookDeliveries(ctx context.Context, webhookID int64, limit int) ([]entities.WebhookDelivery, error) {
+	ret := _mock.Called(ctx, webhookID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListWebhookDeliveries")
+	}
+
+	var r0 []entities.WebhookDelivery
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int) ([]entities.WebhookDelivery, error)); ok {
+		return returnFunc(ctx, webhookID, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int) []entities.WebhookDelivery); ok {
+		r0 = returnFunc(ctx, webhookID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entities.WebhookDelivery)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int) error); ok {
+		r1 = returnFunc(ctx, webhookID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockWebhookRepo_ListWebhookDeliveries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListWebhookDeliveries'
+type MockWebhookRepo_ListWebhookDeliveries_Call struct {
+	*mock.Call
+}
+
+// ListWebhookDeliveries is a helper method to define mock.On call
+//   - ctx context.Context
+//   - webhookID int64
+//   - limit int
+func (_e *MockWebhookRepo_Expecter) ListWebhookDeliveries(ctx interface{}, webhookID interface{}, limit interface{}) *MockWebhookRepo_ListWebhookDeliveries_Call {
+	return &MockWebhookRepo_ListWebhookDeliveries_Call{Call: _e.mock.On("ListWebhookDeliveries", ctx, webhookID, limit)}
+}
+
+func (_c *MockWebhookRepo_ListWebhookDeliveries_Call) Run(run func(ctx context.Context, webhookID int64, limit int)) *MockWebhookRepo_ListWebhookDeliveries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int64
+		if args[1] != nil {
+			arg1 = args[1].(int64)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockWebhookRepo_ListWebhookDeliveries_Call) Return(webhookDeliverys []entities.WebhookDelivery, err error) *MockWebhookRepo_ListWebhookDeliveries_Call {
+	_c.Call.Return(webhookDeliverys, err)
+	return _c
+}
+
+func (_c *MockWebhookRepo_ListWebhookDeliveries_Call) RunAndReturn(run func(ctx context.Context, webhookID int64, limit int) ([]entities.WebhookDelivery, error)) *MockWebhookRepo_ListWebhookDeliveries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListWebhooks provides a mock function for the type MockWebhookRepo
+func (_mock *MockWebhookRepo) ListWebhooks(ctx context.Context) ([]entities.Webhook, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListWebhooks")
+	}
+
+	var r0 []entities.Webhook
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]entities.Webhook, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []entities.Webhook); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entities.Webhook)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockWebhookRepo_ListWebhooks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListWebhooks'
+type MockWebhookRepo_ListWebhooks_Call struct {
+	*mock.Call
+}
+
+// ListWebhooks is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockWebhookRepo_Expecter) ListWebhooks(ctx interface{}) *MockWebhookRepo_ListWebhooks_Call {
+	return &MockWebhookRepo_ListWebhooks_Call{Call: _e.mock.On("ListWebhooks", ctx)}
+}
+
+func (_c *MockWebhookRepo_ListWebhooks_Call) Run(run func(ctx context.Context)) *MockWebhookRepo_ListWebhooks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockWebhookRepo_ListWebhooks_Call) Return(webhooks []entities.Webhook, err error) *MockWebhookRepo_ListWebhooks_Call {
+	_c.Call.Return(webhooks, err)
+	return _c
+}
+
+func (_c *MockWebhookRepo_ListWebhooks_Call) RunAndReturn(run func(ctx context.Context) ([]entities.Webhook, error)) *MockWebhookRepo_ListWebhooks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecordWebhookFailure provides a mock function for the type MockWebhookRepo
+func (_mock *MockWebhookRepo) RecordWebhookFailure(ctx context.Context, id int64, disableAfter int) (bool, error) {
+	ret := _mock.Called(ctx, id, disableAfter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordWebhookFailure")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int) (bool, error)); ok {
+		return returnFunc(ctx, id, disableAfter)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int) bool); ok {
+		r0 = returnFunc(ctx, id, disableAfter)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int) error); ok {
+		r1 = returnFunc(ctx, id, disableAfter)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockWebhookRepo_RecordWebhookFailure_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordWebhookFailure'
+type MockWebhookRepo_RecordWebhookFailure_Call struct {
+	*mock.Call
+}
+
+// RecordWebhookFailure is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+//   - disableAfter int
+func (_e *MockWebhookRepo_Expecter) RecordWebhookFailure(ctx interface{}, id interface{}, disableAfter interface{}) *MockWebhookRepo_RecordWebhookFailure_Call {
+	return &MockWebhookRepo_RecordWebhookFailure_Call{Call: _e.mock.On("RecordWebhookFailure", ctx, id, disableAfter)}
+}
+
+func (_c *MockWebhookRepo_RecordWebhookFailure_Call) Run(run func(ctx context.Context, id int64, disableAfter int)) *MockWebhookRepo_RecordWebhookFailure_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int64
+		if args[1] != nil {
+			arg1 = args[1].(int64)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockWebhookRepo_RecordWebhookFailure_Call) Return(b bool, err error) *MockWebhookRepo_RecordWebhookFailure_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockWebhookRepo_RecordWebhookFailure_Call) RunAndReturn(run func(ctx context.Context, id int64, disableAfter int) (bool, error)) *MockWebhookRepo_RecordWebhookFailure_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecordWebhookSuccess provides a mock function for the type MockWebhookRepo
+func (_mock *MockWebhookRepo) RecordWebhookSuccess(ctx context.Context, id int64) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordWebhookSuccess")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockWebhookRepo_RecordWebhookSuccess_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordWebhookSuccess'
+type MockWebhookRepo_RecordWebhookSuccess_Call struct {
+	*mock.Call
+}
+
+// RecordWebhookSuccess is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+func (_e *MockWebhookRepo_Expecter) RecordWebhookSuccess(ctx interface{}, id interface{}) *MockWebhookRepo_RecordWebhookSuccess_Call {
+	return &MockWebhookRepo_RecordWebhookSuccess_Call{Call: _e.mock.On("RecordWebhookSuccess", ctx, id)}
+}
+
+func (_c *MockWebhookRepo_RecordWebhookSuccess_Call) Run(run func(ctx context.Context, id int64)) *MockWebhookRepo_RecordWebhookSuccess_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int64
+		if args[1] != nil {
+			arg1 = args[1].(int64)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockWebhookRepo_RecordWebhookSuccess_Call) Return(err error) *MockWebhookRepo_RecordWebhookSuccess_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockWebhookRepo_RecordWebhookSuccess_Call) RunAndReturn(run func(ctx context.Context, id int64) error) *MockWebhookRepo_RecordWebhookSuccess_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveWebhookDelivery provides a mock function for the type MockWebhookRepo
+func (_mock *MockWebhookRepo) SaveWebhookDelivery(ctx context.Context, d entities.WebhookDelivery) error {
+	ret := _mock.Called(ctx, d)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveWebhookDelivery")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, entities.WebhookDelivery) error); ok {
+		r0 = returnFunc(ctx, d)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockWebhookRepo_SaveWebhookDelivery_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveWebhookDelivery'
+type MockWebhookRepo_SaveWebhookDelivery_Call struct {
+	*mock.Call
+}
+
+// SaveWebhookDelivery is a helper method to define mock.On call
+//   - ctx context.Context
+//   - d entities.WebhookDelivery
+func (_e *MockWebhookRepo_Expecter) SaveWebhookDelivery(ctx interface{}, d interface{}) *MockWebhookRepo_SaveWebhookDelivery_Call {
+	return &MockWebhookRepo_SaveWebhookDelivery_Call{Call: _e.mock.On("SaveWebhookDelivery", ctx, d)}
+}
+
+func (_c *MockWebhookRepo_SaveWebhookDelivery_Call) Run(run func(ctx context.Context, d entities.WebhookDelivery)) *MockWebhookRepo_SaveWebhookDelivery_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 entities.WebhookDelivery
+		if args[1] != nil {
+			arg1 = args[1].(entities.WebhookDelivery)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockWebhookRepo_SaveWebhookDelivery_Call) Return(err error) *MockWebhookRepo_SaveWebhookDelivery_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockWebhookRepo_SaveWebhookDelivery_Call) RunAndReturn(run func(ctx context.Context, d entities.WebhookDelivery) error) *MockWebhookRepo_SaveWebhookDelivery_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateWebhook provides a mock function for the type MockWebhookRepo
+func (_mock *MockWebhookRepo) UpdateWebhook(ctx context.Context, id int64, upd entities.WebhookUpdate) (entities.Webhook, error) {
+	ret := _mock.Called(ctx, id, upd)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateWebhook")
+	}
+
+	var r0 entities.Webhook
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, entities.WebhookUpdate) (entities.Webhook, error)); ok {
+		return returnFunc(ctx, id, upd)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, entities.WebhookUpdate) entities.Webhook); ok {
+		r0 = returnFunc(ctx, id, upd)
+	} else {
+		r0 = ret.Get(0).(entities.Webhook)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, entities.WebhookUpdate) error); ok {
+		r1 = returnFunc(ctx, id, upd)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockWebhookRepo_UpdateWebhook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateWebhook'
+type MockWebhookRepo_UpdateWebhook_Call struct {
+	*mock.Call
+}
+
+// UpdateWebhook is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+//   - upd entities.WebhookUpdate
+func (_e *MockWebhookRepo_Expecter) UpdateWebhook(ctx interface{}, id interface{}, upd interface{}) *MockWebhookRepo_UpdateWebhook_Call {
+	return &MockWebhookRepo_UpdateWebhook_Call{Call: _e.mock.On("UpdateWebhook", ctx, id, upd)}
+}
+
+func (_c *MockWebhookRepo_UpdateWebhook_Call) Run(run func(ctx context.Context, id int64, upd entities.WebhookUpdate)) *MockWebhookRepo_UpdateWebhook_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int64
+		if args[1] != nil {
+			arg1 = args[1].(int64)
+		}
+		var arg2 entities.WebhookUpdate
+		if args[2] != nil {
+			arg2 = args[2].(entities.WebhookUpdate)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockWebhookRepo_UpdateWebhook_Call) Return(webhook entities.Webhook, err error) *MockWebhookRepo_UpdateWebhook_Call {
+	_c.Call.Return(webhook, err)
+	return _c
+}
+
+func (_c *MockWebhookRepo_UpdateWebhook_Call) RunAndReturn(run func(ctx context.Context, id int64, upd entities.WebhookUpdate) (entities.Webhook, error)) *MockWebhookRepo_UpdateWebhook_Call {
+	_c.Call.Return(run)
+	return _c
+}
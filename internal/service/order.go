@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
@@ -13,44 +14,109 @@ import (
 )
 
 type OrderRepo interface {
-	GetOrderByID(ctx context.Context, orderUID string) (entities.Order, error)
+	GetOrderByID(ctx context.Context, orderUID string, withItems bool) (entities.Order, error)
+	GetOrdersByIDs(ctx context.Context, orderUIDs []string) ([]entities.Order, error)
 	LatestOrders(ctx context.Context, count int) ([]entities.Order, error)
+	ListOrders(ctx context.Context, filter entities.OrderFilter) ([]entities.Order, error)
+	ExportOrders(ctx context.Context, filter entities.OrderFilter, fn func(orders []entities.Order) error) error
+	GetOrderUIDByTrackNumber(ctx context.Context, trackNumber string) (string, error)
+	GetOrderUIDsByItemTrackNumber(
+		ctx context.Context, trackNumber string, limit int, after *entities.OrderCursor,
+	) ([]entities.OrderCursor, error)
 
-	// Операции идемпотентны, т.к. используется ON CONFLICT DO NOTHING
+	// Доставка и оплата перезаписываются, товары с уже сохраненным RID пропускаются
 	SaveItems(ctx context.Context, orderUID string, items []entities.Item) error
 	SavePayment(ctx context.Context, orderUID string, p entities.Payment) error
 	SaveDelivery(ctx context.Context, orderUID string, d entities.Delivery) error
-	SaveOrder(ctx context.Context, o entities.Order) error
+	DeleteItems(ctx context.Context, orderUID string) error
+	SaveOrder(ctx context.Context, o entities.Order) (entities.OrderSaveResult, error)
 }
 
 type Cache interface {
 	Get(key string) ([]byte, bool)
 	Set(key string, value []byte)
+	Delete(key string)
+	Purge()
 }
 
+// retryConfig используется для всех операций с репозиторием
+var retryConfig = utils.RetryConfig{
+	InitialDelay: 100 * time.Millisecond,
+	MaxAttempts:  5,
+	Multiplier:   2,
+}
+
+// trackKeyPrefix префикс ключей кэша, по которым трек номер заказа сопоставлен с его UID
+const trackKeyPrefix = "track:"
+
 type OrderService struct {
 	logger    *slog.Logger
 	txManager trm.Manager
 	repo      OrderRepo
 	cache     Cache
+	// trackCache связи трек номеров с UID, отдельно от заказов, чтобы не вытеснять их
+	trackCache  Cache
+	hooks       []SaveHook
+	updateHooks []SaveHook
 }
 
-func NewOrderService(logger *slog.Logger, txManager trm.Manager, repo OrderRepo, cache Cache) *OrderService {
+func NewOrderService(
+	logger *slog.Logger, txManager trm.Manager, repo OrderRepo, cache Cache, trackCache Cache,
+) *OrderService {
 	return &OrderService{
-		logger:    logger.With(slog.String("service", "order")),
-		txManager: txManager,
-		repo:      repo,
-		cache:     cache,
+		logger:     logger.With(slog.String("service", "order")),
+		txManager:  txManager,
+		repo:       repo,
+		cache:      cache,
+		trackCache: trackCache,
 	}
 }
 
+// SaveHook вызывается после сохранения заказа
+type SaveHook func(ctx context.Context, order entities.Order)
+
+// OnSave добавляет обработчики, которые вызываются после сохранения нового заказа.
+// Заказ сохраняется идемпотентно: повторно полученный заказ с тем же UID и содержимым не сохраняется,
+// поэтому обработчики для него не вызываются и не получают данные, которых нет в базе.
+func (s *OrderService) OnSave(hooks ...SaveHook) {
+	s.hooks = append(s.hooks, hooks...)
+}
+
+// OnUpdate добавляет обработчики, которые вызываются после замены сохраненного заказа
+// полученным с тем же UID, но другим содержимым
+func (s *OrderService) OnUpdate(hooks ...SaveHook) {
+	s.updateHooks = append(s.updateHooks, hooks...)
+}
+
+// Notify удаляет из кэша заказ, замененный на этой или другой реплике, подходит для канала order_updated
+func (s *OrderService) Notify(orderUID string) {
+	s.cache.Delete(orderUID)
+}
+
+// NotifyAll очищает кэш заказов, когда уведомления о заменах могли потеряться при переподключении к Postgres
+func (s *OrderService) NotifyAll() {
+	s.cache.Purge()
+}
+
 func (s *OrderService) SaveOrder(ctx context.Context, order entities.Order) error {
+	var res entities.OrderSaveResult
 	fn := func() error {
 		return s.txManager.Do(ctx, func(ctx context.Context) error {
 			// для начала надо сохранить информацию о заказе чтобы был доступен внешний ключ
-			if err := s.repo.SaveOrder(ctx, order); err != nil {
+			var err error
+			res, err = s.repo.SaveOrder(ctx, order)
+			if err != nil {
 				return fmt.Errorf("failed to save order: %w", err)
 			}
+			if !res.Created && !res.Updated {
+				return nil
+			}
+			// у замененного заказа мог измениться состав товаров
+			if res.Updated {
+				if err := s.repo.DeleteItems(ctx, order.OrderUID); err != nil {
+					return fmt.Errorf("failed to replace order: %w", err)
+				}
+			}
 
 			eg, ctx := errgroup.WithContext(ctx)
 
@@ -74,20 +140,33 @@ func (s *OrderService) SaveOrder(ctx context.Context, order entities.Order) erro
 		})
 	}
 
-	cfg := utils.RetryConfig{
-		InitialDelay: 100 * time.Millisecond,
-		MaxAttempts:  5,
-		Multiplier:   2,
-	}
-
-	err := utils.Retry(cfg, fn)
+	err := utils.Retry(retryConfig, fn)
 	if err != nil {
 		return fmt.Errorf("failed after retry: %w", err)
 	}
+
+	order.SavedAt = res.SavedAt
+	switch {
+	case res.Created:
+		for _, hook := range s.hooks {
+			hook(ctx, order)
+		}
+	case res.Updated:
+		// другие реплики удалят заказ из кэша по уведомлению order_updated
+		s.cache.Delete(order.OrderUID)
+		for _, hook := range s.updateHooks {
+			hook(ctx, order)
+		}
+	default:
+		s.logger.DebugContext(ctx, "order already saved", "order_uid", order.OrderUID)
+	}
 	return nil
 }
 
-func (s *OrderService) GetOrderByID(ctx context.Context, orderUID string) (entities.Order, error) {
+// GetOrderByID возвращает заказ по UID. С excludeItems товары не загружаются из базы.
+// В кэше лежат только полные заказы: заказ без товаров берется из кэша, если он там есть,
+// а загруженный из базы без товаров в кэш не кладется.
+func (s *OrderService) GetOrderByID(ctx context.Context, orderUID string, excludeItems bool) (entities.Order, error) {
 	// Проверяем кэш
 	if data, ok := s.cache.Get(orderUID); ok {
 		s.logger.DebugContext(ctx, "cache hit", "order_uid", orderUID)
@@ -95,6 +174,9 @@ func (s *OrderService) GetOrderByID(ctx context.Context, orderUID string) (entit
 		if err := order.Unmarshal(data); err != nil {
 			return entities.Order{}, fmt.Errorf("failed to unmarshal order: %w", err)
 		}
+		if excludeItems {
+			order.Items = nil
+		}
 		return order, nil
 	}
 
@@ -102,21 +184,19 @@ func (s *OrderService) GetOrderByID(ctx context.Context, orderUID string) (entit
 	var order entities.Order
 	fn := func() error {
 		var err error
-		order, err = s.repo.GetOrderByID(ctx, orderUID)
+		order, err = s.repo.GetOrderByID(ctx, orderUID, !excludeItems)
 		if err != nil {
 			return fmt.Errorf("failed to get order: %w", err)
 		}
 		return nil
 	}
-	cfg := utils.RetryConfig{
-		InitialDelay: 100 * time.Millisecond,
-		MaxAttempts:  5,
-		Multiplier:   2,
-	}
 	// Не ретраим если заказ не найден
-	if err := utils.Retry(cfg, fn, entities.ErrOrderNotFound); err != nil {
+	if err := utils.Retry(retryConfig, fn, entities.ErrOrderNotFound); err != nil {
 		return entities.Order{}, fmt.Errorf("failed after retry: %w", err)
 	}
+	if excludeItems {
+		return order, nil
+	}
 
 	data, err := order.Marshal()
 	if err != nil {
@@ -126,6 +206,182 @@ func (s *OrderService) GetOrderByID(ctx context.Context, orderUID string) (entit
 	return order, nil
 }
 
+// GetOrdersByIDs возвращает заказы по списку UID в порядке запроса и UID, которые не были найдены.
+// Заказы из кэша отдаются сразу, остальные загружаются из базы одним запросом.
+func (s *OrderService) GetOrdersByIDs(ctx context.Context, orderUIDs []string) ([]entities.Order, []string, error) {
+	// повторяющиеся UID обрабатываются один раз
+	uids := make([]string, 0, len(orderUIDs))
+	for _, uid := range orderUIDs {
+		if !slices.Contains(uids, uid) {
+			uids = append(uids, uid)
+		}
+	}
+
+	found := make(map[string]entities.Order, len(uids))
+	var misses []string
+	for _, uid := range uids {
+		data, ok := s.cache.Get(uid)
+		if !ok {
+			misses = append(misses, uid)
+			continue
+		}
+		var order entities.Order
+		if err := order.Unmarshal(data); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal order: %w", err)
+		}
+		found[uid] = order
+	}
+	s.logger.DebugContext(ctx, "batch cache lookup", "hits", len(found), "misses", len(misses))
+
+	if len(misses) > 0 {
+		var loaded []entities.Order
+		fn := func() error {
+			var err error
+			loaded, err = s.repo.GetOrdersByIDs(ctx, misses)
+			if err != nil {
+				return fmt.Errorf("failed to get orders: %w", err)
+			}
+			return nil
+		}
+		if err := utils.Retry(retryConfig, fn); err != nil {
+			return nil, nil, fmt.Errorf("failed after retry: %w", err)
+		}
+
+		for _, order := range loaded {
+			data, err := order.Marshal()
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to marshal order: %w", err)
+			}
+			s.cache.Set(order.OrderUID, data)
+			found[order.OrderUID] = order
+		}
+	}
+
+	orders := make([]entities.Order, 0, len(found))
+	missing := make([]string, 0, len(uids)-len(found))
+	for _, uid := range uids {
+		if order, ok := found[uid]; ok {
+			orders = append(orders, order)
+		} else {
+			missing = append(missing, uid)
+		}
+	}
+	return orders, missing, nil
+}
+
+// GetOrderByTrackNumber возвращает заказ по его трек номеру.
+// Трек номер уникален, поэтому его связь с UID кэшируется, а сам заказ берется через GetOrderByID.
+// Трек номер меняется только при замене заказа, тогда связь из кэша перестает совпадать с заказом и удаляется.
+func (s *OrderService) GetOrderByTrackNumber(
+	ctx context.Context, trackNumber string, excludeItems bool,
+) (entities.Order, error) {
+	key := trackKeyPrefix + trackNumber
+	if data, ok := s.trackCache.Get(key); ok {
+		s.logger.DebugContext(ctx, "cache hit", "track_number", trackNumber)
+		order, err := s.GetOrderByID(ctx, string(data), excludeItems)
+		if err != nil || order.TrackNumber == trackNumber {
+			return order, err
+		}
+		s.trackCache.Delete(key)
+	}
+
+	var orderUID string
+	fn := func() error {
+		var err error
+		orderUID, err = s.repo.GetOrderUIDByTrackNumber(ctx, trackNumber)
+		if err != nil {
+			return fmt.Errorf("failed to get order uid: %w", err)
+		}
+		return nil
+	}
+	if err := utils.Retry(retryConfig, fn, entities.ErrOrderNotFound); err != nil {
+		return entities.Order{}, fmt.Errorf("failed after retry: %w", err)
+	}
+
+	s.trackCache.Set(key, []byte(orderUID))
+	return s.GetOrderByID(ctx, orderUID, excludeItems)
+}
+
+// GetOrdersByItemTrackNumber возвращает страницу заказов, в которых есть товар с таким трек номером,
+// от новых к старым. Сами заказы берутся через GetOrdersByIDs, одним запросом для тех, что не в кэше.
+func (s *OrderService) GetOrdersByItemTrackNumber(
+	ctx context.Context, trackNumber string, limit int, after *entities.OrderCursor,
+) (entities.OrderPage, error) {
+	var keys []entities.OrderCursor
+	fn := func() error {
+		var err error
+		// на один заказ больше, чтобы понять есть ли следующая страница
+		keys, err = s.repo.GetOrderUIDsByItemTrackNumber(ctx, trackNumber, limit+1, after)
+		if err != nil {
+			return fmt.Errorf("failed to get order uids: %w", err)
+		}
+		return nil
+	}
+	if err := utils.Retry(retryConfig, fn); err != nil {
+		return entities.OrderPage{}, fmt.Errorf("failed after retry: %w", err)
+	}
+
+	if len(keys) == 0 && after == nil {
+		return entities.OrderPage{}, entities.ErrOrderNotFound
+	}
+
+	var page entities.OrderPage
+	if len(keys) > limit {
+		keys = keys[:limit]
+		page.Next = &keys[limit-1]
+	}
+
+	uids := make([]string, len(keys))
+	for i, k := range keys {
+		uids[i] = k.OrderUID
+	}
+	orders, _, err := s.GetOrdersByIDs(ctx, uids)
+	if err != nil {
+		return entities.OrderPage{}, err
+	}
+	page.Orders = orders
+	return page, nil
+}
+
+// ListOrders возвращает страницу заказов по фильтру
+func (s *OrderService) ListOrders(ctx context.Context, filter entities.OrderFilter) (entities.OrderPage, error) {
+	return pageOrders(ctx, s.repo.ListOrders, filter)
+}
+
+// ExportOrders передает fn все заказы по фильтру пачками по filter.Limit, от старых к новым,
+// начиная после filter.After. Заказы читаются из Postgres по мере записи и мимо кэша.
+func (s *OrderService) ExportOrders(
+	ctx context.Context, filter entities.OrderFilter, fn func(orders []entities.Order) error,
+) error {
+	// позиция выгрузки задается курсором, поэтому порядок всегда от старых к новым
+	filter.SortAsc = true
+	return s.txManager.Do(ctx, func(ctx context.Context) error {
+		return s.repo.ExportOrders(ctx, filter, fn)
+	})
+}
+
+type listOrdersFunc func(ctx context.Context, filter entities.OrderFilter) ([]entities.Order, error)
+
+func pageOrders(ctx context.Context, list listOrdersFunc, filter entities.OrderFilter) (entities.OrderPage, error) {
+	limit := filter.Limit
+	// запрашиваем на один заказ больше, чтобы понять есть ли следующая страница
+	filter.Limit++
+
+	orders, err := list(ctx, filter)
+	if err != nil {
+		return entities.OrderPage{}, fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	page := entities.OrderPage{Orders: orders}
+	if len(orders) > limit {
+		page.Orders = orders[:limit]
+		last := page.Orders[limit-1]
+		page.Next = &entities.OrderCursor{DateCreated: last.DateCreated, OrderUID: last.OrderUID}
+	}
+
+	return page, nil
+}
+
 func (s *OrderService) WarmUpCache(ctx context.Context, count int) error {
 	orders, err := s.repo.LatestOrders(ctx, count)
 	if err != nil {
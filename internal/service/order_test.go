@@ -6,6 +6,7 @@ import (
 	"io"
 	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
 	"github.com/SergeyBogomolovv/l0-order-service/internal/service"
@@ -20,32 +21,59 @@ func TestOrderService_SaveOrder(t *testing.T) {
 	type MockBehavior func(orderRepo *mocks.MockOrderRepo)
 
 	dbError := errors.New("db error")
+	created := entities.OrderSaveResult{Created: true}
+	updated := entities.OrderSaveResult{Updated: true}
 
 	testCases := []struct {
 		name         string
 		order        entities.Order
 		mockBehavior MockBehavior
+		wantCreated  bool
+		wantUpdated  bool
 		wantErr      error
 	}{
 		{
 			name: "OK",
 			order: entities.Order{
-				OrderUID: "123",
+				OrderUID:   "123",
+				CustomerID: "c1",
 			},
 			mockBehavior: func(orderRepo *mocks.MockOrderRepo) {
-				orderRepo.EXPECT().SaveOrder(mock.Anything, mock.Anything).Return(nil)
+				orderRepo.EXPECT().SaveOrder(mock.Anything, mock.Anything).Return(created, nil)
 				orderRepo.EXPECT().SaveDelivery(mock.Anything, mock.Anything, mock.Anything).Return(nil)
 				orderRepo.EXPECT().SavePayment(mock.Anything, mock.Anything, mock.Anything).Return(nil)
 				orderRepo.EXPECT().SaveItems(mock.Anything, mock.Anything, mock.Anything).Return(nil)
 			},
-			wantErr: nil,
+			wantCreated: true,
+			wantErr:     nil,
+		},
+		{
+			name:  "Already saved",
+			order: entities.Order{OrderUID: "123", CustomerID: "c1"},
+			mockBehavior: func(orderRepo *mocks.MockOrderRepo) {
+				orderRepo.EXPECT().SaveOrder(mock.Anything, mock.Anything).Return(entities.OrderSaveResult{}, nil)
+			},
+			wantCreated: false,
+			wantErr:     nil,
+		},
+		{
+			name:  "Updated",
+			order: entities.Order{OrderUID: "123", CustomerID: "c1"},
+			mockBehavior: func(orderRepo *mocks.MockOrderRepo) {
+				orderRepo.EXPECT().SaveOrder(mock.Anything, mock.Anything).Return(updated, nil)
+				orderRepo.EXPECT().DeleteItems(mock.Anything, "123").Return(nil)
+				orderRepo.EXPECT().SaveDelivery(mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				orderRepo.EXPECT().SavePayment(mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				orderRepo.EXPECT().SaveItems(mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			},
+			wantUpdated: true,
 		},
 		{
 			name:  "SaveOrder fails",
 			order: entities.Order{OrderUID: "123"},
 			mockBehavior: func(orderRepo *mocks.MockOrderRepo) {
 				orderRepo.EXPECT().SaveOrder(mock.Anything, mock.Anything).
-					Return(dbError)
+					Return(entities.OrderSaveResult{}, dbError)
 			},
 			wantErr: dbError,
 		},
@@ -53,7 +81,7 @@ func TestOrderService_SaveOrder(t *testing.T) {
 			name:  "SaveDelivery fails",
 			order: entities.Order{OrderUID: "123"},
 			mockBehavior: func(orderRepo *mocks.MockOrderRepo) {
-				orderRepo.EXPECT().SaveOrder(mock.Anything, mock.Anything).Return(nil)
+				orderRepo.EXPECT().SaveOrder(mock.Anything, mock.Anything).Return(created, nil)
 				orderRepo.EXPECT().SaveDelivery(mock.Anything, mock.Anything, mock.Anything).
 					Return(dbError)
 				orderRepo.EXPECT().SavePayment(mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
@@ -67,15 +95,16 @@ func TestOrderService_SaveOrder(t *testing.T) {
 			mockBehavior: func(orderRepo *mocks.MockOrderRepo) {
 				// первая попытка - SaveOrder падает
 				orderRepo.EXPECT().SaveOrder(mock.Anything, mock.Anything).
-					Once().Return(errors.New("temporary error"))
+					Once().Return(entities.OrderSaveResult{}, errors.New("temporary error"))
 				// вторая попытка - всё ок
 				orderRepo.EXPECT().SaveOrder(mock.Anything, mock.Anything).
-					Once().Return(nil)
+					Once().Return(created, nil)
 				orderRepo.EXPECT().SaveDelivery(mock.Anything, mock.Anything, mock.Anything).Return(nil)
 				orderRepo.EXPECT().SavePayment(mock.Anything, mock.Anything, mock.Anything).Return(nil)
 				orderRepo.EXPECT().SaveItems(mock.Anything, mock.Anything, mock.Anything).Return(nil)
 			},
-			wantErr: nil,
+			wantCreated: true,
+			wantErr:     nil,
 		},
 	}
 
@@ -95,16 +124,41 @@ func TestOrderService_SaveOrder(t *testing.T) {
 
 			tc.mockBehavior(orderRepo)
 
-			svc := service.NewOrderService(logger, tx, orderRepo, cache)
+			if tc.wantUpdated {
+				// замененный заказ больше не должен отдаваться из кэша
+				cache.EXPECT().Delete(tc.order.OrderUID).Return().Once()
+			}
+
+			svc := service.NewOrderService(logger, tx, orderRepo, cache, mocks.NewMockCache(t))
+			var hooked, hookedUpdates []string
+			svc.OnSave(func(_ context.Context, order entities.Order) {
+				hooked = append(hooked, order.OrderUID)
+			})
+			svc.OnUpdate(func(_ context.Context, order entities.Order) {
+				hookedUpdates = append(hookedUpdates, order.OrderUID)
+			})
 
 			err := svc.SaveOrder(context.Background(), tc.order)
 
 			if tc.wantErr != nil {
 				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Empty(t, hooked)
+				assert.Empty(t, hookedUpdates)
 				return
 			}
 
 			assert.NoError(t, err)
+			// уже сохраненный заказ с тем же содержимым обработчики не получают
+			if tc.wantCreated {
+				assert.Equal(t, []string{tc.order.OrderUID}, hooked)
+			} else {
+				assert.Empty(t, hooked)
+			}
+			if tc.wantUpdated {
+				assert.Equal(t, []string{tc.order.OrderUID}, hookedUpdates)
+			} else {
+				assert.Empty(t, hookedUpdates)
+			}
 		})
 	}
 }
@@ -116,9 +170,14 @@ func TestOrderService_GetOrderByID(t *testing.T) {
 	validData, err := validOrder.Marshal()
 	require.NoError(t, err)
 
+	fullOrder := entities.Order{OrderUID: "123", Items: []entities.Item{{ChrtID: 1}}}
+	fullData, err := fullOrder.Marshal()
+	require.NoError(t, err)
+
 	testCases := []struct {
 		name         string
 		orderUID     string
+		excludeItems bool
 		order        entities.Order
 		mockBehavior MockBehavior
 		wantErr      error
@@ -154,7 +213,7 @@ func TestOrderService_GetOrderByID(t *testing.T) {
 					Get("123").
 					Return(nil, false).Once()
 				orderRepo.EXPECT().
-					GetOrderByID(mock.Anything, "123").
+					GetOrderByID(mock.Anything, "123", true).
 					Return(validOrder, nil).Once()
 				cache.EXPECT().
 					Set("123", validData).
@@ -170,7 +229,7 @@ func TestOrderService_GetOrderByID(t *testing.T) {
 					Get("not-exist").
 					Return(nil, false).Once()
 				orderRepo.EXPECT().
-					GetOrderByID(mock.Anything, "not-exist").
+					GetOrderByID(mock.Anything, "not-exist", true).
 					Return(entities.Order{}, entities.ErrOrderNotFound).Once()
 			},
 			wantErr: entities.ErrOrderNotFound,
@@ -183,10 +242,10 @@ func TestOrderService_GetOrderByID(t *testing.T) {
 					Get("123").
 					Return(nil, false).Once()
 				orderRepo.EXPECT().
-					GetOrderByID(mock.Anything, "123").
+					GetOrderByID(mock.Anything, "123", true).
 					Return(entities.Order{}, errors.New("some error")).Once()
 				orderRepo.EXPECT().
-					GetOrderByID(mock.Anything, "123").
+					GetOrderByID(mock.Anything, "123", true).
 					Return(validOrder, nil).Once()
 				cache.EXPECT().
 					Set("123", validData).
@@ -194,6 +253,31 @@ func TestOrderService_GetOrderByID(t *testing.T) {
 			},
 			want: validOrder,
 		},
+		{
+			name:         "exclude items from cache",
+			orderUID:     "123",
+			excludeItems: true,
+			mockBehavior: func(_ *mocks.MockOrderRepo, cache *mocks.MockCache) {
+				cache.EXPECT().
+					Get("123").
+					Return(fullData, true).Once()
+			},
+			want: validOrder,
+		},
+		{
+			name:         "exclude items from repo without cache",
+			orderUID:     "123",
+			excludeItems: true,
+			mockBehavior: func(orderRepo *mocks.MockOrderRepo, cache *mocks.MockCache) {
+				cache.EXPECT().
+					Get("123").
+					Return(nil, false).Once()
+				orderRepo.EXPECT().
+					GetOrderByID(mock.Anything, "123", false).
+					Return(validOrder, nil).Once()
+			},
+			want: validOrder,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -205,9 +289,171 @@ func TestOrderService_GetOrderByID(t *testing.T) {
 
 			tc.mockBehavior(orderRepo, cache)
 
-			svc := service.NewOrderService(logger, tx, orderRepo, cache)
+			svc := service.NewOrderService(logger, tx, orderRepo, cache, mocks.NewMockCache(t))
+
+			got, err := svc.GetOrderByID(context.Background(), tc.orderUID, tc.excludeItems)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestOrderService_ListOrders(t *testing.T) {
+	now := time.Now()
+	orders := []entities.Order{
+		{OrderUID: "3", DateCreated: now},
+		{OrderUID: "2", DateCreated: now.Add(-time.Minute)},
+		{OrderUID: "1", DateCreated: now.Add(-2 * time.Minute)},
+	}
+
+	testCases := []struct {
+		name     string
+		limit    int
+		repoResp []entities.Order
+		want     entities.OrderPage
+	}{
+		{
+			name:     "has next page",
+			limit:    2,
+			repoResp: orders,
+			want: entities.OrderPage{
+				Orders: orders[:2],
+				Next:   &entities.OrderCursor{DateCreated: orders[1].DateCreated, OrderUID: "2"},
+			},
+		},
+		{
+			name:     "last page",
+			limit:    3,
+			repoResp: orders,
+			want:     entities.OrderPage{Orders: orders},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			orderRepo := mocks.NewMockOrderRepo(t)
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			orderRepo.EXPECT().
+				ListOrders(mock.Anything, entities.OrderFilter{CustomerID: "c", Limit: tc.limit + 1}).
+				Return(tc.repoResp, nil).Once()
+
+			svc := service.NewOrderService(
+				logger, txMocks.NewMockManager(t), orderRepo, mocks.NewMockCache(t), mocks.NewMockCache(t),
+			)
+
+			got, err := svc.ListOrders(context.Background(), entities.OrderFilter{CustomerID: "c", Limit: tc.limit})
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestOrderService_ExportOrders(t *testing.T) {
+	orders := []entities.Order{{OrderUID: "1"}, {OrderUID: "2"}}
+	after := &entities.OrderCursor{DateCreated: time.Now(), OrderUID: "0"}
+
+	orderRepo := mocks.NewMockOrderRepo(t)
+	tx := txMocks.NewMockManager(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tx.EXPECT().
+		Do(mock.Anything, mock.Anything).
+		RunAndReturn(func(ctx context.Context, cb func(ctx context.Context) error) error {
+			return cb(ctx)
+		}).Once()
+	// выгрузка всегда идет от старых заказов к новым, иначе курсор не задает позицию
+	orderRepo.EXPECT().
+		ExportOrders(mock.Anything, entities.OrderFilter{CustomerID: "c", SortAsc: true, After: after, Limit: 2},
+			mock.Anything).
+		RunAndReturn(func(_ context.Context, _ entities.OrderFilter, fn func([]entities.Order) error) error {
+			return fn(orders)
+		}).Once()
 
-			got, err := svc.GetOrderByID(context.Background(), tc.orderUID)
+	svc := service.NewOrderService(logger, tx, orderRepo, mocks.NewMockCache(t), mocks.NewMockCache(t))
+
+	var got []entities.Order
+	err := svc.ExportOrders(context.Background(), entities.OrderFilter{CustomerID: "c", After: after, Limit: 2},
+		func(batch []entities.Order) error {
+			got = append(got, batch...)
+			return nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, orders, got)
+}
+
+func TestOrderService_GetOrderByTrackNumber(t *testing.T) {
+	type MockBehavior func(orderRepo *mocks.MockOrderRepo, cache, trackCache *mocks.MockCache)
+
+	validOrder := entities.Order{OrderUID: "123", TrackNumber: "TRACK"}
+	validData, err := validOrder.Marshal()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name         string
+		mockBehavior MockBehavior
+		want         entities.Order
+		wantErr      error
+	}{
+		{
+			name: "track and order from cache",
+			mockBehavior: func(_ *mocks.MockOrderRepo, cache, trackCache *mocks.MockCache) {
+				trackCache.EXPECT().Get("track:TRACK").Return([]byte("123"), true).Once()
+				cache.EXPECT().Get("123").Return(validData, true).Once()
+			},
+			want: validOrder,
+		},
+		{
+			name: "track from repo",
+			mockBehavior: func(orderRepo *mocks.MockOrderRepo, cache, trackCache *mocks.MockCache) {
+				trackCache.EXPECT().Get("track:TRACK").Return(nil, false).Once()
+				orderRepo.EXPECT().GetOrderUIDByTrackNumber(mock.Anything, "TRACK").Return("123", nil).Once()
+				trackCache.EXPECT().Set("track:TRACK", []byte("123")).Return().Once()
+				cache.EXPECT().Get("123").Return(validData, true).Once()
+			},
+			want: validOrder,
+		},
+		{
+			name: "track changed by order update",
+			mockBehavior: func(orderRepo *mocks.MockOrderRepo, cache, trackCache *mocks.MockCache) {
+				updatedData, err := (&entities.Order{OrderUID: "456", TrackNumber: "OTHER"}).Marshal()
+				require.NoError(t, err)
+				trackCache.EXPECT().Get("track:TRACK").Return([]byte("456"), true).Once()
+				cache.EXPECT().Get("456").Return(updatedData, true).Once()
+				trackCache.EXPECT().Delete("track:TRACK").Return().Once()
+				orderRepo.EXPECT().GetOrderUIDByTrackNumber(mock.Anything, "TRACK").Return("123", nil).Once()
+				trackCache.EXPECT().Set("track:TRACK", []byte("123")).Return().Once()
+				cache.EXPECT().Get("123").Return(validData, true).Once()
+			},
+			want: validOrder,
+		},
+		{
+			name: "not found",
+			mockBehavior: func(orderRepo *mocks.MockOrderRepo, cache, trackCache *mocks.MockCache) {
+				trackCache.EXPECT().Get("track:TRACK").Return(nil, false).Once()
+				orderRepo.EXPECT().GetOrderUIDByTrackNumber(mock.Anything, "TRACK").
+					Return("", entities.ErrOrderNotFound).Once()
+			},
+			wantErr: entities.ErrOrderNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			orderRepo := mocks.NewMockOrderRepo(t)
+			cache := mocks.NewMockCache(t)
+			trackCache := mocks.NewMockCache(t)
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			tc.mockBehavior(orderRepo, cache, trackCache)
+
+			svc := service.NewOrderService(logger, txMocks.NewMockManager(t), orderRepo, cache, trackCache)
+
+			got, err := svc.GetOrderByTrackNumber(context.Background(), "TRACK", false)
 			if tc.wantErr != nil {
 				assert.ErrorIs(t, err, tc.wantErr)
 				return
@@ -217,3 +463,93 @@ func TestOrderService_GetOrderByID(t *testing.T) {
 		})
 	}
 }
+
+func TestOrderService_GetOrdersByItemTrackNumber(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cached := entities.Order{OrderUID: "3"}
+	cachedData, err := cached.Marshal()
+	require.NoError(t, err)
+	stored := entities.Order{OrderUID: "2"}
+	keys := []entities.OrderCursor{
+		{OrderUID: "3", DateCreated: created.Add(2 * time.Hour)},
+		{OrderUID: "2", DateCreated: created.Add(time.Hour)},
+		{OrderUID: "1", DateCreated: created},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("page with next cursor", func(t *testing.T) {
+		orderRepo := mocks.NewMockOrderRepo(t)
+		cache := mocks.NewMockCache(t)
+		orderRepo.EXPECT().GetOrderUIDsByItemTrackNumber(mock.Anything, "ITEM", 3, (*entities.OrderCursor)(nil)).
+			Return(keys, nil).Once()
+		// заказы берутся одним запросом для тех, что не в кэше
+		cache.EXPECT().Get("3").Return(cachedData, true).Once()
+		cache.EXPECT().Get("2").Return(nil, false).Once()
+		orderRepo.EXPECT().GetOrdersByIDs(mock.Anything, []string{"2"}).Return([]entities.Order{stored}, nil).Once()
+		cache.EXPECT().Set("2", mock.Anything).Return().Once()
+
+		svc := service.NewOrderService(logger, txMocks.NewMockManager(t), orderRepo, cache, mocks.NewMockCache(t))
+
+		got, err := svc.GetOrdersByItemTrackNumber(context.Background(), "ITEM", 2, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []entities.Order{cached, stored}, got.Orders)
+		assert.Equal(t, &keys[1], got.Next)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		orderRepo := mocks.NewMockOrderRepo(t)
+		orderRepo.EXPECT().GetOrderUIDsByItemTrackNumber(mock.Anything, "ITEM", 3, (*entities.OrderCursor)(nil)).
+			Return(nil, nil).Once()
+
+		svc := service.NewOrderService(logger, txMocks.NewMockManager(t), orderRepo, mocks.NewMockCache(t),
+			mocks.NewMockCache(t))
+
+		_, err := svc.GetOrdersByItemTrackNumber(context.Background(), "ITEM", 2, nil)
+		assert.ErrorIs(t, err, entities.ErrOrderNotFound)
+	})
+}
+
+func TestOrderService_GetOrdersByIDs(t *testing.T) {
+	cached := entities.Order{OrderUID: "1"}
+	cachedData, err := cached.Marshal()
+	require.NoError(t, err)
+
+	stored := entities.Order{OrderUID: "2"}
+	storedData, err := stored.Marshal()
+	require.NoError(t, err)
+
+	t.Run("cache hits and single repo query for misses", func(t *testing.T) {
+		orderRepo := mocks.NewMockOrderRepo(t)
+		cache := mocks.NewMockCache(t)
+		cache.EXPECT().Get("1").Return(cachedData, true).Once()
+		cache.EXPECT().Get("2").Return(nil, false).Once()
+		cache.EXPECT().Get("3").Return(nil, false).Once()
+		orderRepo.EXPECT().GetOrdersByIDs(mock.Anything, []string{"2", "3"}).Return([]entities.Order{stored}, nil).Once()
+		cache.EXPECT().Set("2", storedData).Return().Once()
+
+		svc := service.NewOrderService(
+			slog.New(slog.NewTextHandler(io.Discard, nil)), txMocks.NewMockManager(t), orderRepo, cache,
+			mocks.NewMockCache(t),
+		)
+
+		orders, missing, err := svc.GetOrdersByIDs(context.Background(), []string{"2", "1", "3", "2"})
+		require.NoError(t, err)
+		assert.Equal(t, []entities.Order{stored, cached}, orders)
+		assert.Equal(t, []string{"3"}, missing)
+	})
+
+	t.Run("all from cache", func(t *testing.T) {
+		cache := mocks.NewMockCache(t)
+		cache.EXPECT().Get("1").Return(cachedData, true).Once()
+
+		svc := service.NewOrderService(
+			slog.New(slog.NewTextHandler(io.Discard, nil)), txMocks.NewMockManager(t), mocks.NewMockOrderRepo(t), cache,
+			mocks.NewMockCache(t),
+		)
+
+		orders, missing, err := svc.GetOrdersByIDs(context.Background(), []string{"1"})
+		require.NoError(t, err)
+		assert.Equal(t, []entities.Order{cached}, orders)
+		assert.Empty(t, missing)
+	})
+}
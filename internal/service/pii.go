@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/utils"
+)
+
+const (
+	// piiAuditBatchSize сколько записей журнала аудита вставляется одним запросом
+	piiAuditBatchSize = 500
+	// piiAuditWriteTimeout ограничение на запись одной пачки вместе с повторами
+	piiAuditWriteTimeout = 10 * time.Second
+)
+
+var (
+	errPIIAuditQueueFull = errors.New("pii audit queue is full")
+	errPIIAuditClosed    = errors.New("pii audit is closed")
+)
+
+type PIIAuditRepo interface {
+	SavePIIAccesses(ctx context.Context, accesses []entities.PIIAccess) error
+}
+
+// PIIService маскирует персональные данные получателя и ведет журнал их раскрытия.
+// Записи журнала копятся в очереди и вставляются пачками в фоне, поэтому чтение заказа не ждет Postgres.
+// Записи из очереди теряются только при аварийном завершении процесса, при остановке их дописывает Close.
+type PIIService struct {
+	logger        *slog.Logger
+	repo          PIIAuditRepo
+	fields        []string
+	flushInterval time.Duration
+
+	mu     sync.RWMutex
+	closed bool
+	audit  chan entities.PIIAccess
+	// done закрывается, когда запись журнала закончена
+	done chan struct{}
+}
+
+func NewPIIService(logger *slog.Logger, cfg config.PII, repo PIIAuditRepo) *PIIService {
+	return &PIIService{
+		logger:        logger.With(slog.String("service", "pii")),
+		repo:          repo,
+		fields:        cfg.MaskedFields,
+		flushInterval: cfg.AuditFlushInterval,
+		audit:         make(chan entities.PIIAccess, cfg.AuditQueueSize),
+		done:          make(chan struct{}),
+	}
+}
+
+// CanReveal разрешено ли клиенту видеть персональные данные без маскирования
+func (s *PIIService) CanReveal(p entities.Principal) bool {
+	return p.HasScope(entities.ScopePIIRead)
+}
+
+// LogAccess ставит раскрытие персональных данных в очередь журнала аудита. Если очередь заполнена
+// или журнал закрыт, возвращает ошибку: без записи в журнал данные не должны выдаваться.
+func (s *PIIService) LogAccess(_ context.Context, a entities.PIIAccess) error {
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return errPIIAuditClosed
+	}
+	select {
+	case s.audit <- a:
+		return nil
+	default:
+		piiAuditRejected.Inc()
+		return errPIIAuditQueueFull
+	}
+}
+
+// Start запускает запись журнала аудита в фоне. Она продолжается и после отмены ctx, до Close:
+// при остановке запросы дорабатывают и раскрывают данные, их записи тоже должны попасть в журнал.
+func (s *PIIService) Start(context.Context) error {
+	go s.writeAudit()
+	return nil
+}
+
+// Close перестает принимать записи и ждет, пока очередь будет записана. Вызывается после Start,
+// когда серверы уже остановлены.
+func (s *PIIService) Close() {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.audit)
+	}
+	s.mu.Unlock()
+	<-s.done
+}
+
+func (s *PIIService) writeAudit() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]entities.PIIAccess, 0, piiAuditBatchSize)
+	for {
+		select {
+		case a, ok := <-s.audit:
+			if !ok {
+				s.flushAudit(batch)
+				return
+			}
+			batch = append(batch, a)
+			if len(batch) < piiAuditBatchSize {
+				continue
+			}
+		case <-ticker.C:
+		}
+		batch = s.flushAudit(batch)
+	}
+}
+
+// flushAudit вставляет пачку записей и возвращает ее очищенной для следующих
+func (s *PIIService) flushAudit(batch []entities.PIIAccess) []entities.PIIAccess {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), piiAuditWriteTimeout)
+	defer cancel()
+	err := utils.Retry(retryConfig, func() error {
+		return s.repo.SavePIIAccesses(ctx, batch)
+	})
+	if err != nil {
+		// данные уже выданы, поэтому потерю записей можно только зафиксировать
+		piiAuditLost.Add(float64(len(batch)))
+		s.logger.Error("failed to save pii access log, records lost",
+			slog.Any("error", err), slog.Int("count", len(batch)))
+	}
+	return batch[:0]
+}
+
+// MaskDelivery маскирует поля доставки по политике
+func (s *PIIService) MaskDelivery(d entities.Delivery) entities.Delivery {
+	for field, value := range map[string]*string{
+		entities.PIIFieldName:    &d.Name,
+		entities.PIIFieldPhone:   &d.Phone,
+		entities.PIIFieldZIP:     &d.ZIP,
+		entities.PIIFieldCity:    &d.City,
+		entities.PIIFieldAddress: &d.Address,
+		entities.PIIFieldRegion:  &d.Region,
+		entities.PIIFieldEmail:   &d.Email,
+	} {
+		if !slices.Contains(s.fields, field) {
+			continue
+		}
+		switch field {
+		case entities.PIIFieldPhone:
+			*value = maskPhone(*value)
+		case entities.PIIFieldEmail:
+			*value = maskEmail(*value)
+		default:
+			*value = maskWords(*value)
+		}
+	}
+	return d
+}
+
+// maskPhone оставляет код страны и последние 4 цифры: +7******4567
+func maskPhone(phone string) string {
+	const prefix, suffix = 2, 4
+	runes := []rune(phone)
+	if len(runes) <= prefix+suffix {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:prefix]) + strings.Repeat("*", len(runes)-prefix-suffix) + string(runes[len(runes)-suffix:])
+}
+
+// maskEmail оставляет первую букву имени и домен: j***@example.com
+func maskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return maskWords(email)
+	}
+	first, _ := utf8.DecodeRuneInString(local)
+	return string(first) + "***@" + domain
+}
+
+// maskWords оставляет первую букву каждого слова: Test Testov -> T*** T*****
+func maskWords(s string) string {
+	words := strings.Fields(s)
+	for i, word := range words {
+		first, size := utf8.DecodeRuneInString(word)
+		words[i] = string(first) + strings.Repeat("*", utf8.RuneCountInString(word[size:]))
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,124 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/service"
+	mocks "github.com/SergeyBogomolovv/l0-order-service/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPIIService_MaskDelivery(t *testing.T) {
+	delivery := entities.Delivery{
+		Name:    "Test Testov",
+		Phone:   "+79991234567",
+		ZIP:     "2639809",
+		City:    "Kiryat Mozkin",
+		Address: "Ploshad Mira 15",
+		Region:  "Kraiot",
+		Email:   "john@example.com",
+	}
+
+	testCases := []struct {
+		name   string
+		fields []string
+		want   entities.Delivery
+	}{
+		{
+			name:   "default policy",
+			fields: []string{"name", "phone", "address", "email"},
+			want: entities.Delivery{
+				Name:    "T*** T*****",
+				Phone:   "+7******4567",
+				ZIP:     "2639809",
+				City:    "Kiryat Mozkin",
+				Address: "P****** M*** 1*",
+				Region:  "Kraiot",
+				Email:   "j***@example.com",
+			},
+		},
+		{
+			name:   "only phone",
+			fields: []string{"phone"},
+			want: entities.Delivery{
+				Name:    "Test Testov",
+				Phone:   "+7******4567",
+				ZIP:     "2639809",
+				City:    "Kiryat Mozkin",
+				Address: "Ploshad Mira 15",
+				Region:  "Kraiot",
+				Email:   "john@example.com",
+			},
+		},
+		{
+			name:   "nothing masked",
+			fields: nil,
+			want:   delivery,
+		},
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := service.NewPIIService(logger, config.PII{MaskedFields: tc.fields}, mocks.NewMockPIIAuditRepo(t))
+			assert.Equal(t, tc.want, svc.MaskDelivery(delivery))
+		})
+	}
+}
+
+func TestPIIService_MaskDelivery_Edge(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := config.PII{MaskedFields: []string{"name", "phone", "email"}}
+	svc := service.NewPIIService(logger, cfg, mocks.NewMockPIIAuditRepo(t))
+
+	got := svc.MaskDelivery(entities.Delivery{Name: "Иван", Phone: "12345", Email: "not-an-email"})
+	assert.Equal(t, entities.Delivery{Name: "И***", Phone: "*****", Email: "n***********"}, got)
+}
+
+func TestPIIService_CanReveal(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := service.NewPIIService(logger, config.PII{}, mocks.NewMockPIIAuditRepo(t))
+
+	assert.True(t, svc.CanReveal(entities.Principal{Scopes: []string{entities.ScopePIIRead}}))
+	assert.True(t, svc.CanReveal(entities.Principal{Scopes: []string{entities.ScopeAdmin}}))
+	assert.False(t, svc.CanReveal(entities.Principal{Scopes: []string{entities.ScopeOrdersRead}}))
+}
+
+func TestPIIService_LogAccess(t *testing.T) {
+	repo := mocks.NewMockPIIAuditRepo(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := config.PII{AuditQueueSize: 2, AuditFlushInterval: time.Hour}
+	svc := service.NewPIIService(logger, cfg, repo)
+	ctx := context.Background()
+
+	first := entities.PIIAccess{Subject: "a", Resource: "/order/1", OrderUIDs: []string{"1"}}
+	second := entities.PIIAccess{Subject: "b", Resource: "/order/2", OrderUIDs: []string{"2"}}
+	require.NoError(t, svc.LogAccess(ctx, first))
+	require.NoError(t, svc.LogAccess(ctx, second))
+	// пока записи не вставлены, очередь заполнена и данные без записи в журнал не выдаются
+	assert.Error(t, svc.LogAccess(ctx, first))
+
+	var saved []entities.PIIAccess
+	repo.EXPECT().SavePIIAccesses(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, accesses []entities.PIIAccess) error {
+			saved = append(saved, accesses...)
+			return nil
+		}).Once()
+
+	require.NoError(t, svc.Start(ctx))
+	// при закрытии очередь дописывается пачкой, не дожидаясь интервала
+	svc.Close()
+
+	require.Len(t, saved, 2)
+	assert.Equal(t, []string{"a", "b"}, []string{saved[0].Subject, saved[1].Subject})
+	assert.False(t, saved[0].CreatedAt.IsZero())
+	assert.Error(t, svc.LogAccess(ctx, second))
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+)
+
+// OrderWaiter будит запросы, которые ждут появления заказа.
+// Уведомления приходят из SaveOrder этого процесса и через Postgres NOTIFY от других реплик.
+type OrderWaiter struct {
+	mu      sync.Mutex
+	waiting map[string]map[chan struct{}]struct{}
+}
+
+func NewOrderWaiter() *OrderWaiter {
+	return &OrderWaiter{waiting: make(map[string]map[chan struct{}]struct{})}
+}
+
+// Wait возвращает канал, который закроется при сохранении заказа, и функцию отмены ожидания.
+// Подписываться нужно до проверки заказа в базе, иначе можно пропустить сохранение между ними.
+func (w *OrderWaiter) Wait(orderUID string) (<-chan struct{}, func()) {
+	ch := make(chan struct{})
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.waiting[orderUID] == nil {
+		w.waiting[orderUID] = make(map[chan struct{}]struct{})
+	}
+	w.waiting[orderUID][ch] = struct{}{}
+
+	return ch, func() { w.cancel(orderUID, ch) }
+}
+
+// Notify будит всех, кто ждет заказ orderUID
+func (w *OrderWaiter) Notify(orderUID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for ch := range w.waiting[orderUID] {
+		close(ch)
+	}
+	delete(w.waiting, orderUID)
+}
+
+// NotifyAll будит всех ожидающих, чтобы они перепроверили базу.
+// Нужен, когда уведомления могли потеряться, например при переподключении к Postgres.
+func (w *OrderWaiter) NotifyAll() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, chans := range w.waiting {
+		for ch := range chans {
+			close(ch)
+		}
+	}
+	clear(w.waiting)
+}
+
+// Publish подходит в качестве SaveHook
+func (w *OrderWaiter) Publish(_ context.Context, order entities.Order) {
+	w.Notify(order.OrderUID)
+}
+
+func (w *OrderWaiter) cancel(orderUID string, ch chan struct{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	chans, ok := w.waiting[orderUID]
+	if !ok {
+		return
+	}
+	// канал уже закрыт и удален, если заказ был сохранен
+	delete(chans, ch)
+	if len(chans) == 0 {
+		delete(w.waiting, orderUID)
+	}
+}
@@ -0,0 +1,40 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderWaiter(t *testing.T) {
+	waiter := service.NewOrderWaiter()
+
+	first, cancelFirst := waiter.Wait("1")
+	defer cancelFirst()
+	second, cancelSecond := waiter.Wait("2")
+	cancelled, cancel := waiter.Wait("1")
+	cancel()
+
+	waiter.Publish(context.Background(), entities.Order{OrderUID: "1"})
+
+	assert.True(t, isClosed(first))
+	assert.False(t, isClosed(second))
+	assert.False(t, isClosed(cancelled))
+
+	waiter.NotifyAll()
+	assert.True(t, isClosed(second))
+	// отмена после уведомления ничего не ломает
+	cancelSecond()
+}
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+)
+
+// webhookSecretSize длина секрета подписи в байтах
+const webhookSecretSize = 32
+
+type WebhookRepo interface {
+	CreateWebhook(ctx context.Context, w entities.Webhook) (entities.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]entities.Webhook, error)
+	ListEnabledWebhooks(ctx context.Context, event string) ([]entities.Webhook, error)
+	GetWebhook(ctx context.Context, id int64) (entities.Webhook, error)
+	UpdateWebhook(ctx context.Context, id int64, upd entities.WebhookUpdate) (entities.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+
+	RecordWebhookSuccess(ctx context.Context, id int64) error
+	RecordWebhookFailure(ctx context.Context, id int64, disableAfter int) (bool, error)
+	SaveWebhookDelivery(ctx context.Context, d entities.WebhookDelivery) error
+	ClaimWebhookRetries(
+		ctx context.Context, now time.Time, limit int, lease time.Duration,
+	) ([]entities.WebhookRetry, error)
+	ListWebhookDeliveries(ctx context.Context, webhookID int64, limit int) ([]entities.WebhookDelivery, error)
+	DeleteOldWebhookDeliveries(ctx context.Context, createdBefore time.Time) (int64, error)
+}
+
+// WebhookService управляет вебхуками партнеров
+type WebhookService struct {
+	repo WebhookRepo
+}
+
+func NewWebhookService(repo WebhookRepo) *WebhookService {
+	return &WebhookService{repo: repo}
+}
+
+// CreateWebhook регистрирует вебхук со сгенерированным секретом подписи
+func (s *WebhookService) CreateWebhook(ctx context.Context, url string, events []string) (entities.Webhook, error) {
+	secret := make([]byte, webhookSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return entities.Webhook{}, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	return s.repo.CreateWebhook(ctx, entities.Webhook{
+		URL:    url,
+		Secret: hex.EncodeToString(secret),
+		Events: events,
+	})
+}
+
+func (s *WebhookService) ListWebhooks(ctx context.Context) ([]entities.Webhook, error) {
+	return s.repo.ListWebhooks(ctx)
+}
+
+func (s *WebhookService) GetWebhook(ctx context.Context, id int64) (entities.Webhook, error) {
+	return s.repo.GetWebhook(ctx, id)
+}
+
+func (s *WebhookService) UpdateWebhook(
+	ctx context.Context, id int64, upd entities.WebhookUpdate,
+) (entities.Webhook, error) {
+	return s.repo.UpdateWebhook(ctx, id, upd)
+}
+
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id int64) error {
+	return s.repo.DeleteWebhook(ctx, id)
+}
+
+// ListWebhookDeliveries возвращает журнал доставок вебхука, начиная с последних попыток
+func (s *WebhookService) ListWebhookDeliveries(
+	ctx context.Context, id int64, limit int,
+) ([]entities.WebhookDelivery, error) {
+	// пустой журнал у несуществующего вебхука должен отличаться от журнала вебхука без доставок
+	if _, err := s.repo.GetWebhook(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListWebhookDeliveries(ctx, id, limit)
+}
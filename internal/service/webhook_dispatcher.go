@@ -0,0 +1,362 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	mathrand "math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/utils"
+)
+
+// Заголовки запроса с событием
+const (
+	WebhookIDHeader        = "X-Webhook-ID"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	// WebhookSignatureHeader содержит sha256=<hex HMAC-SHA256 от "<timestamp>.<тело>">
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// webhookResponseLimit сколько байт ответа получателя вычитывается, чтобы переиспользовать соединение
+const webhookResponseLimit = 4 << 10
+
+// webhookCleanupInterval как часто удаляются записи журнала доставок старше DeliveryRetention
+const webhookCleanupInterval = time.Hour
+
+// WebhookEncoder кодирует событие в тело запроса
+type WebhookEncoder func(event entities.WebhookEvent) ([]byte, error)
+
+// SignWebhook подписывает тело события. Время входит в подпись, чтобы перехваченный запрос
+// нельзя было повторить позже допустимого получателем окна.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookJob доставка события одному вебхуку
+type webhookJob struct {
+	webhook entities.Webhook
+	event   entities.WebhookEvent
+	body    []byte
+	attempt int
+}
+
+// WebhookDispatcher доставляет события по заказам вебхукам партнеров.
+// События ставятся в очередь в памяти, поэтому сохранение заказа не ждет получателей.
+// Неудачная доставка повторяется с экспоненциальной задержкой, а вебхук,
+// которому подряд не удалось доставить DisableAfter событий, выключается.
+// Повтор записывается в Postgres вместе с попыткой, поэтому переживает перезапуск и выполняется любой репликой.
+// Теряются при остановке только события из очереди, до первой попытки.
+type WebhookDispatcher struct {
+	logger *slog.Logger
+	repo   WebhookRepo
+	encode WebhookEncoder
+	client *http.Client
+	cfg    config.Webhooks
+
+	events chan entities.WebhookEvent
+	jobs   chan webhookJob
+}
+
+func NewWebhookDispatcher(
+	logger *slog.Logger, cfg config.Webhooks, repo WebhookRepo, encode WebhookEncoder,
+) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		logger: logger.With(slog.String("service", "webhook")),
+		repo:   repo,
+		encode: encode,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			// редирект считается ошибкой, получатель должен зарегистрировать итоговый адрес
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		cfg:    cfg,
+		events: make(chan entities.WebhookEvent, cfg.QueueSize),
+		jobs:   make(chan webhookJob, cfg.Workers),
+	}
+}
+
+// Publish ставит в очередь событие order.saved, подходит в качестве SaveHook для OnSave
+func (d *WebhookDispatcher) Publish(ctx context.Context, order entities.Order) {
+	d.publish(ctx, entities.EventOrderSaved, order)
+}
+
+// PublishUpdated ставит в очередь событие order.updated, подходит в качестве SaveHook для OnUpdate
+func (d *WebhookDispatcher) PublishUpdated(ctx context.Context, order entities.Order) {
+	d.publish(ctx, entities.EventOrderUpdated, order)
+}
+
+func (d *WebhookDispatcher) publish(ctx context.Context, eventType string, order entities.Order) {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id) // crypto/rand.Read не возвращает ошибок
+	event := entities.WebhookEvent{
+		ID:        hex.EncodeToString(id),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Order:     order,
+	}
+
+	select {
+	case d.events <- event:
+	default:
+		webhookEventsDropped.Inc()
+		d.logger.WarnContext(ctx, "webhook queue is full, event dropped",
+			slog.String("event", eventType), slog.String("orderUID", order.OrderUID))
+	}
+}
+
+// Start запускает доставку событий, запланированных повторов и очистку журнала в фоне до отмены ctx
+func (d *WebhookDispatcher) Start(ctx context.Context) error {
+	go d.fanOut(ctx)
+	go d.retry(ctx)
+	go d.cleanup(ctx)
+	for range d.cfg.Workers {
+		go d.work(ctx)
+	}
+	return nil
+}
+
+// fanOut превращает событие в доставки всем подписанным на него вебхукам
+func (d *WebhookDispatcher) fanOut(ctx context.Context) {
+	for {
+		var event entities.WebhookEvent
+		select {
+		case <-ctx.Done():
+			return
+		case event = <-d.events:
+		}
+
+		var webhooks []entities.Webhook
+		err := utils.Retry(retryConfig, func() error {
+			var err error
+			webhooks, err = d.repo.ListEnabledWebhooks(ctx, event.Type)
+			return err
+		})
+		if err != nil {
+			d.logger.ErrorContext(ctx, "failed to list webhooks, event dropped",
+				slog.Any("error", err), slog.String("eventID", event.ID))
+			continue
+		}
+		if len(webhooks) == 0 {
+			continue
+		}
+
+		body, err := d.encode(event)
+		if err != nil {
+			d.logger.ErrorContext(ctx, "failed to encode webhook event",
+				slog.Any("error", err), slog.String("eventID", event.ID))
+			continue
+		}
+
+		for _, webhook := range webhooks {
+			select {
+			case d.jobs <- webhookJob{webhook: webhook, event: event, body: body, attempt: 1}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// retry забирает из Postgres повторы, время которых наступило, и передает их воркерам
+func (d *WebhookDispatcher) retry(ctx context.Context) {
+	// повтор закреплен за репликой, пока воркер не запишет попытку. Если закрепление истечет раньше,
+	// повтор заберет другая реплика и получатель получит событие дважды, отбросить его можно по X-Webhook-ID
+	lease := 2*d.cfg.Timeout + d.cfg.RetryPollInterval
+
+	ticker := time.NewTicker(d.cfg.RetryPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			retries, err := d.repo.ClaimWebhookRetries(ctx, time.Now(), d.cfg.Workers, lease)
+			if err != nil {
+				if ctx.Err() == nil {
+					d.logger.ErrorContext(ctx, "failed to claim webhook retries", slog.Any("error", err))
+				}
+				break
+			}
+
+			for _, retry := range retries {
+				job := webhookJob{
+					webhook: retry.Webhook,
+					event: entities.WebhookEvent{
+						ID:    retry.EventID,
+						Type:  retry.Event,
+						Order: entities.Order{OrderUID: retry.OrderUID},
+					},
+					body:    retry.Payload,
+					attempt: retry.Attempt + 1,
+				}
+				select {
+				case d.jobs <- job:
+				case <-ctx.Done():
+					return
+				}
+			}
+			// неполная пачка значит, что наступивших повторов больше нет
+			if len(retries) < d.cfg.Workers {
+				break
+			}
+		}
+	}
+}
+
+// cleanup удаляет старые записи журнала доставок, иначе он растет с каждой попыткой без ограничений
+func (d *WebhookDispatcher) cleanup(ctx context.Context) {
+	ticker := time.NewTicker(webhookCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		deleted, err := d.repo.DeleteOldWebhookDeliveries(ctx, time.Now().Add(-d.cfg.DeliveryRetention))
+		if err != nil {
+			if ctx.Err() == nil {
+				d.logger.ErrorContext(ctx, "failed to delete old webhook deliveries", slog.Any("error", err))
+			}
+			continue
+		}
+		if deleted > 0 {
+			d.logger.DebugContext(ctx, "old webhook deliveries deleted", slog.Int64("count", deleted))
+		}
+	}
+}
+
+func (d *WebhookDispatcher) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-d.jobs:
+			d.deliver(ctx, job)
+		}
+	}
+}
+
+func (d *WebhookDispatcher) deliver(ctx context.Context, job webhookJob) {
+	logger := d.logger.With(
+		slog.Int64("webhookID", job.webhook.ID),
+		slog.String("eventID", job.event.ID),
+		slog.Int("attempt", job.attempt),
+	)
+
+	start := time.Now()
+	status, err := d.send(ctx, job)
+	delivery := entities.WebhookDelivery{
+		WebhookID:  job.webhook.ID,
+		EventID:    job.event.ID,
+		Event:      job.event.Type,
+		OrderUID:   job.event.Order.OrderUID,
+		Attempt:    job.attempt,
+		StatusCode: status,
+		Duration:   time.Since(start),
+	}
+	retry := err != nil && job.attempt < d.cfg.MaxAttempts
+	var delay time.Duration
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	if retry {
+		// воркер не ждет задержку, чтобы недоступный получатель не задерживал остальных
+		delay = d.backoff(job.attempt)
+		delivery.NextAttemptAt = time.Now().Add(delay)
+		delivery.Payload = job.body
+	}
+	if err := d.repo.SaveWebhookDelivery(ctx, delivery); err != nil {
+		logger.ErrorContext(ctx, "failed to save webhook delivery", slog.Any("error", err), slog.Bool("retry", retry))
+	}
+
+	if err == nil {
+		webhookDeliveries.WithLabelValues("success").Inc()
+		if err := d.repo.RecordWebhookSuccess(ctx, job.webhook.ID); err != nil {
+			logger.ErrorContext(ctx, "failed to record webhook success", slog.Any("error", err))
+		}
+		return
+	}
+	webhookDeliveries.WithLabelValues("failure").Inc()
+
+	if retry {
+		logger.WarnContext(ctx, "webhook delivery failed, will retry",
+			slog.Any("error", err), slog.Duration("delay", delay))
+		return
+	}
+
+	logger.WarnContext(ctx, "webhook delivery failed, giving up", slog.Any("error", err))
+	disabled, err := d.repo.RecordWebhookFailure(ctx, job.webhook.ID, d.cfg.DisableAfter)
+	if err != nil && !errors.Is(err, entities.ErrWebhookNotFound) {
+		logger.ErrorContext(ctx, "failed to record webhook failure", slog.Any("error", err))
+		return
+	}
+	if disabled {
+		webhooksDisabled.Inc()
+		logger.WarnContext(ctx, "webhook disabled after consecutive failures", slog.String("url", job.webhook.URL))
+	}
+}
+
+// send выполняет одну попытку доставки и возвращает код ответа, 0 если ответ не получен
+func (d *WebhookDispatcher) send(ctx context.Context, job webhookJob) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.webhook.URL, bytes.NewReader(job.body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// подпись считается заново на каждую попытку, чтобы время в ней было свежим
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, job.event.ID)
+	req.Header.Set(WebhookEventHeader, job.event.Type)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(job.webhook.Secret, timestamp, job.body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close() //nolint:errcheck // тело уже прочитано
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, webhookResponseLimit))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff задержка перед следующей попыткой. Половина задержки случайна,
+// чтобы повторы от разных событий не приходили получателю одновременно.
+func (d *WebhookDispatcher) backoff(attempt int) time.Duration {
+	delay := d.cfg.InitialBackoff
+	for i := 1; i < attempt && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, d.cfg.MaxBackoff)
+	return delay/2 + mathrand.N(delay/2+1)
+}
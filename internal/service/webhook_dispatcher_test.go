@@ -0,0 +1,277 @@
+package service_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/internal/config"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/entities"
+	"github.com/SergeyBogomolovv/l0-order-service/internal/service"
+	mocks "github.com/SergeyBogomolovv/l0-order-service/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var webhookConfig = config.Webhooks{
+	Workers:        2,
+	QueueSize:      10,
+	Timeout:        time.Second,
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     5 * time.Millisecond,
+	DisableAfter:   5,
+
+	RetryPollInterval: time.Millisecond,
+}
+
+func encodeTestEvent(e entities.WebhookEvent) ([]byte, error) {
+	return json.Marshal(map[string]string{"id": e.ID, "type": e.Type, "order_uid": e.Order.OrderUID})
+}
+
+// webhookReceiver принимает события и отвечает кодами из statuses по очереди
+type webhookReceiver struct {
+	t        *testing.T
+	secret   string
+	statuses []int
+
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rv *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	require.NoError(rv.t, err)
+
+	// подпись проверяется так же, как это сделает партнер
+	mac := hmac.New(sha256.New, []byte(rv.secret))
+	mac.Write([]byte(r.Header.Get(service.WebhookTimestampHeader) + "."))
+	mac.Write(body)
+	assert.Equal(rv.t, "sha256="+hex.EncodeToString(mac.Sum(nil)), r.Header.Get(service.WebhookSignatureHeader))
+
+	rv.mu.Lock()
+	defer rv.mu.Unlock()
+	status := rv.statuses[min(len(rv.requests), len(rv.statuses)-1)]
+	rv.requests = append(rv.requests, r)
+	rv.bodies = append(rv.bodies, body)
+	w.WriteHeader(status)
+}
+
+// retryStore имитирует журнал попыток в Postgres: попытка с NextAttemptAt становится повтором,
+// который забирается, когда его время наступит
+type retryStore struct {
+	webhook entities.Webhook
+
+	mu       sync.Mutex
+	attempts []entities.WebhookDelivery
+	pending  []entities.WebhookDelivery
+}
+
+func (s *retryStore) save(_ context.Context, d entities.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts = append(s.attempts, d)
+	if !d.NextAttemptAt.IsZero() {
+		s.pending = append(s.pending, d)
+	}
+	return nil
+}
+
+func (s *retryStore) claim(
+	_ context.Context, now time.Time, limit int, _ time.Duration,
+) ([]entities.WebhookRetry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var retries []entities.WebhookRetry
+	rest := s.pending[:0]
+	for _, d := range s.pending {
+		if d.NextAttemptAt.After(now) || len(retries) == limit {
+			rest = append(rest, d)
+			continue
+		}
+		retries = append(retries, entities.WebhookRetry{
+			Webhook: s.webhook, EventID: d.EventID, Event: d.Event, OrderUID: d.OrderUID,
+			Attempt: d.Attempt, Payload: d.Payload,
+		})
+	}
+	s.pending = rest
+	return retries, nil
+}
+
+func TestWebhookDispatcher_Retry(t *testing.T) {
+	receiver := &webhookReceiver{t: t, secret: "secret", statuses: []int{500, 503, 200}}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	webhook := entities.Webhook{ID: 1, URL: srv.URL, Secret: "secret", Enabled: true}
+	repo := mocks.NewMockWebhookRepo(t)
+	repo.EXPECT().ListEnabledWebhooks(mock.Anything, entities.EventOrderSaved).
+		Return([]entities.Webhook{webhook}, nil).Once()
+
+	store := &retryStore{webhook: webhook}
+	repo.EXPECT().SaveWebhookDelivery(mock.Anything, mock.Anything).RunAndReturn(store.save).Times(3)
+	repo.EXPECT().ClaimWebhookRetries(mock.Anything, mock.Anything, webhookConfig.Workers, mock.Anything).
+		RunAndReturn(store.claim)
+
+	done := make(chan struct{})
+	repo.EXPECT().RecordWebhookSuccess(mock.Anything, int64(1)).
+		RunAndReturn(func(context.Context, int64) error {
+			close(done)
+			return nil
+		}).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	dispatcher := service.NewWebhookDispatcher(logger, webhookConfig, repo, encodeTestEvent)
+	require.NoError(t, dispatcher.Start(ctx))
+	dispatcher.Publish(ctx, entities.Order{OrderUID: "123"})
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered")
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	attempts := store.attempts
+	require.Len(t, attempts, 3)
+	for i, d := range attempts {
+		assert.Equal(t, i+1, d.Attempt)
+		assert.Equal(t, "123", d.OrderUID)
+		assert.Equal(t, entities.EventOrderSaved, d.Event)
+		// все попытки доставляют одно и то же событие
+		assert.Equal(t, attempts[0].EventID, d.EventID)
+	}
+	assert.Equal(t, 500, attempts[0].StatusCode)
+	assert.Equal(t, "unexpected status code 500", attempts[0].Error)
+	// повтор запланирован и хранит тело события только после неудачных попыток
+	assert.False(t, attempts[0].NextAttemptAt.IsZero())
+	assert.NotEmpty(t, attempts[0].Payload)
+	assert.Equal(t, 200, attempts[2].StatusCode)
+	assert.Empty(t, attempts[2].Error)
+	assert.True(t, attempts[2].NextAttemptAt.IsZero())
+	assert.Empty(t, attempts[2].Payload)
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	assert.Equal(t, attempts[0].EventID, receiver.requests[2].Header.Get(service.WebhookIDHeader))
+	assert.Equal(t, entities.EventOrderSaved, receiver.requests[2].Header.Get(service.WebhookEventHeader))
+	wantBody := `{"id":"` + attempts[0].EventID + `","type":"order.saved","order_uid":"123"}`
+	assert.JSONEq(t, wantBody, string(receiver.bodies[2]))
+}
+
+func TestWebhookDispatcher_Disable(t *testing.T) {
+	receiver := &webhookReceiver{t: t, secret: "secret", statuses: []int{500}}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	cfg := webhookConfig
+	cfg.MaxAttempts = 2
+
+	webhook := entities.Webhook{ID: 1, URL: srv.URL, Secret: "secret", Enabled: true}
+	repo := mocks.NewMockWebhookRepo(t)
+	repo.EXPECT().ListEnabledWebhooks(mock.Anything, entities.EventOrderSaved).
+		Return([]entities.Webhook{webhook}, nil).Once()
+	store := &retryStore{webhook: webhook}
+	repo.EXPECT().SaveWebhookDelivery(mock.Anything, mock.Anything).RunAndReturn(store.save).Times(2)
+	repo.EXPECT().ClaimWebhookRetries(mock.Anything, mock.Anything, cfg.Workers, mock.Anything).
+		RunAndReturn(store.claim)
+
+	done := make(chan struct{})
+	repo.EXPECT().RecordWebhookFailure(mock.Anything, int64(1), cfg.DisableAfter).
+		RunAndReturn(func(context.Context, int64, int) (bool, error) {
+			close(done)
+			return true, nil
+		}).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	dispatcher := service.NewWebhookDispatcher(logger, cfg, repo, encodeTestEvent)
+	require.NoError(t, dispatcher.Start(ctx))
+	dispatcher.Publish(ctx, entities.Order{OrderUID: "123"})
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("failure was not recorded")
+	}
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	assert.Len(t, receiver.requests, 2)
+}
+
+func TestWebhookDispatcher_ResumeRetry(t *testing.T) {
+	receiver := &webhookReceiver{t: t, secret: "secret", statuses: []int{200}}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	// повтор запланировала реплика, которая остановилась до него
+	retry := entities.WebhookRetry{
+		Webhook:  entities.Webhook{ID: 1, URL: srv.URL, Secret: "secret", Enabled: true},
+		EventID:  "e1",
+		Event:    entities.EventOrderSaved,
+		OrderUID: "123",
+		Attempt:  2,
+		Payload:  []byte(`{"id":"e1"}`),
+	}
+	repo := mocks.NewMockWebhookRepo(t)
+	repo.EXPECT().ClaimWebhookRetries(mock.Anything, mock.Anything, webhookConfig.Workers, mock.Anything).
+		Return([]entities.WebhookRetry{retry}, nil).Once()
+	repo.EXPECT().ClaimWebhookRetries(mock.Anything, mock.Anything, webhookConfig.Workers, mock.Anything).
+		Return(nil, nil)
+	repo.EXPECT().SaveWebhookDelivery(mock.Anything, mock.MatchedBy(func(d entities.WebhookDelivery) bool {
+		return d.EventID == "e1" && d.Attempt == 3 && d.StatusCode == 200
+	})).Return(nil).Once()
+
+	done := make(chan struct{})
+	repo.EXPECT().RecordWebhookSuccess(mock.Anything, int64(1)).
+		RunAndReturn(func(context.Context, int64) error {
+			close(done)
+			return nil
+		}).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	dispatcher := service.NewWebhookDispatcher(logger, webhookConfig, repo, encodeTestEvent)
+	require.NoError(t, dispatcher.Start(ctx))
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("retry was not delivered")
+	}
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	require.Len(t, receiver.requests, 1)
+	assert.Equal(t, "e1", receiver.requests[0].Header.Get(service.WebhookIDHeader))
+	assert.Equal(t, `{"id":"e1"}`, string(receiver.bodies[0]))
+}
+
+func TestSignWebhook(t *testing.T) {
+	// значение посчитано независимо: printf '1700000000.{}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t,
+		"sha256=b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163",
+		service.SignWebhook("secret", 1700000000, []byte("{}")),
+	)
+}
@@ -0,0 +1,12 @@
+BEGIN;
+
+DROP INDEX IF EXISTS items_brand_idx;
+DROP INDEX IF EXISTS items_order_uid_idx;
+DROP INDEX IF EXISTS payments_provider_idx;
+DROP INDEX IF EXISTS payments_currency_idx;
+DROP INDEX IF EXISTS orders_locale_idx;
+DROP INDEX IF EXISTS orders_delivery_service_idx;
+DROP INDEX IF EXISTS orders_customer_id_idx;
+DROP INDEX IF EXISTS orders_date_created_idx;
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS orders_date_created_idx ON orders (date_created, order_uid);
+CREATE INDEX IF NOT EXISTS orders_customer_id_idx ON orders (customer_id, date_created, order_uid);
+CREATE INDEX IF NOT EXISTS orders_delivery_service_idx ON orders (delivery_service, date_created);
+CREATE INDEX IF NOT EXISTS orders_locale_idx ON orders (locale, date_created);
+
+CREATE INDEX IF NOT EXISTS payments_currency_idx ON payments (currency);
+CREATE INDEX IF NOT EXISTS payments_provider_idx ON payments (provider);
+
+-- товары загружаются по order_uid для каждого заказа, а индекса по внешнему ключу не было
+CREATE INDEX IF NOT EXISTS items_order_uid_idx ON items (order_uid);
+CREATE INDEX IF NOT EXISTS items_brand_idx ON items (brand, order_uid);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP INDEX IF EXISTS items_track_number_idx;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS items_track_number_idx ON items (track_number);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS orders_notify_saved ON orders;
+DROP FUNCTION IF EXISTS notify_order_saved();
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+-- уведомление доставляется слушателям после коммита, когда доставка, оплата и товары уже сохранены
+CREATE OR REPLACE FUNCTION notify_order_saved() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('order_saved', NEW.order_uid);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER orders_notify_saved
+  AFTER INSERT ON orders
+  FOR EACH ROW EXECUTE FUNCTION notify_order_saved();
+
+COMMIT;
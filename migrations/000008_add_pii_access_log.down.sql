@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS pii_access_log;
+
+COMMIT;
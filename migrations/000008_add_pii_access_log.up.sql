@@ -0,0 +1,15 @@
+BEGIN;
+
+-- журнал выдачи персональных данных получателей без маскирования
+CREATE TABLE IF NOT EXISTS pii_access_log (
+  id BIGSERIAL PRIMARY KEY,
+  subject TEXT NOT NULL,
+  resource TEXT NOT NULL,
+  order_uids TEXT[] NOT NULL DEFAULT '{}', -- пустой для потоков, там раскрывается каждый следующий заказ
+  request_id TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS pii_access_log_subject_idx ON pii_access_log (subject, created_at);
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+DROP INDEX IF EXISTS deliveries_key_id_idx;
+DROP INDEX IF EXISTS deliveries_email_bidx_idx;
+DROP INDEX IF EXISTS deliveries_phone_bidx_idx;
+
+ALTER TABLE deliveries
+  DROP COLUMN IF EXISTS email_bidx,
+  DROP COLUMN IF EXISTS phone_bidx,
+  DROP COLUMN IF EXISTS data_key,
+  DROP COLUMN IF EXISTS key_id;
+
+COMMIT;
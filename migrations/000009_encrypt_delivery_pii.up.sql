@@ -0,0 +1,15 @@
+BEGIN;
+
+-- персональные данные получателя шифруются ключом данных строки (data_key),
+-- который зашифрован ключом связки key_id. Строки с key_id NULL еще не зашифрованы.
+ALTER TABLE deliveries
+  ADD COLUMN IF NOT EXISTS key_id TEXT,
+  ADD COLUMN IF NOT EXISTS data_key BYTEA,
+  ADD COLUMN IF NOT EXISTS phone_bidx TEXT, -- слепые индексы для поиска по точному совпадению
+  ADD COLUMN IF NOT EXISTS email_bidx TEXT;
+
+CREATE INDEX IF NOT EXISTS deliveries_phone_bidx_idx ON deliveries (phone_bidx);
+CREATE INDEX IF NOT EXISTS deliveries_email_bidx_idx ON deliveries (email_bidx);
+CREATE INDEX IF NOT EXISTS deliveries_key_id_idx ON deliveries (key_id);
+
+COMMIT;
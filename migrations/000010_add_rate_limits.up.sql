@@ -0,0 +1,13 @@
+BEGIN;
+
+-- корзины ограничения частоты запросов, общие для всех реплик
+CREATE TABLE IF NOT EXISTS rate_limits (
+  key TEXT PRIMARY KEY,
+  tokens DOUBLE PRECISION NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL -- когда корзина наполнится, после этого строку можно удалить
+);
+
+CREATE INDEX IF NOT EXISTS rate_limits_expires_at_idx ON rate_limits (expires_at);
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+CREATE OR REPLACE FUNCTION notify_order_saved() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('order_saved', NEW.order_uid);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+
+-- сводка по покупателю кэшируется на каждой реплике, поэтому новый заказ покупателя сообщается всем
+CREATE OR REPLACE FUNCTION notify_order_saved() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('order_saved', NEW.order_uid);
+  PERFORM pg_notify('customer_orders_changed', NEW.customer_id);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+
+-- неудачная попытка, после которой запланирован повтор, хранит его время и тело события,
+-- поэтому повторы переживают перезапуск сервиса и выполняются любой репликой
+ALTER TABLE webhook_deliveries
+  ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS payload BYTEA;
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_next_attempt_at_idx ON webhook_deliveries (next_attempt_at)
+  WHERE next_attempt_at IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+-- без клиента ключи разных клиентов совпадут, записи живут не дольше HTTP_IDEMPOTENCY_TTL
+DELETE FROM idempotency_keys WHERE subject <> '';
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (key);
+
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS subject;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+-- ключ идемпотентности принадлежит клиенту: другой клиент с тем же ключом не получит чужой ответ
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS subject TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (subject, key);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+-- время последнего сохранения заказа сервисом, из него отдается Last-Modified.
+-- у уже сохраненных заказов оно равно времени миграции, точнее его не восстановить
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS saved_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP INDEX IF EXISTS webhook_deliveries_created_at_idx;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+-- по времени создания удаляются старые записи журнала доставок
+CREATE INDEX IF NOT EXISTS webhook_deliveries_created_at_idx ON webhook_deliveries (created_at);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS orders_notify_updated ON orders;
+DROP FUNCTION IF EXISTS notify_order_updated();
+
+ALTER TABLE orders DROP COLUMN IF EXISTS payload_hash;
+
+COMMIT;
@@ -0,0 +1,25 @@
+BEGIN;
+
+-- хеш содержимого заказа: по нему повторно полученный заказ с другим содержимым заменяет сохраненный.
+-- у сохраненных раньше заказов хеша нет, поэтому их первый повтор считается изменением
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS payload_hash TEXT;
+
+-- кэши заказов и сводок по покупателям есть на каждой реплике, поэтому об изменении заказа сообщается всем
+CREATE OR REPLACE FUNCTION notify_order_updated() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('order_updated', NEW.order_uid);
+  PERFORM pg_notify('customer_orders_changed', NEW.customer_id);
+  IF OLD.customer_id <> NEW.customer_id THEN
+    PERFORM pg_notify('customer_orders_changed', OLD.customer_id);
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER orders_notify_updated
+  AFTER UPDATE ON orders
+  FOR EACH ROW
+  WHEN (OLD.payload_hash IS DISTINCT FROM NEW.payload_hash)
+  EXECUTE FUNCTION notify_order_updated();
+
+COMMIT;
@@ -69,6 +69,15 @@ func (c *LRUCache) Set(key string, value []byte) {
 	}
 }
 
+func (c *LRUCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ele, ok := c.cache[key]; ok {
+		c.removeElement(ele)
+	}
+}
+
 func (c *LRUCache) removeOldest() {
 	ele := c.ll.Back()
 	if ele != nil {
@@ -88,6 +97,19 @@ func (c *LRUCache) Size() int {
 	return c.ll.Len()
 }
 
+func (c *LRUCache) Capacity() int {
+	return c.capacity
+}
+
+// Purge удаляет все записи
+func (c *LRUCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ll.Init()
+	clear(c.cache)
+}
+
+// Start периодически удаляет просроченные записи
 func (c *LRUCache) Start(ctx context.Context) error {
 	go func() {
 		ticker := time.NewTicker(janitorInterval)
@@ -102,7 +124,7 @@ func (c *LRUCache) Start(ctx context.Context) error {
 		}
 	}()
 
-	// for app adapter
+	// очистка идет в фоне до отмены ctx, запуск приложения не ждет ее
 	return nil
 }
 
@@ -72,6 +72,43 @@ func TestLRUCache(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:     "delete removes key",
+			capacity: 2,
+			ttl:      time.Second,
+			actions: func(c *cache.LRUCache, t *testing.T) {
+				c.Set("a", []byte("1"))
+				c.Set("b", []byte("2"))
+				c.Delete("a")
+				c.Delete("missing")
+				if _, ok := c.Get("a"); ok {
+					t.Errorf("expected key 'a' to be deleted")
+				}
+				if c.Size() != 1 {
+					t.Errorf("expected size=1, got %d", c.Size())
+				}
+			},
+		},
+		{
+			name:     "purge removes all keys",
+			capacity: 2,
+			ttl:      time.Second,
+			actions: func(c *cache.LRUCache, t *testing.T) {
+				c.Set("a", []byte("1"))
+				c.Set("b", []byte("2"))
+				c.Purge()
+				if _, ok := c.Get("a"); ok {
+					t.Errorf("expected key 'a' to be purged")
+				}
+				if c.Size() != 0 {
+					t.Errorf("expected size=0, got %d", c.Size())
+				}
+				c.Set("c", []byte("3"))
+				if v, ok := c.Get("c"); !ok || string(v) != "3" {
+					t.Errorf("expected c=3 after purge, got %v", v)
+				}
+			},
+		},
 		{
 			name:     "janitor removes expired",
 			capacity: 2,
@@ -0,0 +1,223 @@
+// Package fieldcrypt шифрует отдельные поля строк по схеме envelope encryption:
+// у каждой строки свой ключ данных (DEK), который хранится рядом со строкой,
+// зашифрованный одним из ключей связки (KEK). Для смены ключа связки достаточно
+// перешифровать ключи данных, сами поля не меняются.
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// KeySize длина ключей связки, ключей данных и ключа слепых индексов, AES-256
+const KeySize = 32
+
+var ErrUnknownKey = errors.New("unknown key id")
+
+// Keyring ключи шифрования ключей данных. Новые ключи данных шифруются активным ключом,
+// остальные ключи нужны, чтобы читать строки, еще не перешифрованные после ротации.
+type Keyring struct {
+	active string
+	keys   map[string]cipher.AEAD
+	// indexKey ключ HMAC для слепых индексов, не ротируется вместе с ключами связки,
+	// иначе пришлось бы пересчитать все индексы
+	indexKey []byte
+}
+
+// keyringFile формат файла связки, ключи в base64
+type keyringFile struct {
+	ActiveKey string            `json:"active_key"`
+	Keys      map[string]string `json:"keys"`
+	IndexKey  string            `json:"index_key"`
+}
+
+// LoadKeyring читает связку ключей из JSON файла вида
+// {"active_key":"k2","keys":{"k1":"<base64>","k2":"<base64>"},"index_key":"<base64>"}
+func LoadKeyring(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyring file: %w", err)
+	}
+	var f keyringFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse keyring file: %w", err)
+	}
+
+	keys := make(map[string][]byte, len(f.Keys))
+	for id, encoded := range f.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		keys[id] = key
+	}
+	indexKey, err := base64.StdEncoding.DecodeString(f.IndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("index key: %w", err)
+	}
+	return NewKeyring(f.ActiveKey, keys, indexKey)
+}
+
+func NewKeyring(active string, keys map[string][]byte, indexKey []byte) (*Keyring, error) {
+	if _, ok := keys[active]; !ok {
+		return nil, fmt.Errorf("active key %q is not in keyring", active)
+	}
+	if len(indexKey) != KeySize {
+		return nil, fmt.Errorf("index key must be %d bytes", KeySize)
+	}
+
+	k := &Keyring{active: active, keys: make(map[string]cipher.AEAD, len(keys)), indexKey: indexKey}
+	for id, key := range keys {
+		if id == "" {
+			return nil, errors.New("key id can not be empty")
+		}
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("key %q must be %d bytes", id, KeySize)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		k.keys[id] = aead
+	}
+	return k, nil
+}
+
+// ActiveKeyID ключ, которым шифруются новые ключи данных
+func (k *Keyring) ActiveKeyID() string {
+	return k.active
+}
+
+// NewDataKey создает ключ данных для новой строки
+func (k *Keyring) NewDataKey() (*DataKey, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	return k.wrap(key)
+}
+
+// OpenDataKey расшифровывает ключ данных строки
+func (k *Keyring) OpenDataKey(keyID string, wrapped []byte) (*DataKey, error) {
+	key, err := k.unwrap(keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &DataKey{KeyID: keyID, Wrapped: wrapped, aead: aead}, nil
+}
+
+// Rewrap перешифровывает ключ данных активным ключом связки
+func (k *Keyring) Rewrap(keyID string, wrapped []byte) (*DataKey, error) {
+	key, err := k.unwrap(keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	return k.wrap(key)
+}
+
+func (k *Keyring) wrap(key []byte) (*DataKey, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	// id ключа входит в aad, чтобы ключ данных нельзя было выдать за зашифрованный другим ключом связки
+	wrapped, err := seal(k.keys[k.active], key, []byte(k.active))
+	if err != nil {
+		return nil, err
+	}
+	return &DataKey{KeyID: k.active, Wrapped: wrapped, aead: aead}, nil
+}
+
+func (k *Keyring) unwrap(keyID string, wrapped []byte) ([]byte, error) {
+	kek, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+	key, err := open(kek, wrapped, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return key, nil
+}
+
+// BlindIndex детерминированный HMAC-SHA256 значения, позволяет искать по зашифрованному полю на точное совпадение.
+// field разделяет индексы разных полей, чтобы одинаковые значения в них не совпадали.
+func (k *Keyring) BlindIndex(field, value string) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// DataKey расшифрованный ключ данных строки
+type DataKey struct {
+	// KeyID ключ связки, которым зашифрован Wrapped
+	KeyID   string
+	Wrapped []byte
+	aead    cipher.AEAD
+}
+
+// Encrypt шифрует значение поля. aad привязывает шифротекст к строке и полю,
+// чтобы его нельзя было незаметно переставить в другую строку или другое поле.
+func (d *DataKey) Encrypt(plaintext string, aad string) (string, error) {
+	sealed, err := seal(d.aead, []byte(plaintext), []byte(aad))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (d *DataKey) Decrypt(ciphertext string, aad string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("invalid ciphertext: %w", err)
+	}
+	plaintext, err := open(d.aead, sealed, []byte(aad))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal возвращает nonce вместе с шифротекстом
+func seal(aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(aead cipher.AEAD, sealed, aad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return plaintext, nil
+}
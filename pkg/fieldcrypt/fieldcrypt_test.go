@@ -0,0 +1,133 @@
+package fieldcrypt_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/fieldcrypt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newKeyring(t *testing.T, active string, ids ...string) *fieldcrypt.Keyring {
+	t.Helper()
+	keys := make(map[string][]byte, len(ids))
+	for i, id := range ids {
+		keys[id] = bytes.Repeat([]byte{byte(i + 1)}, fieldcrypt.KeySize)
+	}
+	k, err := fieldcrypt.NewKeyring(active, keys, bytes.Repeat([]byte{0xff}, fieldcrypt.KeySize))
+	require.NoError(t, err)
+	return k
+}
+
+func TestDataKey_EncryptDecrypt(t *testing.T) {
+	k := newKeyring(t, "k1", "k1")
+
+	dk, err := k.NewDataKey()
+	require.NoError(t, err)
+	assert.Equal(t, "k1", dk.KeyID)
+
+	ciphertext, err := dk.Encrypt("+79991234567", "order1/phone")
+	require.NoError(t, err)
+	assert.NotContains(t, ciphertext, "79991234567")
+
+	opened, err := k.OpenDataKey(dk.KeyID, dk.Wrapped)
+	require.NoError(t, err)
+	plaintext, err := opened.Decrypt(ciphertext, "order1/phone")
+	require.NoError(t, err)
+	assert.Equal(t, "+79991234567", plaintext)
+
+	// шифротекст, перенесенный в другое поле или строку, не расшифровывается
+	_, err = opened.Decrypt(ciphertext, "order2/phone")
+	require.Error(t, err)
+
+	// одинаковые значения шифруются по-разному
+	again, err := dk.Encrypt("+79991234567", "order1/phone")
+	require.NoError(t, err)
+	assert.NotEqual(t, ciphertext, again)
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	old := newKeyring(t, "k1", "k1")
+	dk, err := old.NewDataKey()
+	require.NoError(t, err)
+	ciphertext, err := dk.Encrypt("secret", "aad")
+	require.NoError(t, err)
+
+	// после ротации старый ключ остается в связке для чтения
+	rotated := newKeyring(t, "k2", "k1", "k2")
+	opened, err := rotated.OpenDataKey("k1", dk.Wrapped)
+	require.NoError(t, err)
+	plaintext, err := opened.Decrypt(ciphertext, "aad")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", plaintext)
+
+	rewrapped, err := rotated.Rewrap("k1", dk.Wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, "k2", rewrapped.KeyID)
+
+	// поля не перешифровываются, меняется только ключ данных
+	opened, err = rotated.OpenDataKey(rewrapped.KeyID, rewrapped.Wrapped)
+	require.NoError(t, err)
+	plaintext, err = opened.Decrypt(ciphertext, "aad")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", plaintext)
+
+	// ключ данных нельзя выдать за зашифрованный другим ключом связки
+	_, err = rotated.OpenDataKey("k2", dk.Wrapped)
+	require.Error(t, err)
+
+	_, err = old.OpenDataKey("k2", rewrapped.Wrapped)
+	require.ErrorIs(t, err, fieldcrypt.ErrUnknownKey)
+}
+
+func TestKeyring_BlindIndex(t *testing.T) {
+	k := newKeyring(t, "k1", "k1")
+	rotated := newKeyring(t, "k2", "k1", "k2")
+
+	assert.Equal(t, k.BlindIndex("phone", "+79991234567"), k.BlindIndex("phone", "+79991234567"))
+	// индекс не зависит от ключей связки
+	assert.Equal(t, k.BlindIndex("phone", "+79991234567"), rotated.BlindIndex("phone", "+79991234567"))
+	assert.NotEqual(t, k.BlindIndex("phone", "+79991234567"), k.BlindIndex("phone", "+79991234568"))
+	assert.NotEqual(t, k.BlindIndex("phone", "a@b.c"), k.BlindIndex("email", "a@b.c"))
+}
+
+func TestLoadKeyring(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, fieldcrypt.KeySize))
+	short := base64.StdEncoding.EncodeToString([]byte("short"))
+	index := `"index_key":"` + key + `"`
+
+	testCases := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{name: "valid", content: `{"active_key":"k1","keys":{"k1":"` + key + `"},` + index + `}`},
+		{
+			name:    "unknown active key",
+			content: `{"active_key":"k2","keys":{"k1":"` + key + `"},` + index + `}`,
+			wantErr: true,
+		},
+		{name: "short key", content: `{"active_key":"k1","keys":{"k1":"` + short + `"},` + index + `}`, wantErr: true},
+		{name: "no index key", content: `{"active_key":"k1","keys":{"k1":"` + key + `"}}`, wantErr: true},
+		{name: "invalid json", content: `{`, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "keyring.json")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+
+			k, err := fieldcrypt.LoadKeyring(path)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "k1", k.ActiveKeyID())
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package problem
+
+import "net/http"
+
+// Code стабильный машиночитаемый код ошибки. Клиенты должны опираться на него, а не на тексты,
+// которые зависят от языка и могут меняться. Коды только добавляются, существующие не переименовываются.
+type Code string
+
+const (
+	CodeValidationFailed       Code = "validation_failed"
+	CodeEmptyBatch             Code = "empty_batch"
+	CodeIdempotencyKeyTooLong  Code = "idempotency_key_too_long"
+	CodeUnauthorized           Code = "unauthorized"
+	CodeForbidden              Code = "forbidden"
+	CodeNotFound               Code = "not_found"
+	CodeOrderNotFound          Code = "order_not_found"
+	CodeCustomerNotFound       Code = "customer_not_found"
+	CodeWebhookNotFound        Code = "webhook_not_found"
+	CodeAPIKeyNotFound         Code = "api_key_not_found"
+	CodeCacheNotFound          Code = "cache_not_found"
+	CodeMethodNotAllowed       Code = "method_not_allowed"
+	CodeNotAcceptable          Code = "not_acceptable"
+	CodeIdempotencyInProgress  Code = "idempotency_in_progress"
+	CodeRequestTooLarge        Code = "request_too_large"
+	CodeBatchTooLarge          Code = "batch_too_large"
+	CodeIdempotencyKeyMismatch Code = "idempotency_key_mismatch"
+	CodeRateLimited            Code = "rate_limited"
+	CodeInternal               Code = "internal_error"
+	CodeOverloaded             Code = "overloaded"
+)
+
+type entry struct {
+	status int
+	title  text
+	detail text
+}
+
+var catalogue = map[Code]entry{
+	CodeValidationFailed: {
+		status: http.StatusBadRequest,
+		title:  text{en: "Invalid request", ru: "Неверный запрос"},
+		detail: text{
+			en: "One or more fields are invalid, see errors.",
+			ru: "Одно или несколько полей заполнены неверно, подробности в errors.",
+		},
+	},
+	CodeEmptyBatch: {
+		status: http.StatusBadRequest,
+		title:  text{en: "Empty batch", ru: "Пустой пакет"},
+		detail: text{
+			en: "The batch must contain at least one order.",
+			ru: "Пакет должен содержать хотя бы один заказ.",
+		},
+	},
+	CodeIdempotencyKeyTooLong: {
+		status: http.StatusBadRequest,
+		title:  text{en: "Idempotency key is too long", ru: "Слишком длинный ключ идемпотентности"},
+		detail: text{
+			en: "Idempotency-Key must not exceed 255 characters.",
+			ru: "Idempotency-Key должен быть не длиннее 255 символов.",
+		},
+	},
+	CodeUnauthorized: {
+		status: http.StatusUnauthorized,
+		title:  text{en: "Unauthorized", ru: "Требуется аутентификация"},
+		detail: text{
+			en: "Provide a valid API key or bearer token.",
+			ru: "Передайте действующий API ключ или токен.",
+		},
+	},
+	CodeForbidden: {
+		status: http.StatusForbidden,
+		title:  text{en: "Forbidden", ru: "Доступ запрещен"},
+		detail: text{
+			en: "The credentials do not grant access to this resource.",
+			ru: "У клиента нет прав на этот ресурс.",
+		},
+	},
+	CodeNotFound: {
+		status: http.StatusNotFound,
+		title:  text{en: "Not found", ru: "Не найдено"},
+		detail: text{en: "The requested path does not exist.", ru: "Запрошенного пути не существует."},
+	},
+	CodeOrderNotFound: {
+		status: http.StatusNotFound,
+		title:  text{en: "Order not found", ru: "Заказ не найден"},
+		detail: text{en: "No order matches the request.", ru: "Нет заказа, подходящего под запрос."},
+	},
+	CodeCustomerNotFound: {
+		status: http.StatusNotFound,
+		title:  text{en: "Customer not found", ru: "Покупатель не найден"},
+		detail: text{en: "The customer has no orders.", ru: "У покупателя нет заказов."},
+	},
+	CodeWebhookNotFound: {
+		status: http.StatusNotFound,
+		title:  text{en: "Webhook not found", ru: "Вебхук не найден"},
+		detail: text{en: "No webhook with this id.", ru: "Нет вебхука с таким id."},
+	},
+	CodeAPIKeyNotFound: {
+		status: http.StatusNotFound,
+		title:  text{en: "API key not found", ru: "API ключ не найден"},
+		detail: text{en: "No active API key with this id.", ru: "Нет действующего API ключа с таким id."},
+	},
+	CodeCacheNotFound: {
+		status: http.StatusNotFound,
+		title:  text{en: "Cache not found", ru: "Кэш не найден"},
+		detail: text{en: "No cache with this name.", ru: "Нет кэша с таким именем."},
+	},
+	CodeMethodNotAllowed: {
+		status: http.StatusMethodNotAllowed,
+		title:  text{en: "Method not allowed", ru: "Метод не поддерживается"},
+		detail: text{en: "The path does not support this method.", ru: "Путь не поддерживает этот метод."},
+	},
+	CodeNotAcceptable: {
+		status: http.StatusNotAcceptable,
+		title:  text{en: "Not acceptable", ru: "Формат не поддерживается"},
+		detail: text{
+			en: "None of the formats in Accept is supported.",
+			ru: "Ни один из форматов в Accept не поддерживается.",
+		},
+	},
+	CodeIdempotencyInProgress: {
+		status: http.StatusConflict,
+		title:  text{en: "Request is in progress", ru: "Запрос еще выполняется"},
+		detail: text{
+			en: "A request with this idempotency key is still in progress, retry later.",
+			ru: "Запрос с этим ключом идемпотентности еще выполняется, повторите позже.",
+		},
+	},
+	CodeRequestTooLarge: {
+		status: http.StatusRequestEntityTooLarge,
+		title:  text{en: "Request is too large", ru: "Слишком большой запрос"},
+		detail: text{en: "The request body exceeds the size limit.", ru: "Тело запроса превышает допустимый размер."},
+	},
+	CodeBatchTooLarge: {
+		status: http.StatusRequestEntityTooLarge,
+		title:  text{en: "Batch is too large", ru: "Слишком большой пакет"},
+		detail: text{en: "The batch contains too many orders.", ru: "В пакете слишком много заказов."},
+	},
+	CodeIdempotencyKeyMismatch: {
+		status: http.StatusUnprocessableEntity,
+		title:  text{en: "Idempotency key reused", ru: "Ключ идемпотентности уже использован"},
+		detail: text{
+			en: "The idempotency key was used with another request.",
+			ru: "Ключ идемпотентности использован с другим запросом.",
+		},
+	},
+	CodeRateLimited: {
+		status: http.StatusTooManyRequests,
+		title:  text{en: "Too many requests", ru: "Слишком много запросов"},
+		detail: text{
+			en: "The rate limit is exceeded, retry after Retry-After seconds.",
+			ru: "Превышен лимит запросов, повторите через Retry-After секунд.",
+		},
+	},
+	CodeInternal: {
+		status: http.StatusInternalServerError,
+		title:  text{en: "Internal server error", ru: "Внутренняя ошибка сервера"},
+		detail: text{
+			en: "The request could not be processed, retry later.",
+			ru: "Не удалось обработать запрос, повторите позже.",
+		},
+	},
+	CodeOverloaded: {
+		status: http.StatusServiceUnavailable,
+		title:  text{en: "Service overloaded", ru: "Сервис перегружен"},
+		detail: text{
+			en: "The service is overloaded, retry after Retry-After seconds.",
+			ru: "Сервис перегружен, повторите через Retry-After секунд.",
+		},
+	},
+}
@@ -0,0 +1,82 @@
+package problem
+
+import (
+	"net/http"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Lang язык текстов ошибки
+type Lang string
+
+const (
+	LangEN Lang = "en"
+	LangRU Lang = "ru"
+)
+
+// первый язык используется, если клиент не передал Accept-Language или ни один из языков не поддерживается
+var (
+	langs   = []Lang{LangEN, LangRU}
+	matcher = language.NewMatcher([]language.Tag{language.English, language.Russian})
+)
+
+// Language выбирает язык ответа по заголовку Accept-Language
+func Language(r *http.Request) Lang {
+	tags, _, err := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	if err != nil || len(tags) == 0 {
+		return langs[0]
+	}
+	_, i, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return langs[0]
+	}
+	return langs[i]
+}
+
+type text struct {
+	en string
+	ru string
+}
+
+func (t text) in(lang Lang) string {
+	if lang == LangRU {
+		return t.ru
+	}
+	return t.en
+}
+
+// ruleMessages тексты нарушенных правил валидации, %s заменяется параметром правила
+var ruleMessages = map[string]text{
+	"required": {en: "field is required", ru: "обязательное поле"},
+	"e164":     {en: "must be a phone number in E.164 format", ru: "должен быть номером телефона в формате E.164"},
+	"email":    {en: "must be a valid email address", ru: "должен быть адресом электронной почты"},
+	"http_url": {en: "must be an HTTP or HTTPS URL", ru: "должен быть HTTP или HTTPS адресом"},
+	"gte":      {en: "must be greater than or equal to %s", ru: "должно быть не меньше %s"},
+	"gt":       {en: "must be greater than %s", ru: "должно быть больше %s"},
+	"min":      {en: "must be at least %s", ru: "должно быть не меньше %s"},
+	"max":      {en: "must be at most %s", ru: "должно быть не больше %s"},
+	"oneof":    {en: "must be one of the allowed values", ru: "должно быть одним из допустимых значений"},
+	"unique":   {en: "must be unique", ru: "значения не должны повторяться"},
+	"type":     {en: "unexpected value type: %s", ru: "неожиданный тип значения: %s"},
+	"json":     {en: "must be valid JSON", ru: "должно быть корректным JSON"},
+	"datetime": {en: "must be a date-time in RFC 3339 format", ru: "должно быть датой и временем в формате RFC 3339"},
+	"duration": {en: "must be a duration, for example 5s", ru: "должно быть длительностью, например 5s"},
+	"range":    {en: "is out of the allowed range", ru: "вне допустимого диапазона"},
+	"number":   {en: "must be a positive integer", ru: "должно быть положительным целым числом"},
+	"boolean":  {en: "must be true or false", ru: "должно быть true или false"},
+	"cursor":   {en: "is not a valid cursor", ru: "некорректный курсор"},
+	"fieldset": {en: "contains an unknown field", ru: "содержит неизвестное поле"},
+}
+
+// ruleMessage текст нарушенного правила на языке lang
+func ruleMessage(lang Lang, rule, param string) string {
+	msg, ok := ruleMessages[rule]
+	if !ok {
+		if lang == LangRU {
+			return "не выполнено правило '" + rule + "'"
+		}
+		return "failed on the '" + rule + "' rule"
+	}
+	return strings.Replace(msg.in(lang), "%s", param, 1)
+}
@@ -0,0 +1,110 @@
+// Package problem формирует ответы с ошибками в формате RFC 7807 (application/problem+json)
+// с кодами из каталога и текстами на языке из Accept-Language.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/utils"
+	chimw "github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	ContentType = "application/problem+json"
+	// TypeBase к нему добавляется код ошибки, по ссылке описан каждый код
+	TypeBase = "https://github.com/SergeyBogomolovv/l0-order-service/blob/main/docs/problems.md#"
+)
+
+// Problem описание ошибки по RFC 7807
+// swagger:model Problem
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// Instance ID запроса, по нему ошибку можно найти в логах
+	Instance string `json:"instance,omitempty"`
+	Code     Code   `json:"code"`
+	// Errors ошибки отдельных полей для validation_failed
+	Errors []utils.FieldError `json:"errors,omitempty"`
+
+	lang Lang
+}
+
+// New ошибка из каталога на языке запроса. Неизвестный код считается внутренней ошибкой.
+func New(r *http.Request, code Code) Problem {
+	e, ok := catalogue[code]
+	if !ok {
+		code, e = CodeInternal, catalogue[CodeInternal]
+	}
+
+	lang := Language(r)
+	return Problem{
+		Type:     TypeBase + string(code),
+		Title:    e.title.in(lang),
+		Status:   e.status,
+		Detail:   e.detail.in(lang),
+		Instance: chimw.GetReqID(r.Context()),
+		Code:     code,
+		lang:     lang,
+	}
+}
+
+// NewValidation ошибка validation_failed с ошибками разбора JSON и валидации err
+func NewValidation(r *http.Request, err error) Problem {
+	p := New(r, CodeValidationFailed)
+	p.Errors = FieldErrors(r, err)
+	return p
+}
+
+// NewFields ошибка validation_failed по ошибкам разбора параметров запроса: параметр -> нарушенное правило
+func NewFields(r *http.Request, fields map[string]string) Problem {
+	p := New(r, CodeValidationFailed)
+	for field, rule := range fields {
+		p.Errors = append(p.Errors, utils.FieldError{Field: field, Rule: rule, Message: ruleMessage(p.lang, rule, "")})
+	}
+	slices.SortFunc(p.Errors, func(a, b utils.FieldError) int {
+		return strings.Compare(a.Field, b.Field)
+	})
+	return p
+}
+
+// FieldErrors ошибки разбора JSON и валидации err с текстами на языке запроса
+func FieldErrors(r *http.Request, err error) []utils.FieldError {
+	lang := Language(r)
+	errs := utils.ValidationErrors(err)
+	for i, fe := range errs {
+		errs[i].Message = ruleMessage(lang, fe.Rule, fe.Param)
+	}
+	return errs
+}
+
+func (p Problem) Write(w http.ResponseWriter) error {
+	h := w.Header()
+	h.Set("Content-Type", ContentType)
+	if p.lang != "" {
+		h.Set("Content-Language", string(p.lang))
+		// язык выбран по Accept-Language, общий кэш не должен отдать этот ответ клиенту с другим языком
+		h.Add("Vary", "Accept-Language")
+	}
+	w.WriteHeader(p.Status)
+	return json.NewEncoder(w).Encode(p)
+}
+
+// Write отвечает ошибкой code из каталога
+func Write(w http.ResponseWriter, r *http.Request, code Code) error {
+	return New(r, code).Write(w)
+}
+
+// WriteValidation отвечает ошибкой validation_failed с ошибками разбора JSON и валидации err
+func WriteValidation(w http.ResponseWriter, r *http.Request, err error) error {
+	return NewValidation(r, err).Write(w)
+}
+
+// WriteFields отвечает ошибкой validation_failed по ошибкам разбора параметров запроса
+func WriteFields(w http.ResponseWriter, r *http.Request, fields map[string]string) error {
+	return NewFields(r, fields).Write(w)
+}
@@ -0,0 +1,101 @@
+package problem_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/problem"
+	chimw "github.com/go-chi/chi/v5/middleware"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLanguage(t *testing.T) {
+	testCases := []struct {
+		header string
+		want   problem.Lang
+	}{
+		{header: "", want: problem.LangEN},
+		{header: "ru-RU,ru;q=0.9,en;q=0.8", want: problem.LangRU},
+		{header: "en;q=0.5,ru;q=0.9", want: problem.LangRU},
+		{header: "de-DE", want: problem.LangEN},
+		{header: "invalid;;q", want: problem.LangEN},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.header, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept-Language", tc.header)
+			assert.Equal(t, tc.want, problem.Language(r))
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	h := chimw.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		problem.Write(w, r, problem.CodeOrderNotFound)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/order/1", nil)
+	r.Header.Set("Accept-Language", "ru")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, "ru", w.Header().Get("Content-Language"))
+	assert.Equal(t, "Accept-Language", w.Header().Get("Vary"))
+
+	var p problem.Problem
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+	assert.Equal(t, problem.TypeBase+"order_not_found", p.Type)
+	assert.Equal(t, "Заказ не найден", p.Title)
+	assert.Equal(t, http.StatusNotFound, p.Status)
+	assert.Equal(t, problem.CodeOrderNotFound, p.Code)
+	assert.NotEmpty(t, p.Instance)
+}
+
+func TestWriteValidation(t *testing.T) {
+	type request struct {
+		Phone string `json:"phone" validate:"required,e164"`
+		Age   int    `json:"age"   validate:"gte=18"`
+	}
+	err := validator.New().Struct(request{Phone: "123", Age: 10})
+	require.Error(t, err)
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set("Accept-Language", "ru")
+	w := httptest.NewRecorder()
+	problem.WriteValidation(w, r, err)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var p problem.Problem
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+	assert.Equal(t, problem.CodeValidationFailed, p.Code)
+	require.Len(t, p.Errors, 2)
+	assert.Equal(t, "Phone", p.Errors[0].Field)
+	assert.Equal(t, "должен быть номером телефона в формате E.164", p.Errors[0].Message)
+	assert.Equal(t, "должно быть не меньше 18", p.Errors[1].Message)
+}
+
+func TestWriteFields(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	w := httptest.NewRecorder()
+	problem.WriteFields(w, r, map[string]string{"sort": "oneof", "limit": "range"})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{
+		"type": "`+problem.TypeBase+`validation_failed",
+		"title": "Invalid request",
+		"status": 400,
+		"detail": "One or more fields are invalid, see errors.",
+		"code": "validation_failed",
+		"errors": [
+			{"field": "limit", "rule": "range", "message": "is out of the allowed range"},
+			{"field": "sort", "rule": "oneof", "message": "must be one of the allowed values"}
+		]
+	}`, w.Body.String())
+}
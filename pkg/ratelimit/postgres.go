@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// refilled токены корзины rl с учетом пополнения, $2 емкость, $3 скорость
+const refilled = "LEAST($2::float8, rl.tokens + EXTRACT(EPOCH FROM now() - rl.updated_at)::float8 * $3::float8)"
+
+// takeQuery пополняет корзину и забирает токен одним запросом. Если токена нет, строка не меняется
+// и запрос ничего не возвращает. Время берется из Postgres, поэтому часы реплик не важны.
+var takeQuery = `
+INSERT INTO rate_limits AS rl (key, tokens, updated_at, expires_at)
+VALUES ($1, $2::float8 - 1, now(), now() + make_interval(secs => 1 / $3::float8))
+ON CONFLICT (key) DO UPDATE SET
+	tokens = ` + refilled + ` - 1,
+	updated_at = now(),
+	expires_at = now() + make_interval(secs => ($2::float8 - ` + refilled + ` + 1) / $3::float8)
+WHERE ` + refilled + ` >= 1
+RETURNING tokens`
+
+var tokensQuery = "SELECT " + refilled + " FROM rate_limits rl WHERE rl.key = $1"
+
+// PostgresStore хранит корзины в таблице rate_limits, лимит общий для всех реплик
+type PostgresStore struct {
+	db *sqlx.DB
+}
+
+func NewPostgresStore(db *sqlx.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	var tokens float64
+	err := s.db.GetContext(ctx, &tokens, takeQuery, key, float64(limit.Burst), limit.Rate)
+	if err == nil {
+		return newResult(true, tokens, limit), nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return Result{}, fmt.Errorf("failed to take token: %w", err)
+	}
+
+	// токена нет, узнаем когда он появится
+	err = s.db.GetContext(ctx, &tokens, tokensQuery, key, float64(limit.Burst), limit.Rate)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return Result{}, fmt.Errorf("failed to get tokens: %w", err)
+	}
+	return newResult(false, tokens, limit), nil
+}
+
+// Start периодически удаляет полные корзины
+func (s *PostgresStore) Start(ctx context.Context) error {
+	go func() {
+		ticker := time.NewTicker(janitorInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				// не удаленные строки удалятся при следующем запуске
+				_, _ = s.db.ExecContext(ctx, "DELETE FROM rate_limits WHERE expires_at < now()")
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// очистка идет в фоне до отмены ctx, запуск приложения не ждет ее
+	return nil
+}
@@ -0,0 +1,57 @@
+// Package ratelimit ограничивает частоту запросов алгоритмом token bucket.
+// Корзины хранятся в памяти процесса, в Redis или в Postgres, в последних двух случаях
+// лимит общий для всех реплик.
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// Limit параметры корзины: Burst токенов помещается в корзину, Rate токенов в секунду добавляется
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+type Result struct {
+	Allowed bool
+	// Remaining сколько запросов можно сделать сразу
+	Remaining int
+	// RetryAfter через сколько появится токен, если запрос отклонен
+	RetryAfter time.Duration
+	// Reset через сколько корзина наполнится полностью
+	Reset time.Duration
+}
+
+// refill добавляет токены, накопившиеся за elapsed
+func refill(tokens float64, elapsed time.Duration, limit Limit) float64 {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(float64(limit.Burst), tokens+elapsed.Seconds()*limit.Rate)
+}
+
+// take забирает токен из корзины, если он есть, и возвращает оставшиеся токены
+func take(tokens float64) (float64, bool) {
+	if tokens >= 1 {
+		return tokens - 1, true
+	}
+	return tokens, false
+}
+
+func newResult(allowed bool, tokens float64, limit Limit) Result {
+	res := Result{
+		Allowed:   allowed,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     secondsToDuration((float64(limit.Burst) - tokens) / limit.Rate),
+	}
+	if !allowed {
+		res.RetryAfter = secondsToDuration((1 - tokens) / limit.Rate)
+	}
+	return res
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
@@ -0,0 +1,86 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SergeyBogomolovv/l0-order-service/pkg/ratelimit"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type store interface {
+	Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error)
+}
+
+func stores(t *testing.T) map[string]store {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return map[string]store{
+		"memory": ratelimit.NewMemoryStore(),
+		"redis":  ratelimit.NewRedisStore(client),
+	}
+}
+
+func TestStore_Take(t *testing.T) {
+	limit := ratelimit.Limit{Rate: 1, Burst: 2}
+
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			res, err := s.Take(ctx, "client", limit)
+			require.NoError(t, err)
+			assert.True(t, res.Allowed)
+			assert.Equal(t, 1, res.Remaining)
+
+			res, err = s.Take(ctx, "client", limit)
+			require.NoError(t, err)
+			assert.True(t, res.Allowed)
+			assert.Equal(t, 0, res.Remaining)
+
+			res, err = s.Take(ctx, "client", limit)
+			require.NoError(t, err)
+			assert.False(t, res.Allowed)
+			assert.Equal(t, 0, res.Remaining)
+			assert.Greater(t, res.RetryAfter, time.Duration(0))
+			assert.LessOrEqual(t, res.RetryAfter, time.Second)
+			assert.LessOrEqual(t, res.Reset, 2*time.Second)
+
+			// у другого клиента своя корзина
+			res, err = s.Take(ctx, "other", limit)
+			require.NoError(t, err)
+			assert.True(t, res.Allowed)
+		})
+	}
+}
+
+func TestStore_Refill(t *testing.T) {
+	limit := ratelimit.Limit{Rate: 100, Burst: 1}
+
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			res, err := s.Take(ctx, "client", limit)
+			require.NoError(t, err)
+			require.True(t, res.Allowed)
+
+			res, err = s.Take(ctx, "client", limit)
+			require.NoError(t, err)
+			require.False(t, res.Allowed)
+
+			time.Sleep(res.RetryAfter + 10*time.Millisecond)
+
+			res, err = s.Take(ctx, "client", limit)
+			require.NoError(t, err)
+			assert.True(t, res.Allowed)
+		})
+	}
+}
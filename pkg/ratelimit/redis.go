@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript пополняет корзину и забирает токен атомарно.
+// Время передает клиент, чтобы скрипт не зависел от версии Redis.
+// Ключ удаляется, когда корзина наполнится, полная корзина не отличается от новой.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) / 1000 * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+
+-- Redis обрезает дробные числа до целых, поэтому токены возвращаются строкой
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore хранит корзины в Redis, лимит общий для всех реплик
+type RedisStore struct {
+	client redis.Scripter
+	prefix string
+}
+
+func NewRedisStore(client redis.Scripter) *RedisStore {
+	return &RedisStore{client: client, prefix: "ratelimit:"}
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	res, err := takeScript.Run(ctx, s.client, []string{s.prefix + key},
+		limit.Rate, limit.Burst, time.Now().UnixMilli(),
+	).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to run rate limit script: %w", err)
+	}
+	if len(res) != 2 {
+		return Result{}, fmt.Errorf("unexpected rate limit script result: %v", res)
+	}
+
+	allowed, _ := res[0].(int64)
+	raw, _ := res[1].(string)
+	tokens, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid tokens in rate limit script result: %w", err)
+	}
+	return newResult(allowed == 1, tokens, limit), nil
+}
+
+// Start ничего не делает, ключи полных корзин удаляет сам Redis
+func (s *RedisStore) Start(context.Context) error {
+	return nil
+}
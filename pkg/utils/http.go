@@ -3,7 +3,9 @@ package utils
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -14,40 +16,84 @@ func WriteJSON(w http.ResponseWriter, payload any, code int) error {
 	return json.NewEncoder(w).Encode(payload)
 }
 
+// WriteRawJSON пишет уже закодированный JSON
+func WriteRawJSON(w http.ResponseWriter, data []byte, code int) error {
+	return WriteRaw(w, "application/json", data, code)
+}
+
+// WriteRaw пишет уже закодированный ответ в любом формате
+func WriteRaw(w http.ResponseWriter, contentType string, data []byte, code int) error {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(code)
+	_, err := w.Write(data)
+	return err
+}
+
 func DecodeBody(r *http.Request, v any) error {
 	return json.NewDecoder(r.Body).Decode(v)
 }
 
-// ValidationErrorResponse contains field-specific validation messages
-// swagger:model ValidationErrorResponse
-type ValidationErrorResponse struct {
-	Message string            `json:"message"`
-	Fields  map[string]string `json:"fields"`
+// FieldError описывает нарушение правила валидации в одном поле, Message на языке клиента заполняет пакет problem
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
 }
 
-func WriteValidationError(w http.ResponseWriter, err error) error {
-	res := ValidationErrorResponse{
-		Message: "invalid request",
-		Fields:  make(map[string]string),
+// ValidationErrors возвращает все ошибки валидации и разбора JSON.
+// Field - путь к полю в JSON (например items[0].chrt_id), для ошибок всего тела - body.
+func ValidationErrors(err error) []FieldError {
+	var res []FieldError
+	seen := make(map[string]struct{})
+
+	add := func(field, rule, param string) {
+		if _, ok := seen[field]; ok {
+			return
+		}
+		seen[field] = struct{}{}
+		res = append(res, FieldError{Field: field, Rule: rule, Param: param})
+	}
+
+	var (
+		typeErr   *json.UnmarshalTypeError
+		syntaxErr *json.SyntaxError
+		ve        validator.ValidationErrors
+	)
+	switch {
+	case errors.As(err, &typeErr):
+		add(typeErr.Field, "type", typeErr.Value)
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		add("body", "json", "")
+	case errors.Is(err, io.EOF):
+		add("body", "required", "")
 	}
 
-	var ve validator.ValidationErrors
+	// ошибки тегов идут после ошибки разбора, поэтому поле с неверным типом не попадет в ответ дважды
 	if errors.As(err, &ve) {
 		for _, err := range ve {
-			field := err.Field()
-			res.Fields[field] = err.Tag()
+			add(fieldPath(err), err.Tag(), err.Param())
 		}
 	}
 
-	return WriteJSON(w, res, http.StatusBadRequest)
+	return res
 }
 
-// ErrorResponse describes a standard error response
-// swagger:model ErrorResponse
-type ErrorResponse struct {
-	Message string `json:"message"`
+// ValidationFields возвращает ошибки валидации по полям.
+// Ключ - путь к полю в JSON, значение - нарушенное правило.
+func ValidationFields(err error) map[string]string {
+	fields := make(map[string]string)
+	for _, fe := range ValidationErrors(err) {
+		fields[fe.Field] = fe.Rule
+	}
+	return fields
 }
 
-func WriteError(w http.ResponseWriter, message string, code int) error {
-	return WriteJSON(w, ErrorResponse{Message: message}, code)
+// fieldPath возвращает путь к полю без имени корневой структуры
+func fieldPath(err validator.FieldError) string {
+	ns := err.Namespace()
+	if _, path, ok := strings.Cut(ns, "."); ok {
+		return path
+	}
+	return err.Field()
 }